@@ -43,7 +43,11 @@ onde os argumentos sempre têm precedência sobre as configurações do arquivo.
 
 Exemplo de uso:
   svndiff --config config.yaml
-  svndiff --urlA https://svn.example.com/branchA --revsA 123,124 --urlB https://svn.example.com/branchB --revsB 125 --output diff`,
+  svndiff --urlA https://svn.example.com/branchA --revsA 123,124 --urlB https://svn.example.com/branchB --revsB 125 --output diff
+  svndiff --urlA https://svn.example.com/branchA --urlB https://svn.example.com/branchB --issue PROJ-1234
+  svndiff --urlA https://svn.example.com/branchA --authorA alice --sinceA 2024-03-01 --urlB https://svn.example.com/branchB --revsB 125
+  svndiff --urlA https://svn.example.com/trunk --revsA HEAD --urlB ./meu-checkout
+  svndiff --urlA https://svn.example.com/branches/x --revsA HEAD --urlB git+file:///srv/espelho --git-layout`,
 	Version: getVersion(),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Carrega a configuração do Viper para a struct
@@ -73,29 +77,93 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "arquivo de configuração (padrão: config.yaml)")
 
 	// Flags para Branch A
-	rootCmd.PersistentFlags().String("urlA", "", "URL da Branch A")
+	rootCmd.PersistentFlags().String("urlA", "", "URL da Branch A, caminho de uma cópia de trabalho ou repositório Git (git+file:)")
 	rootCmd.PersistentFlags().StringSlice("revsA", []string{}, "revisões da Branch A (separadas por vírgula)")
+	rootCmd.PersistentFlags().String("authorA", "", "seleciona as revisões da Branch A deste autor")
+	rootCmd.PersistentFlags().String("sinceA", "", "seleciona as revisões da Branch A a partir desta data (AAAA-MM-DD)")
+	rootCmd.PersistentFlags().String("untilA", "", "seleciona as revisões da Branch A até esta data, inclusive (AAAA-MM-DD)")
 
 	// Flags para Branch B
-	rootCmd.PersistentFlags().String("urlB", "", "URL da Branch B")
+	rootCmd.PersistentFlags().String("urlB", "", "URL da Branch B, caminho de uma cópia de trabalho ou repositório Git (git+file:)")
 	rootCmd.PersistentFlags().StringSlice("revsB", []string{}, "revisões da Branch B (separadas por vírgula)")
+	rootCmd.PersistentFlags().String("authorB", "", "seleciona as revisões da Branch B deste autor")
+	rootCmd.PersistentFlags().String("sinceB", "", "seleciona as revisões da Branch B a partir desta data (AAAA-MM-DD)")
+	rootCmd.PersistentFlags().String("untilB", "", "seleciona as revisões da Branch B até esta data, inclusive (AAAA-MM-DD)")
 
 	// Flags de autenticação
 	rootCmd.PersistentFlags().String("user", "", "usuário SVN")
 	rootCmd.PersistentFlags().String("password", "", "senha SVN")
 
+	// Flags de descoberta de revisões por issue
+	rootCmd.PersistentFlags().String("issue", "", "chave da issue (ex: PROJ-1234) usada para descobrir as revisões pelo log")
+	rootCmd.PersistentFlags().String("issue-pattern", "", "expressão regular da issue; {key} é substituído pela chave")
+	rootCmd.PersistentFlags().Int("issue-limit", 0, "quantidade de entradas de log examinadas por branch (padrão: 500)")
+
+	// Flag de comparação com repositórios Git
+	rootCmd.PersistentFlags().Bool("git-layout", false, "deduz a ref do lado Git pela URL SVN do outro lado (trunk, branches/X, tags/T)")
+
+	// Flag de backend
+	rootCmd.PersistentFlags().String("backend", "auto", "acesso aos repositórios (auto, cli, native)")
+
 	// Flags de saída
-	rootCmd.PersistentFlags().String("output", "list", "formato de saída (list, diff, json)")
+	rootCmd.PersistentFlags().String("output", "list", "formato de saída (list, diff, json, ndjson, patch, git-patch, html, side-by-side, markdown, template, junit, sarif)")
+	rootCmd.PersistentFlags().String("out-dir", "", "grava um patch por revisão da Branch B neste diretório (patch, git-patch)")
+	rootCmd.PersistentFlags().String("template", "", "arquivo de modelo Go da saída template (.html usa html/template)")
+	rootCmd.PersistentFlags().Int("width", 0, "largura da saída side-by-side (padrão: largura do terminal)")
+	rootCmd.PersistentFlags().Bool("wrap", false, "quebra as linhas longas da saída side-by-side em vez de truncá-las")
+	rootCmd.PersistentFlags().Int("md-max-lines", 0, "linhas de diff por arquivo na saída markdown (padrão: 300)")
+	rootCmd.PersistentFlags().Int("md-max-bytes", 0, "tamanho máximo da saída markdown (padrão: 60000)")
+	rootCmd.PersistentFlags().StringSlice("include", nil, "avalia só os caminhos que casam com estes globs (junit, sarif)")
+	rootCmd.PersistentFlags().StringSlice("exclude", nil, "ignora os caminhos que casam com estes globs (junit, sarif)")
+	rootCmd.PersistentFlags().StringSlice("allow", nil, "diferenças esperadas, que não contam como falha (junit, sarif)")
+	rootCmd.PersistentFlags().Bool("renames", false, "detecta renomeações e cópias pelo histórico de cópias da Branch B (list, json, ndjson, template e junit)")
+	rootCmd.PersistentFlags().Int("similarity", 0, "similaridade mínima (%) para parear remoções e adições pelo conteúdo (0 = desativado)")
+	rootCmd.PersistentFlags().Bool("binary-details", false, "relata o tamanho e o SHA-256 de cada lado dos arquivos binários alterados (exceto em patch e git-patch)")
+	rootCmd.PersistentFlags().Bool("image-size", false, "relata também as dimensões das imagens binárias alteradas")
+	rootCmd.PersistentFlags().Bool("ignore-space-change", false, "ignora mudanças na quantidade de espaços, como reindentação")
+	rootCmd.PersistentFlags().Bool("ignore-all-space", false, "ignora todos os espaços ao comparar as linhas")
+	rootCmd.PersistentFlags().Bool("ignore-eol-style", false, "ignora diferenças de fim de linha (CRLF e LF)")
+	rootCmd.PersistentFlags().Bool("ignore-blank-lines", false, "ignora linhas em branco adicionadas ou removidas")
+	rootCmd.PersistentFlags().Bool("expand-dirs", false, "lista o conteúdo dos diretórios adicionados e removidos")
 	rootCmd.PersistentFlags().Bool("summarize", true, "mostrar apenas resumo das diferenças")
 
 	// Vincula flags ao Viper
 	_ = viper.BindPFlag("branchA.url", rootCmd.PersistentFlags().Lookup("urlA"))
 	_ = viper.BindPFlag("branchA.revisions", rootCmd.PersistentFlags().Lookup("revsA"))
+	_ = viper.BindPFlag("branchA.author", rootCmd.PersistentFlags().Lookup("authorA"))
+	_ = viper.BindPFlag("branchA.since", rootCmd.PersistentFlags().Lookup("sinceA"))
+	_ = viper.BindPFlag("branchA.until", rootCmd.PersistentFlags().Lookup("untilA"))
 	_ = viper.BindPFlag("branchB.url", rootCmd.PersistentFlags().Lookup("urlB"))
 	_ = viper.BindPFlag("branchB.revisions", rootCmd.PersistentFlags().Lookup("revsB"))
+	_ = viper.BindPFlag("branchB.author", rootCmd.PersistentFlags().Lookup("authorB"))
+	_ = viper.BindPFlag("branchB.since", rootCmd.PersistentFlags().Lookup("sinceB"))
+	_ = viper.BindPFlag("branchB.until", rootCmd.PersistentFlags().Lookup("untilB"))
 	_ = viper.BindPFlag("auth.user", rootCmd.PersistentFlags().Lookup("user"))
 	_ = viper.BindPFlag("auth.password", rootCmd.PersistentFlags().Lookup("password"))
+	_ = viper.BindPFlag("issue.key", rootCmd.PersistentFlags().Lookup("issue"))
+	_ = viper.BindPFlag("issue.pattern", rootCmd.PersistentFlags().Lookup("issue-pattern"))
+	_ = viper.BindPFlag("issue.limit", rootCmd.PersistentFlags().Lookup("issue-limit"))
+	_ = viper.BindPFlag("git.layout", rootCmd.PersistentFlags().Lookup("git-layout"))
+	_ = viper.BindPFlag("backend", rootCmd.PersistentFlags().Lookup("backend"))
 	_ = viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output"))
+	_ = viper.BindPFlag("outDir", rootCmd.PersistentFlags().Lookup("out-dir"))
+	_ = viper.BindPFlag("template", rootCmd.PersistentFlags().Lookup("template"))
+	_ = viper.BindPFlag("sideBySide.width", rootCmd.PersistentFlags().Lookup("width"))
+	_ = viper.BindPFlag("sideBySide.wrap", rootCmd.PersistentFlags().Lookup("wrap"))
+	_ = viper.BindPFlag("markdown.maxFileLines", rootCmd.PersistentFlags().Lookup("md-max-lines"))
+	_ = viper.BindPFlag("markdown.maxBytes", rootCmd.PersistentFlags().Lookup("md-max-bytes"))
+	_ = viper.BindPFlag("filter.include", rootCmd.PersistentFlags().Lookup("include"))
+	_ = viper.BindPFlag("filter.exclude", rootCmd.PersistentFlags().Lookup("exclude"))
+	_ = viper.BindPFlag("filter.allow", rootCmd.PersistentFlags().Lookup("allow"))
+	_ = viper.BindPFlag("renames.detect", rootCmd.PersistentFlags().Lookup("renames"))
+	_ = viper.BindPFlag("renames.similarity", rootCmd.PersistentFlags().Lookup("similarity"))
+	_ = viper.BindPFlag("binary.details", rootCmd.PersistentFlags().Lookup("binary-details"))
+	_ = viper.BindPFlag("binary.imageSize", rootCmd.PersistentFlags().Lookup("image-size"))
+	_ = viper.BindPFlag("ignore.spaceChange", rootCmd.PersistentFlags().Lookup("ignore-space-change"))
+	_ = viper.BindPFlag("ignore.allSpace", rootCmd.PersistentFlags().Lookup("ignore-all-space"))
+	_ = viper.BindPFlag("ignore.eolStyle", rootCmd.PersistentFlags().Lookup("ignore-eol-style"))
+	_ = viper.BindPFlag("ignore.blankLines", rootCmd.PersistentFlags().Lookup("ignore-blank-lines"))
+	_ = viper.BindPFlag("expandDirs", rootCmd.PersistentFlags().Lookup("expand-dirs"))
 	_ = viper.BindPFlag("summarize", rootCmd.PersistentFlags().Lookup("summarize"))
 }
 
@@ -130,6 +198,7 @@ func initConfig() {
 	}
 
 	// Define valores padrão
+	viper.SetDefault("backend", "auto")
 	viper.SetDefault("output", "list")
 	viper.SetDefault("summarize", true)
 }
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/dav"
+	"svndiff/internal/svn/dump"
+	"svndiff/internal/svn/fsfs"
+	"svndiff/internal/svn/git"
+	"svndiff/internal/svn/rasvn"
+	"svndiff/internal/svn/wc"
+	"svndiff/pkg/config"
+)
+
+// newBackend escolhe a implementação usada para acessar os repositórios.
+// No modo auto o comando svn é preferido quando instalado; sem ele, os
+// clientes nativos são usados se suportarem as URLs das duas branches.
+// URLs dump://, repositórios Git e cópias de trabalho só são lidos pelo
+// backend nativo.
+func newBackend(cfg *config.Config) svn.Backend {
+	native := svn.NewRepositoryBackend(openRepository(&cfg.Auth))
+
+	switch cfg.Backend {
+	case "native":
+		return native
+	case "cli":
+		return svn.NewClient(&cfg.Auth)
+	}
+
+	if isDump(cfg.BranchA.URL) || isDump(cfg.BranchB.URL) ||
+		cfg.BranchA.IsLocalPath() || cfg.BranchB.IsLocalPath() ||
+		cfg.BranchA.IsGit() || cfg.BranchB.IsGit() {
+		return native
+	}
+	if _, err := exec.LookPath("svn"); err != nil &&
+		nativeSupported(cfg.BranchA.URL) && nativeSupported(cfg.BranchB.URL) {
+		return native
+	}
+	return svn.NewClient(&cfg.Auth)
+}
+
+// openRepository retorna o Opener dos clientes nativos, escolhido pelo
+// esquema da URL
+func openRepository(auth *config.AuthConfig) svn.Opener {
+	return func(rawURL string) (svn.Repository, error) {
+		if isDump(rawURL) {
+			return dump.Open(rawURL)
+		}
+		if isGit(rawURL) {
+			return git.Open(rawURL)
+		}
+		if !strings.Contains(rawURL, "://") {
+			return wc.Open(rawURL)
+		}
+		switch scheme(rawURL) {
+		case "svn":
+			return rasvn.Dial(rawURL, auth.User, auth.Password)
+		case "http", "https":
+			return dav.Open(rawURL, auth.User, auth.Password)
+		case "file":
+			return fsfs.Open(rawURL)
+		default:
+			return nil, fmt.Errorf("esquema não suportado pelo backend nativo: %s", rawURL)
+		}
+	}
+}
+
+// nativeSupported indica se a URL pode ser acessada pelos clientes nativos
+func nativeSupported(rawURL string) bool {
+	if isDump(rawURL) || isGit(rawURL) || !strings.Contains(rawURL, "://") {
+		return true
+	}
+	switch scheme(rawURL) {
+	case "svn", "http", "https", "file":
+		return true
+	}
+	return false
+}
+
+// isDump indica se a URL aponta para um arquivo de dump. O caminho não é
+// uma URL válida em todos os casos, por isso não passa por url.Parse.
+func isDump(rawURL string) bool {
+	return strings.HasPrefix(rawURL, dump.Scheme)
+}
+
+// isGit indica se a URL aponta para um repositório Git local
+func isGit(rawURL string) bool {
+	return strings.HasPrefix(rawURL, git.Scheme)
+}
+
+// scheme retorna o esquema da URL, ou "" se inválida
+func scheme(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme
+}
@@ -0,0 +1,302 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"  // registra o formato para image.DecodeConfig
+	_ "image/jpeg" // registra o formato para image.DecodeConfig
+	_ "image/png"  // registra o formato para image.DecodeConfig
+	"io"
+	"iter"
+	"strings"
+
+	"svndiff/internal/svn"
+	"svndiff/pkg/config"
+)
+
+// BinaryInfo descreve os dois lados de um arquivo binário alterado
+type BinaryInfo struct {
+	MimeType string `json:"mimeType,omitempty"`
+	// Old e New são nil do lado em que o arquivo não existe ou quando o
+	// backend não lê arquivos
+	Old *BlobInfo `json:"old,omitempty"`
+	New *BlobInfo `json:"new,omitempty"`
+}
+
+// BlobInfo descreve o conteúdo de um lado do arquivo binário
+type BlobInfo struct {
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Width e Height são as dimensões das imagens, com imageSize
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+}
+
+// sniffedMimeType é o tipo dos binários sem svn:mime-type, o mesmo que os
+// backends nativos informam
+const sniffedMimeType = "application/octet-stream"
+
+// binaryInfo lê os dois lados do binário em path, relativo à branch
+func (d *Differ) binaryInfo(path, mimeType string) (*BinaryInfo, error) {
+	info := &BinaryInfo{MimeType: mimeType}
+	reader, ok := d.svnClient.(svn.FileReader)
+	if !ok {
+		return info, nil
+	}
+	sides, err := d.readSides(reader, path)
+	if err != nil {
+		return nil, err
+	}
+	return sides.info(mimeType, d.config.Binary.ImageSize), nil
+}
+
+// sniffBinary examina o conteúdo dos dois lados de path, que o diff trata
+// como texto, e retorna os detalhes quando um deles é binário. O svn só
+// marca como binários os arquivos com svn:mime-type; os backends nativos já
+// examinam o conteúdo, como aqui, e não precisam da nova leitura.
+func (d *Differ) sniffBinary(path string) (*BinaryInfo, error) {
+	reader, ok := d.svnClient.(svn.FileReader)
+	if _, native := d.svnClient.(*svn.RepositoryBackend); !ok || native {
+		return nil, nil
+	}
+	sides, err := d.readSides(reader, path)
+	if err != nil {
+		return nil, err
+	}
+	for _, content := range sides {
+		if content != nil && svn.IsBinaryContent(content) {
+			return sides.info(sniffedMimeType, d.config.Binary.ImageSize), nil
+		}
+	}
+	return nil, nil
+}
+
+// fileSides guarda o conteúdo das Branches A e B, nil do lado em que o
+// arquivo não existe
+type fileSides [2][]byte
+
+// readSides lê os dois lados de path, relativo à branch
+func (d *Differ) readSides(reader svn.FileReader, path string) (fileSides, error) {
+	var sides fileSides
+	for i, side := range []struct {
+		name   string
+		branch *config.BranchConfig
+	}{{"A", &d.config.BranchA}, {"B", &d.config.BranchB}} {
+		content, exists, err := reader.ReadFile(side.branch, path)
+		if err != nil {
+			return sides, fmt.Errorf("erro ao ler %s na Branch %s: %w", path, side.name, err)
+		}
+		if exists {
+			sides[i] = append([]byte{}, content...)
+		}
+	}
+	return sides, nil
+}
+
+// info descreve os dois lados
+func (s fileSides) info(mimeType string, imageSize bool) *BinaryInfo {
+	info := &BinaryInfo{MimeType: mimeType}
+	if s[0] != nil {
+		info.Old = blobInfo(s[0], imageSize)
+	}
+	if s[1] != nil {
+		info.New = blobInfo(s[1], imageSize)
+	}
+	return info
+}
+
+// blobInfo calcula o tamanho, o SHA-256 e, com imageSize, as dimensões do
+// conteúdo, quando ele é uma imagem em formato conhecido
+func blobInfo(content []byte, imageSize bool) *BlobInfo {
+	sum := sha256.Sum256(content)
+	blob := &BlobInfo{Size: len(content), SHA256: hex.EncodeToString(sum[:])}
+	if imageSize {
+		if cfg, _, err := image.DecodeConfig(bytes.NewReader(content)); err == nil {
+			blob.Width, blob.Height = cfg.Width, cfg.Height
+		}
+	}
+	return blob
+}
+
+// lines descreve o binário em linhas de texto, do lado A para o lado B
+func (b *BinaryInfo) lines() []string {
+	if b.Old == nil && b.New == nil {
+		return nil
+	}
+	lines := []string{
+		fmt.Sprintf("Tamanho: %s → %s", blobText(b.Old, blobSize), blobText(b.New, blobSize)),
+		"SHA-256 A: " + blobText(b.Old, func(blob *BlobInfo) string { return blob.SHA256 }),
+		"SHA-256 B: " + blobText(b.New, func(blob *BlobInfo) string { return blob.SHA256 }),
+	}
+	if (b.Old != nil && b.Old.Width > 0) || (b.New != nil && b.New.Width > 0) {
+		lines = append(lines, fmt.Sprintf("Dimensões: %s → %s", blobText(b.Old, blobDimensions), blobText(b.New, blobDimensions)))
+	}
+	return lines
+}
+
+// blobText formata um atributo do lado, ou marca o lado inexistente
+func blobText(blob *BlobInfo, format func(*BlobInfo) string) string {
+	if blob == nil {
+		return "(inexistente)"
+	}
+	return format(blob)
+}
+
+func blobSize(blob *BlobInfo) string {
+	return fmt.Sprintf("%d bytes", blob.Size)
+}
+
+func blobDimensions(blob *BlobInfo) string {
+	if blob.Width == 0 {
+		return "?"
+	}
+	return fmt.Sprintf("%dx%d", blob.Width, blob.Height)
+}
+
+// withBinaries completa as mudanças de conteúdo de seq que o diff completo
+// marca como binárias, ou cujo conteúdo é binário, com os detalhes de cada
+// lado. O índice do diff completo é lido na primeira mudança de arquivo
+// encontrada.
+func (d *Differ) withBinaries(seq iter.Seq2[FileChange, error]) iter.Seq2[FileChange, error] {
+	if !d.config.Binary.Enabled() {
+		return seq
+	}
+	return func(yield func(FileChange, error) bool) {
+		for change, err := range seq {
+			if err == nil && change.Status != propertyChanged && change.Kind != string(svn.NodeDir) {
+				var index *diffIndex
+				if index, err = d.fullIndex(); err == nil {
+					path := branchPath(d.config.BranchA.URL, change.Path)
+					if mimeType, ok := index.mimeTypes[path]; ok {
+						change.Binary, err = d.binaryInfo(path, mimeType)
+					} else {
+						change.Binary, err = d.sniffBinary(path)
+					}
+				}
+			}
+			if !yield(change, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// binaryDescriber acrescenta os detalhes dos binários ao diff lido de src,
+// linha a linha. Os arquivos que o diff mostra como texto, mas cujo conteúdo
+// é binário, passam a ser mostrados como binários: o cabeçalho ---/+++ fica
+// retido até o primeiro hunk, que decide se o arquivo é examinado.
+type binaryDescriber struct {
+	d        *Differ
+	src      io.ReadCloser
+	br       *bufio.Reader
+	path     string
+	binary   bool
+	inHeader bool
+	header   []string
+	skipping bool
+	pending  []byte
+	err      error
+}
+
+func (b *binaryDescriber) Read(p []byte) (int, error) {
+	for len(b.pending) == 0 {
+		if b.err != nil {
+			return 0, b.err
+		}
+		line, err := b.br.ReadString('\n')
+		if err != nil {
+			b.pending = append(b.pending, strings.Join(b.flushHeader(), "")...)
+			b.pending = append(b.pending, line...)
+			b.err = err
+			continue
+		}
+		lines, err := b.describe(line)
+		if err != nil {
+			b.err = err
+		}
+		for _, out := range lines {
+			b.pending = append(b.pending, out...)
+		}
+	}
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+// describe acompanha a seção atual e retorna as linhas a escrever no lugar
+// de raw, com a quebra de linha
+func (b *binaryDescriber) describe(raw string) ([]string, error) {
+	line := strings.TrimSuffix(strings.TrimSuffix(raw, "\n"), "\r")
+	if b.skipping {
+		if isHunkLine(line) {
+			return nil, nil
+		}
+		b.skipping = false
+	}
+	if b.inHeader {
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			b.header = append(b.header, raw)
+			return nil, nil
+		case strings.HasPrefix(line, "@@ ") && len(b.header) > 0:
+			b.inHeader = false
+			info, err := b.d.sniffBinary(branchPath(b.d.config.BranchA.URL, b.path))
+			if err != nil || info == nil {
+				return append(b.flushHeader(), raw), err
+			}
+			b.header, b.skipping = nil, true
+			out := []string{
+				"Cannot display: file marked as a binary type.\n",
+				"svn:mime-type = " + info.MimeType + "\n",
+			}
+			for _, extra := range info.lines() {
+				out = append(out, extra+"\n")
+			}
+			return out, nil
+		}
+	}
+	out := append(b.flushHeader(), raw)
+	if b.inHeader && !strings.HasPrefix(line, "=") {
+		b.inHeader = false
+	}
+	switch {
+	case strings.HasPrefix(line, "Index: "):
+		b.path, b.binary, b.inHeader = strings.TrimPrefix(line, "Index: "), false, true
+	case strings.HasPrefix(line, "Cannot display: file marked as a binary type."):
+		b.binary, b.inHeader = true, false
+	case b.binary && strings.HasPrefix(line, "svn:mime-type = "):
+		b.binary = false
+		path := branchPath(b.d.config.BranchA.URL, b.path)
+		info, err := b.d.binaryInfo(path, strings.TrimPrefix(line, "svn:mime-type = "))
+		if err != nil {
+			return out, err
+		}
+		for _, extra := range info.lines() {
+			out = append(out, extra+"\n")
+		}
+	}
+	return out, nil
+}
+
+// flushHeader devolve o cabeçalho retido e encerra a retenção
+func (b *binaryDescriber) flushHeader() []string {
+	header := b.header
+	b.header = nil
+	return header
+}
+
+// isHunkLine informa se line pertence a um hunk do diff unificado
+func isHunkLine(line string) bool {
+	if strings.HasPrefix(line, "@@ ") {
+		return true
+	}
+	return line != "" && strings.ContainsRune(" +-\\", rune(line[0]))
+}
+
+func (b *binaryDescriber) Close() error {
+	return b.src.Close()
+}
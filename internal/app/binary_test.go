@@ -0,0 +1,296 @@
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"svndiff/internal/patch"
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/dump"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+// binaryDiffer compara um repositório em que logo.png cresce de 1x1 para
+// 3x2 e a.txt, texto, também muda
+func binaryDiffer(t *testing.T) (*Differ, []byte, []byte) {
+	t.Helper()
+	oldPNG, newPNG := encodePNG(t, 1, 1), encodePNG(t, 3, 2)
+	dumpFile := filepath.Join(t.TempDir(), "repo.dump")
+	history := [][]svntest.DumpNode{
+		{
+			{Path: "trunk", Kind: "dir", Action: "add"},
+			{Path: "trunk/logo.png", Kind: "file", Action: "add", Content: string(oldPNG), Props: []string{"svn:mime-type", "image/png"}},
+			{Path: "trunk/a.txt", Kind: "file", Action: "add", Content: "a\n"},
+		},
+		{
+			{Path: "trunk/logo.png", Kind: "file", Action: "change", Content: string(newPNG), Props: []string{"svn:mime-type", "image/png"}},
+			{Path: "trunk/a.txt", Kind: "file", Action: "change", Content: "A\n"},
+		},
+	}
+	if err := os.WriteFile(dumpFile, svntest.WriteDump(history), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	url := dump.Scheme + filepath.ToSlash(dumpFile) + "/trunk"
+	differ := &Differ{
+		config: &config.Config{
+			BranchA: config.BranchConfig{URL: url, Revisions: []string{"1"}},
+			BranchB: config.BranchConfig{URL: url, Revisions: []string{"2"}},
+			Binary:  config.BinaryConfig{Details: true, ImageSize: true},
+		},
+		svnClient: svn.NewRepositoryBackend(func(url string) (svn.Repository, error) { return dump.Open(url) }),
+	}
+	return differ, oldPNG, newPNG
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDiffer_withBinaries(t *testing.T) {
+	differ, oldPNG, newPNG := binaryDiffer(t)
+	r, err := differ.openDiff(true)
+	if err != nil {
+		t.Fatalf("openDiff() error = %v", err)
+	}
+	defer r.Close()
+
+	got := make(map[string]FileChange)
+	for change, err := range differ.withBinaries(differ.changes(r)) {
+		if err != nil {
+			t.Fatalf("withBinaries() error = %v", err)
+		}
+		got[change.Path] = change
+	}
+
+	want := &BinaryInfo{
+		MimeType: "image/png",
+		Old:      &BlobInfo{Size: len(oldPNG), SHA256: sha256Hex(oldPNG), Width: 1, Height: 1},
+		New:      &BlobInfo{Size: len(newPNG), SHA256: sha256Hex(newPNG), Width: 3, Height: 2},
+	}
+	if logo := got["logo.png"]; !reflect.DeepEqual(logo.Binary, want) {
+		t.Errorf("logo.png Binary = %+v, want %+v", logo.Binary, want)
+	}
+	if a := got["a.txt"]; a.Status != "Modified" || a.Binary != nil {
+		t.Errorf("a.txt = %+v, want texto sem Binary", a)
+	}
+
+	// Os tipos dos binários e as propriedades vêm do mesmo índice
+	backend := &countingBackend{Backend: differ.svnClient}
+	differ.svnClient = backend
+	r, err = differ.openDiff(true)
+	if err != nil {
+		t.Fatalf("openDiff() error = %v", err)
+	}
+	defer r.Close()
+	for _, err := range differ.withProps(differ.withBinaries(differ.changes(r))) {
+		if err != nil {
+			t.Fatalf("withProps() error = %v", err)
+		}
+	}
+	if backend.fullDiffs != 0 {
+		t.Errorf("withProps() e withBinaries() releram o diff completo %d vez(es)", backend.fullDiffs)
+	}
+}
+
+func TestDiffer_openFullDiff(t *testing.T) {
+	differ, oldPNG, newPNG := binaryDiffer(t)
+	r, err := differ.openFullDiff()
+	if err != nil {
+		t.Fatalf("openFullDiff() error = %v", err)
+	}
+	defer r.Close()
+
+	files, err := patch.Parse(r)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	sections := make(map[string]*patch.File)
+	for _, f := range files {
+		sections[f.Path] = f
+	}
+
+	want := []string{
+		"Tamanho: " + strconv.Itoa(len(oldPNG)) + " bytes → " + strconv.Itoa(len(newPNG)) + " bytes",
+		"SHA-256 A: " + sha256Hex(oldPNG),
+		"SHA-256 B: " + sha256Hex(newPNG),
+		"Dimensões: 1x1 → 3x2",
+	}
+	if logo := sections["logo.png"]; logo == nil || !logo.Binary || !reflect.DeepEqual(logo.Details, want) {
+		t.Errorf("logo.png = %+v, want Details %q", logo, want)
+	}
+	if a := sections["a.txt"]; a == nil || len(a.Hunks) != 1 || a.Details != nil {
+		t.Errorf("a.txt = %+v, want um hunk sem Details", a)
+	}
+}
+
+func TestBinaryInfo_lines(t *testing.T) {
+	info := &BinaryInfo{New: &BlobInfo{Size: 4, SHA256: "abcd"}}
+	want := []string{
+		"Tamanho: (inexistente) → 4 bytes",
+		"SHA-256 A: (inexistente)",
+		"SHA-256 B: abcd",
+	}
+	if got := info.lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("lines() = %q, want %q", got, want)
+	}
+	if got := (&BinaryInfo{MimeType: "application/octet-stream"}).lines(); got != nil {
+		t.Errorf("lines() sem conteúdo = %q, want nil", got)
+	}
+}
+
+// cliBinaryDiffer compara, pelo cliente svn, um diff em que logo.bin não tem
+// svn:mime-type e por isso chega como texto. O comando svn é um script que
+// responde ao diff e ao cat com as saídas preparadas.
+func cliBinaryDiffer(t *testing.T) (*Differ, []byte, []byte) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh não encontrado")
+	}
+	oldBin, newBin := []byte("\x00\x01antigo"), []byte("\x00\x02novo\x00")
+	dir := t.TempDir()
+	files := map[string]string{
+		"summary.xml": summaryXML(
+			"M       svn://fake/trunk/logo.bin",
+			"M       svn://fake/trunk/a.txt",
+		),
+		"diff.txt": "Index: logo.bin\n" +
+			"===================================================================\n" +
+			"--- logo.bin\t(revision 1)\n" +
+			"+++ logo.bin\t(revision 2)\n" +
+			"@@ -1 +1 @@\n" +
+			"-" + string(oldBin) + "\n" +
+			"\\ No newline at end of file\n" +
+			"+" + string(newBin) + "\n" +
+			"\\ No newline at end of file\n" +
+			"Index: a.txt\n" +
+			"===================================================================\n" +
+			"--- a.txt\t(revision 1)\n" +
+			"+++ a.txt\t(revision 2)\n" +
+			"@@ -1 +1 @@\n" +
+			"-a\n" +
+			"+A\n",
+		"A/logo.bin": string(oldBin),
+		"B/logo.bin": string(newBin),
+		"A/a.txt":    "a\n",
+		"B/a.txt":    "A\n",
+		"svn": `#!/bin/sh
+dir=$(dirname "$0")
+for last; do :; done
+case "$1" in
+diff)
+	case " $* " in
+	*" --summarize "*) cat "$dir/summary.xml" ;;
+	*) cat "$dir/diff.txt" ;;
+	esac ;;
+cat)
+	side=B
+	case "$last" in *@1) side=A ;; esac
+	file=${last##*/}
+	cat "$dir/$side/${file%@*}" ;;
+*)
+	exit 1 ;;
+esac
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	differ := &Differ{
+		config: &config.Config{
+			BranchA: config.BranchConfig{URL: "svn://fake/trunk", Revisions: []string{"1"}},
+			BranchB: config.BranchConfig{URL: "svn://fake/trunk", Revisions: []string{"2"}},
+			Binary:  config.BinaryConfig{Details: true},
+		},
+		svnClient: svn.NewClient(&config.AuthConfig{}),
+	}
+	return differ, oldBin, newBin
+}
+
+func TestDiffer_withBinaries_WithoutMimeType(t *testing.T) {
+	differ, oldBin, newBin := cliBinaryDiffer(t)
+	r, err := differ.openDiff(true)
+	if err != nil {
+		t.Fatalf("openDiff() error = %v", err)
+	}
+	defer r.Close()
+
+	got := make(map[string]FileChange)
+	for change, err := range differ.withBinaries(differ.changes(r)) {
+		if err != nil {
+			t.Fatalf("withBinaries() error = %v", err)
+		}
+		got[branchPath(differ.config.BranchA.URL, change.Path)] = change
+	}
+
+	want := &BinaryInfo{
+		MimeType: "application/octet-stream",
+		Old:      &BlobInfo{Size: len(oldBin), SHA256: sha256Hex(oldBin)},
+		New:      &BlobInfo{Size: len(newBin), SHA256: sha256Hex(newBin)},
+	}
+	if logo := got["logo.bin"]; !reflect.DeepEqual(logo.Binary, want) {
+		t.Errorf("logo.bin Binary = %+v, want %+v", logo.Binary, want)
+	}
+	if a := got["a.txt"]; a.Status != "Modified" || a.Binary != nil {
+		t.Errorf("a.txt = %+v, want texto sem Binary", a)
+	}
+}
+
+func TestDiffer_openFullDiff_WithoutMimeType(t *testing.T) {
+	differ, oldBin, newBin := cliBinaryDiffer(t)
+	r, err := differ.openFullDiff()
+	if err != nil {
+		t.Fatalf("openFullDiff() error = %v", err)
+	}
+	defer r.Close()
+	output, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	want := "Index: logo.bin\n" +
+		"===================================================================\n" +
+		"Cannot display: file marked as a binary type.\n" +
+		"svn:mime-type = application/octet-stream\n" +
+		"Tamanho: " + strconv.Itoa(len(oldBin)) + " bytes → " + strconv.Itoa(len(newBin)) + " bytes\n" +
+		"SHA-256 A: " + sha256Hex(oldBin) + "\n" +
+		"SHA-256 B: " + sha256Hex(newBin) + "\n" +
+		"Index: a.txt\n" +
+		"===================================================================\n" +
+		"--- a.txt\t(revision 1)\n" +
+		"+++ a.txt\t(revision 2)\n" +
+		"@@ -1 +1 @@\n" +
+		"-a\n" +
+		"+A\n"
+	if string(output) != want {
+		t.Errorf("openFullDiff() =\n%q\nwant\n%q", output, want)
+	}
+}
@@ -3,12 +3,16 @@
 package app
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/fatih/color"
 
+	"svndiff/internal/diff"
 	"svndiff/internal/svn"
 	"svndiff/pkg/config"
 )
@@ -16,12 +20,14 @@ import (
 // Differ é a estrutura principal que orquestra as operações de diff
 type Differ struct {
 	config    *config.Config
-	svnClient *svn.Client
+	svnClient svn.Backend
+	// index é o índice do diff completo, lido sob demanda por fullIndex
+	index *diffIndex
 }
 
 // NewDiffer cria uma nova instância do Differ
 func NewDiffer(cfg *config.Config) *Differ {
-	svnClient := svn.NewClient(&cfg.Auth)
+	svnClient := newBackend(cfg)
 	return &Differ{
 		config:    cfg,
 		svnClient: svnClient,
@@ -32,8 +38,26 @@ func NewDiffer(cfg *config.Config) *Differ {
 type FileChange struct {
 	Path   string `json:"path"`
 	Status string `json:"status"`
+	// PropStatus é o status das propriedades, vazio quando elas não mudaram
+	PropStatus string `json:"propStatus,omitempty"`
+	// Kind é o tipo do nó (file ou dir), quando conhecido
+	Kind string `json:"kind,omitempty"`
+	// URL é o endereço completo do caminho na Branch A
+	URL string `json:"url,omitempty"`
+	// OldPath é a origem das renomeações e cópias
+	OldPath string `json:"oldPath,omitempty"`
+	// Similarity é a porcentagem de conteúdo em comum com OldPath
+	Similarity int `json:"similarity,omitempty"`
+	// Props traz os valores antigo e novo das propriedades alteradas
+	Props []PropChange `json:"props,omitempty"`
+	// Binary detalha os arquivos binários, com binary.details
+	Binary *BinaryInfo `json:"binary,omitempty"`
 }
 
+// propertyChanged é o status das mudanças só de propriedades, como em
+// patch.File.Status
+const propertyChanged = "PropertyChanged"
+
 // DiffSummary representa um resumo das diferenças
 type DiffSummary struct {
 	BranchA    BranchInfo   `json:"branchA"`
@@ -56,11 +80,21 @@ func (d *Differ) Run() error {
 		return fmt.Errorf("configuração inválida: %w", err)
 	}
 
+	// Deduz a ref do lado Git pelo layout da branch SVN
+	if err := applyGitLayout(d.config); err != nil {
+		return fmt.Errorf("erro no mapeamento de layout Git: %w", err)
+	}
+
 	// Verifica conectividade (opcional, mas útil para debug)
 	if err := d.checkConnections(); err != nil {
 		return fmt.Errorf("erro de conectividade: %w", err)
 	}
 
+	// Descobre revisões a partir da issue, quando configurada
+	if err := d.resolveRevisions(); err != nil {
+		return fmt.Errorf("erro ao descobrir revisões: %w", err)
+	}
+
 	// Executa o diff baseado no formato de saída solicitado
 	switch d.config.Output {
 	case "list":
@@ -69,6 +103,22 @@ func (d *Differ) Run() error {
 		return d.outputDiff()
 	case "json":
 		return d.outputJSON()
+	case "ndjson":
+		return d.outputNDJSON()
+	case "patch", "git-patch":
+		return d.outputPatch()
+	case "html":
+		return d.outputHTML()
+	case "side-by-side":
+		return d.outputSideBySide()
+	case "markdown":
+		return d.outputMarkdown()
+	case "template":
+		return d.outputTemplate()
+	case "junit":
+		return d.outputJUnit()
+	case "sarif":
+		return d.outputSARIF()
 	default:
 		return fmt.Errorf("formato de saída não suportado: %s", d.config.Output)
 	}
@@ -87,164 +137,377 @@ func (d *Differ) checkConnections() error {
 	return nil
 }
 
-// outputList gera uma saída simples listando os arquivos modificados
+// outputList gera uma saída simples listando os arquivos modificados, à
+// medida que a saída do svn é lida
 func (d *Differ) outputList() error {
-	result, err := d.svnClient.GetDiff(&d.config.BranchA, &d.config.BranchB, true)
+	r, err := d.openDiff(true)
 	if err != nil {
 		return fmt.Errorf("erro ao executar diff: %w", err)
 	}
+	defer r.Close()
 
 	// Imprime cabeçalho informativo
 	d.printHeader()
 
+	// Imprime a lista de arquivos
+	total := 0
+	for change, err := range d.fileChanges(r) {
+		if err != nil {
+			return fmt.Errorf("erro ao executar diff: %w", err)
+		}
+		if total == 0 {
+			color.Yellow("Arquivos modificados:\n")
+		}
+		// Diretórios terminam em "/" para não se confundirem com arquivos
+		path := change.Path
+		if change.Kind == string(svn.NodeDir) {
+			path = strings.TrimSuffix(path, "/") + "/"
+		}
+		switch {
+		case change.OldPath != "" && change.Similarity > 0:
+			fmt.Printf("  %s → %s (%s, %d%%)\n", change.OldPath, path, change.Status, change.Similarity)
+		case change.OldPath != "":
+			fmt.Printf("  %s → %s (%s)\n", change.OldPath, path, change.Status)
+		default:
+			fmt.Printf("  %s\n", path)
+		}
+		if change.Binary != nil {
+			for _, line := range change.Binary.lines() {
+				color.Cyan("    %s\n", line)
+			}
+		}
+		for _, prop := range change.Props {
+			color.Cyan("    Propriedade %s %s: %q → %q\n", prop.Name, propAction(prop.Action), prop.Old, prop.New)
+		}
+		total++
+	}
+
 	// Se não há diferenças
-	if len(result.FileList) == 0 {
+	if total == 0 {
 		color.Green("✓ Nenhuma diferença encontrada entre as branches.\n")
 		return nil
 	}
-
-	// Imprime a lista de arquivos
-	color.Yellow("Arquivos modificados (%d):\n", len(result.FileList))
-	for _, file := range result.FileList {
-		fmt.Printf("  %s\n", file)
-	}
+	color.Yellow("Total: %d arquivos\n", total)
 
 	return nil
 }
 
-// outputDiff gera a saída completa do diff unificado
+// outputDiff gera a saída completa do diff unificado, colorindo as linhas
+// à medida que a saída do svn é lida
 func (d *Differ) outputDiff() error {
-	result, err := d.svnClient.GetDiff(&d.config.BranchA, &d.config.BranchB, false)
+	r, err := d.openFullDiff()
 	if err != nil {
 		return fmt.Errorf("erro ao executar diff: %w", err)
 	}
+	defer r.Close()
 
 	// Imprime cabeçalho informativo
 	d.printHeader()
 
+	// Imprime o diff com coloração
+	empty, err := copyColorizedDiff(color.Output, r)
+	if err != nil {
+		return fmt.Errorf("erro ao executar diff: %w", err)
+	}
+
 	// Se não há diferenças
-	if strings.TrimSpace(result.Output) == "" {
+	if empty {
 		color.Green("✓ Nenhuma diferença encontrada entre as branches.\n")
-		return nil
 	}
 
-	// Imprime o diff com coloração
-	d.printColorizedDiff(result.Output)
-
 	return nil
 }
 
 // outputJSON gera a saída em formato JSON
 func (d *Differ) outputJSON() error {
-	result, err := d.svnClient.GetDiff(&d.config.BranchA, &d.config.BranchB, true)
+	r, err := d.openDiff(true)
 	if err != nil {
 		return fmt.Errorf("erro ao executar diff: %w", err)
 	}
+	defer r.Close()
+
+	return d.writeJSON(os.Stdout, r)
+}
 
-	// Constrói o objeto de resumo
-	summary := DiffSummary{
-		BranchA: BranchInfo{
-			URL:       d.config.BranchA.URL,
-			Revisions: d.config.BranchA.Revisions,
-			Latest:    d.config.BranchA.GetLatestRevision(),
-		},
-		BranchB: BranchInfo{
-			URL:       d.config.BranchB.URL,
-			Revisions: d.config.BranchB.Revisions,
-			Latest:    d.config.BranchB.GetLatestRevision(),
-		},
-		Changes:    d.parseFileChanges(result.Output),
-		TotalFiles: len(result.FileList),
-	}
-
-	// Serializa para JSON
-	jsonOutput, err := json.MarshalIndent(summary, "", "  ")
+// writeJSON escreve o DiffSummary das linhas do svn diff --summarize lidas
+// de r. As mudanças são serializadas uma a uma, sem montar o resumo em
+// memória, com a mesma formatação de json.MarshalIndent.
+func (d *Differ) writeJSON(w io.Writer, r io.Reader) error {
+	branchA, err := json.MarshalIndent(branchInfo(&d.config.BranchA), "  ", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao gerar JSON: %w", err)
+	}
+	branchB, err := json.MarshalIndent(branchInfo(&d.config.BranchB), "  ", "  ")
 	if err != nil {
 		return fmt.Errorf("erro ao gerar JSON: %w", err)
 	}
 
-	fmt.Println(string(jsonOutput))
-	return nil
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "{\n  \"branchA\": %s,\n  \"branchB\": %s,\n  \"changes\": ", branchA, branchB)
+
+	total := 0
+	for change, err := range d.fileChanges(r) {
+		if err != nil {
+			bw.Flush()
+			return fmt.Errorf("erro ao executar diff: %w", err)
+		}
+		item, err := json.MarshalIndent(change, "    ", "  ")
+		if err != nil {
+			return fmt.Errorf("erro ao gerar JSON: %w", err)
+		}
+		if total == 0 {
+			bw.WriteString("[\n    ")
+		} else {
+			bw.WriteString(",\n    ")
+		}
+		bw.Write(item)
+		total++
+	}
+	if total == 0 {
+		bw.WriteString("null")
+	} else {
+		bw.WriteString("\n  ]")
+	}
+	fmt.Fprintf(bw, ",\n  \"totalFiles\": %d\n}\n", total)
+
+	return bw.Flush()
+}
+
+// branchInfo descreve a branch nos relatórios
+func branchInfo(branch *config.BranchConfig) BranchInfo {
+	return BranchInfo{
+		URL:       branch.URL,
+		Revisions: branch.Revisions,
+		Latest:    branch.GetLatestRevision(),
+	}
 }
 
 // printHeader imprime um cabeçalho informativo
 func (d *Differ) printHeader() {
 	color.Cyan("=== SVN Diff Comparison ===\n")
-	fmt.Printf("Branch A: %s @ %s\n", d.config.BranchA.URL, d.config.BranchA.GetLatestRevision())
-	fmt.Printf("Branch B: %s @ %s\n", d.config.BranchB.URL, d.config.BranchB.GetLatestRevision())
+	fmt.Printf("Branch A: %s @ %s\n", d.config.BranchA.URL, branchRevision(&d.config.BranchA))
+	fmt.Printf("Branch B: %s @ %s\n", d.config.BranchB.URL, branchRevision(&d.config.BranchB))
 	fmt.Println()
 }
 
-// printColorizedDiff imprime o diff com cores para melhor legibilidade
-func (d *Differ) printColorizedDiff(diffOutput string) {
-	lines := strings.Split(diffOutput, "\n")
+// branchRevision descreve a revisão exibida no cabeçalho. Cópias de trabalho
+// são comparadas no estado atual do disco e repositórios Git na ref da URL.
+func branchRevision(branch *config.BranchConfig) string {
+	if branch.IsLocalPath() {
+		return "cópia de trabalho"
+	}
+	if branch.IsGit() {
+		return "git " + gitRef(branch.URL)
+	}
+	return branch.GetLatestRevision()
+}
 
-	for _, line := range lines {
-		switch {
-		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
-			color.Blue(line)
-		case strings.HasPrefix(line, "@@"):
-			color.Magenta(line)
-		case strings.HasPrefix(line, "+"):
-			color.Green(line)
-		case strings.HasPrefix(line, "-"):
-			color.Red(line)
-		case strings.HasPrefix(line, "Index:") || strings.HasPrefix(line, "==="):
-			color.Yellow(line)
-		default:
-			fmt.Println(line)
+// copyColorizedDiff copia o diff lido de r para w com cores, linha a linha.
+// empty indica que a saída não tinha diferenças.
+func copyColorizedDiff(w io.Writer, r io.Reader) (empty bool, err error) {
+	c := &diffColorizer{w: w}
+	defer c.flush()
+
+	empty = true
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			if strings.TrimSpace(line) != "" {
+				empty = false
+			}
+			c.writeLine(strings.TrimSuffix(line, "\n"))
+		}
+		if err == io.EOF {
+			return empty, nil
+		}
+		if err != nil {
+			return empty, err
 		}
 	}
 }
 
-// parseFileChanges processa a saída do diff e extrai as mudanças de arquivo com status
-func (d *Differ) parseFileChanges(output string) []FileChange {
-	var changes []FileChange
-	lines := strings.Split(strings.TrimSpace(output), "\n")
+// diffColorizer escreve o diff colorido em w. As linhas removidas seguidas
+// de adicionadas ficam retidas até o fim do bloco, para que as palavras
+// alteradas de cada par sejam destacadas.
+type diffColorizer struct {
+	w              io.Writer
+	removed, added []string
+	// props indica que as linhas são da seção de propriedades do arquivo
+	props bool
+}
+
+func (c *diffColorizer) writeLine(line string) {
+	switch {
+	case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+		c.flush()
+		fmt.Fprintln(c.w, color.BlueString("%s", line))
+	case strings.HasPrefix(line, "@@") || strings.HasPrefix(line, "##"):
+		c.flush()
+		fmt.Fprintln(c.w, color.MagentaString("%s", line))
+	case strings.HasPrefix(line, "+"):
+		c.added = append(c.added, line)
+	case strings.HasPrefix(line, "-"):
+		if len(c.added) > 0 {
+			c.flush()
+		}
+		c.removed = append(c.removed, line)
+	case strings.HasPrefix(line, "Index:") || strings.HasPrefix(line, "==="):
+		c.flush()
+		c.props = false
+		fmt.Fprintln(c.w, color.YellowString("%s", line))
+	case strings.HasPrefix(line, "Property changes on:") || strings.HasPrefix(line, "___"):
+		c.flush()
+		c.props = true
+		fmt.Fprintln(c.w, color.YellowString("%s", line))
+	case c.props && isPropHeader(line):
+		c.flush()
+		fmt.Fprintln(c.w, color.CyanString("%s", line))
+	default:
+		c.flush()
+		fmt.Fprintln(c.w, line)
+	}
+}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+// isPropHeader reconhece a linha que abre a mudança de uma propriedade,
+// como "Modified: svn:mergeinfo"
+func isPropHeader(line string) bool {
+	for _, action := range []string{"Added: ", "Deleted: ", "Modified: ", "Name: "} {
+		if strings.HasPrefix(line, action) {
+			return true
 		}
+	}
+	return false
+}
+
+// flush escreve o bloco retido de linhas removidas e adicionadas
+func (c *diffColorizer) flush() {
+	removedSpans := make([][]diff.Span, len(c.removed))
+	addedSpans := make([][]diff.Span, len(c.added))
+	for i := 0; i < len(c.removed) && i < len(c.added); i++ {
+		removedSpans[i], addedSpans[i] = diff.Words(c.removed[i][1:], c.added[i][1:])
+	}
+	for i, line := range c.removed {
+		fmt.Fprintln(c.w, paintLine('-', line, removedSpans[i]))
+	}
+	for i, line := range c.added {
+		fmt.Fprintln(c.w, paintLine('+', line, addedSpans[i]))
+	}
+	c.removed, c.added = nil, nil
+}
+
+// lineColors retorna as cores de uma linha do diff e dos trechos alterados
+// dentro dela; nil mantém a cor do terminal
+func lineColors(kind byte) (base, highlight *color.Color) {
+	switch kind {
+	case '-':
+		return color.New(color.FgRed), color.New(color.FgRed, color.ReverseVideo)
+	case '+':
+		return color.New(color.FgGreen), color.New(color.FgGreen, color.ReverseVideo)
+	}
+	return nil, nil
+}
 
-		// O formato do svn diff --summarize é: "STATUS   PATH"
-		parts := strings.Fields(line)
-		if len(parts) >= 2 {
-			status := parts[0]
-			filePath := strings.Join(parts[1:], " ")
+// paintLine colore uma linha removida ou adicionada, com o marcador na
+// primeira coluna e os trechos alterados em destaque
+func paintLine(kind byte, line string, spans []diff.Span) string {
+	base, highlight := lineColors(kind)
+	if spans == nil {
+		return colorize(base, line)
+	}
+	var b strings.Builder
+	b.WriteString(colorize(base, line[:1]))
+	for _, span := range spans {
+		if span.Changed {
+			b.WriteString(colorize(highlight, span.Text))
+		} else {
+			b.WriteString(colorize(base, span.Text))
+		}
+	}
+	return b.String()
+}
 
-			// Mapeia os status SVN para nomes mais legíveis
-			statusName := d.mapSVNStatus(status)
+// colorize aplica a cor ao texto; nil e texto vazio ficam como estão
+func colorize(c *color.Color, text string) string {
+	if c == nil || text == "" {
+		return text
+	}
+	return c.Sprint(text)
+}
 
-			changes = append(changes, FileChange{
-				Path:   filePath,
-				Status: statusName,
-			})
+// parseFileChanges processa a saída do svn diff --summarize --xml e extrai
+// as mudanças de arquivo com status
+func (d *Differ) parseFileChanges(output string) ([]FileChange, error) {
+	var changes []FileChange
+	for change, err := range d.changes(strings.NewReader(output)) {
+		if err != nil {
+			return nil, err
 		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// fileChange converte uma entrada do resumo XML; ok é false nas entradas
+// sem mudança
+func (d *Differ) fileChange(entry svn.SummaryEntry) (FileChange, bool) {
+	change := FileChange{Path: entry.Path, URL: d.entryURL(entry.Path)}
+	if entry.Kind != svn.NodeNone {
+		change.Kind = string(entry.Kind)
+	}
+
+	// O conteúdo e as propriedades têm status separados; um caminho em que
+	// só as propriedades mudaram tem status próprio
+	propsChanged := !isUnchanged(entry.Props)
+	switch {
+	case !isUnchanged(entry.Item):
+		change.Status = d.mapSummaryItem(entry.Item)
+	case propsChanged:
+		change.Status = propertyChanged
+	default:
+		return FileChange{}, false
+	}
+	if propsChanged {
+		change.PropStatus = d.mapSummaryItem(entry.Props)
 	}
+	return change, true
+}
 
-	return changes
+// isUnchanged indica um status do resumo XML sem mudança
+func isUnchanged(status string) bool {
+	return status == "" || status == "none" || status == "normal"
 }
 
-// mapSVNStatus mapeia códigos de status SVN para nomes legíveis
-func (d *Differ) mapSVNStatus(status string) string {
+// entryURL retorna a URL completa de um caminho do resumo. O comando svn já
+// relata URLs; os clientes nativos relatam caminhos relativos à Branch A.
+// Cópias de trabalho não têm URL.
+func (d *Differ) entryURL(path string) string {
+	if strings.Contains(path, "://") {
+		return path
+	}
+	if d.config == nil || d.config.BranchA.IsLocalPath() {
+		return ""
+	}
+	base := strings.TrimSuffix(d.config.BranchA.URL, "/")
+	if path == "" || path == "." {
+		return base
+	}
+	return base + "/" + path
+}
+
+// mapSummaryItem mapeia os status do svn diff --summarize --xml para nomes
+// legíveis
+func (d *Differ) mapSummaryItem(item string) string {
 	statusMap := map[string]string{
-		"M": "Modified",
-		"A": "Added",
-		"D": "Deleted",
-		"R": "Replaced",
-		"C": "Conflicted",
-		"X": "External",
-		"I": "Ignored",
-		"?": "Unversioned",
-		"!": "Missing",
-		"~": "Obstructed",
-	}
-
-	if mapped, exists := statusMap[status]; exists {
+		"added":    "Added",
+		"deleted":  "Deleted",
+		"modified": "Modified",
+		"replaced": "Replaced",
+	}
+
+	if mapped, exists := statusMap[item]; exists {
 		return mapped
 	}
 
-	return status // Retorna o status original se não encontrado
+	return item // Retorna o status original se não encontrado
 }
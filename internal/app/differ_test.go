@@ -1,13 +1,39 @@
 package app
 
 import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
+	"svndiff/internal/svn"
 	"svndiff/pkg/config"
 )
 
+// summaryXML monta a saída do svn diff --summarize --xml a partir de linhas
+// no formato texto ("SP      PATH"), com o tipo entre parênteses no fim. O
+// caminho começa na coluna 8, como no svn, e é mantido como está escrito.
+func summaryXML(lines ...string) string {
+	var b strings.Builder
+	b.WriteString(xml.Header + "<diff>\n<paths>\n")
+	for _, line := range lines {
+		kind := "file"
+		path := line[8:]
+		if rest, ok := strings.CutSuffix(path, " (dir)"); ok {
+			path, kind = rest, "dir"
+		}
+		entry := svn.Change{Path: path, Kind: svn.NodeKind(kind), Text: line[0], Props: line[1]}.SummaryEntry()
+		fmt.Fprintf(&b, "<path\n   props=\"%s\"\n   kind=\"%s\"\n   item=\"%s\">", entry.Props, entry.Kind, entry.Item)
+		xml.EscapeText(&b, []byte(entry.Path))
+		b.WriteString("</path>\n")
+	}
+	b.WriteString("</paths>\n</diff>\n")
+	return b.String()
+}
+
 func TestDiffer_parseFileChanges(t *testing.T) {
-	differ := &Differ{}
+	differ := &Differ{config: &config.Config{BranchA: config.BranchConfig{URL: "https://svn.example.com/trunk"}}}
 
 	tests := []struct {
 		name     string
@@ -21,64 +47,79 @@ func TestDiffer_parseFileChanges(t *testing.T) {
 		},
 		{
 			name:   "múltiplos arquivos",
-			output: "M       src/main.go\nA       README.md\nD       old_file.txt",
+			output: summaryXML("M       src/main.go", "A       README.md", "D       old_file.txt"),
 			expected: []FileChange{
-				{Path: "src/main.go", Status: "Modified"},
-				{Path: "README.md", Status: "Added"},
-				{Path: "old_file.txt", Status: "Deleted"},
+				{Path: "src/main.go", Status: "Modified", Kind: "file", URL: "https://svn.example.com/trunk/src/main.go"},
+				{Path: "README.md", Status: "Added", Kind: "file", URL: "https://svn.example.com/trunk/README.md"},
+				{Path: "old_file.txt", Status: "Deleted", Kind: "file", URL: "https://svn.example.com/trunk/old_file.txt"},
 			},
 		},
 		{
-			name:   "arquivo com espaços no nome",
-			output: "M       src/test file.go",
+			name:   "mudanças de propriedades",
+			output: summaryXML(" M      trunk (dir)", "MM      src/main.go", "A       lib (dir)"),
 			expected: []FileChange{
-				{Path: "src/test file.go", Status: "Modified"},
+				{Path: "trunk", Status: "PropertyChanged", PropStatus: "Modified", Kind: "dir", URL: "https://svn.example.com/trunk/trunk"},
+				{Path: "src/main.go", Status: "Modified", PropStatus: "Modified", Kind: "file", URL: "https://svn.example.com/trunk/src/main.go"},
+				{Path: "lib", Status: "Added", Kind: "dir", URL: "https://svn.example.com/trunk/lib"},
+			},
+		},
+		{
+			name:   "caminhos com espaços e URLs do comando svn",
+			output: summaryXML("M       src/test file.go", "A       https://svn.example.com/trunk/a&b.txt"),
+			expected: []FileChange{
+				{Path: "src/test file.go", Status: "Modified", Kind: "file", URL: "https://svn.example.com/trunk/src/test file.go"},
+				{Path: "https://svn.example.com/trunk/a&b.txt", Status: "Added", Kind: "file", URL: "https://svn.example.com/trunk/a&b.txt"},
+			},
+		},
+		{
+			name:   "caminhos que começam ou terminam com espaço",
+			output: summaryXML("A        lead.txt", "M       docs/ trail  (dir)"),
+			expected: []FileChange{
+				{Path: " lead.txt", Status: "Added", Kind: "file", URL: "https://svn.example.com/trunk/ lead.txt"},
+				{Path: "docs/ trail ", Status: "Modified", Kind: "dir", URL: "https://svn.example.com/trunk/docs/ trail "},
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := differ.parseFileChanges(tt.output)
+			result, err := differ.parseFileChanges(tt.output)
+			if err != nil {
+				t.Fatalf("parseFileChanges() error = %v", err)
+			}
 			if len(result) != len(tt.expected) {
 				t.Errorf("parseFileChanges() len = %v, want %v", len(result), len(tt.expected))
 				return
 			}
 
 			for i, change := range result {
-				if change.Path != tt.expected[i].Path || change.Status != tt.expected[i].Status {
-					t.Errorf("parseFileChanges()[%d] = %v, want %v", i, change, tt.expected[i])
+				if !reflect.DeepEqual(change, tt.expected[i]) {
+					t.Errorf("parseFileChanges()[%d] = %+v, want %+v", i, change, tt.expected[i])
 				}
 			}
 		})
 	}
 }
 
-func TestDiffer_mapSVNStatus(t *testing.T) {
+func TestDiffer_mapSummaryItem(t *testing.T) {
 	differ := &Differ{}
 
 	tests := []struct {
-		status   string
+		item     string
 		expected string
 	}{
-		{"M", "Modified"},
-		{"A", "Added"},
-		{"D", "Deleted"},
-		{"R", "Replaced"},
-		{"C", "Conflicted"},
-		{"X", "External"},
-		{"I", "Ignored"},
-		{"?", "Unversioned"},
-		{"!", "Missing"},
-		{"~", "Obstructed"},
-		{"Z", "Z"}, // Status desconhecido deve retornar o original
+		{"added", "Added"},
+		{"deleted", "Deleted"},
+		{"modified", "Modified"},
+		{"replaced", "Replaced"},
+		{"conflicted", "conflicted"}, // Status desconhecido deve retornar o original
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.status, func(t *testing.T) {
-			result := differ.mapSVNStatus(tt.status)
+		t.Run(tt.item, func(t *testing.T) {
+			result := differ.mapSummaryItem(tt.item)
 			if result != tt.expected {
-				t.Errorf("mapSVNStatus(%s) = %s, want %s", tt.status, result, tt.expected)
+				t.Errorf("mapSummaryItem(%s) = %s, want %s", tt.item, result, tt.expected)
 			}
 		})
 	}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"net/url"
+
+	"svndiff/internal/svn/git"
+	"svndiff/pkg/config"
+)
+
+// applyGitLayout completa a URL do lado Git com a ref e o caminho deduzidos
+// da URL SVN do outro lado, quando o mapeamento de layout está habilitado
+func applyGitLayout(cfg *config.Config) error {
+	if !cfg.Git.Layout {
+		return nil
+	}
+	gitSide, svnSide := &cfg.BranchA, &cfg.BranchB
+	if !gitSide.IsGit() {
+		gitSide, svnSide = svnSide, gitSide
+	}
+
+	mapped, err := git.MapLayout(gitSide.URL, svnSide.URL)
+	if err != nil {
+		return err
+	}
+	gitSide.URL = mapped
+	return nil
+}
+
+// gitRef retorna a ref de uma URL git+file, HEAD quando omitida
+func gitRef(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		if ref := u.Query().Get("ref"); ref != "" {
+			return ref
+		}
+	}
+	return "HEAD"
+}
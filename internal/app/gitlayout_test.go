@@ -0,0 +1,71 @@
+package app
+
+import (
+	"testing"
+
+	"svndiff/pkg/config"
+)
+
+func TestApplyGitLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantA   string
+		wantB   string
+		wantErr bool
+	}{
+		{
+			name: "desabilitado",
+			cfg: config.Config{
+				BranchA: config.BranchConfig{URL: "https://svn/proj/branches/x"},
+				BranchB: config.BranchConfig{URL: "git+file:///m"},
+			},
+			wantA: "https://svn/proj/branches/x",
+			wantB: "git+file:///m",
+		},
+		{
+			name: "Git no lado B",
+			cfg: config.Config{
+				BranchA: config.BranchConfig{URL: "https://svn/proj/branches/x"},
+				BranchB: config.BranchConfig{URL: "git+file:///m"},
+				Git:     config.GitConfig{Layout: true},
+			},
+			wantA: "https://svn/proj/branches/x",
+			wantB: "git+file:///m?ref=x",
+		},
+		{
+			name: "Git no lado A",
+			cfg: config.Config{
+				BranchA: config.BranchConfig{URL: "git+file:///m"},
+				BranchB: config.BranchConfig{URL: "https://svn/proj/trunk/src"},
+				Git:     config.GitConfig{Layout: true},
+			},
+			wantA: "git+file:///m?path=src&ref=HEAD",
+			wantB: "https://svn/proj/trunk/src",
+		},
+		{
+			name: "fora do layout",
+			cfg: config.Config{
+				BranchA: config.BranchConfig{URL: "https://svn/proj"},
+				BranchB: config.BranchConfig{URL: "git+file:///m"},
+				Git:     config.GitConfig{Layout: true},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyGitLayout(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyGitLayout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.cfg.BranchA.URL != tt.wantA || tt.cfg.BranchB.URL != tt.wantB {
+				t.Errorf("applyGitLayout() = %q, %q, want %q, %q", tt.cfg.BranchA.URL, tt.cfg.BranchB.URL, tt.wantA, tt.wantB)
+			}
+		})
+	}
+}
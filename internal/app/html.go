@@ -0,0 +1,163 @@
+package app
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"svndiff/internal/patch"
+)
+
+//go:embed templates/report.html
+var reportTemplate string
+
+// htmlReport reúne os dados do relatório HTML
+type htmlReport struct {
+	Generated        string
+	BranchA, BranchB htmlBranch
+	Stats            diffStats
+	Tree             []*treeNode
+	Files            []htmlFile
+}
+
+// htmlBranch é a branch como exibida no cabeçalho
+type htmlBranch struct {
+	BranchInfo
+	Revision string
+}
+
+// diffStats resume as mudanças do diff. PropertyChanged conta as mudanças
+// só de propriedades e Other os demais status, como Replaced.
+type diffStats struct {
+	Files, Added, Deleted, Modified int
+	PropertyChanged, Other          int
+	Insertions, Deletions           int
+}
+
+// htmlFile é a seção de um arquivo no relatório
+type htmlFile struct {
+	*patch.File
+	Anchor                string
+	Insertions, Deletions int
+}
+
+// treeNode é um diretório ou arquivo da árvore lateral; Anchor só é
+// definido nos caminhos com diff, inclusive nos diretórios com filhos cuja
+// própria mudança (propriedades, por exemplo) também está no relatório
+type treeNode struct {
+	Name     string
+	Anchor   string
+	Status   string
+	Children []*treeNode
+}
+
+// outputHTML gera um relatório HTML autocontido, para anexar a tickets
+func (d *Differ) outputHTML() error {
+	files, err := d.fullDiff()
+	if err != nil {
+		return err
+	}
+	return d.writeHTML(os.Stdout, files, time.Now())
+}
+
+// writeHTML escreve o relatório das seções do diff
+func (d *Differ) writeHTML(w io.Writer, files []*patch.File, now time.Time) error {
+	report := htmlReport{
+		Generated: now.Format("2006-01-02 15:04:05"),
+		BranchA:   htmlBranch{branchInfo(&d.config.BranchA), branchRevision(&d.config.BranchA)},
+		BranchB:   htmlBranch{branchInfo(&d.config.BranchB), branchRevision(&d.config.BranchB)},
+	}
+
+	for i, f := range files {
+		insertions, deletions := f.Stats()
+		report.Files = append(report.Files, htmlFile{
+			File: f, Anchor: fmt.Sprintf("file-%d", i+1), Insertions: insertions, Deletions: deletions,
+		})
+		report.Stats.add(f.Status(), insertions, deletions)
+	}
+	report.Tree = buildTree(report.Files)
+
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"kindClass": kindClass,
+		"marker":    func(kind byte) string { return string(kind) },
+		"lower":     strings.ToLower,
+	}).Parse(reportTemplate)
+	if err != nil {
+		return fmt.Errorf("erro no modelo do relatório: %w", err)
+	}
+	return tmpl.Execute(w, report)
+}
+
+// add contabiliza um arquivo nas estatísticas
+func (s *diffStats) add(status string, insertions, deletions int) {
+	s.Files++
+	switch status {
+	case "Added":
+		s.Added++
+	case "Deleted":
+		s.Deleted++
+	case "Modified":
+		s.Modified++
+	case propertyChanged:
+		s.PropertyChanged++
+	default:
+		s.Other++
+	}
+	s.Insertions += insertions
+	s.Deletions += deletions
+}
+
+// kindClass retorna a classe CSS de uma linha de hunk
+func kindClass(kind byte) string {
+	switch kind {
+	case '+':
+		return "add"
+	case '-':
+		return "del"
+	}
+	return "ctx"
+}
+
+// buildTree monta a árvore de diretórios dos caminhos do diff, com os
+// diretórios antes dos arquivos e cada grupo em ordem alfabética
+func buildTree(files []htmlFile) []*treeNode {
+	root := &treeNode{}
+	for _, f := range files {
+		node := root
+		for _, name := range strings.Split(f.Path, "/") {
+			var child *treeNode
+			for _, c := range node.Children {
+				if c.Name == name {
+					child = c
+					break
+				}
+			}
+			if child == nil {
+				child = &treeNode{Name: name}
+				node.Children = append(node.Children, child)
+			}
+			node = child
+		}
+		node.Anchor, node.Status = f.Anchor, f.Status()
+	}
+	sortTree(root)
+	return root.Children
+}
+
+func sortTree(node *treeNode) {
+	sort.SliceStable(node.Children, func(i, j int) bool {
+		a, b := node.Children[i], node.Children[j]
+		if (len(a.Children) > 0) != (len(b.Children) > 0) {
+			return len(a.Children) > 0
+		}
+		return a.Name < b.Name
+	})
+	for _, child := range node.Children {
+		sortTree(child)
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"svndiff/internal/patch"
+	"svndiff/pkg/config"
+)
+
+func TestDiffer_writeHTML_ChangedDirectory(t *testing.T) {
+	diff := "Index: src\n===\n--- src\t(revision 1)\n+++ src\t(revision 2)\n\nProperty changes on: src\n___\nAdded: svn:ignore\n## -0,0 +1 ##\n+*.o\n" +
+		"Index: src/a.go\n===\n--- src/a.go\t(revision 1)\n+++ src/a.go\t(revision 2)\n@@ -1 +1 @@\n-um\n+dois\n"
+	files, err := patch.ParseString(diff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	d := NewDiffer(&config.Config{})
+
+	var out strings.Builder
+	if err := d.writeHTML(&out, files, time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("writeHTML() error = %v", err)
+	}
+	html := out.String()
+
+	// o diretório alterado continua com link, mesmo tendo filhos, e conta
+	// como mudança só de propriedades
+	for _, want := range []string{
+		"<span>1</span> modificados",
+		"<span>1</span> só com propriedades",
+		`<a class="dir status-propertychanged" href="#file-1">src/</a>`,
+		`<a class="status-modified" href="#file-2">a.go</a>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("writeHTML() não contém %q", want)
+		}
+	}
+}
+
+func TestDiffer_writeHTML(t *testing.T) {
+	diff := "Index: src/a.go\n===\n--- src/a.go\t(revision 1)\n+++ src/a.go\t(revision 2)\n@@ -1,4 +1,4 @@\n um\n-<dois>\n-x = 1\n+2 & 3\n+x = 2\n três\n" +
+		"Index: src/lib/novo.go\n===\n--- src/lib/novo.go\t(nonexistent)\n+++ src/lib/novo.go\t(revision 2)\n@@ -0,0 +1,2 @@\n+a\n+b\n" +
+		"Index: README\n===\n--- README\t(revision 1)\n+++ README\t(nonexistent)\n@@ -1 +0,0 @@\n-leia\n" +
+		"Index: img.png\n===\nCannot display: file marked as a binary type.\nsvn:mime-type = image/png\n"
+	files, err := patch.ParseString(diff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	d := NewDiffer(&config.Config{
+		BranchA: config.BranchConfig{URL: "https://svn.example.com/trunk", Revisions: []string{"1"}},
+		BranchB: config.BranchConfig{URL: "https://svn.example.com/branches/x", Revisions: []string{"2"}},
+	})
+
+	var out strings.Builder
+	if err := d.writeHTML(&out, files, time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("writeHTML() error = %v", err)
+	}
+	html := out.String()
+
+	for _, want := range []string{
+		"https://svn.example.com/trunk @ 1",
+		"https://svn.example.com/branches/x @ 2",
+		"2024-03-01 10:30:00",
+		"<span>4</span> arquivos",
+		"<span>1</span> adicionados",
+		"<span>2</span> modificados",
+		"<span>1</span> removidos",
+		"<span>+4</span> linhas",
+		"<span>−3</span> linhas",
+		`<td class="del">&lt;dois&gt;</td>`,
+		`<td class="add">2 &amp; 3</td>`,
+		`<td class="del">x = <mark>1</mark></td>`,
+		`<td class="add">x = <mark>2</mark></td>`,
+		`<details id="file-2" open>`,
+		`<a class="status-added" href="#file-2">novo.go</a>`,
+		`<span class="dir">lib/</span>`,
+		"Arquivo binário (image/png)",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("writeHTML() não contém %q", want)
+		}
+	}
+	if strings.Contains(html, "<dois>") {
+		t.Error("writeHTML() não escapou o conteúdo do diff")
+	}
+	// diretórios antes dos arquivos na árvore
+	if strings.Index(html, `<span class="dir">src/</span>`) > strings.Index(html, `href="#file-3">README</a>`) {
+		t.Error("writeHTML() deveria listar os diretórios antes dos arquivos")
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"svndiff/internal/patch"
+)
+
+// diffIndex guarda, por caminho relativo à branch, o que o svn diff
+// --summarize não informa e só o diff completo traz. Os filtros das
+// mudanças compartilham o índice, lido uma única vez.
+type diffIndex struct {
+	props map[string][]PropChange
+	// mimeTypes tem os arquivos binários e os seus tipos
+	mimeTypes map[string]string
+	// whitespaceOnly tem os arquivos cujos hunks desaparecem sem as
+	// diferenças de espaço ignoradas
+	whitespaceOnly map[string]bool
+}
+
+// fullIndex lê o diff completo na primeira chamada e devolve o índice
+func (d *Differ) fullIndex() (*diffIndex, error) {
+	if d.index != nil {
+		return d.index, nil
+	}
+	r, err := d.openDiff(false)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao executar diff: %w", err)
+	}
+	defer r.Close()
+
+	index := &diffIndex{
+		props:          make(map[string][]PropChange),
+		mimeTypes:      make(map[string]string),
+		whitespaceOnly: make(map[string]bool),
+	}
+	ws := d.whitespace()
+	reader := patch.NewReader(r)
+	for {
+		f, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao interpretar diff: %w", err)
+		}
+		if f.Binary {
+			index.mimeTypes[f.Path] = f.MimeType
+		}
+		for _, prop := range f.Props {
+			index.props[f.Path] = append(index.props[f.Path], PropChange(prop))
+		}
+		if ws.Enabled() && !f.Binary && len(f.Hunks) > 0 {
+			if f.IgnoreWhitespace(ws); len(f.Hunks) == 0 {
+				index.whitespaceOnly[f.Path] = true
+			}
+		}
+	}
+	d.index = index
+	return index, nil
+}
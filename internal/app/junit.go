@@ -0,0 +1,180 @@
+package app
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"svndiff/internal/patch"
+	"svndiff/pkg/config"
+)
+
+// junitTestSuites é a raiz do relatório JUnit
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite é uma comparação entre as duas branches
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// junitTestCase é uma diferença entre as branches: falha quando é
+// inesperada e passa quando está na lista de permitidas
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// outputJUnit gera um relatório JUnit em que a comparação é uma suíte e
+// cada diferença inesperada é um caso de teste com falha, para que o
+// desvio entre as branches apareça nos relatórios de teste do CI
+func (d *Differ) outputJUnit() error {
+	matcher, err := d.config.Filter.Matcher()
+	if err != nil {
+		return err
+	}
+
+	r, err := d.openDiff(true)
+	if err != nil {
+		return fmt.Errorf("erro ao executar diff: %w", err)
+	}
+	var changes []FileChange
+	for change, err := range d.detectRenames(d.expandDirs(d.ignoreWhitespace(d.changes(r)))) {
+		if err != nil {
+			r.Close()
+			return fmt.Errorf("erro ao ler diff: %w", err)
+		}
+		change.Path = branchPath(d.config.BranchA.URL, change.Path)
+		if matcher.Selects(change.Path) {
+			changes = append(changes, change)
+		}
+	}
+	r.Close()
+
+	// O diff completo só é lido quando há falhas para detalhar
+	var files []*patch.File
+	for _, change := range changes {
+		if !matcher.Allows(change.Path) {
+			files, err = d.fullDiff()
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	return d.writeJUnit(os.Stdout, d.junitReport(changes, files, matcher, time.Now()))
+}
+
+// fullDiff lê as seções do diff completo, com os binários detalhados
+// quando configurado
+func (d *Differ) fullDiff() ([]*patch.File, error) {
+	r, err := d.openFullDiff()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao executar diff: %w", err)
+	}
+	defer r.Close()
+	files, err := patch.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao interpretar diff: %w", err)
+	}
+	return files, nil
+}
+
+// junitReport monta a suíte da comparação a partir das mudanças já
+// filtradas e das seções do diff completo, usadas no corpo das falhas
+func (d *Differ) junitReport(changes []FileChange, files []*patch.File, matcher *config.PathMatcher, now time.Time) junitTestSuites {
+	sections := make(map[string]*patch.File, len(files))
+	for _, f := range files {
+		sections[f.Path] = f
+	}
+
+	suite := junitTestSuite{
+		Name:      fmt.Sprintf("%s@%s vs %s@%s", d.config.BranchA.URL, branchRevision(&d.config.BranchA), d.config.BranchB.URL, branchRevision(&d.config.BranchB)),
+		Time:      "0",
+		Timestamp: now.Format("2006-01-02T15:04:05"),
+		Properties: []junitProperty{
+			{Name: "branchA", Value: d.config.BranchA.URL},
+			{Name: "revisionsA", Value: strings.Join(d.config.BranchA.Revisions, ",")},
+			{Name: "branchB", Value: d.config.BranchB.URL},
+			{Name: "revisionsB", Value: strings.Join(d.config.BranchB.Revisions, ",")},
+		},
+	}
+	for _, change := range changes {
+		tc := junitTestCase{Name: change.Path, ClassName: "svndiff." + change.Status, Time: "0"}
+		if matcher.Allows(change.Path) {
+			tc.SystemOut = "Diferença permitida: " + change.Status
+		} else {
+			body := "(sem diff textual)"
+			if f, ok := sections[change.Path]; ok {
+				body = strings.Join(sectionLines(f), "\n")
+			}
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("Diferença inesperada (%s): %s", change.Status, change.Path),
+				Type:    change.Status,
+				Body:    body,
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+
+	return junitTestSuites{
+		Name:     "svndiff",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+}
+
+// writeJUnit escreve o relatório em XML
+func (d *Differ) writeJUnit(w io.Writer, report junitTestSuites) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("erro ao gerar JUnit: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// branchPath torna relativo à branch o caminho do svn diff --summarize, que
+// o cliente svn escreve como URL completa da Branch A
+func branchPath(url, path string) string {
+	prefix := strings.TrimSuffix(url, "/") + "/"
+	return strings.TrimPrefix(path, prefix)
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"svndiff/internal/patch"
+	"svndiff/pkg/config"
+)
+
+func TestDiffer_junitReport(t *testing.T) {
+	d := NewDiffer(&config.Config{
+		BranchA: config.BranchConfig{URL: "https://svn.example.com/trunk", Revisions: []string{"10"}},
+		BranchB: config.BranchConfig{URL: "https://svn.example.com/branches/x", Revisions: []string{"12"}},
+	})
+	matcher, err := (&config.FilterConfig{Allow: []string{"docs/**"}}).Matcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := patch.ParseString(`Index: src/a.go
+===================================================================
+--- src/a.go	(revision 10)
++++ src/a.go	(revision 12)
+@@ -1,1 +1,1 @@
+-timeout = 30
++timeout = 60 & <mais>
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := []FileChange{
+		{Path: "src/a.go", Status: "Modified"},
+		{Path: "docs/guia.md", Status: "Added"},
+		{Path: "lib", Status: "Deleted"},
+	}
+
+	var out strings.Builder
+	report := d.junitReport(changes, files, matcher, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	if err := d.writeJUnit(&out, report); err != nil {
+		t.Fatalf("writeJUnit() error = %v", err)
+	}
+
+	var got junitTestSuites
+	if err := xml.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("XML inválido: %v\n%s", err, out.String())
+	}
+	if got.Tests != 3 || got.Failures != 2 || len(got.Suites) != 1 {
+		t.Fatalf("testsuites = %d testes, %d falhas, %d suítes", got.Tests, got.Failures, len(got.Suites))
+	}
+	suite := got.Suites[0]
+	if suite.Name != "https://svn.example.com/trunk@10 vs https://svn.example.com/branches/x@12" || suite.Timestamp != "2024-03-01T12:00:00" {
+		t.Errorf("suíte = %q em %q", suite.Name, suite.Timestamp)
+	}
+
+	modified, added, deleted := suite.Cases[0], suite.Cases[1], suite.Cases[2]
+	if modified.Failure == nil || modified.ClassName != "svndiff.Modified" ||
+		!strings.Contains(modified.Failure.Body, "+timeout = 60 & <mais>") {
+		t.Errorf("caso modificado = %+v", modified)
+	}
+	if added.Failure != nil || added.SystemOut == "" {
+		t.Errorf("diferença permitida deveria passar: %+v", added)
+	}
+	if deleted.Failure == nil || deleted.Failure.Body != "(sem diff textual)" {
+		t.Errorf("caso sem seção no diff = %+v", deleted)
+	}
+}
+
+func TestBranchPath(t *testing.T) {
+	tests := []struct {
+		url, path, want string
+	}{
+		{"https://svn.example.com/trunk", "https://svn.example.com/trunk/src/a.go", "src/a.go"},
+		{"https://svn.example.com/trunk/", "https://svn.example.com/trunk/src/a.go", "src/a.go"},
+		{"https://svn.example.com/trunk", "src/a.go", "src/a.go"},
+		{"https://svn.example.com/trunk", "https://svn.example.com/trunk2/a.go", "https://svn.example.com/trunk2/a.go"},
+	}
+	for _, tt := range tests {
+		if got := branchPath(tt.url, tt.path); got != tt.want {
+			t.Errorf("branchPath(%q, %q) = %q, want %q", tt.url, tt.path, got, tt.want)
+		}
+	}
+}
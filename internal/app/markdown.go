@@ -0,0 +1,207 @@
+package app
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+
+	"svndiff/internal/patch"
+	"svndiff/pkg/config"
+)
+
+// markdownNoteSize reserva espaço no limite do relatório para o aviso de
+// arquivos omitidos
+const markdownNoteSize = 200
+
+// outputMarkdown gera um relatório Markdown para colar em tickets e wikis
+func (d *Differ) outputMarkdown() error {
+	files, err := d.fullDiff()
+	if err != nil {
+		return err
+	}
+	return d.writeMarkdown(os.Stdout, files)
+}
+
+// writeMarkdown escreve o cabeçalho das branches, a tabela de resumo e um
+// bloco recolhível com o diff de cada arquivo. Os diffs são truncados em
+// MaxFileLines linhas e, quando o relatório passaria de MaxBytes, os
+// arquivos restantes ficam só na tabela ou, se nem ela cabe, são omitidos.
+func (d *Differ) writeMarkdown(w io.Writer, files []*patch.File) error {
+	maxLines, maxBytes := d.config.Markdown.GetMaxFileLines(), d.config.Markdown.GetMaxBytes()
+
+	var b strings.Builder
+	b.WriteString("# SVN Diff Comparison\n\n")
+	b.WriteString("| Branch | URL | Revisões |\n| --- | --- | --- |\n")
+	for _, side := range []struct {
+		name   string
+		branch *config.BranchConfig
+	}{{"A", &d.config.BranchA}, {"B", &d.config.BranchB}} {
+		info := branchInfo(side.branch)
+		revisions := strings.Join(info.Revisions, ", ")
+		if revisions == "" {
+			revisions = branchRevision(side.branch)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", side.name, mdCode(info.URL), mdCell(revisions))
+	}
+	b.WriteString("\n")
+
+	if len(files) == 0 {
+		b.WriteString("✓ Nenhuma diferença encontrada entre as branches.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	var stats diffStats
+	for _, f := range files {
+		insertions, deletions := f.Stats()
+		stats.add(f.Status(), insertions, deletions)
+	}
+	b.WriteString("## Resumo\n\n")
+	fmt.Fprintf(&b, "%d arquivos: %d adicionados, %d modificados, %d removidos", stats.Files, stats.Added, stats.Modified, stats.Deleted)
+	if stats.PropertyChanged > 0 {
+		fmt.Fprintf(&b, ", %d só com propriedades", stats.PropertyChanged)
+	}
+	if stats.Other > 0 {
+		fmt.Fprintf(&b, ", %d outros", stats.Other)
+	}
+	fmt.Fprintf(&b, " · linhas: +%d −%d\n\n", stats.Insertions, stats.Deletions)
+
+	// As linhas da tabela também entram no limite; sem espaço para todas,
+	// os diffs ficam de fora
+	b.WriteString("| Status | Arquivo | + | − |\n| --- | --- | --: | --: |\n")
+	listed := len(files)
+	for i, f := range files {
+		insertions, deletions := f.Stats()
+		row := fmt.Sprintf("| %s | %s | %d | %d |\n", f.Status(), mdCode(f.Path), insertions, deletions)
+		if b.Len()+len(row)+markdownNoteSize > maxBytes {
+			listed = i
+			break
+		}
+		b.WriteString(row)
+	}
+	if listed < len(files) {
+		fmt.Fprintf(&b, "\n> ⚠️ %d arquivos omitidos para respeitar o limite de %d bytes; use `--output diff` para o diff completo.\n", len(files)-listed, maxBytes)
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	b.WriteString("\n## Arquivos\n\n")
+
+	omitted := 0
+	for _, f := range files {
+		block := markdownFile(f, maxLines)
+		if omitted > 0 || b.Len()+len(block)+markdownNoteSize > maxBytes {
+			omitted++
+			continue
+		}
+		b.WriteString(block)
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "> ⚠️ Diff de %d arquivos omitido para respeitar o limite de %d bytes; use `--output diff` para o diff completo.\n", omitted, maxBytes)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownFile formata o bloco recolhível de um arquivo, com no máximo
+// maxLines linhas de diff
+func markdownFile(f *patch.File, maxLines int) string {
+	insertions, deletions := f.Stats()
+	lines := sectionLines(f)
+	hidden := 0
+	if len(lines) > maxLines {
+		hidden = len(lines) - maxLines
+		lines = lines[:maxLines]
+	}
+	fence := mdFence(lines)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<details>\n<summary><code>%s</code> (%s, +%d −%d)</summary>\n\n", html.EscapeString(f.Path), f.Status(), insertions, deletions)
+	fmt.Fprintf(&b, "%sdiff\n%s\n%s\n", fence, strings.Join(lines, "\n"), fence)
+	if hidden > 0 {
+		fmt.Fprintf(&b, "\n_… %d linhas omitidas_\n", hidden)
+	}
+	b.WriteString("\n</details>\n\n")
+	return b.String()
+}
+
+// sectionLines reconstrói as linhas do diff unificado de um arquivo
+func sectionLines(f *patch.File) []string {
+	var lines []string
+	if f.LabelA != "" || f.LabelB != "" {
+		lines = append(lines, "--- "+f.Path+"\t"+f.LabelA, "+++ "+f.Path+"\t"+f.LabelB)
+	}
+	if f.Binary {
+		lines = append(lines, "Cannot display: file marked as a binary type.")
+		if f.MimeType != "" {
+			lines = append(lines, "svn:mime-type = "+f.MimeType)
+		}
+		lines = append(lines, f.Details...)
+	}
+	for _, hunk := range f.Hunks {
+		lines = append(lines, hunkLines(hunk)...)
+	}
+	for _, prop := range f.Props {
+		lines = append(lines, prop.Action+": "+prop.Name)
+		for _, old := range propLines(prop.Old) {
+			lines = append(lines, "-"+old)
+		}
+		for _, new := range propLines(prop.New) {
+			lines = append(lines, "+"+new)
+		}
+	}
+	return lines
+}
+
+// hunkLines reconstrói as linhas de um hunk de conteúdo, com o cabeçalho
+func hunkLines(hunk patch.Hunk) []string {
+	lines := []string{fmt.Sprintf("@@ -%d,%d +%d,%d @@", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines)}
+	for _, line := range hunk.Lines {
+		lines = append(lines, string(line.Kind)+line.Text)
+		if line.NoNewline {
+			lines = append(lines, `\ No newline at end of file`)
+		}
+	}
+	return lines
+}
+
+// mdFence retorna uma cerca de código maior que qualquer sequência de
+// crases do conteúdo
+func mdFence(lines []string) string {
+	return strings.Repeat("`", max(3, backtickRun(lines)+1))
+}
+
+// backtickRun retorna a maior sequência de crases das linhas
+func backtickRun(lines []string) int {
+	longest := 0
+	for _, line := range lines {
+		run := 0
+		for _, r := range line {
+			if r == '`' {
+				run++
+				longest = max(longest, run)
+			} else {
+				run = 0
+			}
+		}
+	}
+	return longest
+}
+
+// mdCell escapa o separador de colunas das tabelas Markdown
+func mdCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
+
+// mdCode formata o texto como código numa célula de tabela, delimitado por
+// mais crases que a maior sequência dele. Os espaços em volta, que o
+// Markdown descarta, separam as crases do texto das do delimitador.
+func mdCode(text string) string {
+	fence := strings.Repeat("`", backtickRun([]string{text})+1)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+	return fence + mdCell(text) + fence
+}
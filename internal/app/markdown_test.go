@@ -0,0 +1,134 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"svndiff/internal/patch"
+	"svndiff/pkg/config"
+)
+
+func TestDiffer_writeMarkdown(t *testing.T) {
+	diff := "Index: a|b.txt\n===\n--- a|b.txt\t(revision 1)\n+++ a|b.txt\t(revision 2)\n@@ -1,2 +1,2 @@\n um\n-```\n+~~~\n" +
+		"Index: <novo>.txt\n===\n--- <novo>.txt\t(nonexistent)\n+++ <novo>.txt\t(revision 2)\n@@ -0,0 +1,4 @@\n+1\n+2\n+3\n+4\n"
+	files, err := patch.ParseString(diff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	newDiffer := func(markdown config.MarkdownConfig) *Differ {
+		return NewDiffer(&config.Config{
+			BranchA:  config.BranchConfig{URL: "https://svn.example.com/trunk", Revisions: []string{"1"}},
+			BranchB:  config.BranchConfig{URL: "https://svn.example.com/branches/x", Revisions: []string{"2", "5"}},
+			Markdown: markdown,
+		})
+	}
+
+	t.Run("completo", func(t *testing.T) {
+		var out strings.Builder
+		if err := newDiffer(config.MarkdownConfig{MaxFileLines: 6}).writeMarkdown(&out, files); err != nil {
+			t.Fatalf("writeMarkdown() error = %v", err)
+		}
+		for _, want := range []string{
+			"| A | `https://svn.example.com/trunk` | 1 |\n",
+			"| B | `https://svn.example.com/branches/x` | 2, 5 |\n",
+			"2 arquivos: 1 adicionados, 1 modificados, 0 removidos · linhas: +5 −1\n",
+			"| Modified | `a\\|b.txt` | 1 | 1 |\n",
+			"| Added | `<novo>.txt` | 4 | 0 |\n",
+			"<summary><code>a|b.txt</code> (Modified, +1 −1)</summary>\n\n````diff\n--- a|b.txt\t(revision 1)\n",
+			"-```\n+~~~\n````\n",
+			"<summary><code>&lt;novo&gt;.txt</code> (Added, +4 −0)</summary>",
+			"+1\n+2\n+3\n```\n\n_… 1 linhas omitidas_\n",
+		} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("writeMarkdown() não contém %q:\n%s", want, out.String())
+			}
+		}
+	})
+
+	t.Run("limite de tamanho", func(t *testing.T) {
+		var out strings.Builder
+		if err := newDiffer(config.MarkdownConfig{MaxBytes: 900}).writeMarkdown(&out, files); err != nil {
+			t.Fatalf("writeMarkdown() error = %v", err)
+		}
+		if out.Len() > 900 {
+			t.Errorf("writeMarkdown() = %d bytes, want no máximo 900", out.Len())
+		}
+		if !strings.Contains(out.String(), "Diff de 1 arquivos omitido") || strings.Contains(out.String(), "<summary><code>&lt;novo") {
+			t.Errorf("writeMarkdown() deveria omitir o último arquivo:\n%s", out.String())
+		}
+		if !strings.Contains(out.String(), "| Added | `<novo>.txt` | 4 | 0 |\n") {
+			t.Error("writeMarkdown() deveria manter o arquivo omitido na tabela")
+		}
+	})
+}
+
+func TestDiffer_writeMarkdown_PropertyChanged(t *testing.T) {
+	diff := "Index: a.txt\n===\n--- a.txt\t(revision 1)\n+++ a.txt\t(revision 2)\n@@ -1 +1 @@\n-a\n+A\n" +
+		"Index: b.txt\n===\n--- b.txt\t(revision 1)\n+++ b.txt\t(revision 2)\n\nProperty changes on: b.txt\n___\nAdded: svn:eol-style\n## -0,0 +1 ##\n+native\n"
+	files, err := patch.ParseString(diff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	var out strings.Builder
+	if err := NewDiffer(&config.Config{}).writeMarkdown(&out, files); err != nil {
+		t.Fatalf("writeMarkdown() error = %v", err)
+	}
+	want := "2 arquivos: 0 adicionados, 1 modificados, 0 removidos, 1 só com propriedades · linhas: +1 −1\n"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("writeMarkdown() não contém %q:\n%s", want, out.String())
+	}
+}
+
+func TestDiffer_writeMarkdown_TableLimit(t *testing.T) {
+	var diff strings.Builder
+	for i := range 20 {
+		fmt.Fprintf(&diff, "Index: src/arquivo%02d.txt\n===\n--- src/arquivo%02d.txt\t(revision 1)\n+++ src/arquivo%02d.txt\t(revision 2)\n@@ -1 +1 @@\n-a\n+b\n", i, i, i)
+	}
+	files, err := patch.ParseString(diff.String())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	d := NewDiffer(&config.Config{Markdown: config.MarkdownConfig{MaxBytes: 700}})
+
+	var out strings.Builder
+	if err := d.writeMarkdown(&out, files); err != nil {
+		t.Fatalf("writeMarkdown() error = %v", err)
+	}
+	if out.Len() > 700 {
+		t.Errorf("writeMarkdown() = %d bytes, want no máximo 700:\n%s", out.Len(), out.String())
+	}
+	listed := strings.Count(out.String(), "| Modified |")
+	if listed == 0 || listed == len(files) {
+		t.Fatalf("writeMarkdown() listou %d de %d arquivos na tabela:\n%s", listed, len(files), out.String())
+	}
+	for _, want := range []string{
+		"20 arquivos: 0 adicionados, 20 modificados",
+		fmt.Sprintf("> ⚠️ %d arquivos omitidos", len(files)-listed),
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("writeMarkdown() não contém %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "<details>") {
+		t.Error("writeMarkdown() não deveria incluir diffs com a tabela cortada")
+	}
+}
+
+func TestMdCode(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"a.txt", "`a.txt`"},
+		{"a|b.txt", "`a\\|b.txt`"},
+		{"a`b.txt", "``a`b.txt``"},
+		{"`a``.txt", "``` `a``.txt ```"},
+	}
+	for _, tt := range tests {
+		if got := mdCode(tt.text); got != tt.want {
+			t.Errorf("mdCode(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
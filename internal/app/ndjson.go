@@ -0,0 +1,63 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ndjsonFile é o registro NDJSON de um arquivo alterado, com os campos de
+// FileChange
+type ndjsonFile struct {
+	Type string `json:"type"`
+	FileChange
+}
+
+// ndjsonSummary é o registro final do NDJSON, com os totais da comparação
+type ndjsonSummary struct {
+	Type       string     `json:"type"`
+	BranchA    BranchInfo `json:"branchA"`
+	BranchB    BranchInfo `json:"branchB"`
+	TotalFiles int        `json:"totalFiles"`
+}
+
+// outputNDJSON escreve um objeto JSON por linha para cada arquivo alterado,
+// à medida que a saída do svn é lida, seguido de um registro de resumo
+func (d *Differ) outputNDJSON() error {
+	r, err := d.openDiff(true)
+	if err != nil {
+		return fmt.Errorf("erro ao executar diff: %w", err)
+	}
+	defer r.Close()
+
+	return d.writeNDJSON(os.Stdout, r)
+}
+
+// writeNDJSON converte as linhas do svn diff --summarize lidas de r em
+// registros NDJSON escritos em w
+func (d *Differ) writeNDJSON(w io.Writer, r io.Reader) error {
+	enc := json.NewEncoder(w)
+	total := 0
+
+	for change, err := range d.fileChanges(r) {
+		if err != nil {
+			return fmt.Errorf("erro ao executar diff: %w", err)
+		}
+		if err := enc.Encode(ndjsonFile{Type: "file", FileChange: change}); err != nil {
+			return fmt.Errorf("erro ao gerar JSON: %w", err)
+		}
+		total++
+	}
+
+	summary := ndjsonSummary{
+		Type:       "summary",
+		BranchA:    branchInfo(&d.config.BranchA),
+		BranchB:    branchInfo(&d.config.BranchB),
+		TotalFiles: total,
+	}
+	if err := enc.Encode(summary); err != nil {
+		return fmt.Errorf("erro ao gerar JSON: %w", err)
+	}
+	return nil
+}
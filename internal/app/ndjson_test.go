@@ -0,0 +1,72 @@
+package app
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"svndiff/pkg/config"
+)
+
+func TestDiffer_writeNDJSON(t *testing.T) {
+	d := NewDiffer(&config.Config{
+		BranchA: config.BranchConfig{URL: "https://svn.example.com/trunk", Revisions: []string{"1"}},
+		BranchB: config.BranchConfig{URL: "https://svn.example.com/branches/x", Revisions: []string{"2"}},
+	})
+
+	var out strings.Builder
+	err := d.writeNDJSON(&out, strings.NewReader(summaryXML("M       src/a.go", "A       docs/guia rápido.md (dir)", "D       old.txt")))
+	if err != nil {
+		t.Fatalf("writeNDJSON() error = %v", err)
+	}
+	want := `{"type":"file","path":"src/a.go","status":"Modified","kind":"file","url":"https://svn.example.com/trunk/src/a.go"}
+{"type":"file","path":"docs/guia rápido.md","status":"Added","kind":"dir","url":"https://svn.example.com/trunk/docs/guia rápido.md"}
+{"type":"file","path":"old.txt","status":"Deleted","kind":"file","url":"https://svn.example.com/trunk/old.txt"}
+{"type":"summary","branchA":{"url":"https://svn.example.com/trunk","revisions":["1"],"latest":"1"},"branchB":{"url":"https://svn.example.com/branches/x","revisions":["2"],"latest":"2"},"totalFiles":3}
+`
+	if out.String() != want {
+		t.Errorf("writeNDJSON() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	// uma falha na leitura interrompe a saída sem o registro de resumo
+	out.Reset()
+	failing := io.MultiReader(strings.NewReader(xml.Header+"<diff>\n<paths>\n<path props=\"none\" kind=\"file\" item=\"modified\">a.txt</path>\n"), iotest.ErrReader(errors.New("comando svn falhou")))
+	if err := d.writeNDJSON(&out, failing); err == nil || strings.Contains(out.String(), `"summary"`) {
+		t.Errorf("writeNDJSON() = %q, %v, want erro sem resumo", out.String(), err)
+	}
+}
+
+func TestDiffer_writeJSON(t *testing.T) {
+	d := NewDiffer(&config.Config{
+		BranchA: config.BranchConfig{URL: "https://svn.example.com/trunk", Revisions: []string{"1", "3"}},
+		BranchB: config.BranchConfig{URL: "https://svn.example.com/branches/<x>", Revisions: []string{"2"}},
+	})
+
+	for _, output := range []string{summaryXML("M       src/a.go", "A       docs/guia & notas.md"), "", summaryXML("D       old.txt")} {
+		changes, err := d.parseFileChanges(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := json.MarshalIndent(DiffSummary{
+			BranchA:    branchInfo(&d.config.BranchA),
+			BranchB:    branchInfo(&d.config.BranchB),
+			Changes:    changes,
+			TotalFiles: len(changes),
+		}, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var out strings.Builder
+		if err := d.writeJSON(&out, strings.NewReader(output)); err != nil {
+			t.Fatalf("writeJSON() error = %v", err)
+		}
+		if out.String() != string(want)+"\n" {
+			t.Errorf("writeJSON() =\n%s\nwant\n%s", out.String(), want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"svndiff/internal/patch"
+	"svndiff/internal/svn"
+	"svndiff/pkg/config"
+)
+
+// outputPatch escreve o diff sem cores nem cabeçalho, no formato aceito
+// pelo svn patch e pelo patch -p0, incluindo as mudanças de propriedades e
+// os marcadores de arquivos binários, ou no formato do git apply (git-patch).
+// Com --out-dir, grava um arquivo por revisão da Branch B.
+func (d *Differ) outputPatch() error {
+	if d.config.OutDir != "" {
+		return d.writeRevisionPatches()
+	}
+
+	output, err := d.patchText(&d.config.BranchA, &d.config.BranchB)
+	if err != nil {
+		return fmt.Errorf("erro ao executar diff: %w", err)
+	}
+	fmt.Print(output)
+	return nil
+}
+
+// patchText gera o patch entre as branches no formato configurado: o do
+// svn diff ou, com git-patch, o formato estendido do git
+func (d *Differ) patchText(branchA, branchB *config.BranchConfig) (string, error) {
+	result, err := d.svnClient.GetDiff(branchA, branchB, false)
+	if err != nil {
+		return "", err
+	}
+	return d.formatPatch(result.Output, branchA, branchB)
+}
+
+// formatPatch converte a saída do svn diff entre branchA e branchB para o
+// formato de patch configurado, sem as diferenças de espaço ignoradas
+func (d *Differ) formatPatch(output string, branchA, branchB *config.BranchConfig) (string, error) {
+	output, err := d.filterWhitespace(output)
+	if err != nil {
+		return "", err
+	}
+	if d.config.Output != "git-patch" {
+		return output, nil
+	}
+
+	files, err := patch.ParseString(output)
+	if err != nil {
+		return "", err
+	}
+	var contents patch.Contents
+	if reader, ok := d.svnClient.(svn.FileReader); ok {
+		contents = func(path string) ([]byte, []byte, error) {
+			old, _, err := reader.ReadFile(branchA, path)
+			if err != nil {
+				return nil, nil, err
+			}
+			new, _, err := reader.ReadFile(branchB, path)
+			return old, new, err
+		}
+	}
+
+	var out strings.Builder
+	if err := patch.WriteGit(&out, files, contents); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// writeRevisionPatches grava em OutDir o diff de cada revisão da Branch B
+// em relação à anterior, numerados na ordem das revisões (0001-r125.patch).
+// Revisões que não alteram a branch não geram arquivo; a que cria a branch
+// gera o patch de todo o seu conteúdo.
+func (d *Differ) writeRevisionPatches() error {
+	branch := &d.config.BranchB
+	if !branch.HasRevisions() || len(branch.Revisions) == 0 {
+		return fmt.Errorf("--out-dir exige revisões da Branch B")
+	}
+	if err := os.MkdirAll(d.config.OutDir, 0o755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de patches: %w", err)
+	}
+
+	written := 0
+	for _, value := range branch.Revisions {
+		rev, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || rev < 1 {
+			return fmt.Errorf("revisão '%s' da Branch B não é numérica; patches por revisão exigem números", value)
+		}
+
+		before := &config.BranchConfig{URL: branch.URL, Revisions: []string{strconv.FormatInt(rev-1, 10)}}
+		after := &config.BranchConfig{URL: branch.URL, Revisions: []string{value}}
+		output, err := d.revisionPatch(before, after, rev)
+		if err != nil {
+			return fmt.Errorf("erro ao executar diff da revisão %d: %w", rev, err)
+		}
+		if output == "" {
+			fmt.Printf("r%d: sem mudanças na branch\n", rev)
+			continue
+		}
+
+		written++
+		path := filepath.Join(d.config.OutDir, fmt.Sprintf("%04d-r%d.patch", written, rev))
+		if err := os.WriteFile(path, []byte(output), 0o644); err != nil {
+			return fmt.Errorf("erro ao gravar patch: %w", err)
+		}
+		fmt.Printf("r%d: %s\n", rev, path)
+	}
+	return nil
+}
+
+// revisionPatch gera o patch das mudanças de rev, de before para after.
+// Backends com diff por revisão tratam o lado anterior inexistente, como na
+// revisão que cria a branch, como vazio.
+func (d *Differ) revisionPatch(before, after *config.BranchConfig, rev int64) (string, error) {
+	differ, ok := d.svnClient.(svn.RevisionDiffer)
+	if !ok {
+		return d.patchText(before, after)
+	}
+	result, err := differ.GetRevisionDiff(after, rev)
+	if err != nil {
+		return "", err
+	}
+	return d.formatPatch(result.Output, before, after)
+}
@@ -0,0 +1,160 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/dump"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+// patchHistory tem o trunk em r1 e três revisões a portar: uma alteração com
+// propriedade, uma em outra branch e uma com remoção e arquivo binário
+var patchHistory = [][]svntest.DumpNode{
+	{
+		{Path: "trunk", Kind: "dir", Action: "add"},
+		{Path: "trunk/a.txt", Kind: "file", Action: "add", Content: "um\ndois\ntrês\n"},
+		{Path: "trunk/rm.txt", Kind: "file", Action: "add", Content: "rm\n"},
+		{Path: "branches", Kind: "dir", Action: "add"},
+	},
+	{
+		{Path: "trunk/a.txt", Kind: "file", Action: "change", Content: "um\n2\ntrês\n"},
+		{Path: "trunk/novo.txt", Kind: "file", Action: "add", Content: "novo\n", Props: []string{"svn:eol-style", "native"}},
+	},
+	{
+		{Path: "branches/x", Kind: "dir", Action: "add", CopyFrom: "trunk", CopyRev: 2},
+	},
+	{
+		{Path: "trunk/rm.txt", Action: "delete"},
+		{Path: "trunk/img.bin", Kind: "file", Action: "add", Content: "\x89PNG\x00\x01", Props: []string{"svn:mime-type", "image/png"}},
+	},
+}
+
+func TestDiffer_writeRevisionPatches(t *testing.T) {
+	dumpFile := filepath.Join(t.TempDir(), "repo.dump")
+	if err := os.WriteFile(dumpFile, svntest.WriteDump(patchHistory), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	outDir := filepath.Join(t.TempDir(), "patches")
+	differ := &Differ{
+		config: &config.Config{
+			BranchB: config.BranchConfig{URL: dump.Scheme + filepath.ToSlash(dumpFile) + "/trunk", Revisions: []string{"2", "3", "4"}},
+			Output:  "patch",
+			OutDir:  outDir,
+		},
+		svnClient: svn.NewRepositoryBackend(func(url string) (svn.Repository, error) { return dump.Open(url) }),
+	}
+
+	if err := differ.writeRevisionPatches(); err != nil {
+		t.Fatalf("writeRevisionPatches() error = %v", err)
+	}
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if got, want := strings.Join(names, ","), "0001-r2.patch,0002-r4.patch"; got != want {
+		t.Fatalf("patches = %s, want %s", got, want)
+	}
+
+	second, err := os.ReadFile(filepath.Join(outDir, "0002-r4.patch"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fragment := range []string{
+		"Index: img.bin\n===================================================================\nCannot display: file marked as a binary type.\nsvn:mime-type = image/png\n",
+		"--- rm.txt\t(revision 3)\n+++ rm.txt\t(nonexistent)\n@@ -1 +0,0 @@\n-rm\n",
+	} {
+		if !strings.Contains(string(second), fragment) {
+			t.Errorf("0002-r4.patch sem %q:\n%s", fragment, second)
+		}
+	}
+
+	// Os patches se aplicam em sequência a uma cópia do trunk@1
+	if _, err := exec.LookPath("patch"); err != nil {
+		t.Skip("patch não encontrado no PATH")
+	}
+	target := t.TempDir()
+	for name, content := range map[string]string{"a.txt": "um\ndois\ntrês\n", "rm.txt": "rm\n"} {
+		if err := os.WriteFile(filepath.Join(target, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range names {
+		cmd := exec.Command("patch", "-p0", "--batch", "-i", filepath.Join(outDir, name))
+		cmd.Dir = target
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("patch -p0 < %s: %v\n%s", name, err, out)
+		}
+	}
+	for name, want := range map[string]string{"a.txt": "um\n2\ntrês\n", "novo.txt": "novo\n"} {
+		if got, err := os.ReadFile(filepath.Join(target, name)); err != nil || string(got) != want {
+			t.Errorf("%s após os patches = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if got, err := os.ReadFile(filepath.Join(target, "rm.txt")); err == nil && len(got) > 0 {
+		t.Errorf("rm.txt após os patches = %q, want removido ou vazio", got)
+	}
+}
+
+func TestDiffer_writeRevisionPatches_BranchCreation(t *testing.T) {
+	dumpFile := filepath.Join(t.TempDir(), "repo.dump")
+	if err := os.WriteFile(dumpFile, svntest.WriteDump(patchHistory), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	outDir := filepath.Join(t.TempDir(), "patches")
+	differ := &Differ{
+		config: &config.Config{
+			BranchB: config.BranchConfig{URL: dump.Scheme + filepath.ToSlash(dumpFile) + "/branches/x", Revisions: []string{"3"}},
+			Output:  "patch",
+			OutDir:  outDir,
+		},
+		svnClient: svn.NewRepositoryBackend(func(url string) (svn.Repository, error) { return dump.Open(url) }),
+	}
+
+	// branches/x não existe em r2: o patch de r3 adiciona todo o conteúdo
+	if err := differ.writeRevisionPatches(); err != nil {
+		t.Fatalf("writeRevisionPatches() error = %v", err)
+	}
+	created, err := os.ReadFile(filepath.Join(outDir, "0001-r3.patch"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fragment := range []string{
+		"--- a.txt\t(nonexistent)\n+++ a.txt\t(revision 3)\n@@ -0,0 +1,3 @@\n+um\n+2\n+três\n",
+		"--- novo.txt\t(nonexistent)\n+++ novo.txt\t(revision 3)\n",
+		"--- rm.txt\t(nonexistent)\n",
+	} {
+		if !strings.Contains(string(created), fragment) {
+			t.Errorf("0001-r3.patch sem %q:\n%s", fragment, created)
+		}
+	}
+}
+
+func TestDiffer_writeRevisionPatches_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		branch config.BranchConfig
+	}{
+		{"sem revisões", config.BranchConfig{URL: "https://svn.example.com/trunk"}},
+		{"revisão não numérica", config.BranchConfig{URL: "https://svn.example.com/trunk", Revisions: []string{"HEAD"}}},
+		{"cópia de trabalho", config.BranchConfig{URL: "/home/dev/checkout"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			differ := &Differ{config: &config.Config{BranchB: tt.branch, OutDir: t.TempDir()}}
+			if err := differ.writeRevisionPatches(); err == nil {
+				t.Error("writeRevisionPatches() esperava erro")
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package app
+
+import "iter"
+
+// PropChange é a mudança de uma propriedade versionada, como svn:mergeinfo,
+// svn:externals ou svn:eol-style
+type PropChange struct {
+	Name string `json:"name"`
+	// Action é "Added", "Deleted" ou "Modified"
+	Action string `json:"action"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// withProps completa as mudanças de seq com os valores das propriedades
+// alteradas. O svn diff --summarize só indica que elas mudaram, então o
+// índice do diff completo é lido na primeira mudança de propriedade.
+func (d *Differ) withProps(seq iter.Seq2[FileChange, error]) iter.Seq2[FileChange, error] {
+	return func(yield func(FileChange, error) bool) {
+		for change, err := range seq {
+			if err == nil && change.PropStatus != "" {
+				var index *diffIndex
+				if index, err = d.fullIndex(); err == nil {
+					change.Props = index.props[branchPath(d.config.BranchA.URL, change.Path)]
+				}
+			}
+			if !yield(change, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// propAction traduz a ação de uma mudança de propriedade
+func propAction(action string) string {
+	switch action {
+	case "Added":
+		return "adicionada"
+	case "Deleted":
+		return "removida"
+	default:
+		return "modificada"
+	}
+}
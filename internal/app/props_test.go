@@ -0,0 +1,94 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/dump"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+// countingBackend conta as execuções do diff completo
+type countingBackend struct {
+	svn.Backend
+	fullDiffs int
+}
+
+func (b *countingBackend) GetDiff(branchA, branchB *config.BranchConfig, summarize bool) (*svn.DiffResult, error) {
+	if !summarize {
+		b.fullDiffs++
+	}
+	return b.Backend.GetDiff(branchA, branchB, summarize)
+}
+
+func TestDiffer_withProps(t *testing.T) {
+	dumpFile := filepath.Join(t.TempDir(), "repo.dump")
+	history := [][]svntest.DumpNode{
+		{
+			{Path: "trunk", Kind: "dir", Action: "add"},
+			{Path: "trunk/a.txt", Kind: "file", Action: "add", Content: "a\n", Props: []string{"svn:eol-style", "LF"}},
+			{Path: "trunk/b.txt", Kind: "file", Action: "add", Content: "b\n"},
+		},
+		{
+			{Path: "trunk/a.txt", Kind: "file", Action: "change", Content: "a\n", Props: []string{"svn:eol-style", "native"}},
+			{Path: "trunk/b.txt", Kind: "file", Action: "change", Content: "B\n"},
+		},
+	}
+	if err := os.WriteFile(dumpFile, svntest.WriteDump(history), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	url := dump.Scheme + filepath.ToSlash(dumpFile) + "/trunk"
+	backend := &countingBackend{Backend: svn.NewRepositoryBackend(func(url string) (svn.Repository, error) { return dump.Open(url) })}
+	differ := &Differ{
+		config: &config.Config{
+			BranchA: config.BranchConfig{URL: url, Revisions: []string{"1"}},
+			BranchB: config.BranchConfig{URL: url, Revisions: []string{"2"}},
+		},
+		svnClient: backend,
+	}
+
+	r, err := differ.openDiff(true)
+	if err != nil {
+		t.Fatalf("openDiff() error = %v", err)
+	}
+	defer r.Close()
+
+	var got []FileChange
+	for change, err := range differ.withProps(differ.changes(r)) {
+		if err != nil {
+			t.Fatalf("withProps() error = %v", err)
+		}
+		got = append(got, change)
+	}
+	if len(got) != 2 {
+		t.Fatalf("mudanças = %+v", got)
+	}
+	a, b := got[0], got[1]
+	if a.Path != "a.txt" || a.Status != "PropertyChanged" || a.PropStatus != "Modified" {
+		t.Errorf("a.txt = %+v", a)
+	}
+	if len(a.Props) != 1 || a.Props[0] != (PropChange{Name: "svn:eol-style", Action: "Modified", Old: "LF", New: "native"}) {
+		t.Errorf("propriedades de a.txt = %+v", a.Props)
+	}
+	if b.Status != "Modified" || b.PropStatus != "" || b.Props != nil {
+		t.Errorf("b.txt = %+v", b)
+	}
+
+	// Uma nova passada reaproveita o índice, sem reler o diff completo
+	r, err = differ.openDiff(true)
+	if err != nil {
+		t.Fatalf("openDiff() error = %v", err)
+	}
+	defer r.Close()
+	for _, err := range differ.withProps(differ.changes(r)) {
+		if err != nil {
+			t.Fatalf("withProps() error = %v", err)
+		}
+	}
+	if backend.fullDiffs != 1 {
+		t.Errorf("withProps() leu o diff completo %d vezes, want 1", backend.fullDiffs)
+	}
+}
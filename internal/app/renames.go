@@ -0,0 +1,362 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"sort"
+	"strings"
+
+	"svndiff/internal/diff"
+	"svndiff/internal/svn"
+)
+
+// detectRenames reconhece entre as mudanças de seq as renomeações e cópias.
+// As cópias registradas vêm do log da Branch B, quando o backend o expõe, e
+// com uma similaridade mínima as remoções e adições restantes são pareadas
+// pelo conteúdo. A detecção precisa do resumo inteiro, então as mudanças são
+// reunidas antes de seguirem adiante.
+func (d *Differ) detectRenames(seq iter.Seq2[FileChange, error]) iter.Seq2[FileChange, error] {
+	if !d.config.Renames.Enabled() {
+		return seq
+	}
+	return func(yield func(FileChange, error) bool) {
+		var changes []FileChange
+		for change, err := range seq {
+			if err != nil {
+				yield(change, err)
+				return
+			}
+			changes = append(changes, change)
+		}
+		changes, err := d.pairRenames(changes)
+		if err != nil {
+			yield(FileChange{}, err)
+			return
+		}
+		for _, change := range changes {
+			if !yield(change, nil) {
+				return
+			}
+		}
+	}
+}
+
+// renamePairing acompanha o pareamento das mudanças de uma comparação
+type renamePairing struct {
+	d       *Differ
+	changes []FileChange
+	// deleted indexa as remoções pelo caminho relativo à branch
+	deleted map[string]int
+	// absorbed marca as remoções incorporadas a uma renomeação
+	absorbed map[int]bool
+	reader   svn.FileReader
+	contents map[string][]byte
+}
+
+// pairRenames converte em Renamed e Copied as adições com origem conhecida
+// e remove as remoções incorporadas às renomeações
+func (d *Differ) pairRenames(changes []FileChange) ([]FileChange, error) {
+	p := &renamePairing{d: d, changes: changes, deleted: make(map[string]int), absorbed: make(map[int]bool), contents: make(map[string][]byte)}
+	p.reader, _ = d.svnClient.(svn.FileReader)
+	for i, change := range changes {
+		if change.Status == "Deleted" {
+			p.deleted[p.rel(change)] = i
+		}
+	}
+
+	if d.config.Renames.Detect {
+		sources, err := d.copySources()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.pairCopies(sources); err != nil {
+			return nil, err
+		}
+	}
+	if d.config.Renames.Similarity > 0 && p.reader != nil {
+		if err := p.pairSimilar(d.config.Renames.Similarity); err != nil {
+			return nil, err
+		}
+	}
+
+	var result []FileChange
+	for i, change := range p.changes {
+		if !p.absorbed[i] {
+			result = append(result, change)
+		}
+	}
+	return result, nil
+}
+
+// copySources lê as cópias do histórico da Branch B. Sem histórico de cópias
+// no backend, avisa que só a similaridade de conteúdo pareia as mudanças.
+func (d *Differ) copySources() (map[string]string, error) {
+	var sources map[string]string
+	err := svn.ErrUnsupported
+	if tracer, ok := d.svnClient.(svn.CopyTracer); ok {
+		sources, err = tracer.CopySources(&d.config.BranchB, d.config.BranchA.URL)
+	}
+	switch {
+	case errors.Is(err, svn.ErrUnsupported):
+		fmt.Fprintln(os.Stderr, "Aviso: o backend não lê o histórico de cópias; --renames só pareia remoções e adições pelo conteúdo, com --similarity")
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("erro ao ler histórico de cópias: %w", err)
+	}
+	return sources, nil
+}
+
+// pairCopies marca as adições copiadas no histórico da Branch B. Se a origem,
+// ou um diretório acima dela, foi removida, a cópia é uma renomeação.
+func (p *renamePairing) pairCopies(sources map[string]string) error {
+	for i := range p.changes {
+		change := &p.changes[i]
+		if change.Status != "Added" && change.Status != "Replaced" {
+			continue
+		}
+		rel := p.rel(*change)
+		source, ok := copySource(sources, rel)
+		if !ok {
+			continue
+		}
+
+		change.Status = "Copied"
+		if j, ok := p.deleted[source]; ok && !p.absorbed[j] {
+			change.Status = "Renamed"
+			p.absorbed[j] = true
+		} else if j, ok := p.deletedAncestor(source); ok {
+			// A remoção do diretório de origem faz parte da renomeação
+			change.Status = "Renamed"
+			p.absorbed[j] = true
+		}
+		change.OldPath = strings.TrimSuffix(change.Path, rel) + source
+
+		if change.Kind != string(svn.NodeDir) && p.reader != nil {
+			score, err := p.similarity(source, rel)
+			if err != nil {
+				return err
+			}
+			change.Similarity = score
+			p.release("B", rel)
+		}
+	}
+	return nil
+}
+
+// similarityCandidates limita as remoções comparadas pelo conteúdo com cada
+// adição, escolhidas entre as de tamanho mais próximo
+const similarityCandidates = 50
+
+// pairSimilar pareia pelo conteúdo as adições e remoções de arquivos que
+// restaram, escolhendo para cada adição a remoção mais parecida com pelo
+// menos threshold% de conteúdo em comum. Só são comparadas as remoções cujo
+// número de linhas permite atingir threshold.
+func (p *renamePairing) pairSimilar(threshold int) error {
+	var removed []int
+	for j, change := range p.changes {
+		if change.Status == "Deleted" && change.Kind != string(svn.NodeDir) && !p.absorbed[j] {
+			removed = append(removed, j)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	for i := range p.changes {
+		change := &p.changes[i]
+		if change.Status != "Added" || change.Kind == string(svn.NodeDir) {
+			continue
+		}
+		rel := p.rel(*change)
+		new, err := p.content("B", rel)
+		if err != nil {
+			return err
+		}
+		if new == nil {
+			continue
+		}
+		candidates, err := p.candidates(removed, lineCount(new), threshold)
+		if err != nil {
+			return err
+		}
+
+		best, bestScore := -1, threshold-1
+		for _, j := range candidates {
+			score, err := p.similarity(p.rel(p.changes[j]), rel)
+			if err != nil {
+				return err
+			}
+			if score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+		p.release("B", rel)
+		if best >= 0 {
+			change.Status = "Renamed"
+			change.OldPath = p.changes[best].Path
+			change.Similarity = bestScore
+			p.absorbed[best] = true
+			p.release("A", p.rel(p.changes[best]))
+		}
+	}
+	return nil
+}
+
+// candidates escolhe entre as remoções ainda livres as que, pelo número de
+// linhas, podem ter threshold% em comum com um conteúdo de lines linhas,
+// das de tamanho mais próximo para as mais distantes
+func (p *renamePairing) candidates(removed []int, lines, threshold int) ([]int, error) {
+	type candidate struct{ index, bound int }
+	var list []candidate
+	for _, j := range removed {
+		if p.absorbed[j] {
+			continue
+		}
+		old, err := p.content("A", p.rel(p.changes[j]))
+		if err != nil {
+			return nil, err
+		}
+		if bound := similarityBound(lineCount(old), lines); old != nil && bound >= threshold {
+			list = append(list, candidate{j, bound})
+		}
+	}
+	sort.SliceStable(list, func(a, b int) bool { return list[a].bound > list[b].bound })
+
+	indexes := make([]int, 0, min(len(list), similarityCandidates))
+	for _, c := range list[:min(len(list), similarityCandidates)] {
+		indexes = append(indexes, c.index)
+	}
+	return indexes, nil
+}
+
+// similarityBound é a maior similaridade possível entre conteúdos com a e b
+// linhas, quando todas as linhas do menor se repetem no maior
+func similarityBound(a, b int) int {
+	if a+b == 0 {
+		return 100
+	}
+	return 200 * min(a, b) / (a + b)
+}
+
+// similarity compara o conteúdo de oldPath na Branch A com o de newPath na
+// Branch B. Arquivos binários só são semelhantes quando idênticos, e
+// caminhos inexistentes em algum dos lados não têm similaridade.
+func (p *renamePairing) similarity(oldPath, newPath string) (int, error) {
+	old, err := p.content("A", oldPath)
+	if err != nil {
+		return 0, err
+	}
+	new, err := p.content("B", newPath)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case old == nil || new == nil:
+		return 0, nil
+	case bytes.Equal(old, new):
+		return 100, nil
+	case bytes.IndexByte(old, 0) >= 0 || bytes.IndexByte(new, 0) >= 0:
+		return 0, nil
+	}
+	return diff.Similarity(contentLines(old), contentLines(new)), nil
+}
+
+// content lê, uma única vez, o conteúdo de um arquivo da branch. Caminhos
+// inexistentes têm conteúdo nil.
+func (p *renamePairing) content(side, path string) ([]byte, error) {
+	key := side + ":" + path
+	if content, ok := p.contents[key]; ok {
+		return content, nil
+	}
+	branch := &p.d.config.BranchA
+	if side == "B" {
+		branch = &p.d.config.BranchB
+	}
+	content, ok, err := p.reader.ReadFile(branch, path)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	case !ok:
+		content = nil
+	case content == nil:
+		content = []byte{}
+	}
+	p.contents[key] = content
+	return content, nil
+}
+
+// release descarta o conteúdo já lido de um arquivo que não será mais
+// comparado
+func (p *renamePairing) release(side, path string) {
+	delete(p.contents, side+":"+path)
+}
+
+// deletedAncestor retorna a remoção do diretório mais próximo acima de path,
+// se houver
+func (p *renamePairing) deletedAncestor(path string) (int, bool) {
+	for dir := parentDir(path); dir != ""; dir = parentDir(dir) {
+		if j, ok := p.deleted[dir]; ok {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+// rel retorna o caminho da mudança relativo à branch
+func (p *renamePairing) rel(change FileChange) string {
+	return branchPath(p.d.config.BranchA.URL, change.Path)
+}
+
+// copySource resolve a origem de path pelas cópias registradas, inclusive
+// as herdadas de um diretório copiado e as cópias em cadeia (a → b → c)
+func copySource(sources map[string]string, path string) (string, bool) {
+	source, found := "", false
+	for range len(sources) {
+		next, ok := ancestorSource(sources, path)
+		if !ok {
+			break
+		}
+		source, found, path = next, true, next
+	}
+	return source, found
+}
+
+// ancestorSource procura a cópia de path ou do diretório mais próximo acima
+// dele, e completa a origem com o restante do caminho
+func ancestorSource(sources map[string]string, path string) (string, bool) {
+	for dir := path; dir != ""; dir = parentDir(dir) {
+		if source, ok := sources[dir]; ok {
+			return source + strings.TrimPrefix(path, dir), true
+		}
+	}
+	return "", false
+}
+
+// parentDir retorna o diretório acima de path, ou "" na raiz
+func parentDir(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// lineCount conta as linhas do conteúdo como contentLines, sem dividi-lo
+func lineCount(content []byte) int {
+	n := bytes.Count(content, []byte("\n"))
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		n++
+	}
+	return n
+}
+
+// contentLines divide o conteúdo em linhas para medir a similaridade
+func contentLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/dump"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+// copyBackend simula um backend com histórico de cópias e leitura de arquivos
+type copyBackend struct {
+	svn.Backend
+	sources  map[string]string
+	contents map[string]string // "A:caminho" ou "B:caminho"
+}
+
+func (b *copyBackend) CopySources(*config.BranchConfig, ...string) (map[string]string, error) {
+	return b.sources, nil
+}
+
+func (b *copyBackend) ReadFile(branch *config.BranchConfig, path string) ([]byte, bool, error) {
+	side := "A"
+	if branch.URL == "https://svn.example.com/branches/x" {
+		side = "B"
+	}
+	content, ok := b.contents[side+":"+path]
+	return []byte(content), ok, nil
+}
+
+func TestDiffer_pairRenames(t *testing.T) {
+	backend := &copyBackend{
+		sources: map[string]string{"src/novo.go": "src/velho.go", "docs/copia.md": "docs/guia.md", "pkg": "lib"},
+		contents: map[string]string{
+			"A:src/velho.go":   "package src\n\nfunc A() {}\n\nfunc B() {}\n",
+			"B:src/novo.go":    "package src\n\nfunc A() {}\n\nfunc C() {}\n",
+			"A:docs/guia.md":   "guia\n",
+			"B:docs/copia.md":  "guia\n",
+			"A:util.txt":       "um\ndois\ntrês\nquatro\n",
+			"B:tools/util.txt": "um\ndois\ntrês\ncinco\n",
+			"A:outro.txt":      "nada\n",
+			"B:novo.txt":       "diferente\n",
+		},
+	}
+	changes := []FileChange{
+		{Path: "https://svn.example.com/trunk/lib", Status: "Deleted", Kind: "dir"},
+		{Path: "https://svn.example.com/trunk/outro.txt", Status: "Deleted", Kind: "file"},
+		{Path: "https://svn.example.com/trunk/src/velho.go", Status: "Deleted", Kind: "file"},
+		{Path: "https://svn.example.com/trunk/util.txt", Status: "Deleted", Kind: "file"},
+		{Path: "https://svn.example.com/trunk/docs/copia.md", Status: "Added", Kind: "file"},
+		{Path: "https://svn.example.com/trunk/novo.txt", Status: "Added", Kind: "file"},
+		{Path: "https://svn.example.com/trunk/pkg", Status: "Added", Kind: "dir"},
+		{Path: "https://svn.example.com/trunk/pkg/a.go", Status: "Added", Kind: "file"},
+		{Path: "https://svn.example.com/trunk/src/novo.go", Status: "Added", Kind: "file"},
+		{Path: "https://svn.example.com/trunk/tools/util.txt", Status: "Added", Kind: "file"},
+	}
+	d := &Differ{
+		config: &config.Config{
+			BranchA: config.BranchConfig{URL: "https://svn.example.com/trunk"},
+			BranchB: config.BranchConfig{URL: "https://svn.example.com/branches/x"},
+			Renames: config.RenamesConfig{Detect: true, Similarity: 50},
+		},
+		svnClient: backend,
+	}
+
+	got, err := d.pairRenames(changes)
+	if err != nil {
+		t.Fatalf("pairRenames() error = %v", err)
+	}
+	base := "https://svn.example.com/trunk/"
+	want := []FileChange{
+		{Path: base + "outro.txt", Status: "Deleted", Kind: "file"},
+		{Path: base + "docs/copia.md", Status: "Copied", Kind: "file", OldPath: base + "docs/guia.md", Similarity: 100},
+		{Path: base + "novo.txt", Status: "Added", Kind: "file"},
+		{Path: base + "pkg", Status: "Renamed", Kind: "dir", OldPath: base + "lib"},
+		{Path: base + "pkg/a.go", Status: "Renamed", Kind: "file", OldPath: base + "lib/a.go"},
+		{Path: base + "src/novo.go", Status: "Renamed", Kind: "file", OldPath: base + "src/velho.go", Similarity: 80},
+		{Path: base + "tools/util.txt", Status: "Renamed", Kind: "file", OldPath: base + "util.txt", Similarity: 75},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pairRenames() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestDiffer_pairRenames_MovedDirectory(t *testing.T) {
+	// old foi movido para new; x.c saiu de lib, removido inteiro
+	backend := &copyBackend{sources: map[string]string{"new": "old", "tools/x.c": "lib/x.c"}}
+	base := "https://svn.example.com/trunk/"
+	changes := []FileChange{
+		{Path: base + "lib", Status: "Deleted", Kind: "dir"},
+		{Path: base + "old", Status: "Deleted", Kind: "dir"},
+		{Path: base + "new", Status: "Added", Kind: "dir"},
+		{Path: base + "new/a.txt", Status: "Added", Kind: "file"},
+		{Path: base + "tools/x.c", Status: "Added", Kind: "file"},
+	}
+	d := &Differ{
+		config: &config.Config{
+			BranchA: config.BranchConfig{URL: "https://svn.example.com/trunk"},
+			BranchB: config.BranchConfig{URL: "https://svn.example.com/branches/x"},
+			Renames: config.RenamesConfig{Detect: true},
+		},
+		svnClient: backend,
+	}
+
+	got, err := d.pairRenames(changes)
+	if err != nil {
+		t.Fatalf("pairRenames() error = %v", err)
+	}
+	want := []FileChange{
+		{Path: base + "new", Status: "Renamed", Kind: "dir", OldPath: base + "old"},
+		{Path: base + "new/a.txt", Status: "Renamed", Kind: "file", OldPath: base + "old/a.txt"},
+		{Path: base + "tools/x.c", Status: "Renamed", Kind: "file", OldPath: base + "lib/x.c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pairRenames() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestSimilarityBound(t *testing.T) {
+	tests := []struct {
+		a, b, want int
+	}{
+		{0, 0, 100},
+		{10, 10, 100},
+		{10, 30, 50},
+		{1, 99, 2},
+	}
+	for _, tt := range tests {
+		if got := similarityBound(tt.a, tt.b); got != tt.want {
+			t.Errorf("similarityBound(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+	for _, content := range []string{"", "a", "a\n", "a\nb", "a\n\nb\n"} {
+		if got, want := lineCount([]byte(content)), len(contentLines([]byte(content))); got != want {
+			t.Errorf("lineCount(%q) = %d, want %d", content, got, want)
+		}
+	}
+}
+
+func TestDiffer_detectRenames_RepositoryBackend(t *testing.T) {
+	dumpFile := filepath.Join(t.TempDir(), "repo.dump")
+	history := [][]svntest.DumpNode{
+		{
+			{Path: "trunk", Kind: "dir", Action: "add"},
+			{Path: "branches", Kind: "dir", Action: "add"},
+			{Path: "trunk/old.txt", Kind: "file", Action: "add", Content: "velho\n"},
+		},
+		{
+			{Path: "branches/x", Kind: "dir", Action: "add", CopyFrom: "trunk", CopyRev: 1},
+		},
+		{
+			{Path: "branches/x/new.txt", Kind: "file", Action: "add", CopyFrom: "branches/x/old.txt", CopyRev: 2},
+			{Path: "branches/x/old.txt", Action: "delete"},
+		},
+	}
+	if err := os.WriteFile(dumpFile, svntest.WriteDump(history), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	base := dump.Scheme + filepath.ToSlash(dumpFile)
+	d := &Differ{
+		config: &config.Config{
+			BranchA: config.BranchConfig{URL: base + "/trunk", Revisions: []string{"1"}},
+			BranchB: config.BranchConfig{URL: base + "/branches/x", Revisions: []string{"3"}},
+			Renames: config.RenamesConfig{Detect: true},
+		},
+		svnClient: svn.NewRepositoryBackend(func(url string) (svn.Repository, error) { return dump.Open(url) }),
+	}
+
+	r, err := d.openDiff(true)
+	if err != nil {
+		t.Fatalf("openDiff() error = %v", err)
+	}
+	defer r.Close()
+	var got []FileChange
+	for change, err := range d.detectRenames(d.changes(r)) {
+		if err != nil {
+			t.Fatalf("detectRenames() error = %v", err)
+		}
+		got = append(got, change)
+	}
+	if len(got) != 1 || got[0].Path != "new.txt" || got[0].Status != "Renamed" || got[0].OldPath != "old.txt" || got[0].Similarity != 100 {
+		t.Errorf("detectRenames() = %+v, want new.txt renomeado de old.txt", got)
+	}
+}
+
+func TestCopySource(t *testing.T) {
+	sources := map[string]string{"c.txt": "b.txt", "b.txt": "a.txt", "novo": "velho"}
+	tests := []struct {
+		path, want string
+		ok         bool
+	}{
+		{"c.txt", "a.txt", true},
+		{"novo/sub/x.go", "velho/sub/x.go", true},
+		{"outro.txt", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := copySource(sources, tt.path)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("copySource(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
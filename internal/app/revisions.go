@@ -0,0 +1,162 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"svndiff/internal/svn"
+	"svndiff/pkg/config"
+)
+
+// logFilter reúne os critérios usados para selecionar entradas de log.
+// Todos os critérios definidos precisam ser satisfeitos.
+type logFilter struct {
+	issue  *regexp.Regexp
+	author string
+	since  time.Time
+	until  time.Time
+}
+
+// resolveRevisions descobre as revisões de cada branch a partir da issue e
+// dos filtros de autor e datas, preenchendo BranchConfig.Revisions antes da
+// comparação
+func (d *Differ) resolveRevisions() error {
+	branches := []struct {
+		name   string
+		branch *config.BranchConfig
+	}{
+		{"A", &d.config.BranchA},
+		{"B", &d.config.BranchB},
+	}
+
+	for _, b := range branches {
+		// Cópias de trabalho e repositórios Git não têm histórico SVN
+		if (d.config.Issue.Key == "" && !b.branch.HasLogFilters()) || !b.branch.HasRevisions() {
+			continue
+		}
+
+		filter, opts, err := d.buildLogFilter(b.branch)
+		if err != nil {
+			return fmt.Errorf("Branch %s: %w", b.name, err)
+		}
+
+		entries, err := d.svnClient.GetLogEntries(b.branch.URL, opts)
+		if err != nil {
+			return fmt.Errorf("erro ao obter log da Branch %s: %w", b.name, err)
+		}
+
+		// A janela da issue pode deixar de fora revisões mais antigas
+		if opts.Limit > 0 && len(entries) >= opts.Limit {
+			fmt.Fprintf(os.Stderr, "Aviso: só as %d entradas de log mais recentes da Branch %s foram examinadas; use --issue-limit para ampliar a busca\n", opts.Limit, b.name)
+		}
+
+		found := filter.match(entries)
+		if len(found) == 0 && len(b.branch.Revisions) == 0 {
+			return fmt.Errorf("nenhuma revisão da Branch %s corresponde aos filtros de log", b.name)
+		}
+
+		b.branch.Revisions = mergeRevisions(b.branch.Revisions, found)
+	}
+
+	return nil
+}
+
+// buildLogFilter monta os critérios e a janela do svn log para uma branch.
+// Com filtros de data a janela é delimitada pelas datas; caso contrário, a
+// busca pela issue examina as entradas mais recentes até o limite
+// configurado, e o filtro só de autor examina o histórico inteiro.
+func (d *Differ) buildLogFilter(branch *config.BranchConfig) (*logFilter, svn.LogOptions, error) {
+	filter := &logFilter{author: branch.Author}
+	opts := svn.LogOptions{Range: "HEAD:1"}
+
+	if d.config.Issue.Key != "" {
+		opts.Limit = d.config.Issue.GetLimit()
+		re, err := d.config.Issue.IssueRegexp()
+		if err != nil {
+			return nil, opts, err
+		}
+		filter.issue = re
+	}
+
+	since, err := branch.SinceTime()
+	if err != nil {
+		return nil, opts, err
+	}
+	until, err := branch.UntilTime()
+	if err != nil {
+		return nil, opts, err
+	}
+	filter.since, filter.until = since, until
+
+	if !since.IsZero() || !until.IsZero() {
+		start, end := "1", "HEAD"
+		if !since.IsZero() {
+			start = svnDate(since)
+		}
+		if !until.IsZero() {
+			end = svnDate(until)
+		}
+		opts = svn.LogOptions{Range: start + ":" + end}
+	}
+
+	return filter, opts, nil
+}
+
+// match retorna as revisões das entradas que satisfazem o filtro
+func (f *logFilter) match(entries []svn.LogEntry) []string {
+	var revisions []string
+	for _, entry := range entries {
+		if f.issue != nil && !f.issue.MatchString(entry.Message) {
+			continue
+		}
+		if f.author != "" && entry.Author != f.author {
+			continue
+		}
+		// O svn resolve {data} para a revisão vigente naquela data, que pode
+		// ser anterior à janela, por isso as datas são conferidas novamente
+		if !f.since.IsZero() && entry.Date.Before(f.since) {
+			continue
+		}
+		if !f.until.IsZero() && !entry.Date.Before(f.until) {
+			continue
+		}
+		revisions = append(revisions, entry.Revision)
+	}
+	return revisions
+}
+
+// svnDate formata uma data no formato de revisão {data} aceito pelo svn
+func svnDate(t time.Time) string {
+	return "{" + t.UTC().Format("2006-01-02T15:04:05Z") + "}"
+}
+
+// mergeRevisions une as listas de revisões sem duplicatas, em ordem numérica
+// crescente, de modo que a última da lista continue sendo a mais recente
+func mergeRevisions(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var merged []string
+	for _, list := range lists {
+		for _, rev := range list {
+			if !seen[rev] {
+				seen[rev] = true
+				merged = append(merged, rev)
+			}
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		ri, errI := strconv.Atoi(merged[i])
+		rj, errJ := strconv.Atoi(merged[j])
+		if errI != nil || errJ != nil {
+			// Revisões não numéricas (ex: HEAD) ficam no fim
+			return errI == nil && errJ != nil
+		}
+		return ri < rj
+	})
+
+	return merged
+}
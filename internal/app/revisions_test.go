@@ -0,0 +1,100 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"svndiff/internal/svn"
+	"svndiff/pkg/config"
+)
+
+func TestLogFilter_matchIssue(t *testing.T) {
+	issue := config.IssueConfig{Key: "PROJ-12"}
+	re, err := issue.IssueRegexp()
+	if err != nil {
+		t.Fatalf("IssueRegexp() error = %v", err)
+	}
+
+	entries := []svn.LogEntry{
+		{Revision: "130", Message: "PROJ-12: ajusta cálculo"},
+		{Revision: "129", Message: "PROJ-123: outra issue"},
+		{Revision: "128", Message: "Merge de PROJ-12 e PROJ-13"},
+		{Revision: "127", Message: "sem referência"},
+	}
+
+	got := (&logFilter{issue: re}).match(entries)
+	want := []string{"130", "128"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("match() = %v, want %v", got, want)
+	}
+}
+
+func TestLogFilter_matchAuthorAndDates(t *testing.T) {
+	branch := config.BranchConfig{Author: "alice", Since: "2024-03-01", Until: "2024-03-14"}
+	since, _ := branch.SinceTime()
+	until, _ := branch.UntilTime()
+	filter := &logFilter{author: branch.Author, since: since, until: until}
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 12, 0, 0, 0, time.Local) }
+	entries := []svn.LogEntry{
+		{Revision: "140", Author: "alice", Date: day(15)},
+		{Revision: "139", Author: "alice", Date: day(14)},
+		{Revision: "138", Author: "bob", Date: day(10)},
+		{Revision: "137", Author: "alice", Date: day(1)},
+		{Revision: "136", Author: "alice", Date: day(1).Add(-13 * time.Hour)},
+	}
+
+	got := filter.match(entries)
+	want := []string{"139", "137"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("match() = %v, want %v", got, want)
+	}
+}
+
+func TestMergeRevisions(t *testing.T) {
+	got := mergeRevisions([]string{"130", "HEAD"}, []string{"128", "130", "9"})
+	want := []string{"9", "128", "130", "HEAD"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeRevisions() = %v, want %v", got, want)
+	}
+}
+
+func TestDiffer_buildLogFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		issue  config.IssueConfig
+		branch config.BranchConfig
+		want   svn.LogOptions
+	}{
+		{
+			name:   "issue examina a janela configurada",
+			issue:  config.IssueConfig{Key: "PROJ-1"},
+			branch: config.BranchConfig{Author: "alice"},
+			want:   svn.LogOptions{Range: "HEAD:1", Limit: config.DefaultIssueLimit},
+		},
+		{
+			name:   "só autor examina o histórico inteiro",
+			issue:  config.IssueConfig{Limit: 10},
+			branch: config.BranchConfig{Author: "alice"},
+			want:   svn.LogOptions{Range: "HEAD:1"},
+		},
+		{
+			name:   "datas delimitam a janela",
+			branch: config.BranchConfig{Since: "2024-03-01T00:00:00Z"},
+			want:   svn.LogOptions{Range: "{2024-03-01T00:00:00Z}:HEAD"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			differ := &Differ{config: &config.Config{Issue: tt.issue}}
+			_, opts, err := differ.buildLogFilter(&tt.branch)
+			if err != nil {
+				t.Fatalf("buildLogFilter() error = %v", err)
+			}
+			if !reflect.DeepEqual(opts, tt.want) {
+				t.Errorf("buildLogFilter() opts = %+v, want %+v", opts, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"svndiff/internal/patch"
+)
+
+// sarifSchema e sarifVersion identificam o formato SARIF gerado
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// sarifRules são as regras do relatório, uma por tipo de mudança
+var sarifRules = []sarifRule{
+	{ID: "added", Name: "AddedInBranchB", ShortDescription: sarifMessage{"Conteúdo presente só na Branch B"}},
+	{ID: "deleted", Name: "DeletedInBranchB", ShortDescription: sarifMessage{"Conteúdo presente só na Branch A"}},
+	{ID: "modified", Name: "ModifiedBetweenBranches", ShortDescription: sarifMessage{"Conteúdo diferente entre as branches"}},
+	{ID: "property-changed", Name: "PropertyChanged", ShortDescription: sarifMessage{"Propriedade SVN diferente entre as branches"}},
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactURI `json:"originalUriBaseIds"`
+	Results            []sarifResult               `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactURI `json:"artifactLocation"`
+	Region           *sarifRegion     `json:"region,omitempty"`
+}
+
+type sarifArtifactURI struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification"`
+}
+
+// outputSARIF gera um relatório SARIF para plataformas de code scanning, com
+// um resultado por trecho divergente
+func (d *Differ) outputSARIF() error {
+	r, err := d.openFullDiff()
+	if err != nil {
+		return fmt.Errorf("erro ao executar diff: %w", err)
+	}
+	defer r.Close()
+	return d.writeSARIF(os.Stdout, r)
+}
+
+// writeSARIF lê as seções do diff de r e escreve o relatório em w. Os
+// trechos de arquivos removidos apontam para a Branch A (BRANCHA); os
+// demais, para a Branch B (BRANCHB). Diferenças permitidas pelo filtro são
+// registradas como suprimidas.
+func (d *Differ) writeSARIF(w io.Writer, r io.Reader) error {
+	matcher, err := d.config.Filter.Matcher()
+	if err != nil {
+		return err
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "svndiff",
+			InformationURI: "https://github.com/tiagotnx/svndiff",
+			Rules:          sarifRules,
+		}},
+		OriginalURIBaseIDs: map[string]sarifArtifactURI{
+			"BRANCHA": {URI: strings.TrimSuffix(d.config.BranchA.URL, "/") + "/"},
+			"BRANCHB": {URI: strings.TrimSuffix(d.config.BranchB.URL, "/") + "/"},
+		},
+		Results: []sarifResult{},
+	}
+
+	reader := patch.NewReader(r)
+	for {
+		f, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao interpretar diff: %w", err)
+		}
+		if !matcher.Selects(f.Path) {
+			continue
+		}
+		results := sarifFileResults(f)
+		if matcher.Allows(f.Path) {
+			for i := range results {
+				results[i].Suppressions = []sarifSuppression{{Kind: "external", Justification: "Diferença permitida pelo filtro"}}
+			}
+		}
+		run.Results = append(run.Results, results...)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}); err != nil {
+		return fmt.Errorf("erro ao gerar SARIF: %w", err)
+	}
+	return nil
+}
+
+// sarifFileResults converte a seção de um arquivo em resultados: um por
+// hunk, um para o arquivo binário e um por propriedade alterada
+func sarifFileResults(f *patch.File) []sarifResult {
+	ruleID, baseID := "modified", "BRANCHB"
+	switch {
+	case f.Added():
+		ruleID = "added"
+	case f.Deleted():
+		ruleID, baseID = "deleted", "BRANCHA"
+	}
+	artifact := sarifArtifactURI{URI: f.Path, URIBaseID: baseID}
+
+	var results []sarifResult
+	for _, hunk := range f.Hunks {
+		start, count := hunk.NewStart, hunk.NewLines
+		if baseID == "BRANCHA" {
+			start, count = hunk.OldStart, hunk.OldLines
+		}
+		// hunks sem linhas do lado apontado marcam a posição da mudança
+		start = max(start, 1)
+		end := max(start+count-1, start)
+		header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines)
+		results = append(results, sarifResult{
+			RuleID:  ruleID,
+			Level:   "warning",
+			Message: sarifMessage{fmt.Sprintf("%s: linhas %d-%d divergem entre as branches (%s)", f.Status(), start, end, header)},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: artifact,
+				Region:           &sarifRegion{StartLine: start, EndLine: end},
+			}}},
+		})
+	}
+	if f.Binary || (len(f.Hunks) == 0 && len(f.Props) == 0) {
+		message := fmt.Sprintf("%s: arquivo diferente entre as branches", f.Status())
+		if len(f.Details) > 0 {
+			message += " (" + strings.Join(f.Details, "; ") + ")"
+		}
+		results = append(results, sarifResult{
+			RuleID:    ruleID,
+			Level:     "warning",
+			Message:   sarifMessage{message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: artifact}}},
+		})
+	}
+	for _, prop := range f.Props {
+		results = append(results, sarifResult{
+			RuleID:    "property-changed",
+			Level:     "note",
+			Message:   sarifMessage{fmt.Sprintf("Propriedade %s %s: %q → %q", prop.Name, propAction(prop.Action), prop.Old, prop.New)},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: artifact}}},
+		})
+	}
+	return results
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"svndiff/pkg/config"
+)
+
+func TestDiffer_writeSARIF(t *testing.T) {
+	d := NewDiffer(&config.Config{
+		BranchA: config.BranchConfig{URL: "https://svn.example.com/trunk"},
+		BranchB: config.BranchConfig{URL: "https://svn.example.com/branches/x/"},
+		Filter:  config.FilterConfig{Exclude: []string{"*.lock"}, Allow: []string{"docs/**"}},
+	})
+	diff := "Index: src/a.go\n===\n--- src/a.go\t(revision 10)\n+++ src/a.go\t(revision 12)\n@@ -3,2 +3,3 @@\n ctx\n-velho\n+novo\n+extra\n" +
+		"Index: rm.txt\n===\n--- rm.txt\t(revision 10)\n+++ rm.txt\t(nonexistent)\n@@ -1,2 +0,0 @@\n-a\n-b\n" +
+		"Index: run.sh\n===\n--- run.sh\t(revision 10)\n+++ run.sh\t(revision 12)\n\n" +
+		"Property changes on: run.sh\n___\nAdded: svn:executable\n## -0,0 +1 ##\n+*\n\\ No newline at end of property\n" +
+		"Index: go.lock\n===\n--- go.lock\t(revision 10)\n+++ go.lock\t(revision 12)\n@@ -1 +1 @@\n-1\n+2\n" +
+		"Index: docs/guia.md\n===\n--- docs/guia.md\t(nonexistent)\n+++ docs/guia.md\t(revision 12)\n@@ -0,0 +1 @@\n+guia\n"
+
+	var out strings.Builder
+	if err := d.writeSARIF(&out, strings.NewReader(diff)); err != nil {
+		t.Fatalf("writeSARIF() error = %v", err)
+	}
+
+	var got sarifLog
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("JSON inválido: %v\n%s", err, out.String())
+	}
+	if got.Version != sarifVersion || len(got.Runs) != 1 {
+		t.Fatalf("log = versão %q, %d runs", got.Version, len(got.Runs))
+	}
+	run := got.Runs[0]
+	if base := run.OriginalURIBaseIDs["BRANCHB"].URI; base != "https://svn.example.com/branches/x/" {
+		t.Errorf("BRANCHB = %q", base)
+	}
+	if len(run.Results) != 4 {
+		t.Fatalf("resultados = %d, want 4\n%s", len(run.Results), out.String())
+	}
+
+	tests := []struct {
+		ruleID, uri, baseID string
+		start, end          int
+		suppressed          bool
+	}{
+		{"modified", "src/a.go", "BRANCHB", 3, 5, false},
+		{"deleted", "rm.txt", "BRANCHA", 1, 2, false},
+		{"property-changed", "run.sh", "BRANCHB", 0, 0, false},
+		{"added", "docs/guia.md", "BRANCHB", 1, 1, true},
+	}
+	for i, tt := range tests {
+		res := run.Results[i]
+		loc := res.Locations[0].PhysicalLocation
+		if res.RuleID != tt.ruleID || loc.ArtifactLocation.URI != tt.uri || loc.ArtifactLocation.URIBaseID != tt.baseID {
+			t.Errorf("resultado %d = %s em %s:%s", i, res.RuleID, loc.ArtifactLocation.URIBaseID, loc.ArtifactLocation.URI)
+		}
+		if tt.start > 0 && (loc.Region == nil || loc.Region.StartLine != tt.start || loc.Region.EndLine != tt.end) {
+			t.Errorf("resultado %d: região = %+v, want %d-%d", i, loc.Region, tt.start, tt.end)
+		}
+		if (len(res.Suppressions) > 0) != tt.suppressed {
+			t.Errorf("resultado %d: supressões = %+v", i, res.Suppressions)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fatih/color"
+	"golang.org/x/text/width"
+
+	"svndiff/internal/diff"
+	"svndiff/internal/patch"
+	"svndiff/pkg/config"
+)
+
+// defaultSideBySideWidth é a largura usada quando a saída não é um terminal
+// e COLUMNS não está definida
+const defaultSideBySideWidth = 120
+
+// sideBySideSeparator separa as colunas das branches A e B
+const sideBySideSeparator = " │ "
+
+// outputSideBySide gera o diff em duas colunas, com a Branch A à esquerda e
+// a Branch B à direita
+func (d *Differ) outputSideBySide() error {
+	r, err := d.openFullDiff()
+	if err != nil {
+		return fmt.Errorf("erro ao executar diff: %w", err)
+	}
+	defer r.Close()
+
+	// Imprime cabeçalho informativo
+	d.printHeader()
+
+	// Desenha cada arquivo à medida que a saída do svn é lida
+	empty, err := writeSideBySide(color.Output, r, sideBySideWidth(d.config.SideBySide.Width), d.config.SideBySide.Wrap)
+	if err != nil {
+		return err
+	}
+
+	// Se não há diferenças
+	if empty {
+		color.Green("✓ Nenhuma diferença encontrada entre as branches.\n")
+	}
+	return nil
+}
+
+// sideBySideWidth escolhe a largura total: a configurada, a do terminal, a
+// de COLUMNS ou o padrão, nessa ordem
+func sideBySideWidth(configured int) int {
+	width := configured
+	if width == 0 {
+		width = ttyWidth()
+	}
+	if width == 0 {
+		width, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	}
+	if width <= 0 {
+		width = defaultSideBySideWidth
+	}
+	return max(width, config.MinSideBySideWidth)
+}
+
+// sideBySide desenha as seções do diff em duas colunas de largura fixa
+type sideBySide struct {
+	w        io.Writer
+	width    int
+	wrap     bool
+	numWidth int
+	colWidth int
+}
+
+// writeSideBySide escreve lado a lado em w as seções do diff lido de r, à
+// medida que são interpretadas, e indica se o diff estava vazio. As linhas
+// mais largas que a coluna são truncadas com "…", ou quebradas com wrap.
+func writeSideBySide(w io.Writer, r io.Reader, width int, wrap bool) (bool, error) {
+	s := &sideBySide{w: w, width: width, wrap: wrap}
+	reader := patch.NewReader(r)
+	empty := true
+	for {
+		f, err := reader.Next()
+		if err == io.EOF {
+			return empty, nil
+		}
+		if err != nil {
+			return empty, fmt.Errorf("erro ao interpretar diff: %w", err)
+		}
+		s.file(f)
+		empty = false
+	}
+}
+
+func (s *sideBySide) file(f *patch.File) {
+	s.numWidth = len(strconv.Itoa(lastLine(f)))
+	s.colWidth = max((s.width-stringWidth(sideBySideSeparator))/2-s.numWidth-3, 1)
+
+	fmt.Fprintln(s.w, color.YellowString("Index: %s", f.Path))
+	fmt.Fprintln(s.w, color.YellowString("%s", strings.Repeat("=", s.width)))
+	if f.LabelA != "" || f.LabelB != "" {
+		side := s.numWidth + 3 + s.colWidth
+		left := truncate(displayText("--- "+f.Path+" "+f.LabelA), side)
+		right := truncate(displayText("+++ "+f.Path+" "+f.LabelB), side)
+		fmt.Fprintln(s.w, color.BlueString("%s%s%s", pad(left, side), sideBySideSeparator, right))
+	}
+	if f.Binary {
+		note := "Arquivo binário"
+		if f.MimeType != "" {
+			note += " (" + f.MimeType + ")"
+		}
+		fmt.Fprintln(s.w, note)
+		for _, detail := range f.Details {
+			fmt.Fprintln(s.w, detail)
+		}
+	}
+
+	for _, hunk := range f.Hunks {
+		fmt.Fprintln(s.w, color.MagentaString("@@ -%d,%d +%d,%d @@", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines))
+		for _, row := range hunk.Rows() {
+			s.row(row)
+		}
+	}
+
+	for _, prop := range f.Props {
+		fmt.Fprintln(s.w, color.YellowString("%s: %s", prop.Action, prop.Name))
+		for _, row := range propRows(prop) {
+			s.row(row)
+		}
+	}
+}
+
+// row escreve uma linha pareada, em várias linhas de terminal quando o
+// texto é quebrado
+func (s *sideBySide) row(row patch.Row) {
+	left, right := s.cellLines(row.Left), s.cellLines(row.Right)
+	for i := 0; i < len(left) || i < len(right); i++ {
+		l, r := "", ""
+		if i < len(left) {
+			l = left[i]
+		} else {
+			l = strings.Repeat(" ", s.numWidth+3+s.colWidth)
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		fmt.Fprintln(s.w, strings.TrimRight(l+sideBySideSeparator+r, " "))
+	}
+}
+
+// cellLines formata um lado da linha: número, marcador e texto, com as
+// continuações de quebra sem número
+func (s *sideBySide) cellLines(cell *patch.Cell) []string {
+	if cell == nil {
+		return []string{strings.Repeat(" ", s.numWidth+3+s.colWidth)}
+	}
+	base, highlight := lineColors(cell.Kind)
+
+	spans := cell.Spans
+	if spans == nil {
+		spans = []diff.Span{{Text: cell.Text}}
+	}
+	var lines []string
+	for i, chunk := range s.fit(styled(spans), s.colWidth) {
+		number := strings.Repeat(" ", s.numWidth)
+		marker := " "
+		if i == 0 {
+			if cell.Number > 0 {
+				number = fmt.Sprintf("%*d", s.numWidth, cell.Number)
+			}
+			marker = string(cell.Kind)
+		}
+		padding := strings.Repeat(" ", max(s.colWidth-chunk.width(), 0))
+		lines = append(lines, number+" "+colorize(base, marker+" ")+chunk.paint(base, highlight)+colorize(base, padding))
+	}
+	return lines
+}
+
+// fit corta o texto na largura da coluna: em pedaços com wrap, ou truncado
+// com "…" sem ele
+func (s *sideBySide) fit(text styledText, width int) []styledText {
+	if text.width() <= width {
+		return []styledText{text}
+	}
+	if !s.wrap {
+		n := text.fitting(width - 1)
+		return []styledText{{
+			runes:   append(text.runes[:n:n], '…'),
+			changed: append(text.changed[:n:n], false),
+		}}
+	}
+	var chunks []styledText
+	for text.width() > width {
+		// Um caractere largo numa coluna de largura 1 ocupa o pedaço sozinho
+		n := max(text.fitting(width), 1)
+		chunks = append(chunks, styledText{text.runes[:n], text.changed[:n]})
+		text = styledText{text.runes[n:], text.changed[n:]}
+	}
+	return append(chunks, text)
+}
+
+// styledText é o texto de uma célula pronto para a coluna, com a marcação
+// de mudança de cada caractere
+type styledText struct {
+	runes   []rune
+	changed []bool
+}
+
+// width é a largura do texto no terminal
+func (t styledText) width() int {
+	return stringWidth(string(t.runes))
+}
+
+// fitting conta os caracteres iniciais do texto que cabem na largura
+func (t styledText) fitting(width int) int {
+	used := 0
+	for i, r := range t.runes {
+		if used += runeWidth(r); used > width {
+			return i
+		}
+	}
+	return len(t.runes)
+}
+
+// styled prepara os trechos para uma coluna de largura fixa: tabulações
+// viram espaços até a próxima parada de 4 e os demais caracteres de
+// controle são removidos
+func styled(spans []diff.Span) styledText {
+	var t styledText
+	column := 0
+	for _, span := range spans {
+		for _, r := range span.Text {
+			switch {
+			case r == '\t':
+				for n := 4 - column%4; n > 0; n-- {
+					t.runes = append(t.runes, ' ')
+					t.changed = append(t.changed, span.Changed)
+					column++
+				}
+			case r < ' ' || r == 0x7f:
+			default:
+				t.runes = append(t.runes, r)
+				t.changed = append(t.changed, span.Changed)
+				column += runeWidth(r)
+			}
+		}
+	}
+	return t
+}
+
+// paint colore o texto, destacando os trechos alterados
+func (t styledText) paint(base, highlight *color.Color) string {
+	var b strings.Builder
+	for start := 0; start < len(t.runes); {
+		end := start + 1
+		for end < len(t.runes) && t.changed[end] == t.changed[start] {
+			end++
+		}
+		c := base
+		if t.changed[start] {
+			c = highlight
+		}
+		b.WriteString(colorize(c, string(t.runes[start:end])))
+		start = end
+	}
+	return b.String()
+}
+
+// truncate corta o texto na largura, marcando o corte com "…"
+func truncate(text string, width int) string {
+	if stringWidth(text) <= width {
+		return text
+	}
+	if width < 1 {
+		return ""
+	}
+	t := styledText{runes: []rune(text)}
+	return string(t.runes[:t.fitting(width-1)]) + "…"
+}
+
+// pad completa o texto com espaços até a largura da coluna
+func pad(text string, width int) string {
+	if n := stringWidth(text); n < width {
+		return text + strings.Repeat(" ", width-n)
+	}
+	return text
+}
+
+// stringWidth é a largura do texto no terminal, em colunas
+func stringWidth(text string) int {
+	n := 0
+	for _, r := range text {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// runeWidth é a largura de um caractere no terminal: 2 para os ideogramas,
+// os caracteres de largura cheia e os emoji, 0 para as marcas combinantes e
+// os caracteres de formatação e 1 para os demais
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// displayText prepara um texto sem destaques para a coluna
+func displayText(text string) string {
+	return string(styled([]diff.Span{{Text: text}}).runes)
+}
+
+// lastLine retorna o maior número de linha dos hunks, para alinhar a
+// numeração das colunas
+func lastLine(f *patch.File) int {
+	last := 1
+	for _, hunk := range f.Hunks {
+		last = max(last, hunk.OldStart+hunk.OldLines, hunk.NewStart+hunk.NewLines)
+	}
+	return last
+}
+
+// propRows pareia os valores antigo e novo de uma propriedade, linha a linha
+// e sem numeração
+func propRows(prop patch.PropChange) []patch.Row {
+	old, new := propLines(prop.Old), propLines(prop.New)
+	rows := make([]patch.Row, 0, max(len(old), len(new)))
+	for i := 0; i < len(old) || i < len(new); i++ {
+		var row patch.Row
+		if i < len(old) {
+			row.Left = &patch.Cell{Kind: '-', Text: old[i]}
+		}
+		if i < len(new) {
+			row.Right = &patch.Cell{Kind: '+', Text: new[i]}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func propLines(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(value, "\n"), "\n")
+}
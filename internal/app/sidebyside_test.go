@@ -0,0 +1,127 @@
+package app
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestWriteSideBySide(t *testing.T) {
+	diff := "Index: a.txt\n===\n--- a.txt\t(revision 1)\n+++ a.txt\t(revision 2)\n" +
+		"@@ -8,4 +8,4 @@\n oito\n-nove\tcom tab\n-dez\n+9\n+uma linha bem mais longa que a coluna\n onze\n"
+
+	tests := []struct {
+		name string
+		wrap bool
+		want string
+	}{
+		{
+			name: "truncado",
+			want: "" +
+				" 8   oito                  │  8   oito\n" +
+				" 9 - nove    com tab       │  9 + 9\n" +
+				"10 - dez                   │ 10 + uma linha bem mais l…\n" +
+				"11   onze                  │ 11   onze\n",
+		},
+		{
+			name: "quebrado",
+			wrap: true,
+			want: "" +
+				" 8   oito                  │  8   oito\n" +
+				" 9 - nove    com tab       │  9 + 9\n" +
+				"10 - dez                   │ 10 + uma linha bem mais lo\n" +
+				"                           │      nga que a coluna\n" +
+				"11   onze                  │ 11   onze\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if _, err := writeSideBySide(&out, strings.NewReader(diff), 55, tt.wrap); err != nil {
+				t.Fatalf("writeSideBySide() error = %v", err)
+			}
+			got := out.String()
+			body := got[strings.Index(got, "@@ -8,4 +8,4 @@\n")+len("@@ -8,4 +8,4 @@\n"):]
+			if body != tt.want {
+				t.Errorf("writeSideBySide() =\n%s\nwant\n%s", body, tt.want)
+			}
+			if !strings.HasPrefix(got, "Index: a.txt\n"+strings.Repeat("=", 55)+"\n--- a.txt (revision 1)") {
+				t.Errorf("writeSideBySide() cabeçalho =\n%s", got)
+			}
+		})
+	}
+}
+
+func TestWriteSideBySide_WideCharacters(t *testing.T) {
+	diff := "Index: a.txt\n===\n--- a.txt\t(revision 1)\n+++ a.txt\t(revision 2)\n" +
+		"@@ -1,2 +1,2 @@\n-日本語\n-e\u0301\n+日本語のテキストですよね\n+🎉 ok\n"
+	// As colunas têm 22 de largura: os ideogramas e o emoji ocupam 2 e o
+	// acento combinante, 0
+	want := "" +
+		"1 - 日本語" + strings.Repeat(" ", 16) + " │ 1 + 日本語のテキストです…\n" +
+		"2 - e\u0301" + strings.Repeat(" ", 21) + " │ 2 + 🎉 ok\n"
+
+	var out strings.Builder
+	if _, err := writeSideBySide(&out, strings.NewReader(diff), 55, false); err != nil {
+		t.Fatalf("writeSideBySide() error = %v", err)
+	}
+	got := out.String()
+	if body := got[strings.Index(got, "@@ -1,2 +1,2 @@\n")+len("@@ -1,2 +1,2 @@\n"):]; body != want {
+		t.Errorf("writeSideBySide() =\n%s\nwant\n%s", body, want)
+	}
+}
+
+func TestWriteSideBySide_Empty(t *testing.T) {
+	empty, err := writeSideBySide(io.Discard, strings.NewReader(""), 55, false)
+	if err != nil || !empty {
+		t.Errorf("writeSideBySide() = %v, %v; want vazio", empty, err)
+	}
+}
+
+func TestSideBySideWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	if got := sideBySideWidth(160); got != 160 {
+		t.Errorf("sideBySideWidth(160) = %d", got)
+	}
+	if ttyWidth() == 0 {
+		if got := sideBySideWidth(0); got != defaultSideBySideWidth {
+			t.Errorf("sideBySideWidth(0) = %d, want %d", got, defaultSideBySideWidth)
+		}
+		t.Setenv("COLUMNS", "90")
+		if got := sideBySideWidth(0); got != 90 {
+			t.Errorf("sideBySideWidth(0) com COLUMNS=90 = %d", got)
+		}
+	}
+}
+
+func TestCopyColorizedDiff_Intraline(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	diff := "@@ -1,2 +1,2 @@\n-timeout = 30\n-fim\n+timeout = 60\n+outro\n"
+	var out strings.Builder
+	if _, err := copyColorizedDiff(&out, strings.NewReader(diff)); err != nil {
+		t.Fatalf("copyColorizedDiff() error = %v", err)
+	}
+
+	red, redHi := color.New(color.FgRed), color.New(color.FgRed, color.ReverseVideo)
+	green, greenHi := color.New(color.FgGreen), color.New(color.FgGreen, color.ReverseVideo)
+	for _, want := range []string{
+		red.Sprint("-") + red.Sprint("timeout = ") + redHi.Sprint("30") + "\n",
+		green.Sprint("+") + green.Sprint("timeout = ") + greenHi.Sprint("60") + "\n",
+		// sem palavras em comum a linha inteira é a mudança
+		red.Sprint("-fim") + "\n",
+		green.Sprint("+outro") + "\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("copyColorizedDiff() não contém %q:\n%q", want, out.String())
+		}
+	}
+	if strings.Index(out.String(), "fim") > strings.Index(out.String(), "60") {
+		t.Error("copyColorizedDiff() deveria manter as remoções antes das adições")
+	}
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+
+	"svndiff/internal/svn"
+	"svndiff/pkg/config"
+)
+
+// openDiff abre a saída do diff para leitura incremental. Backends sem
+// streaming têm a saída completa lida antes.
+func (d *Differ) openDiff(summarize bool) (io.ReadCloser, error) {
+	if streamer, ok := d.svnClient.(svn.DiffStreamer); ok {
+		return streamer.StreamDiff(&d.config.BranchA, &d.config.BranchB, summarize)
+	}
+	result, err := d.svnClient.GetDiff(&d.config.BranchA, &d.config.BranchB, summarize)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(strings.NewReader(result.Output)), nil
+}
+
+// openFullDiff abre o diff completo para as saídas que o exibem, sem as
+// diferenças de espaço em branco ignoradas. Com os detalhes de binários
+// ativos, as seções "Cannot display" ganham, após o svn:mime-type, as linhas
+// de BinaryInfo.
+func (d *Differ) openFullDiff() (io.ReadCloser, error) {
+	r, err := d.openDiff(false)
+	if err != nil {
+		return nil, err
+	}
+	if ws := d.whitespace(); ws.Enabled() {
+		r = &whitespaceFilter{ws: ws, src: r, br: bufio.NewReader(r)}
+	}
+	if d.config.Binary.Enabled() {
+		r = &binaryDescriber{d: d, src: r, br: bufio.NewReader(r)}
+	}
+	return r, nil
+}
+
+// changes percorre as mudanças do svn diff --summarize --xml lido de r, uma
+// a uma. Um erro de leitura é o último elemento.
+func (d *Differ) changes(r io.Reader) iter.Seq2[FileChange, error] {
+	return func(yield func(FileChange, error) bool) {
+		reader := svn.NewSummaryReader(r)
+		for {
+			entry, err := reader.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(FileChange{}, err)
+				return
+			}
+			if change, ok := d.fileChange(entry); ok && !yield(change, nil) {
+				return
+			}
+		}
+	}
+}
+
+// fileChanges percorre as mudanças lidas de r como relatadas nas saídas: sem
+// as que só diferem nos espaços ignorados, com o conteúdo dos diretórios e
+// os binários detalhados e as renomeações reconhecidas, quando configurados,
+// e com os valores das propriedades alteradas
+func (d *Differ) fileChanges(r io.Reader) iter.Seq2[FileChange, error] {
+	return d.withProps(d.withBinaries(d.detectRenames(d.expandDirs(d.ignoreWhitespace(d.changes(r))))))
+}
+
+// expandDirs detalha os diretórios adicionados e removidos de seq com o seu
+// conteúdo, listado na Branch B e na Branch A respectivamente. Caminhos que
+// o backend já relatou dentro desses diretórios não são repetidos.
+func (d *Differ) expandDirs(seq iter.Seq2[FileChange, error]) iter.Seq2[FileChange, error] {
+	lister, ok := d.svnClient.(svn.Lister)
+	if !d.config.ExpandDirs || !ok {
+		return seq
+	}
+	return func(yield func(FileChange, error) bool) {
+		listed := make(map[string]bool)
+		for change, err := range seq {
+			if err != nil {
+				yield(change, err)
+				return
+			}
+			if listed[change.Path] {
+				continue
+			}
+			if !yield(change, nil) {
+				return
+			}
+
+			var branch *config.BranchConfig
+			switch {
+			case change.Kind != string(svn.NodeDir):
+				continue
+			case change.Status == "Added":
+				branch = &d.config.BranchB
+			case change.Status == "Deleted":
+				branch = &d.config.BranchA
+			default:
+				continue
+			}
+			entries, err := lister.ListTree(branch, branchPath(d.config.BranchA.URL, change.Path))
+			if err != nil {
+				yield(FileChange{}, fmt.Errorf("erro ao listar %s: %w", change.Path, err))
+				return
+			}
+			for _, entry := range entries {
+				path := strings.TrimSuffix(change.Path, "/") + "/" + entry.Path
+				listed[path] = true
+				child := FileChange{Path: path, Status: change.Status, Kind: string(entry.Kind), URL: d.entryURL(path)}
+				if !yield(child, nil) {
+					return
+				}
+			}
+		}
+	}
+}
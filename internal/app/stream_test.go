@@ -0,0 +1,69 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/dump"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+func TestDiffer_expandDirs(t *testing.T) {
+	dumpFile := filepath.Join(t.TempDir(), "repo.dump")
+	history := [][]svntest.DumpNode{
+		{
+			{Path: "trunk", Kind: "dir", Action: "add"},
+			{Path: "trunk/lib", Kind: "dir", Action: "add"},
+			{Path: "trunk/lib/a.txt", Kind: "file", Action: "add", Content: "a\n"},
+			{Path: "trunk/lib/sub", Kind: "dir", Action: "add"},
+			{Path: "trunk/lib/sub/b.txt", Kind: "file", Action: "add", Content: "b\n"},
+		},
+		{
+			{Path: "trunk/lib", Action: "delete"},
+			{Path: "trunk/novo", Kind: "dir", Action: "add"},
+			{Path: "trunk/novo/c.txt", Kind: "file", Action: "add", Content: "c\n"},
+		},
+	}
+	if err := os.WriteFile(dumpFile, svntest.WriteDump(history), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	url := dump.Scheme + filepath.ToSlash(dumpFile) + "/trunk"
+	differ := &Differ{
+		config: &config.Config{
+			BranchA:    config.BranchConfig{URL: url, Revisions: []string{"1"}},
+			BranchB:    config.BranchConfig{URL: url, Revisions: []string{"2"}},
+			ExpandDirs: true,
+		},
+		svnClient: svn.NewRepositoryBackend(func(url string) (svn.Repository, error) { return dump.Open(url) }),
+	}
+
+	collect := func() string {
+		r, err := differ.openDiff(true)
+		if err != nil {
+			t.Fatalf("openDiff() error = %v", err)
+		}
+		defer r.Close()
+		var lines []string
+		for change, err := range differ.fileChanges(r) {
+			if err != nil {
+				t.Fatalf("fileChanges() error = %v", err)
+			}
+			lines = append(lines, change.Status+" "+change.Kind+" "+change.Path)
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	want := "Deleted dir lib\nDeleted file lib/a.txt\nDeleted dir lib/sub\nDeleted file lib/sub/b.txt\nAdded dir novo\nAdded file novo/c.txt"
+	if got := collect(); got != want {
+		t.Errorf("fileChanges() com expandDirs =\n%s\nwant\n%s", got, want)
+	}
+
+	differ.config.ExpandDirs = false
+	if got, want := collect(), "Deleted dir lib\nAdded dir novo\nAdded file novo/c.txt"; got != want {
+		t.Errorf("fileChanges() =\n%s\nwant\n%s", got, want)
+	}
+}
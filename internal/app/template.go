@@ -0,0 +1,171 @@
+package app
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fatih/color"
+
+	"svndiff/internal/patch"
+	"svndiff/internal/svn"
+	"svndiff/pkg/config"
+)
+
+// templateData é o valor passado aos modelos do usuário: o mesmo resumo da
+// saída JSON, mais o diff completo e o log das branches, obtidos só quando
+// o modelo os usa
+type templateData struct {
+	DiffSummary
+	Generated time.Time
+
+	d           *Differ
+	files       []*patch.File
+	filesLoaded bool
+}
+
+// Files retorna as seções do diff completo, com os hunks de cada arquivo
+func (t *templateData) Files() ([]*patch.File, error) {
+	if t.filesLoaded {
+		return t.files, nil
+	}
+	files, err := t.d.fullDiff()
+	if err != nil {
+		return nil, err
+	}
+	t.files, t.filesLoaded = files, true
+	return files, nil
+}
+
+// LogA retorna as entradas de log das revisões comparadas da Branch A
+func (t *templateData) LogA() ([]svn.LogEntry, error) {
+	return t.d.branchLog(&t.d.config.BranchA)
+}
+
+// LogB retorna as entradas de log das revisões comparadas da Branch B
+func (t *templateData) LogB() ([]svn.LogEntry, error) {
+	return t.d.branchLog(&t.d.config.BranchB)
+}
+
+// branchLog busca o log do intervalo de revisões da branch e mantém só as
+// revisões comparadas. Cópias de trabalho e repositórios Git não têm log.
+func (d *Differ) branchLog(branch *config.BranchConfig) ([]svn.LogEntry, error) {
+	if !branch.HasRevisions() || len(branch.Revisions) == 0 {
+		return nil, nil
+	}
+	entries, err := d.svnClient.GetLogEntries(branch.URL, svn.LogOptions{Range: branch.GetRevisionRange()})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter log de %s: %w", branch.URL, err)
+	}
+	// Com uma revisão, que pode ser simbólica (HEAD), o intervalo já é ela
+	if len(branch.Revisions) == 1 {
+		return entries, nil
+	}
+	var selected []svn.LogEntry
+	for _, entry := range entries {
+		if slices.Contains(branch.Revisions, entry.Revision) {
+			selected = append(selected, entry)
+		}
+	}
+	return selected, nil
+}
+
+// outputTemplate renderiza o modelo do usuário. Modelos .html e .htm usam
+// html/template, que escapa o conteúdo; os demais, text/template.
+func (d *Differ) outputTemplate() error {
+	result, err := d.svnClient.GetDiff(&d.config.BranchA, &d.config.BranchB, true)
+	if err != nil {
+		return fmt.Errorf("erro ao executar diff: %w", err)
+	}
+	var changes []FileChange
+	for change, err := range d.fileChanges(strings.NewReader(result.Output)) {
+		if err != nil {
+			return fmt.Errorf("erro ao interpretar diff: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	data := &templateData{
+		DiffSummary: DiffSummary{
+			BranchA:    branchInfo(&d.config.BranchA),
+			BranchB:    branchInfo(&d.config.BranchB),
+			Changes:    changes,
+			TotalFiles: len(changes),
+		},
+		Generated: time.Now(),
+		d:         d,
+	}
+	return renderTemplate(os.Stdout, d.config.Template, data)
+}
+
+// renderTemplate executa o modelo do arquivo path com os dados
+func renderTemplate(w io.Writer, path string, data any) error {
+	name := filepath.Base(path)
+	var tmpl interface {
+		Execute(io.Writer, any) error
+	}
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		tmpl, err = htmltemplate.New(name).Funcs(htmltemplate.FuncMap(templateFuncs)).ParseFiles(path)
+	default:
+		tmpl, err = template.New(name).Funcs(templateFuncs).ParseFiles(path)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao carregar modelo: %w", err)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("erro ao renderizar modelo: %w", err)
+	}
+	return nil
+}
+
+// templateColors são as cores aceitas pela função color dos modelos
+var templateColors = map[string]color.Attribute{
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+	"bold":    color.Bold,
+}
+
+// templateFuncs são as funções auxiliares disponíveis nos modelos. Os
+// argumentos seguem a ordem de uso em pipelines ({{.Path | truncate 40}}).
+var templateFuncs = template.FuncMap{
+	// color pinta o texto com uma das cores de templateColors; a cor é
+	// omitida quando a saída não é um terminal
+	"color": func(name, text string) (string, error) {
+		attr, ok := templateColors[name]
+		if !ok {
+			return "", fmt.Errorf("cor desconhecida: %s", name)
+		}
+		return color.New(attr).Sprint(text), nil
+	},
+	// pluralize escreve a quantidade com a forma singular ou plural
+	"pluralize": func(n int, singular, plural string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, singular)
+		}
+		return fmt.Sprintf("%d %s", n, plural)
+	},
+	// truncate corta o texto em width caracteres, terminando com "…"
+	"truncate": func(width int, text string) string {
+		return truncate(text, width)
+	},
+	// relpath torna target relativo a base
+	"relpath": func(base, target string) (string, error) {
+		rel, err := filepath.Rel(filepath.FromSlash(base), filepath.FromSlash(target))
+		if err != nil {
+			return "", err
+		}
+		return filepath.ToSlash(rel), nil
+	},
+}
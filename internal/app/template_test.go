@@ -0,0 +1,108 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/dump"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	dir := t.TempDir()
+	dumpFile := filepath.Join(dir, "repo.dump")
+	if err := os.WriteFile(dumpFile, svntest.WriteDump(patchHistory), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	url := dump.Scheme + filepath.ToSlash(dumpFile) + "/trunk"
+	differ := &Differ{
+		config: &config.Config{
+			BranchA: config.BranchConfig{URL: url, Revisions: []string{"1"}},
+			BranchB: config.BranchConfig{URL: url, Revisions: []string{"2", "4"}},
+		},
+		svnClient: svn.NewRepositoryBackend(func(url string) (svn.Repository, error) { return dump.Open(url) }),
+	}
+	result, err := differ.svnClient.GetDiff(&differ.config.BranchA, &differ.config.BranchB, true)
+	if err != nil {
+		t.Fatalf("GetDiff() error = %v", err)
+	}
+	changes, err := differ.parseFileChanges(result.Output)
+	if err != nil {
+		t.Fatalf("parseFileChanges() error = %v", err)
+	}
+	data := &templateData{
+		DiffSummary: DiffSummary{
+			BranchA:    branchInfo(&differ.config.BranchA),
+			BranchB:    branchInfo(&differ.config.BranchB),
+			Changes:    changes,
+			TotalFiles: len(result.FileList),
+		},
+		d: differ,
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		template string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "texto",
+			file: "relatorio.tmpl",
+			template: `{{pluralize .TotalFiles "arquivo" "arquivos"}} até r{{.BranchB.Latest}}
+{{range .Changes}}{{.Status | truncate 4}} {{.Path}}
+{{end}}{{range .Files}}{{if .Hunks}}{{.Path}}: {{pluralize (len .Hunks) "hunk" "hunks"}}
+{{end}}{{end}}{{range .LogB}}r{{.Revision}} {{.Author}} {{.Message}}
+{{end}}{{relpath "src" "src/app/main.go"}} {{color "red" "<x>"}}`,
+			want: []string{
+				"4 arquivos até r4\n",
+				"Mod… a.txt\n", "Add… novo.txt\n", "Del… rm.txt\n",
+				"a.txt: 1 hunk\n",
+				"r2 alice r2\nr4 alice r4\n",
+				"app/main.go <x>",
+			},
+		},
+		{
+			name:     "html escapa o conteúdo",
+			file:     "relatorio.html",
+			template: `<p>{{color "bold" "<x>"}}</p>{{range .LogA}}<li>r{{.Revision}}</li>{{end}}`,
+			want:     []string{"<p>&lt;x&gt;</p><li>r1</li>"},
+		},
+		{
+			name:     "cor desconhecida",
+			file:     "cor.tmpl",
+			template: `{{color "rosa" "x"}}`,
+			wantErr:  true,
+		},
+		{
+			name:     "modelo inválido",
+			file:     "invalido.tmpl",
+			template: `{{range .Changes}}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.template), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			err := renderTemplate(&out, path, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renderTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("renderTemplate() não contém %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
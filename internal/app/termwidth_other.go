@@ -0,0 +1,9 @@
+//go:build !unix
+
+package app
+
+// ttyWidth não consulta o terminal fora dos sistemas Unix; a largura vem de
+// COLUMNS ou do padrão
+func ttyWidth() int {
+	return 0
+}
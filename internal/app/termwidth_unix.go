@@ -0,0 +1,19 @@
+//go:build unix
+
+package app
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// ttyWidth retorna a largura do terminal da saída padrão, ou zero quando a
+// saída não é um terminal
+func ttyWidth() int {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+	return int(ws.Col)
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"bufio"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+
+	"svndiff/internal/patch"
+	"svndiff/internal/svn"
+)
+
+// whitespace traduz as diferenças ignoradas da configuração
+func (d *Differ) whitespace() patch.Whitespace {
+	return patch.Whitespace{
+		SpaceChange: d.config.Ignore.SpaceChange,
+		AllSpace:    d.config.Ignore.AllSpace,
+		EOLStyle:    d.config.Ignore.EOLStyle,
+		BlankLines:  d.config.Ignore.BlankLines,
+	}
+}
+
+// ignoreWhitespace descarta de seq os arquivos modificados cujo conteúdo só
+// difere nos espaços ignorados; os que também tiveram propriedades alteradas
+// ficam como PropertyChanged. O índice do diff completo é lido na primeira
+// modificação de arquivo encontrada.
+func (d *Differ) ignoreWhitespace(seq iter.Seq2[FileChange, error]) iter.Seq2[FileChange, error] {
+	ws := d.whitespace()
+	if !ws.Enabled() {
+		return seq
+	}
+	return func(yield func(FileChange, error) bool) {
+		for change, err := range seq {
+			if err == nil && change.Status == "Modified" && change.Kind != string(svn.NodeDir) {
+				var index *diffIndex
+				index, err = d.fullIndex()
+				if err == nil && index.whitespaceOnly[branchPath(d.config.BranchA.URL, change.Path)] {
+					if change.PropStatus == "" {
+						continue
+					}
+					change.Status = propertyChanged
+				}
+			}
+			if !yield(change, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// filterWhitespace refaz os hunks do diff em output sem as diferenças de
+// espaço ignoradas, como openFullDiff faz com o diff lido do svn
+func (d *Differ) filterWhitespace(output string) (string, error) {
+	ws := d.whitespace()
+	if !ws.Enabled() {
+		return output, nil
+	}
+	src := io.NopCloser(strings.NewReader(output))
+	filtered, err := io.ReadAll(&whitespaceFilter{ws: ws, src: src, br: bufio.NewReader(src)})
+	return string(filtered), err
+}
+
+// whitespaceFilter refaz os hunks do diff lido de src sem as diferenças
+// ignoradas por ws, uma seção de arquivo por vez. Seções que ficam sem
+// diferenças são omitidas.
+type whitespaceFilter struct {
+	ws      patch.Whitespace
+	src     io.ReadCloser
+	br      *bufio.Reader
+	path    string
+	section []string
+	pending []byte
+	err     error
+}
+
+func (w *whitespaceFilter) Read(p []byte) (int, error) {
+	for len(w.pending) == 0 {
+		if w.err != nil {
+			return 0, w.err
+		}
+		line, err := w.br.ReadString('\n')
+		if err != nil {
+			// A última seção termina com a saída
+			w.section = append(w.section, line)
+			w.flush()
+			w.err = err
+			continue
+		}
+		if w.starts(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")) {
+			w.flush()
+		}
+		w.section = append(w.section, line)
+	}
+	n := copy(p, w.pending)
+	w.pending = w.pending[n:]
+	return n, nil
+}
+
+// starts indica que line abre a seção de outro arquivo
+func (w *whitespaceFilter) starts(line string) bool {
+	path, ok := strings.CutPrefix(line, "Index: ")
+	if !ok {
+		path, ok = strings.CutPrefix(line, "Property changes on: ")
+		ok = ok && path != w.path
+	}
+	if ok {
+		w.path = path
+	}
+	return ok
+}
+
+// flush filtra a seção acumulada e a entrega à leitura
+func (w *whitespaceFilter) flush() {
+	for _, line := range w.filter(w.section) {
+		w.pending = append(w.pending, line...)
+	}
+	w.section = nil
+}
+
+// filter refaz os hunks da seção, mantendo o cabeçalho e as mudanças de
+// propriedades como estão. Seções que não são interpretadas passam intactas.
+func (w *whitespaceFilter) filter(section []string) []string {
+	files, err := patch.ParseString(strings.Join(section, ""))
+	if err != nil || len(files) != 1 || len(files[0].Hunks) == 0 {
+		return section
+	}
+	f := files[0]
+
+	// Delimita as linhas dos hunks, entre o cabeçalho e as propriedades
+	start := slices.IndexFunc(section, func(line string) bool { return strings.HasPrefix(line, "@@ ") })
+	end := start
+	for _, hunk := range f.Hunks {
+		end += 1 + len(hunk.Lines)
+		for _, line := range hunk.Lines {
+			if line.NoNewline {
+				end++
+			}
+		}
+	}
+	if start < 0 || end > len(section) {
+		return section
+	}
+	header, props := section[:start], section[end:]
+
+	f.IgnoreWhitespace(w.ws)
+	if len(f.Hunks) == 0 {
+		// Sem diferenças de conteúdo, resta a seção de propriedades, se houver
+		for len(props) > 0 && strings.TrimSpace(props[0]) == "" {
+			props = props[1:]
+		}
+		return props
+	}
+	result := slices.Clone(header)
+	for _, hunk := range f.Hunks {
+		for _, line := range hunkLines(hunk) {
+			result = append(result, line+"\n")
+		}
+	}
+	return append(result, props...)
+}
+
+func (w *whitespaceFilter) Close() error {
+	return w.src.Close()
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"svndiff/internal/patch"
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/dump"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+// whitespaceDiffer compara um repositório em que crlf.txt passa a LF,
+// indent.go é reindentado, props.txt passa a LF e muda de propriedade e
+// real.txt tem uma mudança de fato junto com a troca de fim de linha
+func whitespaceDiffer(t *testing.T, ignore config.IgnoreConfig) *Differ {
+	t.Helper()
+	dumpFile := filepath.Join(t.TempDir(), "repo.dump")
+	history := [][]svntest.DumpNode{
+		{
+			{Path: "trunk", Kind: "dir", Action: "add"},
+			{Path: "trunk/crlf.txt", Kind: "file", Action: "add", Content: "um\r\ndois\r\n"},
+			{Path: "trunk/indent.go", Kind: "file", Action: "add", Content: "func f() {\n  return\n}\n"},
+			{Path: "trunk/props.txt", Kind: "file", Action: "add", Content: "p\r\n"},
+			{Path: "trunk/real.txt", Kind: "file", Action: "add", Content: "a\r\nb\r\n"},
+		},
+		{
+			{Path: "trunk/crlf.txt", Kind: "file", Action: "change", Content: "um\ndois\n"},
+			{Path: "trunk/indent.go", Kind: "file", Action: "change", Content: "func f() {\n\treturn\n}\n"},
+			{Path: "trunk/props.txt", Kind: "file", Action: "change", Content: "p\n", Props: []string{"svn:eol-style", "native"}},
+			{Path: "trunk/real.txt", Kind: "file", Action: "change", Content: "a\nB\n"},
+		},
+	}
+	if err := os.WriteFile(dumpFile, svntest.WriteDump(history), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	url := dump.Scheme + filepath.ToSlash(dumpFile) + "/trunk"
+	return &Differ{
+		config: &config.Config{
+			BranchA: config.BranchConfig{URL: url, Revisions: []string{"1"}},
+			BranchB: config.BranchConfig{URL: url, Revisions: []string{"2"}},
+			Ignore:  ignore,
+		},
+		svnClient: svn.NewRepositoryBackend(func(url string) (svn.Repository, error) { return dump.Open(url) }),
+	}
+}
+
+func TestDiffer_ignoreWhitespace(t *testing.T) {
+	tests := []struct {
+		name   string
+		ignore config.IgnoreConfig
+		want   map[string]string
+	}{
+		{
+			name: "sem opções",
+			want: map[string]string{"crlf.txt": "Modified", "indent.go": "Modified", "props.txt": "Modified", "real.txt": "Modified"},
+		},
+		{
+			name:   "fim de linha",
+			ignore: config.IgnoreConfig{EOLStyle: true},
+			want:   map[string]string{"indent.go": "Modified", "props.txt": "PropertyChanged", "real.txt": "Modified"},
+		},
+		{
+			name:   "fim de linha e espaços",
+			ignore: config.IgnoreConfig{EOLStyle: true, SpaceChange: true},
+			want:   map[string]string{"props.txt": "PropertyChanged", "real.txt": "Modified"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			differ := whitespaceDiffer(t, tt.ignore)
+			r, err := differ.openDiff(true)
+			if err != nil {
+				t.Fatalf("openDiff() error = %v", err)
+			}
+			defer r.Close()
+
+			got := make(map[string]string)
+			for change, err := range differ.ignoreWhitespace(differ.changes(r)) {
+				if err != nil {
+					t.Fatalf("ignoreWhitespace() error = %v", err)
+				}
+				got[change.Path] = change.Status
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ignoreWhitespace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffer_openFullDiff_ignoreWhitespace(t *testing.T) {
+	differ := whitespaceDiffer(t, config.IgnoreConfig{EOLStyle: true})
+	r, err := differ.openFullDiff()
+	if err != nil {
+		t.Fatalf("openFullDiff() error = %v", err)
+	}
+	defer r.Close()
+	output, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	files, err := patch.ParseString(string(output))
+	if err != nil {
+		t.Fatalf("Parse() error = %v\n%s", err, output)
+	}
+	sections := make(map[string]*patch.File)
+	for _, f := range files {
+		sections[f.Path] = f
+	}
+	if _, ok := sections["crlf.txt"]; ok || strings.Contains(string(output), "crlf.txt") {
+		t.Errorf("crlf.txt continua no diff:\n%s", output)
+	}
+	if props := sections["props.txt"]; props == nil || len(props.Hunks) != 0 || len(props.Props) != 1 {
+		t.Errorf("props.txt = %+v, want só a propriedade", props)
+	}
+	want := []patch.Line{{Kind: ' ', Text: "a\r"}, {Kind: '-', Text: "b\r"}, {Kind: '+', Text: "B"}}
+	if real := sections["real.txt"]; real == nil || len(real.Hunks) != 1 || !reflect.DeepEqual(real.Hunks[0].Lines, want) {
+		t.Errorf("real.txt = %+v, want %+v", real, want)
+	}
+	if indent := sections["indent.go"]; indent == nil || len(indent.Hunks) != 1 {
+		t.Errorf("indent.go = %+v, want o hunk da reindentação", indent)
+	}
+}
+
+func TestDiffer_patchText_ignoreWhitespace(t *testing.T) {
+	// O git-patch não representa svn:eol-style
+	for output, wants := range map[string][]string{
+		"patch":     {"Index: indent.go", "-b\r\n+B\n", "Property changes on: props.txt"},
+		"git-patch": {"diff --git a/indent.go", "-b\r\n+B\n"},
+	} {
+		t.Run(output, func(t *testing.T) {
+			differ := whitespaceDiffer(t, config.IgnoreConfig{EOLStyle: true})
+			differ.config.Output = output
+			text, err := differ.patchText(&differ.config.BranchA, &differ.config.BranchB)
+			if err != nil {
+				t.Fatalf("patchText() error = %v", err)
+			}
+			if strings.Contains(text, "crlf.txt") {
+				t.Errorf("crlf.txt, só com fins de linha trocados, continua no patch:\n%s", text)
+			}
+			for _, want := range wants {
+				if !strings.Contains(text, want) {
+					t.Errorf("patch sem %q:\n%s", want, text)
+				}
+			}
+		})
+	}
+}
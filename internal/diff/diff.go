@@ -0,0 +1,186 @@
+// Package diff implementa o cálculo de diferenças entre sequências usando o
+// algoritmo de Myers em espaço linear, além do agrupamento em hunks no estilo
+// do diff unificado.
+package diff
+
+// Kind identifica o tipo de uma operação do script de edição
+type Kind int
+
+const (
+	// Equal indica elementos presentes nas duas sequências
+	Equal Kind = iota
+	// Delete indica elementos presentes apenas na primeira sequência
+	Delete
+	// Insert indica elementos presentes apenas na segunda sequência
+	Insert
+)
+
+// Op representa um trecho contíguo do script de edição. Os intervalos são
+// semiabertos: a[AStart:AEnd] e b[BStart:BEnd].
+type Op struct {
+	Kind   Kind
+	AStart int
+	AEnd   int
+	BStart int
+	BEnd   int
+}
+
+// Diff calcula o script de edição mínimo que transforma a em b
+func Diff[T comparable](a, b []T) []Op {
+	m := &myers[T]{a: a, b: b}
+	m.compare(0, len(a), 0, len(b))
+	return m.ops()
+}
+
+// myers mantém o estado do algoritmo de Myers em espaço linear
+type myers[T comparable] struct {
+	a, b []T
+	// matches registra os pares de índices iguais encontrados, em ordem
+	matches [][2]int
+}
+
+// compare encontra os elementos comuns entre a[aLo:aHi] e b[bLo:bHi]
+func (m *myers[T]) compare(aLo, aHi, bLo, bHi int) {
+	// Prefixo comum
+	for aLo < aHi && bLo < bHi && m.a[aLo] == m.b[bLo] {
+		m.matches = append(m.matches, [2]int{aLo, bLo})
+		aLo++
+		bLo++
+	}
+
+	// Sufixo comum, registrado após a recursão para manter a ordem
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && m.a[aHi-suffix-1] == m.b[bHi-suffix-1] {
+		suffix++
+	}
+	aHi -= suffix
+	bHi -= suffix
+
+	if aLo < aHi && bLo < bHi {
+		if x, y, ok := m.split(aLo, aHi, bLo, bHi); ok {
+			m.compare(aLo, x, bLo, y)
+			m.compare(x, aHi, y, bHi)
+		}
+	}
+
+	for i := 0; i < suffix; i++ {
+		m.matches = append(m.matches, [2]int{aHi + i, bHi + i})
+	}
+}
+
+// split localiza um ponto do caminho de edição mínimo entre a[aLo:aHi] e
+// b[bLo:bHi] avançando simultaneamente do início e do fim até as buscas se
+// sobreporem. Retorna false quando os trechos não têm elementos em comum.
+func (m *myers[T]) split(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	n, l := aHi-aLo, bHi-bLo
+	maxD := (n + l + 1) / 2
+	offset := maxD
+	size := 2*maxD + 2
+
+	forward := make([]int, size)
+	backward := make([]int, size)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	delta := n - l
+	odd := delta%2 != 0
+	// Diagonais que já ultrapassaram os limites são descartadas
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		// Busca para frente
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			ko := offset + k
+			var x int
+			if k == -d || (k != d && forward[ko-1] < forward[ko+1]) {
+				x = forward[ko+1]
+			} else {
+				x = forward[ko-1] + 1
+			}
+			y := x - k
+			for x < n && y < l && m.a[aLo+x] == m.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[ko] = x
+
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > l:
+				fStart += 2
+			case odd:
+				bo := offset + delta - k
+				if bo >= 0 && bo < size && backward[bo] != -1 && x >= n-backward[bo] {
+					return aLo + x, bLo + y, true
+				}
+			}
+		}
+
+		// Busca para trás, em coordenadas espelhadas a partir do fim
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			ko := offset + k
+			var x int
+			if k == -d || (k != d && backward[ko-1] < backward[ko+1]) {
+				x = backward[ko+1]
+			} else {
+				x = backward[ko-1] + 1
+			}
+			y := x - k
+			for x < n && y < l && m.a[aHi-x-1] == m.b[bHi-y-1] {
+				x++
+				y++
+			}
+			backward[ko] = x
+
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > l:
+				bStart += 2
+			case !odd:
+				fo := offset + delta - k
+				if fo >= 0 && fo < size && forward[fo] != -1 {
+					fx := forward[fo]
+					fy := offset + fx - fo
+					if fx >= n-x {
+						return aLo + fx, bLo + fy, true
+					}
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// ops converte os pares iguais encontrados em um script de edição
+func (m *myers[T]) ops() []Op {
+	var ops []Op
+	add := func(kind Kind, aStart, aEnd, bStart, bEnd int) {
+		if aStart == aEnd && bStart == bEnd {
+			return
+		}
+		if n := len(ops); n > 0 && ops[n-1].Kind == kind && ops[n-1].AEnd == aStart && ops[n-1].BEnd == bStart {
+			ops[n-1].AEnd, ops[n-1].BEnd = aEnd, bEnd
+			return
+		}
+		ops = append(ops, Op{Kind: kind, AStart: aStart, AEnd: aEnd, BStart: bStart, BEnd: bEnd})
+	}
+
+	i, j := 0, 0
+	for _, match := range m.matches {
+		add(Delete, i, match[0], j, j)
+		add(Insert, match[0], match[0], j, match[1])
+		add(Equal, match[0], match[0]+1, match[1], match[1]+1)
+		i, j = match[0]+1, match[1]+1
+	}
+	add(Delete, i, len(m.a), j, j)
+	add(Insert, len(m.a), len(m.a), j, len(m.b))
+
+	return ops
+}
@@ -0,0 +1,116 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// lcsLength calcula o tamanho da maior subsequência comum por programação dinâmica
+func lcsLength(a, b []byte) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else {
+				dp[i][j] = max(dp[i+1][j], dp[i][j+1])
+			}
+		}
+	}
+	return dp[0][0]
+}
+
+// apply reconstrói b a partir de a e do script de edição
+func apply(a, b []byte, ops []Op) ([]byte, int) {
+	var out []byte
+	equal := 0
+	for _, op := range ops {
+		switch op.Kind {
+		case Equal:
+			out = append(out, a[op.AStart:op.AEnd]...)
+			equal += op.AEnd - op.AStart
+		case Insert:
+			out = append(out, b[op.BStart:op.BEnd]...)
+		}
+	}
+	return out, equal
+}
+
+func TestDiff_Minimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		a := make([]byte, rng.Intn(30))
+		b := make([]byte, rng.Intn(30))
+		for j := range a {
+			a[j] = byte('a' + rng.Intn(4))
+		}
+		for j := range b {
+			b[j] = byte('a' + rng.Intn(4))
+		}
+
+		ops := Diff(a, b)
+		got, equal := apply(a, b, ops)
+		if string(got) != string(b) {
+			t.Fatalf("Diff(%q, %q) não reconstrói b: %q", a, b, got)
+		}
+		if want := lcsLength(a, b); equal != want {
+			t.Fatalf("Diff(%q, %q) preserva %d elementos, want %d", a, b, equal, want)
+		}
+	}
+}
+
+func TestHunks(t *testing.T) {
+	a := strings.Split("1 2 3 4 5 6 7 8 9 10 11 12", " ")
+	b := strings.Split("1 2 3 x 5 6 7 8 9 10 11 12 13", " ")
+
+	hunks := Hunks(a, b, 3)
+	if len(hunks) != 2 {
+		t.Fatalf("Hunks() len = %d, want 2", len(hunks))
+	}
+
+	if got := hunks[0].Header(); got != "@@ -1,7 +1,7 @@" {
+		t.Errorf("hunks[0].Header() = %s", got)
+	}
+	if got := hunks[1].Header(); got != "@@ -10,3 +10,4 @@" {
+		t.Errorf("hunks[1].Header() = %s", got)
+	}
+
+	want := []Line{{Equal, "1"}, {Equal, "2"}, {Equal, "3"}, {Delete, "4"}, {Insert, "x"}, {Equal, "5"}, {Equal, "6"}, {Equal, "7"}}
+	if !reflect.DeepEqual(hunks[0].Lines, want) {
+		t.Errorf("hunks[0].Lines = %v, want %v", hunks[0].Lines, want)
+	}
+}
+
+func TestHunks_Empty(t *testing.T) {
+	hunks := Hunks(nil, []string{"a", "b"}, 3)
+	if len(hunks) != 1 || hunks[0].Header() != "@@ -0,0 +1,2 @@" {
+		t.Errorf("Hunks() = %+v", hunks)
+	}
+
+	if hunks := Hunks([]string{"a"}, []string{"a"}, 3); len(hunks) != 0 {
+		t.Errorf("Hunks() de sequências iguais = %+v", hunks)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 100},
+		{"a b c d", "a b c d", 100},
+		{"a b c d", "a b c e", 75},
+		{"a b", "c d", 0},
+		{"a b c d", "", 0},
+	}
+	for _, tt := range tests {
+		if got := Similarity(strings.Fields(tt.a), strings.Fields(tt.b)); got != tt.want {
+			t.Errorf("Similarity(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
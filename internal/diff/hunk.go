@@ -0,0 +1,118 @@
+package diff
+
+import "fmt"
+
+// Line representa uma linha de um hunk
+type Line struct {
+	Kind Kind
+	Text string
+}
+
+// Hunk agrupa mudanças próximas junto com as linhas de contexto ao redor.
+// Os inícios seguem a convenção do cabeçalho "@@ -AStart,ALines +BStart,BLines @@".
+type Hunk struct {
+	AStart int
+	ALines int
+	BStart int
+	BLines int
+	Lines  []Line
+}
+
+// Header retorna o cabeçalho do hunk no formato do diff unificado
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.AStart, h.ALines), hunkRange(h.BStart, h.BLines))
+}
+
+// hunkRange formata um intervalo do cabeçalho, omitindo a contagem quando é 1
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// Hunks calcula as diferenças entre as linhas de a e b e as agrupa em hunks
+// com até context linhas de contexto antes e depois de cada mudança
+func Hunks(a, b []string, context int) []Hunk {
+	return HunksFromOps(Diff(a, b), a, b, context)
+}
+
+// HunksFromOps agrupa um script de edição já calculado em hunks
+func HunksFromOps(ops []Op, a, b []string, context int) []Hunk {
+	var hunks []Hunk
+	var current *Hunk
+	// aPos e bPos apontam para o início (base 0) do hunk corrente
+	var aPos, bPos int
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.AStart = aPos
+		current.BStart = bPos
+		if current.ALines > 0 {
+			current.AStart++
+		}
+		if current.BLines > 0 {
+			current.BStart++
+		}
+		hunks = append(hunks, *current)
+		current = nil
+	}
+
+	for i, op := range ops {
+		if op.Kind == Equal {
+			if current == nil {
+				continue
+			}
+			size := op.AEnd - op.AStart
+			last := i == len(ops)-1
+			if !last && size <= 2*context {
+				// Mudanças próximas permanecem no mesmo hunk
+				for j := 0; j < size; j++ {
+					current.appendLine(Equal, a[op.AStart+j])
+				}
+				continue
+			}
+			for j := 0; j < size && j < context; j++ {
+				current.appendLine(Equal, a[op.AStart+j])
+			}
+			flush()
+			continue
+		}
+
+		if current == nil {
+			current = &Hunk{}
+			// Contexto anterior à mudança
+			lead := 0
+			if i > 0 && ops[i-1].Kind == Equal {
+				lead = min(context, ops[i-1].AEnd-ops[i-1].AStart)
+			}
+			aPos, bPos = op.AStart-lead, op.BStart-lead
+			for j := lead; j > 0; j-- {
+				current.appendLine(Equal, a[op.AStart-j])
+			}
+		}
+
+		for j := op.AStart; j < op.AEnd; j++ {
+			current.appendLine(Delete, a[j])
+		}
+		for j := op.BStart; j < op.BEnd; j++ {
+			current.appendLine(Insert, b[j])
+		}
+	}
+	flush()
+
+	return hunks
+}
+
+// appendLine adiciona uma linha ao hunk atualizando as contagens
+func (h *Hunk) appendLine(kind Kind, text string) {
+	h.Lines = append(h.Lines, Line{Kind: kind, Text: text})
+	if kind != Insert {
+		h.ALines++
+	}
+	if kind != Delete {
+		h.BLines++
+	}
+}
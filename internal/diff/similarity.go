@@ -0,0 +1,16 @@
+package diff
+
+// Similarity retorna a porcentagem de elementos em comum entre a e b, de 0 a
+// 100: o dobro dos elementos iguais sobre o total das duas sequências
+func Similarity[T comparable](a, b []T) int {
+	if len(a)+len(b) == 0 {
+		return 100
+	}
+	equal := 0
+	for _, op := range Diff(a, b) {
+		if op.Kind == Equal {
+			equal += op.AEnd - op.AStart
+		}
+	}
+	return 200 * equal / (len(a) + len(b))
+}
@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Span é um trecho de uma linha, marcado quando não existe na outra linha
+// do par
+type Span struct {
+	Text    string
+	Changed bool
+}
+
+// Words compara duas versões de uma linha palavra a palavra e retorna os
+// trechos de cada uma, marcando os que mudaram. Retorna nil quando as linhas
+// não têm palavras em comum, caso em que a linha inteira é a mudança.
+func Words(a, b string) (aSpans, bSpans []Span) {
+	aTokens, bTokens := tokenize(a), tokenize(b)
+	ops := Diff(aTokens, bTokens)
+
+	common := false
+	for _, op := range ops {
+		if op.Kind == Equal && strings.TrimSpace(strings.Join(aTokens[op.AStart:op.AEnd], "")) != "" {
+			common = true
+			break
+		}
+	}
+	if !common {
+		return nil, nil
+	}
+
+	for i, op := range ops {
+		switch op.Kind {
+		case Equal:
+			text := strings.Join(aTokens[op.AStart:op.AEnd], "")
+			// espaços isolados entre duas mudanças fazem parte da mudança,
+			// para não fragmentar o destaque
+			changed := strings.TrimSpace(text) == "" && i > 0 && i < len(ops)-1
+			aSpans = appendSpan(aSpans, text, changed)
+			bSpans = appendSpan(bSpans, text, changed)
+		case Delete:
+			aSpans = appendSpan(aSpans, strings.Join(aTokens[op.AStart:op.AEnd], ""), true)
+		case Insert:
+			bSpans = appendSpan(bSpans, strings.Join(bTokens[op.BStart:op.BEnd], ""), true)
+		}
+	}
+	return aSpans, bSpans
+}
+
+// appendSpan acrescenta o trecho, juntando-o ao anterior quando têm a mesma
+// marcação
+func appendSpan(spans []Span, text string, changed bool) []Span {
+	if text == "" {
+		return spans
+	}
+	if n := len(spans); n > 0 && spans[n-1].Changed == changed {
+		spans[n-1].Text += text
+		return spans
+	}
+	return append(spans, Span{Text: text, Changed: changed})
+}
+
+// tokenize divide a linha em palavras (letras, dígitos e sublinhado),
+// sequências de espaços e caracteres de pontuação isolados
+func tokenize(s string) []string {
+	var tokens []string
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		n := size
+		switch {
+		case isWord(r):
+			n = tokenEnd(s, isWord)
+		case unicode.IsSpace(r):
+			n = tokenEnd(s, unicode.IsSpace)
+		}
+		tokens = append(tokens, s[:n])
+		s = s[n:]
+	}
+	return tokens
+}
+
+// tokenEnd retorna o tamanho do prefixo de s cujas runas satisfazem f
+func tokenEnd(s string, f func(rune) bool) int {
+	for i, r := range s {
+		if !f(r) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,51 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		wantA []Span
+		wantB []Span
+	}{
+		{
+			name:  "uma palavra",
+			a:     "timeout = 30",
+			b:     "timeout = 60",
+			wantA: []Span{{"timeout = ", false}, {"30", true}},
+			wantB: []Span{{"timeout = ", false}, {"60", true}},
+		},
+		{
+			name:  "palavra inserida",
+			a:     "return a, b",
+			b:     "return a, c, b",
+			wantA: []Span{{"return a, b", false}},
+			wantB: []Span{{"return a, ", false}, {"c, ", true}, {"b", false}},
+		},
+		{
+			name:  "espaço entre mudanças",
+			a:     "x := foo bar",
+			b:     "x := baz qux",
+			wantA: []Span{{"x := ", false}, {"foo bar", true}},
+			wantB: []Span{{"x := ", false}, {"baz qux", true}},
+		},
+		{
+			name: "sem palavras em comum",
+			a:    "abc def",
+			b:    "xyz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotA, gotB := Words(tt.a, tt.b)
+			if !reflect.DeepEqual(gotA, tt.wantA) || !reflect.DeepEqual(gotB, tt.wantB) {
+				t.Errorf("Words() = %+v, %+v, want %+v, %+v", gotA, gotB, tt.wantA, tt.wantB)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package patch
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Contents retorna o conteúdo de um caminho nas Branches A e B; nil indica
+// que o caminho não existe naquele lado
+type Contents func(path string) (old, new []byte, err error)
+
+// Modos de arquivo do git
+const (
+	modeFile       = "100644"
+	modeExecutable = "100755"
+	modeSymlink    = "120000"
+)
+
+// zeroHash é o hash usado pelo git para o lado inexistente
+const zeroHash = "0000000000000000000000000000000000000000"
+
+// dirOnlyProps são propriedades que só existem em diretórios: seções com
+// elas e sem conteúdo são de diretórios, que o git não representa
+var dirOnlyProps = map[string]bool{
+	"svn:ignore": true, "svn:global-ignores": true, "svn:externals": true,
+	"svn:mergeinfo": true, "svn:auto-props": true, "svn:inheritable-ignores": true,
+	"svn:inheritable-auto-props": true,
+}
+
+// gitFile é uma seção já convertida para a semântica do git
+type gitFile struct {
+	*File
+	oldPath, newPath string
+	oldMode, newMode string
+	added, deleted   bool
+	renamed          bool
+	old, new         []byte
+}
+
+// WriteGit escreve as seções no formato estendido do git (diff --git),
+// aplicável com git apply. O git não tem propriedades: svn:executable e
+// svn:special viram modos e as demais são omitidas. Remoções e adições com
+// o mesmo conteúdo viram renomeações. Os binários são lidos com contents e
+// escritos como GIT binary patch; sem contents, apenas são sinalizados.
+func WriteGit(w io.Writer, files []*File, contents Contents) error {
+	var converted []*gitFile
+	for _, f := range files {
+		g, err := convert(f, contents)
+		if err != nil {
+			return err
+		}
+		if g != nil {
+			converted = append(converted, g)
+		}
+	}
+	detectRenames(converted)
+
+	var b strings.Builder
+	for _, g := range converted {
+		if g.renamed && g.deleted {
+			// Já escrito como a origem da renomeação
+			continue
+		}
+		g.write(&b)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// convert determina os modos e o tipo da mudança de uma seção. Seções que
+// só mudam propriedades sem equivalente no git retornam nil.
+func convert(f *File, contents Contents) (*gitFile, error) {
+	g := &gitFile{File: f, oldPath: f.Path, newPath: f.Path, added: f.Added(), deleted: f.Deleted()}
+	g.oldMode, g.newMode = modes(f)
+
+	if f.Binary && contents != nil {
+		old, new, err := contents(f.Path)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler o binário '%s': %w", f.Path, err)
+		}
+		g.old, g.new = old, new
+		g.added, g.deleted = old == nil, new == nil
+	}
+
+	if len(f.Hunks) == 0 && !f.Binary {
+		for _, prop := range f.Props {
+			if dirOnlyProps[prop.Name] {
+				return nil, nil
+			}
+		}
+		if !g.added && !g.deleted && g.oldMode == g.newMode {
+			return nil, nil
+		}
+	}
+	return g, nil
+}
+
+// modes deduz os modos antigo e novo pelas mudanças de svn:executable e
+// svn:special. Propriedades inalteradas não aparecem no diff, por isso o
+// modo padrão é o de arquivo comum.
+func modes(f *File) (string, string) {
+	oldMode, newMode := modeFile, modeFile
+	for _, m := range []struct{ prop, mode string }{
+		{"svn:executable", modeExecutable},
+		{"svn:special", modeSymlink},
+	} {
+		if prop, ok := f.Prop(m.prop); ok {
+			if prop.Action != "Added" {
+				oldMode = m.mode
+			}
+			if prop.Action != "Deleted" {
+				newMode = m.mode
+			}
+		}
+	}
+	return oldMode, newMode
+}
+
+// content retorna o conteúdo completo de uma adição ou remoção
+func (g *gitFile) content() (string, bool) {
+	if g.Binary {
+		if g.added {
+			return string(g.new), g.new != nil
+		}
+		return string(g.old), g.old != nil
+	}
+	var b strings.Builder
+	for _, hunk := range g.Hunks {
+		if g.added {
+			b.WriteString(hunk.New())
+		} else {
+			b.WriteString(hunk.Old())
+		}
+	}
+	return b.String(), true
+}
+
+// detectRenames combina remoções e adições de conteúdo idêntico e não vazio
+func detectRenames(files []*gitFile) {
+	deleted := map[string][]*gitFile{}
+	for _, g := range files {
+		if g.deleted && !g.added {
+			if content, ok := g.content(); ok && content != "" {
+				key := g.oldMode + "\x00" + content
+				deleted[key] = append(deleted[key], g)
+			}
+		}
+	}
+	for _, g := range files {
+		if !g.added || g.deleted {
+			continue
+		}
+		content, ok := g.content()
+		if !ok || content == "" {
+			continue
+		}
+		key := g.newMode + "\x00" + content
+		if sources := deleted[key]; len(sources) > 0 {
+			source := sources[0]
+			deleted[key] = sources[1:]
+			source.renamed, g.renamed = true, true
+			g.oldPath, g.oldMode = source.Path, source.oldMode
+		}
+	}
+}
+
+// write escreve a seção no formato do git
+func (g *gitFile) write(b *strings.Builder) {
+	fmt.Fprintf(b, "diff --git a/%s b/%s\n", g.oldPath, g.newPath)
+	switch {
+	case g.renamed:
+		fmt.Fprintf(b, "similarity index 100%%\nrename from %s\nrename to %s\n", g.oldPath, g.newPath)
+		return
+	case g.added:
+		fmt.Fprintf(b, "new file mode %s\n", g.newMode)
+	case g.deleted:
+		fmt.Fprintf(b, "deleted file mode %s\n", g.oldMode)
+	case g.oldMode != g.newMode:
+		fmt.Fprintf(b, "old mode %s\nnew mode %s\n", g.oldMode, g.newMode)
+	}
+
+	if g.Binary {
+		g.writeBinary(b)
+		return
+	}
+	if len(g.Hunks) == 0 {
+		return
+	}
+
+	oldName, newName := "a/"+g.oldPath, "b/"+g.newPath
+	if g.added {
+		oldName = "/dev/null"
+	}
+	if g.deleted {
+		newName = "/dev/null"
+	}
+	fmt.Fprintf(b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, hunk := range g.Hunks {
+		fmt.Fprintf(b, "@@ -%s +%s @@\n", span(hunk.OldStart, hunk.OldLines), span(hunk.NewStart, hunk.NewLines))
+		for _, line := range hunk.Lines {
+			b.WriteByte(line.Kind)
+			b.WriteString(g.lineText(line))
+			b.WriteString("\n")
+			if line.NoNewline {
+				b.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+}
+
+// lineText remove o prefixo "link " que o svn usa no conteúdo de links
+// simbólicos, guardando no git apenas o destino
+func (g *gitFile) lineText(line Line) string {
+	oldLink, newLink := g.oldMode == modeSymlink, g.newMode == modeSymlink
+	strip := (line.Kind == '-' && oldLink) || (line.Kind == '+' && newLink) ||
+		(line.Kind == ' ' && oldLink && newLink)
+	if strip {
+		return strings.TrimPrefix(line.Text, "link ")
+	}
+	return line.Text
+}
+
+// span formata um intervalo de hunk omitindo a quantidade quando é 1
+func span(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// writeBinary escreve o GIT binary patch com o conteúdo novo e, para
+// permitir a reversão, o antigo. Sem o conteúdo, apenas sinaliza a mudança.
+func (g *gitFile) writeBinary(b *strings.Builder) {
+	if g.old == nil && g.new == nil {
+		fmt.Fprintf(b, "Binary files a/%s and b/%s differ\n", g.oldPath, g.newPath)
+		return
+	}
+	// O git apply só aceita binários com a linha index completa
+	fmt.Fprintf(b, "index %s..%s\n", blobHash(g.old), blobHash(g.new))
+	b.WriteString("GIT binary patch\n")
+	writeLiteral(b, g.new)
+	writeLiteral(b, g.old)
+}
+
+// blobHash calcula o hash do objeto blob do git; nil é o hash nulo
+func blobHash(content []byte) string {
+	if content == nil {
+		return zeroHash
+	}
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeLiteral escreve um bloco "literal": o conteúdo comprimido com zlib em
+// linhas de até 52 bytes, cada uma prefixada pelo tamanho (A-Z para 1-26,
+// a-z para 27-52) e codificada em base85
+func writeLiteral(b *strings.Builder, content []byte) {
+	var compressed bytes.Buffer
+	zw, _ := zlib.NewWriterLevel(&compressed, zlib.BestCompression)
+	zw.Write(content)
+	zw.Close()
+
+	fmt.Fprintf(b, "literal %d\n", len(content))
+	data := compressed.Bytes()
+	for len(data) > 0 {
+		n := min(len(data), 52)
+		if n <= 26 {
+			b.WriteByte(byte('A' + n - 1))
+		} else {
+			b.WriteByte(byte('a' + n - 27))
+		}
+		b.WriteString(encode85(data[:n]))
+		b.WriteString("\n")
+		data = data[n:]
+	}
+	b.WriteString("\n")
+}
+
+// base85 é o alfabeto do base85 usado pelo git
+const base85 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz!#$%&()*+-;<=>?@^_`{|}~"
+
+// encode85 codifica grupos de 4 bytes, completando o último com zeros, em
+// 5 dígitos base85, o mais significativo primeiro
+func encode85(data []byte) string {
+	var out strings.Builder
+	for i := 0; i < len(data); i += 4 {
+		var acc uint32
+		for j := 0; j < 4; j++ {
+			acc <<= 8
+			if i+j < len(data) {
+				acc |= uint32(data[i+j])
+			}
+		}
+		var digits [5]byte
+		for k := 4; k >= 0; k-- {
+			digits[k] = base85[acc%85]
+			acc /= 85
+		}
+		out.Write(digits[:])
+	}
+	return out.String()
+}
@@ -0,0 +1,209 @@
+package patch
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/dump"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+func TestEncode85(t *testing.T) {
+	// zlib de um conteúdo vazio, como no "literal 0" gerado pelo git
+	empty := []byte{0x78, 0x01, 0x03, 0x00, 0x00, 0x00, 0x00, 0x01}
+	if got := encode85(empty); got != "cmV?d00001" {
+		t.Errorf("encode85() = %q, want %q", got, "cmV?d00001")
+	}
+	if got := encode85([]byte{0xff}); got != "{{R30" {
+		t.Errorf("encode85(0xff) = %q, want %q", got, "{{R30")
+	}
+}
+
+func TestBlobHash(t *testing.T) {
+	// git hash-object de um arquivo vazio; nil é o lado inexistente
+	if got := blobHash([]byte{}); got != "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391" {
+		t.Errorf("blobHash(vazio) = %s", got)
+	}
+	if got := blobHash(nil); got != zeroHash {
+		t.Errorf("blobHash(nil) = %s", got)
+	}
+}
+
+func TestWriteGit(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want string
+	}{
+		{
+			name: "modificação",
+			diff: "Index: a.txt\n===\n--- a.txt\t(revision 1)\n+++ a.txt\t(revision 2)\n@@ -1,2 +1,2 @@\n um\n-dois\n+2\n",
+			want: "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n um\n-dois\n+2\n",
+		},
+		{
+			name: "executável adicionado",
+			diff: "Index: run.sh\n===\n--- run.sh\t(nonexistent)\n+++ run.sh\t(revision 2)\n@@ -0,0 +1 @@\n+#!/bin/sh\n\n" +
+				"Property changes on: run.sh\n___\nAdded: svn:executable\n## -0,0 +1 ##\n+*\n\\ No newline at end of property\n",
+			want: "diff --git a/run.sh b/run.sh\nnew file mode 100755\n--- /dev/null\n+++ b/run.sh\n@@ -0,0 +1 @@\n+#!/bin/sh\n",
+		},
+		{
+			name: "remoção",
+			diff: "Index: rm.txt\n===\n--- rm.txt\t(revision 1)\n+++ rm.txt\t(nonexistent)\n@@ -1 +0,0 @@\n-rm\n",
+			want: "diff --git a/rm.txt b/rm.txt\ndeleted file mode 100644\n--- a/rm.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-rm\n",
+		},
+		{
+			name: "mudança de modo",
+			diff: "Index: run.sh\n===\n--- run.sh\t(revision 1)\n+++ run.sh\t(revision 2)\n\n" +
+				"Property changes on: run.sh\n___\nDeleted: svn:executable\n## -1 +0,0 ##\n-*\n\\ No newline at end of property\n",
+			want: "diff --git a/run.sh b/run.sh\nold mode 100755\nnew mode 100644\n",
+		},
+		{
+			name: "link simbólico",
+			diff: "Index: link\n===\n--- link\t(nonexistent)\n+++ link\t(revision 2)\n@@ -0,0 +1 @@\n+link a.txt\n\\ No newline at end of file\n\n" +
+				"Property changes on: link\n___\nAdded: svn:special\n## -0,0 +1 ##\n+*\n\\ No newline at end of property\n",
+			want: "diff --git a/link b/link\nnew file mode 120000\n--- /dev/null\n+++ b/link\n@@ -0,0 +1 @@\n+a.txt\n\\ No newline at end of file\n",
+		},
+		{
+			name: "renomeação",
+			diff: "Index: velho.txt\n===\n--- velho.txt\t(revision 1)\n+++ velho.txt\t(nonexistent)\n@@ -1 +0,0 @@\n-igual\n" +
+				"Index: novo.txt\n===\n--- novo.txt\t(nonexistent)\n+++ novo.txt\t(revision 2)\n@@ -0,0 +1 @@\n+igual\n",
+			want: "diff --git a/velho.txt b/novo.txt\nsimilarity index 100%\nrename from velho.txt\nrename to novo.txt\n",
+		},
+		{
+			name: "só propriedades e diretórios",
+			diff: "Index: a.txt\n===\n--- a.txt\t(revision 1)\n+++ a.txt\t(revision 2)\n\n" +
+				"Property changes on: a.txt\n___\nAdded: svn:eol-style\n## -0,0 +1 ##\n+native\n\\ No newline at end of property\n" +
+				"Index: lib\n===\n--- lib\t(nonexistent)\n+++ lib\t(revision 2)\n\n" +
+				"Property changes on: lib\n___\nAdded: svn:ignore\n## -0,0 +1 ##\n+*.o\n",
+			want: "",
+		},
+		{
+			name: "binário sem conteúdo",
+			diff: "Index: img.png\n===\nCannot display: file marked as a binary type.\nsvn:mime-type = image/png\n",
+			want: "diff --git a/img.png b/img.png\nBinary files a/img.png and b/img.png differ\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := ParseString(tt.diff)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			var out strings.Builder
+			if err := WriteGit(&out, files, nil); err != nil {
+				t.Fatalf("WriteGit() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("WriteGit() =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestWriteGit_GitApply aplica com o git apply o patch gerado a partir da
+// comparação de duas revisões lidas pelo backend nativo
+func TestWriteGit_GitApply(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git não encontrado no PATH")
+	}
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	history := [][]svntest.DumpNode{
+		{
+			{Path: "trunk", Kind: "dir", Action: "add"},
+			{Path: "trunk/a.txt", Kind: "file", Action: "add", Content: "um\ndois\ntrês\n"},
+			{Path: "trunk/velho.txt", Kind: "file", Action: "add", Content: "conteúdo movido\n"},
+			{Path: "trunk/rm.txt", Kind: "file", Action: "add", Content: "rm\n"},
+			{Path: "trunk/img.png", Kind: "file", Action: "add", Content: png, Props: []string{"svn:mime-type", "image/png"}},
+		},
+		{
+			{Path: "trunk/a.txt", Kind: "file", Action: "change", Content: "um\n2\ntrês\n", Props: []string{"svn:eol-style", "native"}},
+			{Path: "trunk/velho.txt", Action: "delete"},
+			{Path: "trunk/novo.txt", Kind: "file", Action: "add", Content: "conteúdo movido\n"},
+			{Path: "trunk/rm.txt", Action: "delete"},
+			{Path: "trunk/run.sh", Kind: "file", Action: "add", Content: "#!/bin/sh\n", Props: []string{"svn:executable", "*"}},
+			{Path: "trunk/link", Kind: "file", Action: "add", Content: "link a.txt", Props: []string{"svn:special", "*"}},
+			{Path: "trunk/img.png", Kind: "file", Action: "change", Content: png + "\x00mais", Props: []string{"svn:mime-type", "image/png"}},
+			{Path: "trunk/bin.dat", Kind: "file", Action: "add", Content: "\x00\x01\x02"},
+		},
+	}
+	dumpFile := filepath.Join(t.TempDir(), "repo.dump")
+	if err := os.WriteFile(dumpFile, svntest.WriteDump(history), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := svn.NewRepositoryBackend(func(url string) (svn.Repository, error) { return dump.Open(url) })
+	url := dump.Scheme + filepath.ToSlash(dumpFile) + "/trunk"
+	branchA := &config.BranchConfig{URL: url, Revisions: []string{"1"}}
+	branchB := &config.BranchConfig{URL: url, Revisions: []string{"2"}}
+	result, err := backend.GetDiff(branchA, branchB, false)
+	if err != nil {
+		t.Fatalf("GetDiff() error = %v", err)
+	}
+	files, err := ParseString(result.Output)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	var out strings.Builder
+	err = WriteGit(&out, files, func(path string) ([]byte, []byte, error) {
+		old, _, err := backend.ReadFile(branchA, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		new, _, err := backend.ReadFile(branchB, path)
+		return old, new, err
+	})
+	if err != nil {
+		t.Fatalf("WriteGit() error = %v", err)
+	}
+	patchFile := filepath.Join(t.TempDir(), "git.patch")
+	if err := os.WriteFile(patchFile, []byte(out.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.txt": "um\ndois\ntrês\n", "velho.txt": "conteúdo movido\n", "rm.txt": "rm\n", "img.png": png,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s\n%s", args, err, output, out.String())
+		}
+	}
+	git("init", "-q")
+	git("apply", "--check", patchFile)
+	git("apply", patchFile)
+
+	for name, want := range map[string]string{
+		"a.txt": "um\n2\ntrês\n", "novo.txt": "conteúdo movido\n", "run.sh": "#!/bin/sh\n",
+		"img.png": png + "\x00mais", "bin.dat": "\x00\x01\x02",
+	} {
+		if got, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"velho.txt", "rm.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s deveria ter sido removido", name)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dir, "run.sh")); err != nil || info.Mode()&0o111 == 0 {
+		t.Errorf("run.sh sem permissão de execução: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(dir, "link")); err != nil || target != "a.txt" {
+		t.Errorf("link = %q, %v, want a.txt", target, err)
+	}
+}
@@ -0,0 +1,355 @@
+// Package patch interpreta a saída textual do svn diff, gerada pelo comando
+// svn ou pelos clientes nativos, em seções por arquivo e converte essas seções
+// para outros formatos de patch.
+package patch
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// File é a seção de um caminho no svn diff: o cabeçalho, os hunks do
+// conteúdo, o marcador de binário e as mudanças de propriedades
+type File struct {
+	Path string
+	// LabelA e LabelB são os rótulos após o caminho nas linhas ---/+++,
+	// como "(revision 12)" ou "(nonexistent)"
+	LabelA, LabelB string
+	Hunks          []Hunk
+	Binary         bool
+	MimeType       string
+	// Details são as linhas após o svn:mime-type de um binário, como o
+	// tamanho e o SHA-256 de cada lado acrescentados pelo svndiff
+	Details []string
+	Props   []PropChange
+}
+
+// Hunk é um trecho do diff unificado
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []Line
+}
+
+// Line é uma linha de hunk. Kind é ' ', '-' ou '+'; Text não inclui o
+// marcador nem a quebra de linha.
+type Line struct {
+	Kind byte
+	Text string
+	// NoNewline indica a marca "\ No newline at end of ..." após a linha
+	NoNewline bool
+}
+
+// PropChange é a mudança de uma propriedade
+type PropChange struct {
+	Name string
+	// Action é "Added", "Deleted" ou "Modified"
+	Action   string
+	Old, New string
+}
+
+// Added indica que o arquivo não existia na Branch A
+func (f *File) Added() bool {
+	return absent(f.LabelA)
+}
+
+// Deleted indica que o arquivo não existe na Branch B
+func (f *File) Deleted() bool {
+	return absent(f.LabelB)
+}
+
+// absent reconhece os rótulos de caminhos inexistentes: "(nonexistent)" no
+// svn 1.9+ e "(revision 0)" nas versões anteriores
+func absent(label string) bool {
+	return strings.HasSuffix(label, "(nonexistent)") || strings.HasSuffix(label, "(revision 0)")
+}
+
+// Prop retorna a mudança da propriedade, se houver
+func (f *File) Prop(name string) (PropChange, bool) {
+	for _, prop := range f.Props {
+		if prop.Name == name {
+			return prop, true
+		}
+	}
+	return PropChange{}, false
+}
+
+// Old reconstrói o conteúdo do lado A a partir dos hunks. Só é completo
+// quando os hunks cobrem o arquivo inteiro, como em adições e remoções.
+func (h Hunk) Old() string {
+	return h.side('+')
+}
+
+// New reconstrói o conteúdo do lado B a partir dos hunks
+func (h Hunk) New() string {
+	return h.side('-')
+}
+
+func (h Hunk) side(skip byte) string {
+	var b strings.Builder
+	for _, line := range h.Lines {
+		if line.Kind == skip {
+			continue
+		}
+		b.WriteString(line.Text)
+		if !line.NoNewline {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// Parse lê a saída do svn diff. Linhas fora das seções reconhecidas são
+// ignoradas, como faz o svn patch.
+func Parse(r io.Reader) ([]*File, error) {
+	reader := NewReader(r)
+	var files []*File
+	for {
+		f, err := reader.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+}
+
+// ParseString lê a saída do svn diff já carregada em memória
+func ParseString(output string) ([]*File, error) {
+	return Parse(strings.NewReader(output))
+}
+
+// Reader lê as seções do svn diff uma a uma, sem carregar a saída inteira
+// em memória
+type Reader struct {
+	p parser
+}
+
+// NewReader cria um Reader sobre a saída do svn diff
+func NewReader(r io.Reader) *Reader {
+	reader := &Reader{p: parser{scanner: bufio.NewScanner(r)}}
+	reader.p.scanner.Buffer(make([]byte, 64*1024), 1<<30)
+	reader.p.scanner.Split(scanLines)
+	return reader
+}
+
+// Next retorna a próxima seção completa, ou io.EOF no fim da saída
+func (r *Reader) Next() (*File, error) {
+	p := &r.p
+	for p.next() {
+		if err := p.parseLine(); err != nil {
+			return nil, fmt.Errorf("linha %d do diff: %w", p.lineNo, err)
+		}
+		if p.done != nil {
+			f := p.done
+			p.done = nil
+			return f, nil
+		}
+	}
+	if err := p.scanner.Err(); err != nil {
+		return nil, err
+	}
+	if f := p.current; f != nil {
+		p.current = nil
+		return f, nil
+	}
+	return nil, io.EOF
+}
+
+type parser struct {
+	scanner *bufio.Scanner
+	line    string
+	lineNo  int
+	current *File
+	// done é a seção encerrada pelo início da seção atual
+	done *File
+	// unread faz next devolver novamente a linha atual
+	unread bool
+	// details indica que as linhas seguintes detalham o binário atual
+	details bool
+}
+
+func (p *parser) next() bool {
+	if p.unread {
+		p.unread = false
+		return true
+	}
+	if !p.scanner.Scan() {
+		return false
+	}
+	p.line = p.scanner.Text()
+	p.lineNo++
+	return true
+}
+
+func (p *parser) start(path string) {
+	p.done = p.current
+	p.current = &File{Path: path}
+	p.details = false
+}
+
+// scanLines divide apenas em "\n": o "\r" de arquivos com CRLF faz parte do
+// conteúdo das linhas dos hunks
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+func (p *parser) parseLine() error {
+	// O svn no Windows termina as linhas de cabeçalho com CRLF
+	line := strings.TrimSuffix(p.line, "\r")
+	if line == "" {
+		p.details = false
+	}
+	switch {
+	case strings.HasPrefix(line, "Index: "):
+		p.start(strings.TrimPrefix(line, "Index: "))
+	case strings.HasPrefix(line, "Property changes on: "):
+		path := strings.TrimPrefix(line, "Property changes on: ")
+		p.details = false
+		if p.current == nil || p.current.Path != path {
+			p.start(path)
+		}
+	case p.current == nil:
+		// Texto antes da primeira seção
+	case strings.HasPrefix(line, "--- ") && len(p.current.Hunks) == 0:
+		p.current.LabelA = label(line)
+	case strings.HasPrefix(line, "+++ ") && len(p.current.Hunks) == 0:
+		p.current.LabelB = label(line)
+	case strings.HasPrefix(line, "@@ "):
+		hunk, err := parseRange(line, "@@")
+		if err != nil {
+			return err
+		}
+		lines, err := p.hunkLines(hunk)
+		if err != nil {
+			return err
+		}
+		hunk.Lines = lines
+		p.current.Hunks = append(p.current.Hunks, hunk)
+	case strings.HasPrefix(line, "Cannot display: file marked as a binary type."):
+		p.current.Binary = true
+	case p.current.Binary && strings.HasPrefix(line, "svn:mime-type = "):
+		p.current.MimeType = strings.TrimPrefix(line, "svn:mime-type = ")
+		p.details = true
+	case p.details && line != "":
+		p.current.Details = append(p.current.Details, line)
+	case strings.HasPrefix(line, "Added: "), strings.HasPrefix(line, "Deleted: "),
+		strings.HasPrefix(line, "Modified: "), strings.HasPrefix(line, "Name: "):
+		action, name, _ := strings.Cut(line, ": ")
+		if action == "Name" {
+			action = "Modified"
+		}
+		p.current.Props = append(p.current.Props, PropChange{Name: name, Action: action})
+	case strings.HasPrefix(line, "## ") && len(p.current.Props) > 0:
+		hunk, err := parseRange(line, "##")
+		if err != nil {
+			return err
+		}
+		lines, err := p.hunkLines(hunk)
+		if err != nil {
+			return err
+		}
+		hunk.Lines = lines
+		prop := &p.current.Props[len(p.current.Props)-1]
+		prop.Old += hunk.Old()
+		prop.New += hunk.New()
+	}
+	return nil
+}
+
+// hunkLines lê as linhas do hunk até completar as contagens do cabeçalho
+func (p *parser) hunkLines(hunk Hunk) ([]Line, error) {
+	var lines []Line
+	oldLeft, newLeft := hunk.OldLines, hunk.NewLines
+	for oldLeft > 0 || newLeft > 0 {
+		if !p.next() {
+			return nil, fmt.Errorf("hunk incompleto")
+		}
+		if strings.HasPrefix(p.line, `\ `) {
+			if len(lines) > 0 {
+				lines[len(lines)-1].NoNewline = true
+			}
+			continue
+		}
+		kind := byte(' ')
+		text := p.line
+		if text != "" {
+			kind, text = text[0], text[1:]
+		}
+		switch kind {
+		case ' ':
+			oldLeft--
+			newLeft--
+		case '-':
+			oldLeft--
+		case '+':
+			newLeft--
+		default:
+			return nil, fmt.Errorf("linha de hunk inválida: %q", p.line)
+		}
+		if oldLeft < 0 || newLeft < 0 {
+			return nil, fmt.Errorf("hunk maior que o cabeçalho")
+		}
+		lines = append(lines, Line{Kind: kind, Text: text})
+	}
+
+	// A marca de ausência de quebra da última linha vem após as contagens
+	if p.next() {
+		if strings.HasPrefix(p.line, `\ `) && len(lines) > 0 {
+			lines[len(lines)-1].NoNewline = true
+		} else {
+			p.unread = true
+		}
+	}
+	return lines, nil
+}
+
+// label extrai o rótulo de uma linha ---/+++: o texto após o primeiro tab
+func label(line string) string {
+	_, rest, _ := strings.Cut(line[4:], "\t")
+	return rest
+}
+
+// parseRange lê "@@ -a,b +c,d @@" (ou com ## nas propriedades)
+func parseRange(line, marker string) (Hunk, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[0] != marker || fields[3] != marker ||
+		!strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return Hunk{}, fmt.Errorf("cabeçalho de hunk inválido: %q", line)
+	}
+	var hunk Hunk
+	var err error
+	if hunk.OldStart, hunk.OldLines, err = parseSpan(fields[1][1:]); err != nil {
+		return Hunk{}, fmt.Errorf("cabeçalho de hunk inválido: %q", line)
+	}
+	if hunk.NewStart, hunk.NewLines, err = parseSpan(fields[2][1:]); err != nil {
+		return Hunk{}, fmt.Errorf("cabeçalho de hunk inválido: %q", line)
+	}
+	return hunk, nil
+}
+
+// parseSpan lê "início,quantidade"; sem a quantidade, ela é 1
+func parseSpan(s string) (int, int, error) {
+	startText, countText, ok := strings.Cut(s, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		return start, 1, nil
+	}
+	count, err := strconv.Atoi(countText)
+	return start, count, err
+}
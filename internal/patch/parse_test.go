@@ -0,0 +1,160 @@
+package patch
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+// cliDiff reproduz a saída do svn diff entre duas URLs, com os rótulos
+// "(.../caminho)" e seções de propriedades e binários
+const cliDiff = "Index: a.txt\n" +
+	"===================================================================\n" +
+	"--- a.txt\t(.../trunk)\t(revision 10)\n" +
+	"+++ a.txt\t(.../branches/x)\t(revision 12)\n" +
+	"@@ -1,3 +1,3 @@\n" +
+	" um\n" +
+	"--- traço\n" +
+	"+++ mais\n" +
+	" três\r\n" +
+	"Index: novo.txt\n" +
+	"===================================================================\n" +
+	"--- novo.txt\t(nonexistent)\n" +
+	"+++ novo.txt\t(revision 12)\n" +
+	"@@ -0,0 +1 @@\n" +
+	"+sem quebra\n" +
+	"\\ No newline at end of file\n" +
+	"\n" +
+	"Property changes on: novo.txt\n" +
+	"___________________________________________________________________\n" +
+	"Added: svn:executable\n" +
+	"## -0,0 +1 ##\n" +
+	"+*\n" +
+	"\\ No newline at end of property\n" +
+	"Modified: svn:externals\n" +
+	"## -1,2 +1,2 ##\n" +
+	" lib http://x/lib\n" +
+	"-old http://x/old\n" +
+	"+new http://x/new\n" +
+	"Index: img.png\n" +
+	"===================================================================\n" +
+	"Cannot display: file marked as a binary type.\n" +
+	"svn:mime-type = image/png\n" +
+	"Property changes on: .\n" +
+	"___________________________________________________________________\n" +
+	"Deleted: svn:ignore\n" +
+	"## -1 +0,0 ##\n" +
+	"-build\n"
+
+func TestParse(t *testing.T) {
+	files, err := ParseString(cliDiff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	if want := []string{"a.txt", "novo.txt", "img.png", "."}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("Parse() caminhos = %v, want %v", paths, want)
+	}
+
+	a := files[0]
+	if a.LabelA != "(.../trunk)\t(revision 10)" || a.Added() || a.Deleted() || len(a.Hunks) != 1 {
+		t.Errorf("a.txt = %+v", a)
+	}
+	wantLines := []Line{{' ', "um", false}, {'-', "-- traço", false}, {'+', "++ mais", false}, {' ', "três\r", false}}
+	if !reflect.DeepEqual(a.Hunks[0].Lines, wantLines) {
+		t.Errorf("a.txt linhas = %+v, want %+v", a.Hunks[0].Lines, wantLines)
+	}
+
+	novo := files[1]
+	if !novo.Added() || novo.Hunks[0].New() != "sem quebra" || !novo.Hunks[0].Lines[0].NoNewline {
+		t.Errorf("novo.txt = %+v", novo)
+	}
+	wantProps := []PropChange{
+		{Name: "svn:executable", Action: "Added", New: "*"},
+		{Name: "svn:externals", Action: "Modified", Old: "lib http://x/lib\nold http://x/old\n", New: "lib http://x/lib\nnew http://x/new\n"},
+	}
+	if !reflect.DeepEqual(novo.Props, wantProps) {
+		t.Errorf("novo.txt propriedades = %+v, want %+v", novo.Props, wantProps)
+	}
+
+	if img := files[2]; !img.Binary || img.MimeType != "image/png" {
+		t.Errorf("img.png = %+v", img)
+	}
+	if prop, ok := files[3].Prop("svn:ignore"); !ok || prop.Action != "Deleted" || prop.Old != "build\n" {
+		t.Errorf(". svn:ignore = %+v, %v", prop, ok)
+	}
+}
+
+func TestParse_BinaryDetails(t *testing.T) {
+	output := "Index: img.png\n" +
+		"===================================================================\n" +
+		"Cannot display: file marked as a binary type.\n" +
+		"svn:mime-type = image/png\n" +
+		"Tamanho: 10 → 12 bytes\n" +
+		"Dimensões: 1x1 → 2x2\n" +
+		"\n" +
+		"Property changes on: img.png\n" +
+		"___________________________________________________________________\n" +
+		"Added: svn:needs-lock\n" +
+		"## -0,0 +1 ##\n" +
+		"+*\n"
+	files, err := ParseString(output)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Parse() = %d seções, want 1", len(files))
+	}
+	want := []string{"Tamanho: 10 → 12 bytes", "Dimensões: 1x1 → 2x2"}
+	if img := files[0]; !reflect.DeepEqual(img.Details, want) || len(img.Props) != 1 {
+		t.Errorf("img.png = %+v, want Details %q e uma propriedade", img, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{"hunk incompleto", "Index: a\n--- a\t(revision 1)\n+++ a\t(revision 2)\n@@ -1,2 +1,2 @@\n a\n"},
+		{"cabeçalho inválido", "Index: a\n@@ -x +1 @@\n+a\n"},
+		{"linha inválida", "Index: a\n@@ -1 +1 @@\n*a\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseString(tt.output); err == nil {
+				t.Error("Parse() esperava erro")
+			}
+		})
+	}
+}
+
+// TestReader_Next confere que cada seção é entregue assim que a seguinte
+// começa, sem esperar o fim da saída
+func TestReader_Next(t *testing.T) {
+	pr, pw := io.Pipe()
+	go func() {
+		io.WriteString(pw, "Index: a.txt\n===\n--- a.txt\t(revision 1)\n+++ a.txt\t(revision 2)\n@@ -1 +1 @@\n-a\n+b\nIndex: b.txt\n")
+	}()
+
+	reader := NewReader(pr)
+	f, err := reader.Next()
+	if err != nil || f.Path != "a.txt" || len(f.Hunks) != 1 {
+		t.Fatalf("Next() = %+v, %v", f, err)
+	}
+
+	go func() {
+		io.WriteString(pw, "===\n--- b.txt\t(nonexistent)\n+++ b.txt\t(revision 2)\n@@ -0,0 +1 @@\n+b\n")
+		pw.Close()
+	}()
+	if f, err := reader.Next(); err != nil || f.Path != "b.txt" || !f.Added() {
+		t.Fatalf("Next() = %+v, %v", f, err)
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Next() error = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,129 @@
+package patch
+
+import "svndiff/internal/diff"
+
+// Cell é uma linha numerada de um dos lados da visão lado a lado
+type Cell struct {
+	Number int
+	// Kind é ' ' para contexto, '-' no lado A e '+' no lado B
+	Kind byte
+	Text string
+	// Spans destaca as palavras alteradas em relação à linha pareada; nil
+	// quando a linha não tem par ou a mudança é a linha inteira
+	Spans []diff.Span
+}
+
+// Row é uma linha da visão lado a lado; um lado nil não tem linha
+// correspondente
+type Row struct {
+	Left, Right *Cell
+}
+
+// Numbered numera as linhas do hunk na ordem do diff unificado: o contexto
+// tem os dois lados, as linhas removidas só Left e as adicionadas só Right
+func (h Hunk) Numbered() []Row {
+	rows := h.cells()
+	pair(rows)
+	return rows
+}
+
+// Rows organiza o hunk em duas colunas: o contexto aparece nos dois lados
+// e cada bloco de linhas removidas é pareado, em ordem, com as linhas
+// adicionadas logo em seguida
+func (h Hunk) Rows() []Row {
+	return pair(h.cells())
+}
+
+// cells numera as linhas do hunk
+func (h Hunk) cells() []Row {
+	rows := make([]Row, 0, len(h.Lines))
+	oldNo, newNo := h.OldStart, h.NewStart
+	for _, line := range h.Lines {
+		switch line.Kind {
+		case '-':
+			rows = append(rows, Row{Left: &Cell{Number: oldNo, Kind: '-', Text: line.Text}})
+			oldNo++
+		case '+':
+			rows = append(rows, Row{Right: &Cell{Number: newNo, Kind: '+', Text: line.Text}})
+			newNo++
+		default:
+			rows = append(rows, Row{
+				Left:  &Cell{Number: oldNo, Kind: ' ', Text: line.Text},
+				Right: &Cell{Number: newNo, Kind: ' ', Text: line.Text},
+			})
+			oldNo++
+			newNo++
+		}
+	}
+	return rows
+}
+
+// pair junta as linhas removidas e adicionadas de cada bloco nas linhas lado
+// a lado e marca nas células pareadas as palavras alteradas
+func pair(numbered []Row) []Row {
+	var rows []Row
+	var removed, added []*Cell
+
+	flush := func() {
+		for i := 0; i < len(removed) || i < len(added); i++ {
+			var row Row
+			if i < len(removed) {
+				row.Left = removed[i]
+			}
+			if i < len(added) {
+				row.Right = added[i]
+			}
+			if row.Left != nil && row.Right != nil {
+				row.Left.Spans, row.Right.Spans = diff.Words(row.Left.Text, row.Right.Text)
+			}
+			rows = append(rows, row)
+		}
+		removed, added = nil, nil
+	}
+
+	for _, row := range numbered {
+		switch {
+		case row.Left != nil && row.Right != nil:
+			flush()
+			rows = append(rows, row)
+		case row.Left != nil:
+			if len(added) > 0 {
+				flush()
+			}
+			removed = append(removed, row.Left)
+		default:
+			added = append(added, row.Right)
+		}
+	}
+	flush()
+	return rows
+}
+
+// Status resume a mudança do arquivo: Added, Deleted, Modified ou
+// PropertyChanged, quando só as propriedades mudaram
+func (f *File) Status() string {
+	switch {
+	case f.Added():
+		return "Added"
+	case f.Deleted():
+		return "Deleted"
+	case len(f.Hunks) == 0 && !f.Binary && len(f.Props) > 0:
+		return "PropertyChanged"
+	}
+	return "Modified"
+}
+
+// Stats conta as linhas adicionadas e removidas nos hunks
+func (f *File) Stats() (added, deleted int) {
+	for _, hunk := range f.Hunks {
+		for _, line := range hunk.Lines {
+			switch line.Kind {
+			case '+':
+				added++
+			case '-':
+				deleted++
+			}
+		}
+	}
+	return added, deleted
+}
@@ -0,0 +1,34 @@
+package patch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHunk_Rows(t *testing.T) {
+	files, err := ParseString("Index: a\n===\n--- a\t(revision 1)\n+++ a\t(revision 2)\n" +
+		"@@ -10,5 +10,5 @@\n um\n-dois\n-três\n+2\n quatro\n-cinco\n+5\n+6\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	hunk := files[0].Hunks[0]
+
+	cell := func(n int, kind byte, text string) *Cell { return &Cell{Number: n, Kind: kind, Text: text} }
+	want := []Row{
+		{cell(10, ' ', "um"), cell(10, ' ', "um")},
+		{cell(11, '-', "dois"), cell(11, '+', "2")},
+		{cell(12, '-', "três"), nil},
+		{cell(13, ' ', "quatro"), cell(12, ' ', "quatro")},
+		{cell(14, '-', "cinco"), cell(13, '+', "5")},
+		{nil, cell(14, '+', "6")},
+	}
+	if got := hunk.Rows(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Rows() = %+v, want %+v", got, want)
+	}
+	if got := len(hunk.Numbered()); got != len(hunk.Lines) {
+		t.Errorf("Numbered() = %d linhas, want %d", got, len(hunk.Lines))
+	}
+	if added, deleted := files[0].Stats(); added != 3 || deleted != 3 {
+		t.Errorf("Stats() = +%d -%d, want +3 -3", added, deleted)
+	}
+}
@@ -0,0 +1,139 @@
+package patch
+
+import (
+	"strings"
+	"unicode"
+
+	"svndiff/internal/diff"
+)
+
+// hunkContext é o número de linhas de contexto dos hunks do svn diff
+const hunkContext = 3
+
+// Whitespace escolhe as diferenças de espaço em branco que IgnoreWhitespace
+// descarta, como as opções -b, -w e --ignore-eol-style do svn diff e -B do
+// GNU diff
+type Whitespace struct {
+	// SpaceChange ignora mudanças na quantidade de espaços e os espaços no
+	// fim da linha
+	SpaceChange bool
+	// AllSpace ignora todos os espaços
+	AllSpace bool
+	// EOLStyle ignora CRLF contra LF e a falta de quebra no fim do arquivo
+	EOLStyle bool
+	// BlankLines ignora as linhas em branco adicionadas ou removidas
+	BlankLines bool
+}
+
+// Enabled indica se alguma diferença é ignorada
+func (ws Whitespace) Enabled() bool {
+	return ws.SpaceChange || ws.AllSpace || ws.EOLStyle || ws.BlankLines
+}
+
+// IgnoreWhitespace refaz os hunks de conteúdo de f sem as diferenças
+// ignoradas por ws. Hunks só com essas diferenças desaparecem; nos demais,
+// as linhas que diferem só nelas viram contexto, com o texto do lado A.
+func (f *File) IgnoreWhitespace(ws Whitespace) {
+	if !ws.Enabled() {
+		return
+	}
+	var hunks []Hunk
+	for _, hunk := range f.Hunks {
+		hunks = append(hunks, ws.hunks(hunk)...)
+	}
+	f.Hunks = hunks
+}
+
+// hunks recalcula as diferenças entre os lados de hunk comparando as linhas
+// normalizadas
+func (ws Whitespace) hunks(hunk Hunk) []Hunk {
+	var old, new []string
+	for _, line := range hunk.Lines {
+		text := line.Text
+		if !line.NoNewline {
+			text += "\n"
+		}
+		if line.Kind != '+' {
+			old = append(old, text)
+		}
+		if line.Kind != '-' {
+			new = append(new, text)
+		}
+	}
+
+	// Os inícios dos hunks recalculados são relativos ao hunk original
+	oldBase, newBase := hunk.OldStart, hunk.NewStart
+	if hunk.OldLines > 0 {
+		oldBase--
+	}
+	if hunk.NewLines > 0 {
+		newBase--
+	}
+
+	var hunks []Hunk
+	ops := diff.Diff(ws.keys(old), ws.keys(new))
+	for _, h := range diff.HunksFromOps(ops, old, new, hunkContext) {
+		if ws.BlankLines && onlyBlankChanges(h) {
+			continue
+		}
+		result := Hunk{
+			OldStart: oldBase + h.AStart, OldLines: h.ALines,
+			NewStart: newBase + h.BStart, NewLines: h.BLines,
+		}
+		for _, line := range h.Lines {
+			kind := byte(' ')
+			switch line.Kind {
+			case diff.Delete:
+				kind = '-'
+			case diff.Insert:
+				kind = '+'
+			}
+			text, newline := strings.CutSuffix(line.Text, "\n")
+			result.Lines = append(result.Lines, Line{Kind: kind, Text: text, NoNewline: !newline})
+		}
+		hunks = append(hunks, result)
+	}
+	return hunks
+}
+
+// keys normaliza as linhas para a comparação
+func (ws Whitespace) keys(lines []string) []string {
+	keys := make([]string, len(lines))
+	for i, line := range lines {
+		text, newline := strings.CutSuffix(line, "\n")
+		if ws.EOLStyle {
+			text, newline = strings.TrimSuffix(text, "\r"), true
+		}
+		switch {
+		case ws.AllSpace:
+			text = strings.Join(strings.Fields(text), "")
+		case ws.SpaceChange:
+			text = strings.Join(strings.Fields(text), " ")
+			if len(text) > 0 && startsWithSpace(line) {
+				text = " " + text
+			}
+		}
+		if newline {
+			text += "\n"
+		}
+		keys[i] = text
+	}
+	return keys
+}
+
+// startsWithSpace indica se a linha é indentada; -b preserva a existência
+// da indentação, só não a quantidade
+func startsWithSpace(line string) bool {
+	return strings.IndexFunc(line, func(r rune) bool { return !unicode.IsSpace(r) }) > 0
+}
+
+// onlyBlankChanges indica que as linhas removidas e adicionadas do hunk
+// estão todas em branco
+func onlyBlankChanges(h diff.Hunk) bool {
+	for _, line := range h.Lines {
+		if line.Kind != diff.Equal && strings.TrimSpace(line.Text) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package patch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFile_IgnoreWhitespace(t *testing.T) {
+	tests := []struct {
+		name string
+		ws   Whitespace
+		diff string
+		// want são as linhas dos hunks restantes; nil quando não resta hunk
+		want [][]Line
+	}{
+		{
+			name: "fim de linha",
+			ws:   Whitespace{EOLStyle: true},
+			diff: "@@ -1,2 +1,2 @@\n-um\r\n-dois\r\n+um\n+dois\n\\ No newline at end of file\n",
+		},
+		{
+			name: "fim de linha não ignora espaços",
+			ws:   Whitespace{EOLStyle: true},
+			diff: "@@ -1,2 +1,2 @@\n-um\r\n-dois\r\n+um\n+dois \n",
+			want: [][]Line{{{' ', "um\r", false}, {'-', "dois\r", false}, {'+', "dois ", false}}},
+		},
+		{
+			name: "reindentação",
+			ws:   Whitespace{SpaceChange: true},
+			diff: "@@ -1,3 +1,3 @@\n if x {\n-\treturn\n+    return  \n }\n",
+		},
+		{
+			name: "quantidade de espaços não ignora a indentação nova",
+			ws:   Whitespace{SpaceChange: true},
+			diff: "@@ -1 +1 @@\n-return\n+  return\n",
+			want: [][]Line{{{'-', "return", false}, {'+', "  return", false}}},
+		},
+		{
+			name: "todos os espaços",
+			ws:   Whitespace{AllSpace: true},
+			diff: "@@ -1 +1 @@\n-a = b+c\n+a=b + c\n",
+		},
+		{
+			name: "linhas em branco",
+			ws:   Whitespace{BlankLines: true},
+			diff: "@@ -1,2 +1,4 @@\n um\n+\n+  \n dois\n",
+		},
+		{
+			name: "mudança real entre espaços ignorados",
+			ws:   Whitespace{SpaceChange: true},
+			diff: "@@ -1,3 +1,3 @@\n-  a\n-  b\n-  c\n+    a\n+    B\n+    c\n",
+			want: [][]Line{{{' ', "  a", false}, {'-', "  b", false}, {'+', "    B", false}, {' ', "  c", false}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := ParseString("Index: a.txt\n===================================================================\n--- a.txt\t(revision 1)\n+++ a.txt\t(revision 2)\n" + tt.diff)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			f := files[0]
+			f.IgnoreWhitespace(tt.ws)
+			var got [][]Line
+			for _, hunk := range f.Hunks {
+				got = append(got, hunk.Lines)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("IgnoreWhitespace() hunks = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFile_IgnoreWhitespace_Ranges(t *testing.T) {
+	diff := "Index: a.txt\n" +
+		"===================================================================\n" +
+		"--- a.txt\t(revision 1)\n" +
+		"+++ a.txt\t(revision 2)\n" +
+		"@@ -10,9 +10,9 @@\n" +
+		"-a\r\n-b\r\n-c\r\n-d\r\n-e\r\n-f\r\n-g\r\n-h\r\n-i\r\n" +
+		"+a\n+b\n+c\n+d\n+E\n+f\n+g\n+h\n+i\n"
+	files, err := ParseString(diff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	f := files[0]
+	f.IgnoreWhitespace(Whitespace{EOLStyle: true})
+	if len(f.Hunks) != 1 {
+		t.Fatalf("IgnoreWhitespace() = %d hunks, want 1", len(f.Hunks))
+	}
+	h := f.Hunks[0]
+	if h.OldStart != 11 || h.OldLines != 7 || h.NewStart != 11 || h.NewLines != 7 {
+		t.Errorf("hunk = -%d,%d +%d,%d, want -11,7 +11,7", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+	}
+}
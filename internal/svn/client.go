@@ -3,8 +3,11 @@
 package svn
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"svndiff/pkg/config"
@@ -28,8 +31,59 @@ type DiffResult struct {
 	FileList []string
 }
 
-// GetDiff executa um svn diff entre duas branches e suas respectivas revisões
+// GetDiff executa um svn diff entre duas branches e suas respectivas
+// revisões e retorna a saída completa. Para diffs grandes, StreamDiff
+// entrega a saída sem mantê-la em memória.
 func (c *Client) GetDiff(branchA, branchB *config.BranchConfig, summarize bool) (*DiffResult, error) {
+	// Executa o comando
+	r, err := c.StreamDiff(branchA, branchB, summarize)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	output, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiffResult{
+		Output: string(output),
+	}
+
+	// Se for um resumo, processa a lista de arquivos
+	if summarize {
+		entries, err := ReadSummary(bytes.NewReader(output))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			result.FileList = append(result.FileList, entry.Path)
+		}
+	}
+
+	return result, nil
+}
+
+// StreamDiff executa o svn diff e entrega a saída à medida que o comando a
+// produz. Uma falha do comando é retornada pela leitura no fim da saída.
+func (c *Client) StreamDiff(branchA, branchB *config.BranchConfig, summarize bool) (io.ReadCloser, error) {
+	cmd := exec.Command("svn", c.diffArgs(branchA, branchB, summarize)...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao executar comando svn: %w", err)
+	}
+	r := &commandReader{cmd: cmd, stdout: stdout}
+	cmd.Stderr = &r.stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("erro ao executar comando svn: %w", err)
+	}
+	return r, nil
+}
+
+// diffArgs monta os argumentos do svn diff entre as últimas revisões das
+// branches
+func (c *Client) diffArgs(branchA, branchB *config.BranchConfig, summarize bool) []string {
 	// Constrói as URLs com as revisões
 	urlA := fmt.Sprintf("%s@%s", branchA.URL, branchA.GetLatestRevision())
 	urlB := fmt.Sprintf("%s@%s", branchB.URL, branchB.GetLatestRevision())
@@ -38,42 +92,74 @@ func (c *Client) GetDiff(branchA, branchB *config.BranchConfig, summarize bool)
 	args := []string{"diff"}
 
 	// Adiciona credenciais se fornecidas
-	if c.auth != nil && c.auth.User != "" {
-		args = append(args, "--username", c.auth.User)
-		if c.auth.Password != "" {
-			args = append(args, "--password", c.auth.Password)
-		}
-	}
+	args = append(args, c.authArgs()...)
 
-	// Adiciona flag de resumo se solicitado
+	// Adiciona flag de resumo se solicitado, em XML para preservar o tipo
+	// do nó, o status das propriedades e caminhos com espaços
 	if summarize {
-		args = append(args, "--summarize")
+		args = append(args, "--summarize", "--xml")
 	}
 
 	// Adiciona as URLs para comparação
-	args = append(args, urlA, urlB)
+	return append(args, urlA, urlB)
+}
+
+// GetRevisionDiff executa svn diff -c com as mudanças de rev na branch,
+// inclusive na revisão que a cria
+func (c *Client) GetRevisionDiff(branch *config.BranchConfig, rev int64) (*DiffResult, error) {
+	args := []string{"diff"}
+	args = append(args, c.authArgs()...)
+	args = append(args, "-c", strconv.FormatInt(rev, 10), fmt.Sprintf("%s@%d", branch.URL, rev))
 
-	// Executa o comando
 	cmd := exec.Command("svn", args...)
 	output, err := cmd.Output()
 	if err != nil {
 		if exitError, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("comando svn falhou: %s\nSaída de erro: %s",
+			return nil, fmt.Errorf("comando svn diff falhou: %s\nSaída de erro: %s",
 				err.Error(), string(exitError.Stderr))
 		}
-		return nil, fmt.Errorf("erro ao executar comando svn: %w", err)
+		return nil, fmt.Errorf("erro ao executar comando svn diff: %w", err)
 	}
+	return &DiffResult{Output: string(output)}, nil
+}
 
-	result := &DiffResult{
-		Output: string(output),
+// commandReader lê a saída de um comando em execução e, no fim dela,
+// aguarda o término e reporta a falha com a saída de erro
+type commandReader struct {
+	cmd     *exec.Cmd
+	stdout  io.ReadCloser
+	stderr  bytes.Buffer
+	done    bool
+	waitErr error
+}
+
+func (r *commandReader) Read(p []byte) (int, error) {
+	n, err := r.stdout.Read(p)
+	if err == io.EOF {
+		if werr := r.wait(); werr != nil {
+			return n, werr
+		}
 	}
+	return n, err
+}
 
-	// Se for um resumo, processa a lista de arquivos
-	if summarize {
-		result.FileList = c.parseFileList(string(output))
+// Close encerra o comando, se ainda estiver em execução
+func (r *commandReader) Close() error {
+	if !r.done {
+		_ = r.cmd.Process.Kill()
+		_ = r.wait()
 	}
+	return nil
+}
 
-	return result, nil
+func (r *commandReader) wait() error {
+	if !r.done {
+		r.done = true
+		if err := r.cmd.Wait(); err != nil {
+			r.waitErr = fmt.Errorf("comando svn falhou: %s\nSaída de erro: %s", err.Error(), r.stderr.String())
+		}
+	}
+	return r.waitErr
 }
 
 // GetLog obtém o log de uma branch para revisões específicas
@@ -81,12 +167,7 @@ func (c *Client) GetLog(branch *config.BranchConfig) (string, error) {
 	args := []string{"log"}
 
 	// Adiciona credenciais se fornecidas
-	if c.auth != nil && c.auth.User != "" {
-		args = append(args, "--username", c.auth.User)
-		if c.auth.Password != "" {
-			args = append(args, "--password", c.auth.Password)
-		}
-	}
+	args = append(args, c.authArgs()...)
 
 	// Adiciona o range de revisões
 	revisionRange := branch.GetRevisionRange()
@@ -111,17 +192,37 @@ func (c *Client) GetLog(branch *config.BranchConfig) (string, error) {
 	return string(output), nil
 }
 
+// ReadFile obtém o conteúdo de um arquivo da branch com svn cat
+func (c *Client) ReadFile(branch *config.BranchConfig, path string) ([]byte, bool, error) {
+	args := []string{"cat"}
+	args = append(args, c.authArgs()...)
+	args = append(args, fmt.Sprintf("%s/%s@%s", strings.TrimSuffix(branch.URL, "/"), path, branch.GetLatestRevision()))
+
+	cmd := exec.Command("svn", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			// E160013/W160013: caminho inexistente na revisão
+			if strings.Contains(string(exitError.Stderr), "160013") {
+				return nil, false, nil
+			}
+			return nil, false, fmt.Errorf("comando svn cat falhou: %s\nSaída de erro: %s",
+				err.Error(), string(exitError.Stderr))
+		}
+		return nil, false, fmt.Errorf("erro ao executar comando svn cat: %w", err)
+	}
+	if output == nil {
+		output = []byte{}
+	}
+	return output, true, nil
+}
+
 // CheckConnection verifica se é possível conectar ao repositório SVN
 func (c *Client) CheckConnection(url string) error {
 	args := []string{"info"}
 
 	// Adiciona credenciais se fornecidas
-	if c.auth != nil && c.auth.User != "" {
-		args = append(args, "--username", c.auth.User)
-		if c.auth.Password != "" {
-			args = append(args, "--password", c.auth.Password)
-		}
-	}
+	args = append(args, c.authArgs()...)
 
 	args = append(args, url)
 
@@ -138,26 +239,15 @@ func (c *Client) CheckConnection(url string) error {
 	return nil
 }
 
-// parseFileList processa a saída do svn diff --summarize e extrai a lista de arquivos
-func (c *Client) parseFileList(output string) []string {
-	var files []string
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// O formato do svn diff --summarize é: "STATUS   PATH"
-		// onde STATUS pode ser M (Modified), A (Added), D (Deleted), etc.
-		parts := strings.Fields(line)
-		if len(parts) >= 2 {
-			// O caminho do arquivo é tudo após o primeiro espaço
-			filePath := strings.Join(parts[1:], " ")
-			files = append(files, filePath)
-		}
+// authArgs retorna os argumentos de autenticação do svn, se configurados
+func (c *Client) authArgs() []string {
+	if c.auth == nil || c.auth.User == "" {
+		return nil
 	}
 
-	return files
+	args := []string{"--username", c.auth.User}
+	if c.auth.Password != "" {
+		args = append(args, "--password", c.auth.Password)
+	}
+	return args
 }
@@ -0,0 +1,50 @@
+package svn
+
+import (
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCommandReader(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh não encontrado no PATH")
+	}
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr string
+	}{
+		{name: "sucesso", script: "printf 'M       a.txt\\n'", want: "M       a.txt\n"},
+		{name: "falha", script: "printf 'A       b.txt\\n'; echo 'E170000: URL inexistente' >&2; exit 1", want: "A       b.txt\n", wantErr: "E170000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("sh", "-c", tt.script)
+			stdout, err := cmd.StdoutPipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := &commandReader{cmd: cmd, stdout: stdout}
+			cmd.Stderr = &r.stderr
+			if err := cmd.Start(); err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			got, err := io.ReadAll(r)
+			if string(got) != tt.want {
+				t.Errorf("ReadAll() = %q, want %q", got, tt.want)
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("ReadAll() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("ReadAll() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
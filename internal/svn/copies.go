@@ -0,0 +1,261 @@
+package svn
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"svndiff/pkg/config"
+)
+
+// CopyTracer é implementado pelos backends que leem do log da branch as
+// cópias registradas (svn copy e svn move). O resultado associa cada caminho
+// copiado à sua origem, ambos relativos à branch; só entram as origens dentro
+// da própria branch ou de uma das URLs em bases.
+type CopyTracer interface {
+	CopySources(branch *config.BranchConfig, bases ...string) (map[string]string, error)
+}
+
+// xmlInfo mapeia a saída de svn info --xml
+type xmlInfo struct {
+	Entries []struct {
+		URL  string `xml:"url"`
+		Root string `xml:"repository>root"`
+	} `xml:"entry"`
+}
+
+// xmlCopyLog mapeia os caminhos alterados da saída de svn log --xml --verbose
+type xmlCopyLog struct {
+	Entries []struct {
+		Paths []struct {
+			Action   string `xml:"action,attr"`
+			CopyFrom string `xml:"copyfrom-path,attr"`
+			Path     string `xml:",chardata"`
+		} `xml:"paths>path"`
+	} `xml:"logentry"`
+}
+
+// CopySources lê as cópias do log da branch: do intervalo de revisões
+// configurado ou, com uma só revisão, de todo o histórico desde a criação
+// da branch
+func (c *Client) CopySources(branch *config.BranchConfig, bases ...string) (map[string]string, error) {
+	target := fmt.Sprintf("%s@%s", branch.URL, branch.GetLatestRevision())
+	root, err := c.repositoryRoot(target)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"log", "--xml", "--verbose"}
+
+	// Adiciona credenciais se fornecidas
+	args = append(args, c.authArgs()...)
+
+	if len(branch.Revisions) > 1 {
+		args = append(args, "-r", branch.GetRevisionRange())
+	} else {
+		args = append(args, "--stop-on-copy")
+	}
+	args = append(args, target)
+
+	cmd := exec.Command("svn", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("comando svn log falhou: %s\nSaída de erro: %s",
+				err.Error(), string(exitError.Stderr))
+		}
+		return nil, fmt.Errorf("erro ao executar comando svn log: %w", err)
+	}
+
+	branchPath, ok := repositoryPath(branch.URL, root)
+	if !ok {
+		return nil, fmt.Errorf("a URL %s não pertence ao repositório %s", branch.URL, root)
+	}
+	sourceRoots := []string{branchPath}
+	for _, base := range bases {
+		if path, ok := repositoryPath(base, root); ok {
+			sourceRoots = append(sourceRoots, path)
+		}
+	}
+	return parseCopyLogXML(output, branchPath, sourceRoots)
+}
+
+// repositoryRoot obtém a URL raiz do repositório de target
+func (c *Client) repositoryRoot(target string) (string, error) {
+	args := []string{"info", "--xml"}
+	args = append(args, c.authArgs()...)
+	args = append(args, target)
+
+	cmd := exec.Command("svn", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("comando svn info falhou: %s\nSaída de erro: %s",
+				err.Error(), string(exitError.Stderr))
+		}
+		return "", fmt.Errorf("erro ao executar comando svn info: %w", err)
+	}
+
+	var info xmlInfo
+	if err := xml.Unmarshal(output, &info); err != nil {
+		return "", fmt.Errorf("erro ao interpretar info XML: %w", err)
+	}
+	if len(info.Entries) == 0 || info.Entries[0].Root == "" {
+		return "", fmt.Errorf("svn info não informou a raiz do repositório de %s", target)
+	}
+	return info.Entries[0].Root, nil
+}
+
+// repositoryPath converte a URL no caminho dentro do repositório com raiz
+// root, no formato dos caminhos do log ("/branches/x")
+func repositoryPath(rawURL, root string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSuffix(rawURL, "/"), strings.TrimSuffix(root, "/"))
+	if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+		return "", false
+	}
+	if unescaped, err := url.PathUnescape(rest); err == nil {
+		rest = unescaped
+	}
+	if rest == "" {
+		rest = "/"
+	}
+	return rest, true
+}
+
+// parseCopyLogXML extrai as cópias da saída de svn log --xml --verbose. Os
+// caminhos copiados ficam relativos a branchPath e as origens, à primeira
+// raiz de sourceRoots que as contém. Como o log vem da revisão mais recente
+// para a mais antiga, vale a cópia mais recente de cada caminho.
+func parseCopyLogXML(output []byte, branchPath string, sourceRoots []string) (map[string]string, error) {
+	var log xmlCopyLog
+	if err := xml.Unmarshal(output, &log); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar log XML: %w", err)
+	}
+
+	sources := make(map[string]string)
+	for _, entry := range log.Entries {
+		changes := make([]ChangedPath, 0, len(entry.Paths))
+		for _, p := range entry.Paths {
+			if p.Action != "" {
+				changes = append(changes, ChangedPath{Path: p.Path, Action: p.Action[0], CopyPath: p.CopyFrom})
+			}
+		}
+		addCopySources(sources, changes, branchPath, sourceRoots)
+	}
+	return sources, nil
+}
+
+// addCopySources acrescenta a sources as cópias entre os caminhos alterados
+// de uma revisão. As revisões devem vir da mais recente para a mais antiga,
+// para que valha a cópia mais recente de cada caminho.
+func addCopySources(sources map[string]string, changes []ChangedPath, branchPath string, sourceRoots []string) {
+	for _, c := range changes {
+		if c.CopyPath == "" || (c.Action != 'A' && c.Action != 'R') {
+			continue
+		}
+		path, ok := relativeTo(c.Path, branchPath)
+		if !ok || path == "" {
+			continue
+		}
+		if _, seen := sources[path]; seen {
+			continue
+		}
+		for _, root := range sourceRoots {
+			if from, ok := relativeTo(c.CopyPath, root); ok && from != "" {
+				sources[path] = from
+				break
+			}
+		}
+	}
+}
+
+// CopySources lê as cópias dos caminhos alterados nas revisões da branch,
+// como Client.CopySources. Repositórios que não listam os caminhos
+// alterados retornam ErrUnsupported.
+func (b *RepositoryBackend) CopySources(branch *config.BranchConfig, bases ...string) (map[string]string, error) {
+	repo, err := b.open(branch.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Close()
+	lister, ok := repo.(ChangeLister)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	// Com uma só revisão, o histórico vai até a criação da branch
+	lo, hi, stopOnCopy := int64(1), int64(0), true
+	if len(branch.Revisions) > 1 {
+		start, end := splitRevisionRange(branch.GetRevisionRange())
+		startRev, err := resolveRevision(repo, start, 1)
+		if err != nil {
+			return nil, err
+		}
+		endRev, err := resolveRevision(repo, end, -1)
+		if err != nil {
+			return nil, err
+		}
+		lo, hi, stopOnCopy = min(startRev, endRev), max(startRev, endRev), false
+	} else if hi, err = resolveRevision(repo, branch.GetLatestRevision(), 0); err != nil {
+		return nil, err
+	}
+
+	branchPath := lister.BasePath()
+	root := rootURL(branch.URL, branchPath)
+	sourceRoots := []string{branchPath}
+	for _, base := range bases {
+		if path, ok := repositoryPath(base, root); ok {
+			sourceRoots = append(sourceRoots, path)
+		}
+	}
+
+	sources := make(map[string]string)
+	for rev := hi; rev >= max(lo, 1); rev-- {
+		changes, err := lister.ChangedPaths(rev)
+		if err != nil {
+			return nil, err
+		}
+		addCopySources(sources, changes, branchPath, sourceRoots)
+		if stopOnCopy && createsPath(changes, branchPath) {
+			break
+		}
+	}
+	return sources, nil
+}
+
+// rootURL obtém a URL raiz do repositório removendo da URL da branch os
+// segmentos do seu caminho no repositório
+func rootURL(branchURL, branchPath string) string {
+	root := strings.TrimSuffix(branchURL, "/")
+	if branchPath = strings.Trim(branchPath, "/"); branchPath == "" {
+		return root
+	}
+	for range strings.Count(branchPath, "/") + 1 {
+		root = root[:max(strings.LastIndex(root, "/"), 0)]
+	}
+	return root
+}
+
+// createsPath indica se a revisão adiciona path ou um diretório acima dele
+func createsPath(changes []ChangedPath, path string) bool {
+	for _, c := range changes {
+		if (c.Action == 'A' || c.Action == 'R') && isAncestor(c.Path, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// relativeTo torna path relativo ao diretório dir do repositório
+func relativeTo(path, dir string) (string, bool) {
+	if dir == "/" {
+		return strings.TrimPrefix(path, "/"), true
+	}
+	if path == dir {
+		return "", true
+	}
+	rest, ok := strings.CutPrefix(path, dir+"/")
+	return rest, ok
+}
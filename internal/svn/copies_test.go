@@ -0,0 +1,67 @@
+package svn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCopyLogXML(t *testing.T) {
+	output := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<log>
+<logentry revision="7">
+<author>alice</author>
+<date>2024-03-05T14:22:10.123456Z</date>
+<paths>
+<path action="A" copyfrom-path="/branches/x/b.txt" copyfrom-rev="6" kind="file">/branches/x/c.txt</path>
+<path action="D" kind="file">/branches/x/b.txt</path>
+</paths>
+<msg>renomeia b</msg>
+</logentry>
+<logentry revision="6">
+<author>alice</author>
+<date>2024-03-04T14:22:10.123456Z</date>
+<paths>
+<path action="A" copyfrom-path="/trunk/lib" copyfrom-rev="5" kind="dir">/branches/x/vendor/lib</path>
+<path action="A" copyfrom-path="/branches/x/a.txt" copyfrom-rev="5" kind="file">/branches/x/b.txt</path>
+<path action="A" copyfrom-path="/tags/1.0/z.txt" copyfrom-rev="5" kind="file">/branches/x/z.txt</path>
+<path action="M" kind="file">/branches/x/a.txt</path>
+</paths>
+<msg>copia</msg>
+</logentry>
+<logentry revision="5">
+<author>alice</author>
+<date>2024-03-03T14:22:10.123456Z</date>
+<paths>
+<path action="A" copyfrom-path="/trunk" copyfrom-rev="4" kind="dir">/branches/x</path>
+</paths>
+<msg>cria a branch</msg>
+</logentry>
+</log>`)
+
+	sources, err := parseCopyLogXML(output, "/branches/x", []string{"/branches/x", "/trunk"})
+	if err != nil {
+		t.Fatalf("parseCopyLogXML() error = %v", err)
+	}
+	want := map[string]string{"c.txt": "b.txt", "vendor/lib": "lib", "b.txt": "a.txt"}
+	if !reflect.DeepEqual(sources, want) {
+		t.Errorf("parseCopyLogXML() = %v, want %v", sources, want)
+	}
+}
+
+func TestRepositoryPath(t *testing.T) {
+	tests := []struct {
+		url, root, want string
+		ok              bool
+	}{
+		{"https://svn.example.com/repo/branches/x/", "https://svn.example.com/repo", "/branches/x", true},
+		{"https://svn.example.com/repo/branches/my%20x", "https://svn.example.com/repo", "/branches/my x", true},
+		{"https://svn.example.com/repo", "https://svn.example.com/repo", "/", true},
+		{"https://svn.example.com/repo2/trunk", "https://svn.example.com/repo", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := repositoryPath(tt.url, tt.root)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("repositoryPath(%q) = %q, %v, want %q, %v", tt.url, got, ok, tt.want, tt.ok)
+		}
+	}
+}
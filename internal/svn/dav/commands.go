@@ -0,0 +1,234 @@
+package dav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"svndiff/internal/svn"
+)
+
+// LatestRevision retorna a revisão mais recente do repositório
+func (s *Session) LatestRevision() (int64, error) {
+	if err := s.options(); err != nil {
+		return 0, err
+	}
+	return s.youngest, nil
+}
+
+// DatedRevision retorna a revisão vigente na data informada
+func (s *Session) DatedRevision(date time.Time) (int64, error) {
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>`+
+		`<S:dated-rev-report xmlns:S="svn:" xmlns:D="DAV:"><D:creationdate>%s</D:creationdate></S:dated-rev-report>`,
+		date.UTC().Format("2006-01-02T15:04:05.000000Z"))
+
+	var report struct {
+		Revision string `xml:"DAV: version-name"`
+	}
+	if err := s.report(s.me, body, &report); err != nil {
+		return 0, err
+	}
+
+	rev, err := strconv.ParseInt(strings.TrimSpace(report.Revision), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("revisão inválida em dated-rev-report: %q", report.Revision)
+	}
+	return rev, nil
+}
+
+// CheckPath retorna o tipo do nó no caminho e revisão informados
+func (s *Session) CheckPath(path string, rev int64) (svn.NodeKind, error) {
+	resources, err := s.propfind(s.revPath(path, s.revision(rev)), "0")
+	if isNotFound(err) {
+		return svn.NodeNone, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(resources) == 0 {
+		return svn.NodeNone, nil
+	}
+	return resources[0].kind, nil
+}
+
+// GetDir lista um diretório com suas propriedades
+func (s *Session) GetDir(path string, rev int64) (*svn.Dir, error) {
+	resources, err := s.propfind(s.revPath(path, s.revision(rev)), "1")
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 || resources[0].kind != svn.NodeDir {
+		return nil, fmt.Errorf("'%s' não é um diretório", path)
+	}
+
+	dir := &svn.Dir{Props: resources[0].props}
+	for _, res := range resources[1:] {
+		dir.Entries = append(dir.Entries, svn.DirEntry{
+			Name:       hrefName(res.href),
+			Kind:       res.kind,
+			Size:       res.size,
+			CreatedRev: res.rev,
+		})
+	}
+	return dir, nil
+}
+
+// GetFile obtém o checksum, as propriedades e, opcionalmente, o conteúdo de
+// um arquivo
+func (s *Session) GetFile(path string, rev int64, withContent bool) (*svn.File, error) {
+	resPath := s.revPath(path, s.revision(rev))
+	resources, err := s.propfind(resPath, "0")
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 || resources[0].kind != svn.NodeFile {
+		return nil, fmt.Errorf("'%s' não é um arquivo", path)
+	}
+
+	file := &svn.File{Checksum: resources[0].checksum, Props: resources[0].props}
+	if !withContent {
+		return file, nil
+	}
+
+	resp, err := s.do("GET", resPath, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if file.Content, err = io.ReadAll(resp.Body); err != nil {
+		return nil, fmt.Errorf("erro ao ler conteúdo de '%s': %w", path, err)
+	}
+	return file, nil
+}
+
+// logItem é uma entrada da resposta do log-report
+type logItem struct {
+	Revision string      `xml:"DAV: version-name"`
+	Author   encodedText `xml:"DAV: creator-displayname"`
+	Date     encodedText `xml:"svn: date"`
+	Comment  encodedText `xml:"DAV: comment"`
+}
+
+// encodedText é um texto que pode chegar codificado em base64
+type encodedText struct {
+	Encoding string `xml:"encoding,attr"`
+	Text     string `xml:",chardata"`
+}
+
+func (t encodedText) value() (string, error) {
+	p := propValue{Encoding: t.Encoding, Text: t.Text}
+	return p.value()
+}
+
+// GetLog obtém as entradas de log do caminho entre start e end, em ordem
+// decrescente quando start > end
+func (s *Session) GetLog(path string, start, end int64, limit int) ([]svn.LogEntry, error) {
+	start, end = s.revision(start), s.revision(end)
+
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0" encoding="utf-8"?><S:log-report xmlns:S="svn:">`)
+	fmt.Fprintf(&body, "<S:start-revision>%d</S:start-revision><S:end-revision>%d</S:end-revision>", start, end)
+	if limit > 0 {
+		fmt.Fprintf(&body, "<S:limit>%d</S:limit>", limit)
+	}
+	body.WriteString("<S:revprop>svn:author</S:revprop><S:revprop>svn:date</S:revprop><S:revprop>svn:log</S:revprop>")
+	body.WriteString("<S:path></S:path></S:log-report>")
+
+	var report struct {
+		Items []logItem `xml:"svn: log-item"`
+	}
+	if err := s.report(s.revPath(path, max(start, end)), body.String(), &report); err != nil {
+		return nil, err
+	}
+
+	entries := make([]svn.LogEntry, 0, len(report.Items))
+	for _, item := range report.Items {
+		entry := svn.LogEntry{Revision: strings.TrimSpace(item.Revision)}
+		author, err := item.Author.value()
+		if err != nil {
+			return nil, err
+		}
+		message, err := item.Comment.value()
+		if err != nil {
+			return nil, err
+		}
+		entry.Author = author
+		entry.Message = strings.TrimSpace(message)
+
+		if date := strings.TrimSpace(item.Date.Text); date != "" {
+			if entry.Date, err = time.Parse(time.RFC3339Nano, date); err != nil {
+				return nil, fmt.Errorf("data inválida na revisão %s: %w", entry.Revision, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// CompareTrees calcula no servidor as mudanças entre a URL da sessão em revA
+// e urlB em revB, usando um update-report sem deltas de conteúdo, da mesma
+// forma que svn diff --summarize
+func (s *Session) CompareTrees(revA int64, urlB string, revB int64) ([]svn.Change, error) {
+	urlB = strings.TrimSuffix(urlB, "/")
+	root := s.RootURL()
+	if urlB != root && !strings.HasPrefix(urlB, root+"/") {
+		return nil, svn.ErrUnsupported
+	}
+
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>`+
+		`<S:update-report xmlns:S="svn:" send-all="true">`+
+		`<S:src-path>%s</S:src-path><S:target-revision>%d</S:target-revision><S:dst-path>%s</S:dst-path>`+
+		`<S:depth>infinity</S:depth><S:ignore-ancestry>yes</S:ignore-ancestry>`+
+		`<S:text-deltas>no</S:text-deltas><S:send-copyfrom-args>no</S:send-copyfrom-args>`+
+		`<S:entry rev="%d" depth="infinity"></S:entry></S:update-report>`,
+		xmlEscape(s.base.String()), s.revision(revB), xmlEscape(urlB), s.revision(revA))
+
+	resp, err := s.do("REPORT", s.me, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	ed := &updateEditor{}
+	if err := ed.drive(xml.NewDecoder(resp.Body)); err != nil {
+		return nil, err
+	}
+
+	// O update-report não informa o tipo dos nós removidos
+	return ed.ResolveDeleted(func(path string) (svn.NodeKind, error) {
+		return s.CheckPath(path, revA)
+	})
+}
+
+// report envia um REPORT e decodifica a resposta em v
+func (s *Session) report(resPath, body string, v any) error {
+	resp, err := s.do("REPORT", resPath, nil, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := xml.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("resposta REPORT inválida: %w", err)
+	}
+	return nil
+}
+
+// revision substitui revisões negativas (HEAD) pela mais recente conhecida
+func (s *Session) revision(rev int64) int64 {
+	if rev < 0 {
+		return s.youngest
+	}
+	return rev
+}
+
+// xmlEscape escapa um texto para uso no corpo das requisições
+func xmlEscape(text string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
@@ -0,0 +1,266 @@
+package dav
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+// route associa uma requisição a uma resposta gravada de um mod_dav_svn real
+type route struct {
+	method string
+	path   string
+	// match é o valor do cabeçalho Depth (PROPFIND) ou o elemento raiz do
+	// corpo (REPORT)
+	match string
+	file  string
+}
+
+var routes = []route{
+	{"OPTIONS", "/svn/repo/trunk", "", "options.http"},
+	{"OPTIONS", "/svn/repo/branches/x", "", "options.http"},
+	{"REPORT", "/svn/repo/!svn/me", "update-report", "report-update.http"},
+	{"REPORT", "/svn/repo/!svn/me", "dated-rev-report", "report-dated-rev.http"},
+	{"REPORT", "/svn/repo/!svn/rvr/3/branches/x", "log-report", "report-log.http"},
+	{"PROPFIND", "/svn/repo/!svn/rvr/3/branches/x", "1", "propfind-x-r3-depth1.http"},
+	{"PROPFIND", "/svn/repo/!svn/rvr/1/trunk/a.txt", "0", "propfind-trunk-a-r1.http"},
+	{"PROPFIND", "/svn/repo/!svn/rvr/1/trunk/b.txt", "0", "propfind-trunk-b-r1.http"},
+	{"PROPFIND", "/svn/repo/!svn/rvr/3/branches/x/a.txt", "0", "propfind-x-a-r3.http"},
+	{"PROPFIND", "/svn/repo/!svn/rvr/3/branches/x/new.txt", "0", "propfind-x-new-r3.http"},
+	{"GET", "/svn/repo/!svn/rvr/1/trunk/a.txt", "", "get-trunk-a-r1.http"},
+	{"GET", "/svn/repo/!svn/rvr/1/trunk/b.txt", "", "get-trunk-b-r1.http"},
+	{"GET", "/svn/repo/!svn/rvr/3/branches/x/a.txt", "", "get-x-a-r3.http"},
+	{"GET", "/svn/repo/!svn/rvr/3/branches/x/new.txt", "", "get-x-new-r3.http"},
+}
+
+var reportRoot = regexp.MustCompile(`<S:([a-z-]+)`)
+
+// newReplayServer sobe um servidor HTTP que responde com as gravações de
+// testdata. Com user definido, exige autenticação básica.
+func newReplayServer(t *testing.T, user, password string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file := "propfind-missing.http"
+		if u, p, ok := r.BasicAuth(); user != "" && (!ok || u != user || p != password) {
+			file = "get-unauthorized.http"
+		} else if rt := findRoute(t, r); rt != nil {
+			file = rt.file
+		}
+		replay(t, w, r, file)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// findRoute procura a gravação correspondente à requisição
+func findRoute(t *testing.T, r *http.Request) *route {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Errorf("leitura do corpo: %v", err)
+	}
+	match := r.Header.Get("Depth")
+	if r.Method == "REPORT" {
+		if m := reportRoot.FindSubmatch(body); m != nil {
+			match = string(m[1])
+		}
+	}
+
+	for i, rt := range routes {
+		if rt.method == r.Method && rt.path == strings.TrimSuffix(r.URL.Path, "/") && rt.match == match {
+			return &routes[i]
+		}
+	}
+	return nil
+}
+
+// replay escreve a resposta gravada no arquivo informado
+func replay(t *testing.T, w http.ResponseWriter, r *http.Request, file string) {
+	data, err := os.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		t.Errorf("gravação %s: %v", file, err)
+		return
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), r)
+	if err != nil {
+		t.Errorf("gravação %s inválida: %v", file, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	for name, values := range resp.Header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+func TestSession_Commands(t *testing.T) {
+	server := newReplayServer(t, "", "")
+
+	s, err := Open(server.URL+"/svn/repo/branches/x/", "", "")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+
+	if got, want := s.RootURL(), server.URL+"/svn/repo"; got != want {
+		t.Errorf("RootURL() = %q, want %q", got, want)
+	}
+	if got := s.UUID(); got != "6f1d2a9e-3c4b-4d5e-8f70-1a2b3c4d5e6f" {
+		t.Errorf("UUID() = %q", got)
+	}
+
+	latest, err := s.LatestRevision()
+	if err != nil || latest != 3 {
+		t.Errorf("LatestRevision() = %d, %v; want 3", latest, err)
+	}
+
+	dated, err := s.DatedRevision(time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC))
+	if err != nil || dated != 2 {
+		t.Errorf("DatedRevision() = %d, %v; want 2", dated, err)
+	}
+
+	for path, want := range map[string]svn.NodeKind{"a.txt": svn.NodeFile, "missing": svn.NodeNone} {
+		kind, err := s.CheckPath(path, 3)
+		if err != nil || kind != want {
+			t.Errorf("CheckPath(%q) = %q, %v; want %q", path, kind, err, want)
+		}
+	}
+
+	dir, err := s.GetDir("", 3)
+	if err != nil {
+		t.Fatalf("GetDir() error = %v", err)
+	}
+	wantProps := map[string]string{"note": "revisão\x01", "svn:ignore": "*.o\n"}
+	if !reflect.DeepEqual(dir.Props, wantProps) {
+		t.Errorf("GetDir() props = %q, want %q", dir.Props, wantProps)
+	}
+	wantEntries := []svn.DirEntry{
+		{Name: "a.txt", Kind: svn.NodeFile, Size: 5, CreatedRev: 3},
+		{Name: "new.txt", Kind: svn.NodeFile, Size: 5, CreatedRev: 3},
+		{Name: "docs novos", Kind: svn.NodeDir, CreatedRev: 2},
+	}
+	if !reflect.DeepEqual(dir.Entries, wantEntries) {
+		t.Errorf("GetDir() entries = %+v, want %+v", dir.Entries, wantEntries)
+	}
+
+	file, err := s.GetFile("a.txt", 3, true)
+	if err != nil {
+		t.Fatalf("GetFile() error = %v", err)
+	}
+	checksum := fmt.Sprintf("%x", md5.Sum(file.Content))
+	if string(file.Content) != "um\n2\n" || file.Props["svn:eol-style"] != "native" || file.Checksum != checksum {
+		t.Errorf("GetFile() = %+v", file)
+	}
+
+	entries, err := s.GetLog("", 3, 1, 0)
+	if err != nil {
+		t.Fatalf("GetLog() error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("GetLog() = %d entradas, want 3", len(entries))
+	}
+	want := svn.LogEntry{Revision: "2", Author: "bob", Message: "cria branch x\x1b",
+		Date: time.Date(2024, 3, 2, 9, 30, 0, 0, time.UTC)}
+	if !reflect.DeepEqual(entries[1], want) {
+		t.Errorf("GetLog()[1] = %+v, want %+v", entries[1], want)
+	}
+	if entries[0].Message != "PROJ-42 ajusta a.txt" {
+		t.Errorf("GetLog()[0].Message = %q", entries[0].Message)
+	}
+}
+
+func TestSession_CompareTrees(t *testing.T) {
+	server := newReplayServer(t, "", "")
+
+	s, err := Open(server.URL+"/svn/repo/trunk", "", "")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+
+	changes, err := s.CompareTrees(1, server.URL+"/svn/repo/branches/x", 3)
+	if err != nil {
+		t.Fatalf("CompareTrees() error = %v", err)
+	}
+	want := []svn.Change{
+		{Path: "b.txt", Kind: svn.NodeFile, Text: 'D', Props: ' '},
+		{Path: "a.txt", Kind: svn.NodeFile, Text: 'M', Props: 'M'},
+		{Path: "new.txt", Kind: svn.NodeFile, Text: 'A', Props: ' '},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("CompareTrees() = %+v, want %+v", changes, want)
+	}
+
+	if _, err := s.CompareTrees(1, "http://outro/svn/repo/trunk", 3); err != svn.ErrUnsupported {
+		t.Errorf("CompareTrees(outro repositório) error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestRepositoryBackend_GetDiff(t *testing.T) {
+	server := newReplayServer(t, "", "")
+	backend := svn.NewRepositoryBackend(func(url string) (svn.Repository, error) {
+		return Open(url, "", "")
+	})
+	branchA := &config.BranchConfig{URL: server.URL + "/svn/repo/trunk", Revisions: []string{"1"}}
+	branchB := &config.BranchConfig{URL: server.URL + "/svn/repo/branches/x", Revisions: []string{"3"}}
+
+	summary, err := backend.GetDiff(branchA, branchB, true)
+	if err != nil {
+		t.Fatalf("GetDiff(summarize) error = %v", err)
+	}
+	wantSummary := "D       b.txt\nMM      a.txt\nA       new.txt\n"
+	if got := svntest.SummaryLines(t, summary.Output); got != wantSummary {
+		t.Errorf("GetDiff(summarize) = %q, want %q", got, wantSummary)
+	}
+
+	full, err := backend.GetDiff(branchA, branchB, false)
+	if err != nil {
+		t.Fatalf("GetDiff() error = %v", err)
+	}
+	for _, want := range []string{
+		"--- b.txt\t(revision 1)\n+++ b.txt\t(nonexistent)\n@@ -1 +0,0 @@\n-velho\n",
+		"@@ -1,2 +1,2 @@\n um\n-dois\n+2\n",
+		"Added: svn:eol-style\n## -0,0 +1 ##\n+native\n",
+		"+++ new.txt\t(revision 3)\n@@ -0,0 +1 @@\n+novo\n",
+	} {
+		if !strings.Contains(full.Output, want) {
+			t.Errorf("GetDiff() output não contém %q:\n%s", want, full.Output)
+		}
+	}
+}
+
+func TestOpen_Auth(t *testing.T) {
+	server := newReplayServer(t, "alice", "secret")
+
+	if _, err := Open(server.URL+"/svn/repo/trunk", "alice", "errada"); err == nil ||
+		!strings.Contains(err.Error(), "autenticação recusada") {
+		t.Errorf("Open(senha errada) error = %v", err)
+	}
+
+	s, err := Open(server.URL+"/svn/repo/trunk", "alice", "secret")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	s.Close()
+
+	if _, err := Open(server.URL+"/svn/outro", "alice", "secret"); err == nil {
+		t.Error("Open(caminho inexistente) esperava erro")
+	}
+}
@@ -0,0 +1,115 @@
+package dav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"svndiff/internal/svn"
+)
+
+// updateEditor converte os elementos da resposta de um update-report em
+// eventos do editor de diferenças, mantendo a pilha de nós abertos
+type updateEditor struct {
+	svn.SummaryEditor
+	stack []*svn.EditorNode
+}
+
+// drive processa a resposta do update-report até o fim do documento
+func (e *updateEditor) drive(dec *xml.Decoder) error {
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			if len(e.stack) > 0 {
+				return fmt.Errorf("resposta do update-report incompleta")
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("resposta do update-report inválida: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space == nsSVN {
+				e.start(t.Name.Local, attr(t, "name"))
+			}
+		case xml.EndElement:
+			if t.Name.Space == nsSVN {
+				e.end(t.Name.Local)
+			}
+		}
+	}
+}
+
+// start trata a abertura de um elemento do editor
+func (e *updateEditor) start(name, entry string) {
+	switch name {
+	case "open-directory", "add-directory", "open-file", "add-file":
+		kind := svn.NodeDir
+		if name == "open-file" || name == "add-file" {
+			kind = svn.NodeFile
+		}
+		added := name == "add-directory" || name == "add-file"
+		e.stack = append(e.stack, e.Open(e.childPath(entry), kind, added))
+
+	case "delete-entry":
+		e.Delete(e.childPath(entry))
+
+	case "txdelta", "fetch-file":
+		if node := e.top(); node != nil {
+			node.Text = true
+		}
+
+	case "set-prop", "remove-prop":
+		e.ChangeProp(e.top(), entry)
+
+	case "fetch-props":
+		if node := e.top(); node != nil {
+			node.Props = true
+		}
+	}
+}
+
+// end trata o fechamento de um elemento, fechando o nó correspondente
+func (e *updateEditor) end(name string) {
+	switch name {
+	case "open-directory", "add-directory", "open-file", "add-file":
+		node := e.top()
+		if node == nil {
+			return
+		}
+		e.stack = e.stack[:len(e.stack)-1]
+		e.Close(node)
+	}
+}
+
+// top retorna o nó aberto mais recente
+func (e *updateEditor) top() *svn.EditorNode {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	return e.stack[len(e.stack)-1]
+}
+
+// childPath monta o caminho de uma entrada do diretório aberto
+func (e *updateEditor) childPath(name string) string {
+	parent := e.top()
+	if parent == nil || parent.Path == "" {
+		return name
+	}
+	if name == "" {
+		return parent.Path
+	}
+	return parent.Path + "/" + name
+}
+
+// attr retorna o valor do atributo informado
+func attr(el xml.StartElement, name string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package dav
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"svndiff/internal/svn"
+)
+
+// multistatus é a resposta de um PROPFIND
+type multistatus struct {
+	Responses []propResponse `xml:"DAV: response"`
+}
+
+// propResponse descreve as propriedades de um recurso
+type propResponse struct {
+	Href      string     `xml:"DAV: href"`
+	Propstats []propstat `xml:"DAV: propstat"`
+}
+
+type propstat struct {
+	Prop   propList `xml:"DAV: prop"`
+	Status string   `xml:"DAV: status"`
+}
+
+type propList struct {
+	Values []propValue `xml:",any"`
+}
+
+// propValue é uma propriedade qualquer; valores binários chegam em base64
+// com o atributo encoding
+type propValue struct {
+	XMLName    xml.Name
+	Encoding   string    `xml:"encoding,attr"`
+	Text       string    `xml:",chardata"`
+	Collection *struct{} `xml:"DAV: collection"`
+}
+
+// value retorna o valor decodificado da propriedade
+func (p *propValue) value() (string, error) {
+	if p.Encoding != "base64" {
+		return p.Text, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(p.Text))
+	if err != nil {
+		return "", fmt.Errorf("propriedade '%s' com base64 inválido: %w", p.XMLName.Local, err)
+	}
+	return string(data), nil
+}
+
+// resource reúne as propriedades de um recurso de uma resposta PROPFIND
+type resource struct {
+	href     string
+	kind     svn.NodeKind
+	size     int64
+	rev      int64
+	checksum string
+	props    map[string]string
+}
+
+// propfind consulta todas as propriedades do recurso com a profundidade informada
+func (s *Session) propfind(resPath, depth string) ([]resource, error) {
+	body := `<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><allprop/></propfind>`
+	resp, err := s.do("PROPFIND", resPath, map[string]string{"Depth": depth}, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("resposta PROPFIND inválida: %w", err)
+	}
+
+	resources := make([]resource, 0, len(ms.Responses))
+	for _, r := range ms.Responses {
+		res, err := parseResource(r)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, res)
+	}
+	return resources, nil
+}
+
+// parseResource converte as propriedades de um recurso, separando as
+// propriedades do WebDAV das propriedades versionadas
+func parseResource(r propResponse) (resource, error) {
+	res := resource{href: r.Href, kind: svn.NodeFile, props: make(map[string]string)}
+	for _, ps := range r.Propstats {
+		if !statusOK(ps.Status) {
+			continue
+		}
+		for i := range ps.Prop.Values {
+			p := &ps.Prop.Values[i]
+			value, err := p.value()
+			if err != nil {
+				return res, err
+			}
+
+			switch p.XMLName.Space {
+			case nsProps:
+				res.props["svn:"+p.XMLName.Local] = value
+			case nsCustom:
+				res.props[p.XMLName.Local] = value
+			case nsSVNDAV:
+				if p.XMLName.Local == "md5-checksum" {
+					res.checksum = strings.TrimSpace(value)
+				}
+			case nsDAV:
+				switch p.XMLName.Local {
+				case "resourcetype":
+					if p.Collection != nil {
+						res.kind = svn.NodeDir
+					}
+				case "getcontentlength":
+					res.size, _ = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+				case "version-name":
+					res.rev, _ = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// hrefName retorna o último segmento, decodificado, de um href
+func hrefName(href string) string {
+	if u, err := url.Parse(href); err == nil {
+		href = u.Path
+	}
+	return path.Base(strings.TrimSuffix(href, "/"))
+}
+
+// isNotFound indica se o erro corresponde a um recurso inexistente
+func isNotFound(err error) bool {
+	_, ok := err.(*notFoundError)
+	return ok
+}
+
+// statusOK indica se a linha de status de um propstat é de sucesso
+func statusOK(status string) bool {
+	return strings.Contains(status, " 200 ")
+}
@@ -0,0 +1,205 @@
+// Package dav implementa um cliente em Go puro para o protocolo HTTP v2 do
+// mod_dav_svn, usado em URLs http:// e https://, dispensando o comando svn.
+package dav
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// requestTimeout limita a duração de cada requisição HTTP
+const requestTimeout = 5 * time.Minute
+
+// Namespaces usados pelo mod_dav_svn
+const (
+	nsDAV    = "DAV:"
+	nsSVN    = "svn:"
+	nsSVNDAV = "http://subversion.tigris.org/xmlns/dav/"
+	nsProps  = "http://subversion.tigris.org/xmlns/svn/"
+	nsCustom = "http://subversion.tigris.org/xmlns/custom/"
+)
+
+// Session é uma sessão com um repositório servido pelo mod_dav_svn,
+// ancorada na URL informada em Open
+type Session struct {
+	client   *http.Client
+	base     *url.URL
+	user     string
+	password string
+
+	// Informações obtidas pela requisição OPTIONS
+	root     string
+	relPath  string
+	revRoot  string
+	me       string
+	uuid     string
+	youngest int64
+}
+
+// Open abre uma sessão com a URL, descobrindo os recursos do protocolo
+// HTTP v2 através de uma requisição OPTIONS
+func Open(rawURL, user, password string) (*Session, error) {
+	return OpenWithClient(&http.Client{Timeout: requestTimeout}, rawURL, user, password)
+}
+
+// OpenWithClient abre a sessão usando o cliente HTTP informado
+func OpenWithClient(client *http.Client, rawURL, user, password string) (*Session, error) {
+	base, err := url.Parse(strings.TrimSuffix(rawURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("URL inválida '%s': %w", rawURL, err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("esquema não suportado pelo cliente WebDAV: %s", base.Scheme)
+	}
+
+	s := &Session{client: client, base: base, user: user, password: password}
+	if err := s.options(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close encerra a sessão
+func (s *Session) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// UUID retorna o identificador do repositório
+func (s *Session) UUID() string {
+	return s.uuid
+}
+
+// RootURL retorna a URL raiz do repositório
+func (s *Session) RootURL() string {
+	root := *s.base
+	root.Path = s.root
+	root.RawPath = ""
+	return root.String()
+}
+
+// options consulta os recursos HTTP v2 anunciados pelo servidor
+func (s *Session) options() error {
+	body := `<?xml version="1.0" encoding="utf-8"?><D:options xmlns:D="DAV:"><D:activity-collection-set/></D:options>`
+	resp, err := s.do("OPTIONS", s.base.EscapedPath(), nil, body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	youngest := resp.Header.Get("SVN-Youngest-Rev")
+	s.root = strings.TrimSuffix(resp.Header.Get("SVN-Repository-Root"), "/")
+	s.revRoot = resp.Header.Get("SVN-Rev-Root-Stub")
+	s.me = resp.Header.Get("SVN-Me-Resource")
+	s.uuid = resp.Header.Get("SVN-Repository-UUID")
+	if youngest == "" || s.revRoot == "" || s.me == "" {
+		return fmt.Errorf("o servidor não suporta o protocolo HTTP v2 do Subversion: %s", s.base)
+	}
+
+	if s.youngest, err = strconv.ParseInt(youngest, 10, 64); err != nil {
+		return fmt.Errorf("revisão inválida no cabeçalho SVN-Youngest-Rev: %s", youngest)
+	}
+
+	basePath := strings.TrimSuffix(s.base.Path, "/")
+	if basePath != s.root && !strings.HasPrefix(basePath, s.root+"/") {
+		return fmt.Errorf("URL '%s' fora da raiz do repositório '%s'", s.base, s.root)
+	}
+	s.relPath = strings.TrimPrefix(strings.TrimPrefix(basePath, s.root), "/")
+
+	return nil
+}
+
+// revPath retorna o caminho HTTP do recurso path (relativo à sessão) na revisão rev
+func (s *Session) revPath(path string, rev int64) string {
+	full := strings.Trim(s.relPath+"/"+path, "/")
+	return fmt.Sprintf("%s/%d/%s", s.revRoot, rev, escapePath(full))
+}
+
+// do executa uma requisição com autenticação básica e trata os códigos de erro
+func (s *Session) do(method, path string, headers map[string]string, body string) (*http.Response, error) {
+	target := *s.base
+	target.RawPath = path
+	target.Path, _ = url.PathUnescape(path)
+	target.RawQuery = ""
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, target.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	if s.user != "" {
+		req.SetBasicAuth(s.user, s.password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro na requisição %s %s: %w", method, target.String(), err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		resp.Body.Close()
+		return nil, fmt.Errorf("autenticação recusada pelo servidor (%s)", resp.Status)
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, &notFoundError{path: target.Path}
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s falhou: %s%s", method, target.Path, resp.Status, humanReadable(msg))
+	}
+
+	return resp, nil
+}
+
+// notFoundError indica um recurso inexistente (HTTP 404)
+type notFoundError struct {
+	path string
+}
+
+func (e *notFoundError) Error() string {
+	return fmt.Sprintf("caminho não encontrado: %s", e.path)
+}
+
+// humanReadable extrai a mensagem de erro do corpo de uma resposta do mod_dav_svn
+func humanReadable(body []byte) string {
+	start := bytes.Index(body, []byte("<m:human-readable"))
+	if start < 0 {
+		return ""
+	}
+	body = body[start:]
+	if open := bytes.IndexByte(body, '>'); open >= 0 {
+		body = body[open+1:]
+	}
+	if end := bytes.Index(body, []byte("</m:human-readable>")); end >= 0 {
+		body = body[:end]
+	}
+	return ": " + strings.TrimSpace(string(body))
+}
+
+// escapePath codifica cada segmento de um caminho para uso em URLs
+func escapePath(path string) string {
+	if path == "" {
+		return ""
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
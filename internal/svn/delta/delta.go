@@ -0,0 +1,267 @@
+// Package delta decodifica o formato svndiff (versões 0, 1 e 2), usado pelo
+// Subversion para armazenar conteúdo como diferença em relação a uma base,
+// tanto nos repositórios FSFS quanto nos arquivos de dump.
+package delta
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Códigos das instruções de um janela svndiff
+const (
+	opCopySource = 0
+	opCopyTarget = 1
+	opNewData    = 2
+)
+
+// ErrCorrupt indica dados svndiff inválidos
+var ErrCorrupt = errors.New("dados svndiff corrompidos")
+
+// Apply reconstrói o conteúdo aplicando o svndiff completo data (cabeçalho
+// e janelas) sobre source
+func Apply(source, data []byte) ([]byte, error) {
+	if len(data) < 4 || !bytes.Equal(data[:3], []byte("SVN")) {
+		return nil, fmt.Errorf("%w: cabeçalho ausente", ErrCorrupt)
+	}
+	version := data[3]
+	if version > 2 {
+		return nil, fmt.Errorf("versão svndiff não suportada: %d", version)
+	}
+
+	var target bytes.Buffer
+	p := data[4:]
+	for len(p) > 0 {
+		var w window
+		var err error
+		if p, err = w.read(p, version); err != nil {
+			return nil, err
+		}
+		if err := w.apply(source, &target); err != nil {
+			return nil, err
+		}
+	}
+	return target.Bytes(), nil
+}
+
+// window é uma janela svndiff já descomprimida
+type window struct {
+	sviewOffset uint64
+	sviewLen    uint64
+	tviewLen    uint64
+	ins         []byte
+	newData     []byte
+}
+
+// read decodifica uma janela no início de p, retornando o restante
+func (w *window) read(p []byte, version byte) ([]byte, error) {
+	var fields [5]uint64
+	for i := range fields {
+		var err error
+		if fields[i], p, err = readUint(p); err != nil {
+			return nil, err
+		}
+	}
+	w.sviewOffset, w.sviewLen, w.tviewLen = fields[0], fields[1], fields[2]
+	insLen, newLen := fields[3], fields[4]
+	if insLen+newLen > uint64(len(p)) {
+		return nil, fmt.Errorf("%w: janela truncada", ErrCorrupt)
+	}
+
+	var err error
+	if w.ins, err = decompress(p[:insLen], version); err != nil {
+		return nil, err
+	}
+	if w.newData, err = decompress(p[insLen:insLen+newLen], version); err != nil {
+		return nil, err
+	}
+	return p[insLen+newLen:], nil
+}
+
+// apply executa as instruções da janela, acrescentando o resultado a target
+func (w *window) apply(source []byte, target *bytes.Buffer) error {
+	if w.sviewOffset+w.sviewLen > uint64(len(source)) {
+		return fmt.Errorf("%w: visão da origem fora dos limites", ErrCorrupt)
+	}
+	sview := source[w.sviewOffset : w.sviewOffset+w.sviewLen]
+	tview := make([]byte, 0, w.tviewLen)
+
+	ins, newData := w.ins, w.newData
+	for len(ins) > 0 {
+		op := ins[0] >> 6
+		length := uint64(ins[0] & 0x3f)
+		ins = ins[1:]
+
+		var err error
+		if length == 0 {
+			if length, ins, err = readUint(ins); err != nil {
+				return err
+			}
+		}
+		var offset uint64
+		if op != opNewData {
+			if offset, ins, err = readUint(ins); err != nil {
+				return err
+			}
+		}
+
+		switch op {
+		case opCopySource:
+			if offset+length > uint64(len(sview)) {
+				return fmt.Errorf("%w: cópia fora da origem", ErrCorrupt)
+			}
+			tview = append(tview, sview[offset:offset+length]...)
+		case opCopyTarget:
+			// A cópia pode sobrepor o trecho sendo gerado, byte a byte
+			if offset >= uint64(len(tview)) {
+				return fmt.Errorf("%w: cópia fora do destino", ErrCorrupt)
+			}
+			for i := uint64(0); i < length; i++ {
+				tview = append(tview, tview[offset+i])
+			}
+		case opNewData:
+			if length > uint64(len(newData)) {
+				return fmt.Errorf("%w: dados novos insuficientes", ErrCorrupt)
+			}
+			tview = append(tview, newData[:length]...)
+			newData = newData[length:]
+		default:
+			return fmt.Errorf("%w: instrução inválida", ErrCorrupt)
+		}
+	}
+
+	if uint64(len(tview)) != w.tviewLen {
+		return fmt.Errorf("%w: janela com %d bytes, esperados %d", ErrCorrupt, len(tview), w.tviewLen)
+	}
+	target.Write(tview)
+	return nil
+}
+
+// readUint lê um inteiro codificado em grupos de 7 bits, mais significativos
+// primeiro, com o bit alto indicando continuação
+func readUint(p []byte) (uint64, []byte, error) {
+	var value uint64
+	for i, b := range p {
+		if i >= 10 {
+			break
+		}
+		value = value<<7 | uint64(b&0x7f)
+		if b&0x80 == 0 {
+			return value, p[i+1:], nil
+		}
+	}
+	return 0, nil, fmt.Errorf("%w: inteiro inválido", ErrCorrupt)
+}
+
+// decompress descomprime uma seção de uma janela. Nas versões 1 e 2 a seção
+// começa com o tamanho original; se igual ao restante, os dados não foram
+// comprimidos.
+func decompress(p []byte, version byte) ([]byte, error) {
+	if version == 0 {
+		return p, nil
+	}
+	return Decompress(p, version == 2)
+}
+
+// Decompress decodifica um bloco no formato svn__compress: o tamanho
+// original seguido dos dados em zlib (ou LZ4, se lz4 for verdadeiro), ou
+// dos próprios dados quando a compressão não reduziu o tamanho
+func Decompress(p []byte, lz4 bool) ([]byte, error) {
+	size, rest, err := readUint(p)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, nil
+	}
+
+	if lz4 {
+		return decompressLZ4(rest, int(size))
+	}
+	if uint64(len(rest)) == size {
+		return rest, nil
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(rest))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	defer r.Close()
+
+	out := make([]byte, size)
+	if _, err := io.ReadFull(r, out); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return out, nil
+}
+
+// decompressLZ4 decodifica um bloco LZ4 com o tamanho final conhecido
+func decompressLZ4(p []byte, size int) ([]byte, error) {
+	out := make([]byte, 0, size)
+	for len(p) > 0 {
+		token := p[0]
+		p = p[1:]
+
+		literals, rest, err := lz4Length(p, int(token>>4))
+		if err != nil {
+			return nil, err
+		}
+		p = rest
+		if literals > len(p) {
+			return nil, fmt.Errorf("%w: literais LZ4 truncados", ErrCorrupt)
+		}
+		out = append(out, p[:literals]...)
+		p = p[literals:]
+
+		// O último bloco contém apenas literais
+		if len(p) == 0 {
+			break
+		}
+		if len(p) < 2 {
+			return nil, fmt.Errorf("%w: deslocamento LZ4 truncado", ErrCorrupt)
+		}
+		offset := int(p[0]) | int(p[1])<<8
+		p = p[2:]
+
+		match, rest, err := lz4Length(p, int(token&0x0f))
+		if err != nil {
+			return nil, err
+		}
+		p = rest
+		match += 4
+
+		if offset == 0 || offset > len(out) {
+			return nil, fmt.Errorf("%w: deslocamento LZ4 inválido", ErrCorrupt)
+		}
+		start := len(out) - offset
+		for i := 0; i < match; i++ {
+			out = append(out, out[start+i])
+		}
+	}
+
+	if len(out) != size {
+		return nil, fmt.Errorf("%w: LZ4 com %d bytes, esperados %d", ErrCorrupt, len(out), size)
+	}
+	return out, nil
+}
+
+// lz4Length completa um comprimento LZ4 cujo valor inicial é 15
+func lz4Length(p []byte, length int) (int, []byte, error) {
+	if length != 15 {
+		return length, p, nil
+	}
+	for {
+		if len(p) == 0 {
+			return 0, nil, fmt.Errorf("%w: comprimento LZ4 truncado", ErrCorrupt)
+		}
+		b := p[0]
+		p = p[1:]
+		length += int(b)
+		if b != 255 {
+			return length, p, nil
+		}
+	}
+}
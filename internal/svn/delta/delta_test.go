@@ -0,0 +1,137 @@
+package delta
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"testing"
+)
+
+// appendUint codifica um inteiro no formato svndiff
+func appendUint(p []byte, v uint64) []byte {
+	var groups []byte
+	groups = append(groups, byte(v&0x7f))
+	for v >>= 7; v > 0; v >>= 7 {
+		groups = append(groups, byte(v&0x7f)|0x80)
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		p = append(p, groups[i])
+	}
+	return p
+}
+
+// encodeWindow monta uma janela com as seções informadas
+func encodeWindow(sviewOffset, sviewLen, tviewLen uint64, ins, newData []byte) []byte {
+	var p []byte
+	for _, v := range []uint64{sviewOffset, sviewLen, tviewLen, uint64(len(ins)), uint64(len(newData))} {
+		p = appendUint(p, v)
+	}
+	return append(append(p, ins...), newData...)
+}
+
+// compress gera uma seção no formato svn__compress com zlib
+func compress(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return append(appendUint(nil, uint64(len(data))), buf.Bytes()...)
+}
+
+func TestApply(t *testing.T) {
+	source := []byte("um\ndois\ntres\n")
+
+	// Copia "um\n" da origem, insere "2\n", copia "tres\n" da origem e
+	// repete os 3 últimos bytes gerados
+	ins := []byte{
+		opCopySource<<6 | 3, 0,
+		opNewData<<6 | 2,
+		opCopySource<<6 | 5, 8,
+		opCopyTarget<<6 | 3, 7,
+	}
+	newData := []byte("2\n")
+	want := "um\n2\ntres\nes\n"
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "versão 0",
+			data: append([]byte("SVN\x00"), encodeWindow(0, uint64(len(source)), uint64(len(want)), ins, newData)...),
+		},
+		{
+			name: "versão 1 com zlib",
+			data: append([]byte("SVN\x01"), encodeWindow(0, uint64(len(source)), uint64(len(want)),
+				compress(t, ins), append(appendUint(nil, 2), newData...))...),
+		},
+		{
+			name: "duas janelas",
+			data: append(append([]byte("SVN\x00"),
+				encodeWindow(0, 3, 5, []byte{opCopySource<<6 | 3, 0, opNewData<<6 | 2}, newData)...),
+				encodeWindow(8, 5, 8, []byte{opCopySource<<6 | 5, 0, opCopyTarget<<6 | 3, 2}, nil)...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(source, tt.data)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if string(got) != want {
+				t.Errorf("Apply() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestApply_Corrupt(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"sem cabeçalho", []byte("XYZ")},
+		{"origem fora dos limites", append([]byte("SVN\x00"), encodeWindow(10, 5, 0, nil, nil)...)},
+		{"tamanho divergente", append([]byte("SVN\x00"), encodeWindow(0, 0, 4, []byte{opNewData<<6 | 2}, []byte("ab"))...)},
+		{"janela truncada", append([]byte("SVN\x00"), 0, 0, 2, 5, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Apply(nil, tt.data); !errors.Is(err, ErrCorrupt) {
+				t.Errorf("Apply() error = %v, want ErrCorrupt", err)
+			}
+		})
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		lz4  bool
+		want string
+	}{
+		{"sem compressão", append(appendUint(nil, 3), "abc"...), false, "abc"},
+		{"zlib", compress(t, []byte("abcabcabcabc")), false, "abcabcabcabc"},
+		{"vazio", appendUint(nil, 0), false, ""},
+		// Literais "abc" seguidos de uma cópia de 9 bytes a 3 de distância
+		{"lz4", append(appendUint(nil, 12), 0x35, 'a', 'b', 'c', 3, 0), true, "abcabcabcabc"},
+		{"lz4 só literais", append(appendUint(nil, 2), 0x20, 'o', 'i'), true, "oi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decompress(tt.data, tt.lz4)
+			if err != nil {
+				t.Fatalf("Decompress() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Decompress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package dump
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+// history é o histórico usado nos testes: trunk criado em r1, copiado para
+// branches/x em r2 e alterado na branch em r3
+var history = [][]svntest.DumpNode{
+	{
+		{Path: "trunk", Kind: "dir", Action: "add"},
+		{Path: "branches", Kind: "dir", Action: "add"},
+		{Path: "trunk/a.txt", Kind: "file", Action: "add", Content: "um\ndois\n", Props: []string{"svn:eol-style", "native"}},
+		{Path: "trunk/old.txt", Kind: "file", Action: "add", Content: "velho\n"},
+		{Path: "trunk/lib", Kind: "dir", Action: "add"},
+		{Path: "trunk/lib/x.c", Kind: "file", Action: "add", Content: "int x;\n"},
+	},
+	{
+		{Path: "branches/x", Kind: "dir", Action: "add", CopyFrom: "trunk", CopyRev: 1},
+	},
+	{
+		{Path: "branches/x/a.txt", Kind: "file", Action: "change", Content: "um\n2\n", Props: []string{"svn:eol-style", "native"}},
+		{Path: "branches/x/new.txt", Kind: "file", Action: "add", Content: "novo\n"},
+		{Path: "branches/x/old.txt", Action: "delete"},
+	},
+	{
+		{Path: "trunk/a.txt", Kind: "file", Action: "change", Content: "um\ndois\ntres\n", Props: []string{"svn:eol-style", "native"}},
+	},
+}
+
+// writeFile grava o dump em um diretório temporário e retorna a URL base
+func writeFile(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "repo.dump")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return "dump://" + filepath.ToSlash(path)
+}
+
+func TestRepository(t *testing.T) {
+	base := writeFile(t, svntest.WriteDump(history))
+
+	repo, err := Open(base + "/branches/x")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer repo.Close()
+
+	if latest, err := repo.LatestRevision(); err != nil || latest != 4 {
+		t.Errorf("LatestRevision() = %d, %v; want 4", latest, err)
+	}
+	for path, want := range map[string]svn.NodeKind{
+		"": svn.NodeDir, "a.txt": svn.NodeFile, "lib": svn.NodeDir, "old.txt": svn.NodeNone, "a.txt/x": svn.NodeNone,
+	} {
+		if kind, err := repo.CheckPath(path, 3); err != nil || kind != want {
+			t.Errorf("CheckPath(%q) = %q, %v; want %q", path, kind, err, want)
+		}
+	}
+
+	dir, err := repo.GetDir("", 3)
+	if err != nil {
+		t.Fatalf("GetDir() error = %v", err)
+	}
+	var names []string
+	for _, entry := range dir.Entries {
+		names = append(names, entry.Name)
+		if entry.Name == "new.txt" && (entry.Size != 5 || entry.CreatedRev != 3) {
+			t.Errorf("GetDir() new.txt = %+v", entry)
+		}
+	}
+	if want := []string{"a.txt", "lib", "new.txt"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("GetDir() nomes = %v, want %v", names, want)
+	}
+
+	file, err := repo.GetFile("a.txt", 3, true)
+	if err != nil {
+		t.Fatalf("GetFile() error = %v", err)
+	}
+	if string(file.Content) != "um\n2\n" || file.Props["svn:eol-style"] != "native" {
+		t.Errorf("GetFile() = %q %v", file.Content, file.Props)
+	}
+	if sum := md5.Sum(file.Content); file.Checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("GetFile() checksum = %q", file.Checksum)
+	}
+
+	if _, err := repo.GetFile("a.txt", 9, false); err == nil {
+		t.Error("GetFile(r9) esperava erro")
+	}
+	if dated, err := repo.DatedRevision(time.Now()); err != nil || dated != 4 {
+		t.Errorf("DatedRevision() = %d, %v; want 4", dated, err)
+	}
+}
+
+func TestRepository_GetLog(t *testing.T) {
+	base := writeFile(t, svntest.WriteDump(history))
+
+	tests := []struct {
+		name       string
+		path       string
+		start, end int64
+		limit      int
+		want       []string
+	}{
+		{"branch segue a cópia", "/branches/x", 4, 1, 0, []string{"3", "2", "1"}},
+		{"trunk", "/trunk", 4, 1, 0, []string{"4", "1"}},
+		{"ordem crescente com limite", "/branches/x", 1, 4, 2, []string{"1", "2"}},
+		{"raiz", "", 4, 0, 0, []string{"4", "3", "2", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := Open(base + tt.path)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			entries, err := repo.GetLog("", tt.start, tt.end, tt.limit)
+			if err != nil {
+				t.Fatalf("GetLog() error = %v", err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Revision)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetLog() = %v, want %v", got, tt.want)
+			}
+			if len(entries) > 0 && (entries[0].Author != "alice" || entries[0].Date.IsZero()) {
+				t.Errorf("GetLog() entrada = %+v", entries[0])
+			}
+		})
+	}
+}
+
+func TestRepositoryBackend_GetDiff(t *testing.T) {
+	base := writeFile(t, svntest.WriteDump(history))
+
+	backend := svn.NewRepositoryBackend(func(url string) (svn.Repository, error) {
+		return Open(url)
+	})
+	branchA := &config.BranchConfig{URL: base + "/trunk", Revisions: []string{"4"}}
+	branchB := &config.BranchConfig{URL: base + "/branches/x", Revisions: []string{"3"}}
+
+	summary, err := backend.GetDiff(branchA, branchB, true)
+	if err != nil {
+		t.Fatalf("GetDiff(summarize) error = %v", err)
+	}
+	if got, want := svntest.SummaryLines(t, summary.Output), "M       a.txt\nA       new.txt\nD       old.txt\n"; got != want {
+		t.Errorf("GetDiff(summarize) = %q, want %q", got, want)
+	}
+
+	full, err := backend.GetDiff(branchA, branchB, false)
+	if err != nil {
+		t.Fatalf("GetDiff() error = %v", err)
+	}
+	if !strings.Contains(full.Output, "@@ -1,3 +1,2 @@\n um\n-dois\n-tres\n+2\n") {
+		t.Errorf("GetDiff() output inesperado:\n%s", full.Output)
+	}
+
+	// o streaming entrega a mesma saída
+	for _, want := range []*svn.DiffResult{summary, full} {
+		r, err := backend.StreamDiff(branchA, branchB, want == summary)
+		if err != nil {
+			t.Fatalf("StreamDiff() error = %v", err)
+		}
+		got, err := io.ReadAll(r)
+		r.Close()
+		if err != nil || string(got) != want.Output {
+			t.Errorf("StreamDiff() = %q, %v, want %q", got, err, want.Output)
+		}
+	}
+}
+
+func TestRepositoryBackend_CopySources(t *testing.T) {
+	moves := append(history[:3:3], []svntest.DumpNode{
+		{Path: "branches/x/renamed.txt", Kind: "file", Action: "add", CopyFrom: "branches/x/a.txt", CopyRev: 3},
+		{Path: "branches/x/a.txt", Action: "delete"},
+		{Path: "branches/x/src", Kind: "dir", Action: "add", CopyFrom: "trunk/lib", CopyRev: 1},
+	})
+	base := writeFile(t, svntest.WriteDump(moves))
+	backend := svn.NewRepositoryBackend(func(url string) (svn.Repository, error) {
+		return Open(url)
+	})
+
+	tests := []struct {
+		name      string
+		revisions []string
+		bases     []string
+		want      map[string]string
+	}{
+		{"até a criação da branch", []string{"4"}, nil, map[string]string{"renamed.txt": "a.txt"}},
+		{"com origens no trunk", []string{"4"}, []string{base + "/trunk"}, map[string]string{"renamed.txt": "a.txt", "src": "lib"}},
+		{"intervalo sem as cópias", []string{"2", "3"}, []string{base + "/trunk"}, map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			branch := &config.BranchConfig{URL: base + "/branches/x", Revisions: tt.revisions}
+			got, err := backend.CopySources(branch, tt.bases...)
+			if err != nil {
+				t.Fatalf("CopySources() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CopySources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// nodeRecord serializa um registro de nó com propriedades e texto opcionais
+func nodeRecord(headers, props, text string) string {
+	var b strings.Builder
+	b.WriteString(headers)
+	if props != "" {
+		fmt.Fprintf(&b, "Prop-content-length: %d\n", len(props))
+	}
+	if text != "" {
+		fmt.Fprintf(&b, "Text-content-length: %d\n", len(text))
+	}
+	fmt.Fprintf(&b, "Content-length: %d\n\n%s%s\n\n", len(props)+len(text), props, text)
+	return b.String()
+}
+
+// svndiff monta um delta versão 0 de uma janela com as instruções dadas
+func svndiff(sourceLen, targetLen int, instructions, newData string) string {
+	return "SVN\x00" + string([]byte{0, byte(sourceLen), byte(targetLen),
+		byte(len(instructions)), byte(len(newData))}) + instructions + newData
+}
+
+func TestParse_Version3(t *testing.T) {
+	revProps := "K 10\nsvn:author\nV 5\nalice\nK 8\nsvn:date\nV 27\n2024-03-01T10:00:00.000000Z\nPROPS-END\n"
+	revision := func(n int) string {
+		return fmt.Sprintf("Revision-number: %d\nProp-content-length: %d\nContent-length: %d\n\n%s\n",
+			n, len(revProps), len(revProps), revProps)
+	}
+
+	dump := "SVN-fs-dump-format-version: 3\n\nUUID: 0b3f2a7e-0000-4000-8000-000000000001\n\n" +
+		revision(0) + revision(1) +
+		nodeRecord("Node-path: trunk\nNode-kind: dir\nNode-action: add\nProp-delta: true\n", "PROPS-END\n", "") +
+		nodeRecord("Node-path: trunk/a.txt\nNode-kind: file\nNode-action: add\nText-delta: true\nProp-delta: true\n",
+			"K 13\nsvn:eol-style\nV 6\nnative\nPROPS-END\n", svndiff(0, 8, "\x88", "um\ndois\n")) +
+		revision(2) +
+		nodeRecord("Node-path: branch\nNode-kind: dir\nNode-action: add\nNode-copyfrom-rev: 1\nNode-copyfrom-path: trunk\n", "", "") +
+		revision(3) +
+		// Copia "um\n" da base e acrescenta "2\n"; remove svn:eol-style
+		nodeRecord("Node-path: branch/a.txt\nNode-kind: file\nNode-action: change\nText-delta: true\nProp-delta: true\n",
+			"D 13\nsvn:eol-style\nK 8\nsvn:mime\nV 10\ntext/plain\nPROPS-END\n", svndiff(8, 5, "\x03\x00\x82", "2\n")) +
+		revision(4) +
+		nodeRecord("Node-path: branch/a.txt\nNode-kind: file\nNode-action: replace\nNode-copyfrom-rev: 1\nNode-copyfrom-path: trunk/a.txt\n", "", "")
+
+	repo, err := Open(writeFile(t, []byte(dump)) + "/branch")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if repo.UUID() != "0b3f2a7e-0000-4000-8000-000000000001" {
+		t.Errorf("UUID() = %q", repo.UUID())
+	}
+
+	tests := []struct {
+		rev   int64
+		want  string
+		props map[string]string
+	}{
+		{2, "um\ndois\n", map[string]string{"svn:eol-style": "native"}},
+		{3, "um\n2\n", map[string]string{"svn:mime": "text/plain"}},
+		{4, "um\ndois\n", map[string]string{"svn:eol-style": "native"}},
+	}
+	for _, tt := range tests {
+		file, err := repo.GetFile("a.txt", tt.rev, true)
+		if err != nil {
+			t.Fatalf("GetFile(r%d) error = %v", tt.rev, err)
+		}
+		if string(file.Content) != tt.want || !reflect.DeepEqual(file.Props, tt.props) {
+			t.Errorf("GetFile(r%d) = %q %v, want %q %v", tt.rev, file.Content, file.Props, tt.want, tt.props)
+		}
+	}
+
+	dir, err := repo.GetDir("", 3)
+	if err != nil || len(dir.Entries) != 1 || dir.Entries[0].Size != 5 {
+		t.Errorf("GetDir(r3) = %+v, %v", dir, err)
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	valid := svntest.WriteDump(history)
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"esquema", "file:///tmp/repo"},
+		{"arquivo inexistente", "dump://" + filepath.ToSlash(t.TempDir()) + "/nada.dump/trunk"},
+		{"versão", writeFile(t, []byte("SVN-fs-dump-format-version: 9\n\n"))},
+		{"sem revisões", writeFile(t, []byte("SVN-fs-dump-format-version: 2\n\n"))},
+		{"cópia fora do dump", writeFile(t, []byte(strings.Replace(string(valid), "Node-copyfrom-rev: 1", "Node-copyfrom-rev: 7", 1)))},
+		{"truncado", writeFile(t, valid[:len(valid)-40])},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Open(tt.url); err == nil {
+				t.Errorf("Open(%q) esperava erro", tt.url)
+			}
+		})
+	}
+}
+
+// TestIntegration_Svnadmin compara um dump com deltas gerado pelo svnadmin.
+// É ignorado quando svnadmin não está instalado.
+func TestIntegration_Svnadmin(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "repo")
+	svntest.CreateRepository(t, dir, svntest.WriteDump(history))
+
+	out, err := exec.Command("svnadmin", "dump", "-q", "--deltas", dir).Output()
+	if err != nil {
+		t.Fatalf("svnadmin dump: %v", err)
+	}
+	base := writeFile(t, out)
+
+	backend := svn.NewRepositoryBackend(func(url string) (svn.Repository, error) {
+		return Open(url)
+	})
+	summary, err := backend.GetDiff(
+		&config.BranchConfig{URL: base + "/trunk", Revisions: []string{"4"}},
+		&config.BranchConfig{URL: base + "/branches/x", Revisions: []string{"3"}}, true)
+	if err != nil {
+		t.Fatalf("GetDiff(summarize) error = %v", err)
+	}
+	if got, want := svntest.SummaryLines(t, summary.Output), "M       a.txt\nA       new.txt\nD       old.txt\n"; got != want {
+		t.Errorf("GetDiff(summarize) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,465 @@
+// Package dump lê arquivos gerados por svnadmin dump (formatos 1 a 3,
+// inclusive com deltas) e os expõe como um repositório em memória, para
+// comparações sem acesso a um servidor. Os conteúdos dos arquivos não são
+// carregados: cada nó guarda a posição do texto no dump e o reconstrói
+// sob demanda.
+package dump
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"svndiff/internal/svn"
+)
+
+// Dump é o conteúdo de um arquivo de dump, com a árvore de cada revisão
+type Dump struct {
+	path   string
+	file   *os.File
+	uuid   string
+	first  int64
+	latest int64
+	revs   map[int64]*revision
+	nextID int64
+}
+
+// revision é o estado do repositório após uma revisão do dump
+type revision struct {
+	props   map[string]string
+	root    *node
+	changes []svn.ChangedPath
+}
+
+// node é um arquivo ou diretório; nós de revisões anteriores são imutáveis
+// e compartilhados entre as árvores
+type node struct {
+	id       int64
+	rev      int64
+	kind     svn.NodeKind
+	props    map[string]string
+	text     *text
+	children map[string]*node
+}
+
+// record é um registro do dump: cabeçalhos e a posição do conteúdo
+type record struct {
+	headers map[string]string
+	offset  int64
+	props   []byte
+}
+
+// parser lê o dump sequencialmente contando a posição no arquivo
+type parser struct {
+	r    *bufio.Reader
+	pos  int64
+	dump *Dump
+	cur  *revision
+	rev  int64
+}
+
+// parse lê todos os registros do dump
+func parse(path string) (*Dump, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Dump{path: path, file: f, revs: make(map[int64]*revision), first: -1}
+	p := &parser{r: bufio.NewReaderSize(f, 1<<16), dump: d}
+	if err := p.run(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("dump inválido '%s': %w", path, err)
+	}
+	if d.first < 0 {
+		f.Close()
+		return nil, fmt.Errorf("dump sem revisões: %s", path)
+	}
+	return d, nil
+}
+
+func (p *parser) run() error {
+	version := 0
+	for {
+		rec, err := p.readRecord()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case rec.headers["SVN-fs-dump-format-version"] != "":
+			if version, err = strconv.Atoi(rec.headers["SVN-fs-dump-format-version"]); err != nil || version < 1 || version > 3 {
+				return fmt.Errorf("versão de dump não suportada: %s", rec.headers["SVN-fs-dump-format-version"])
+			}
+		case rec.headers["UUID"] != "":
+			p.dump.uuid = rec.headers["UUID"]
+		case rec.headers["Revision-number"] != "":
+			if err := p.beginRevision(rec); err != nil {
+				return err
+			}
+		case has(rec.headers, "Node-path"):
+			if p.cur == nil {
+				return fmt.Errorf("nó antes da primeira revisão: %s", rec.headers["Node-path"])
+			}
+			if err := p.applyNode(rec); err != nil {
+				return fmt.Errorf("revisão %d, '%s': %w", p.rev, rec.headers["Node-path"], err)
+			}
+		default:
+			return fmt.Errorf("registro desconhecido na posição %d", rec.offset)
+		}
+	}
+}
+
+// readRecord lê os cabeçalhos de um registro, as propriedades e pula o texto
+func (p *parser) readRecord() (*record, error) {
+	rec := &record{headers: make(map[string]string)}
+	for {
+		line, err := p.readLine()
+		if err == io.EOF && line == "" && len(rec.headers) == 0 {
+			return nil, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line == "" {
+			if len(rec.headers) == 0 && err == nil {
+				// Linhas vazias entre registros
+				continue
+			}
+			break
+		}
+		name, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			return nil, fmt.Errorf("cabeçalho inválido na posição %d: %q", p.pos, line)
+		}
+		rec.headers[name] = value
+	}
+
+	propLen, err := number(rec.headers, "Prop-content-length")
+	if err != nil {
+		return nil, err
+	}
+	textLen, err := number(rec.headers, "Text-content-length")
+	if err != nil {
+		return nil, err
+	}
+	contentLen := propLen + textLen
+	if has(rec.headers, "Content-length") {
+		if contentLen, err = number(rec.headers, "Content-length"); err != nil {
+			return nil, err
+		}
+	}
+
+	rec.props = make([]byte, propLen)
+	if _, err := io.ReadFull(p.r, rec.props); err != nil {
+		return nil, fmt.Errorf("propriedades truncadas: %w", err)
+	}
+	p.pos += propLen
+	rec.offset = p.pos
+
+	skip := contentLen - propLen
+	if _, err := p.r.Discard(int(skip)); err != nil {
+		return nil, fmt.Errorf("conteúdo truncado: %w", err)
+	}
+	p.pos += skip
+	return rec, nil
+}
+
+// readLine lê uma linha sem o terminador
+func (p *parser) readLine() (string, error) {
+	line, err := p.r.ReadString('\n')
+	p.pos += int64(len(line))
+	return strings.TrimSuffix(line, "\n"), err
+}
+
+// beginRevision inicia uma revisão a partir da árvore da anterior
+func (p *parser) beginRevision(rec *record) error {
+	rev, err := strconv.ParseInt(rec.headers["Revision-number"], 10, 64)
+	if err != nil {
+		return fmt.Errorf("número de revisão inválido: %q", rec.headers["Revision-number"])
+	}
+	props, err := parseProps(rec.props, nil)
+	if err != nil {
+		return fmt.Errorf("propriedades da revisão %d: %w", rev, err)
+	}
+
+	root := &node{id: p.newID(), rev: rev, kind: svn.NodeDir, children: map[string]*node{}}
+	if p.cur != nil {
+		root = p.cur.root
+	}
+	if p.dump.first < 0 {
+		p.dump.first = rev
+	}
+
+	p.rev = rev
+	p.cur = &revision{props: props, root: root}
+	p.dump.revs[rev] = p.cur
+	p.dump.latest = rev
+	return nil
+}
+
+// applyNode aplica um registro de nó à árvore da revisão corrente
+func (p *parser) applyNode(rec *record) error {
+	path := strings.Trim(rec.headers["Node-path"], "/")
+	action := rec.headers["Node-action"]
+	change := svn.ChangedPath{Path: "/" + path}
+
+	switch action {
+	case "delete":
+		change.Action = 'D'
+		if err := p.remove(path); err != nil {
+			return err
+		}
+	case "add", "replace":
+		change.Action = 'A'
+		if action == "replace" {
+			change.Action = 'R'
+			if err := p.remove(path); err != nil {
+				return err
+			}
+		}
+
+		n := &node{id: p.newID(), rev: p.rev, kind: svn.NodeKind(rec.headers["Node-kind"])}
+		if has(rec.headers, "Node-copyfrom-rev") {
+			copyRev, err := strconv.ParseInt(rec.headers["Node-copyfrom-rev"], 10, 64)
+			if err != nil {
+				return fmt.Errorf("revisão de origem inválida: %q", rec.headers["Node-copyfrom-rev"])
+			}
+			copyPath := strings.Trim(rec.headers["Node-copyfrom-path"], "/")
+			source, ok := p.dump.revs[copyRev]
+			if !ok || copyRev > p.rev {
+				return fmt.Errorf("origem da cópia fora do dump: /%s@%d", copyPath, copyRev)
+			}
+			if n = lookup(source.root, copyPath); n == nil {
+				return fmt.Errorf("origem da cópia inexistente: /%s@%d", copyPath, copyRev)
+			}
+			change.CopyPath, change.CopyRev = "/"+copyPath, copyRev
+		} else if n.kind == svn.NodeDir {
+			n.children = map[string]*node{}
+		} else if n.kind != svn.NodeFile {
+			return fmt.Errorf("tipo de nó inválido: %q", rec.headers["Node-kind"])
+		}
+
+		parent, name, err := p.parent(path)
+		if err != nil {
+			return err
+		}
+		parent.children[name] = n
+	case "change":
+		change.Action = 'M'
+	default:
+		return fmt.Errorf("ação desconhecida: %q", action)
+	}
+	p.cur.changes = append(p.cur.changes, change)
+
+	if action == "delete" || (!has(rec.headers, "Prop-content-length") && !has(rec.headers, "Text-content-length")) {
+		return nil
+	}
+
+	n, err := p.mutable(path)
+	if err != nil {
+		return err
+	}
+	if has(rec.headers, "Prop-content-length") {
+		base := map[string]string(nil)
+		if rec.headers["Prop-delta"] == "true" {
+			base = n.props
+		}
+		if n.props, err = parseProps(rec.props, base); err != nil {
+			return err
+		}
+	}
+	if has(rec.headers, "Text-content-length") {
+		if n.kind != svn.NodeFile {
+			return fmt.Errorf("texto em nó que não é arquivo")
+		}
+		length, err := number(rec.headers, "Text-content-length")
+		if err != nil {
+			return err
+		}
+		t := &text{dump: p.dump, offset: rec.offset, length: length, md5: rec.headers["Text-content-md5"]}
+		if rec.headers["Text-delta"] == "true" {
+			t.delta, t.base = true, n.text
+		}
+		n.text = t
+	}
+	return nil
+}
+
+// mutable retorna o nó do caminho na revisão corrente, copiando os nós de
+// revisões anteriores ao longo do caminho
+func (p *parser) mutable(path string) (*node, error) {
+	n := p.cur.root
+	if n.rev != p.rev {
+		n = p.clone(n)
+		p.cur.root = n
+	}
+	for _, name := range splitPath(path) {
+		if n.kind != svn.NodeDir {
+			return nil, fmt.Errorf("caminho atravessa um arquivo: %s", path)
+		}
+		child, ok := n.children[name]
+		if !ok {
+			return nil, fmt.Errorf("caminho inexistente: %s", path)
+		}
+		if child.rev != p.rev {
+			child = p.clone(child)
+			n.children[name] = child
+		}
+		n = child
+	}
+	return n, nil
+}
+
+// parent retorna o diretório pai, já copiado para a revisão corrente
+func (p *parser) parent(path string) (*node, string, error) {
+	names := splitPath(path)
+	if len(names) == 0 {
+		return nil, "", fmt.Errorf("a raiz não pode ser adicionada ou removida")
+	}
+	dir, err := p.mutable(strings.Join(names[:len(names)-1], "/"))
+	if err != nil {
+		return nil, "", err
+	}
+	if dir.kind != svn.NodeDir {
+		return nil, "", fmt.Errorf("o pai de '%s' não é um diretório", path)
+	}
+	return dir, names[len(names)-1], nil
+}
+
+// remove retira o caminho da árvore corrente
+func (p *parser) remove(path string) error {
+	dir, name, err := p.parent(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := dir.children[name]; !ok {
+		return fmt.Errorf("caminho inexistente: %s", path)
+	}
+	delete(dir.children, name)
+	return nil
+}
+
+// clone copia um nó para a revisão corrente, com novo identificador
+func (p *parser) clone(n *node) *node {
+	c := *n
+	c.id, c.rev = p.newID(), p.rev
+	if n.children != nil {
+		c.children = make(map[string]*node, len(n.children))
+		for name, child := range n.children {
+			c.children[name] = child
+		}
+	}
+	return &c
+}
+
+func (p *parser) newID() int64 {
+	p.dump.nextID++
+	return p.dump.nextID
+}
+
+// lookup retorna o nó do caminho a partir de root, ou nil
+func lookup(root *node, path string) *node {
+	n := root
+	for _, name := range splitPath(path) {
+		if n.kind != svn.NodeDir {
+			return nil
+		}
+		if n = n.children[name]; n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+func splitPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, "/") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseProps lê um bloco de propriedades ("K n", "V n", "D n" e PROPS-END).
+// Com base, o bloco é um delta aplicado sobre ela.
+func parseProps(data []byte, base map[string]string) (map[string]string, error) {
+	props := make(map[string]string, len(base))
+	for name, value := range base {
+		props[name] = value
+	}
+	if len(data) == 0 {
+		return props, nil
+	}
+
+	r := bufio.NewReader(bytes.NewReader(data))
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("propriedades truncadas")
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "PROPS-END":
+			return props, nil
+		case strings.HasPrefix(line, "K "), strings.HasPrefix(line, "D "):
+			key, err := readCounted(r, line[2:])
+			if err != nil {
+				return nil, err
+			}
+			if line[0] == 'D' {
+				delete(props, key)
+				continue
+			}
+			line, err := r.ReadString('\n')
+			if err != nil || !strings.HasPrefix(line, "V ") {
+				return nil, fmt.Errorf("valor ausente para a propriedade %q", key)
+			}
+			if props[key], err = readCounted(r, strings.TrimSuffix(line[2:], "\n")); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("linha inesperada nas propriedades: %q", line)
+		}
+	}
+}
+
+// readCounted lê um bloco com o tamanho informado seguido de quebra de linha
+func readCounted(r *bufio.Reader, length string) (string, error) {
+	n, err := strconv.Atoi(length)
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("tamanho inválido nas propriedades: %q", length)
+	}
+	data := make([]byte, n+1)
+	if _, err := io.ReadFull(r, data); err != nil || data[n] != '\n' {
+		return "", fmt.Errorf("propriedades truncadas")
+	}
+	return string(data[:n]), nil
+}
+
+func has(headers map[string]string, name string) bool {
+	_, ok := headers[name]
+	return ok
+}
+
+// number lê um cabeçalho numérico, ou 0 se ausente
+func number(headers map[string]string, name string) (int64, error) {
+	value, ok := headers[name]
+	if !ok {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("cabeçalho %s inválido: %q", name, value)
+	}
+	return n, nil
+}
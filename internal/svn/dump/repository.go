@@ -0,0 +1,298 @@
+package dump
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"svndiff/internal/svn"
+)
+
+// Scheme é o prefixo das URLs que apontam para um arquivo de dump
+const Scheme = "dump://"
+
+// Repository é um caminho dentro de um dump, implementando svn.Repository
+type Repository struct {
+	dump *Dump
+	base string
+}
+
+// cache guarda os dumps já lidos, para que as duas branches de uma
+// comparação compartilhem a mesma leitura
+var cache = struct {
+	sync.Mutex
+	dumps map[string]*cached
+}{dumps: map[string]*cached{}}
+
+type cached struct {
+	dump    *Dump
+	size    int64
+	modTime time.Time
+}
+
+// Open abre o dump indicado pela URL dump://arquivo/caminho. O arquivo é o
+// maior prefixo do caminho que existe no disco; o restante é o caminho
+// dentro do repositório.
+func Open(rawURL string) (*Repository, error) {
+	file, base, err := splitURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	d, err := load(file)
+	if err != nil {
+		return nil, err
+	}
+	return &Repository{dump: d, base: base}, nil
+}
+
+// windowsDrive reconhece "/C:/..." em URLs dump:///C:/...
+var windowsDrive = regexp.MustCompile(`^/[A-Za-z]:/`)
+
+// splitURL separa o arquivo de dump do caminho dentro do repositório
+func splitURL(rawURL string) (string, string, error) {
+	rest, ok := strings.CutPrefix(rawURL, Scheme)
+	if !ok {
+		return "", "", fmt.Errorf("URL de dump inválida: %s", rawURL)
+	}
+	if windowsDrive.MatchString(rest) {
+		rest = rest[1:]
+	}
+
+	names := strings.Split(rest, "/")
+	for i := 1; i <= len(names); i++ {
+		candidate := strings.Join(names[:i], "/")
+		if candidate == "" {
+			continue
+		}
+		info, err := os.Stat(filepath.FromSlash(candidate))
+		if err != nil {
+			break
+		}
+		if info.Mode().IsRegular() {
+			return filepath.FromSlash(candidate), strings.Trim(strings.Join(names[i:], "/"), "/"), nil
+		}
+	}
+	return "", "", fmt.Errorf("arquivo de dump não encontrado em %s", rawURL)
+}
+
+// load lê o dump ou o reaproveita do cache, se o arquivo não mudou
+func load(file string) (*Dump, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Lock()
+	defer cache.Unlock()
+	if c, ok := cache.dumps[abs]; ok && c.size == info.Size() && c.modTime.Equal(info.ModTime()) {
+		return c.dump, nil
+	}
+
+	d, err := parse(abs)
+	if err != nil {
+		return nil, err
+	}
+	if old, ok := cache.dumps[abs]; ok {
+		old.dump.file.Close()
+	}
+	cache.dumps[abs] = &cached{dump: d, size: info.Size(), modTime: info.ModTime()}
+	return d, nil
+}
+
+// Close não fecha o dump, que permanece no cache
+func (r *Repository) Close() error {
+	return nil
+}
+
+// UUID retorna o identificador do repositório gravado no dump
+func (r *Repository) UUID() string {
+	return r.dump.uuid
+}
+
+// LatestRevision retorna a última revisão do dump
+func (r *Repository) LatestRevision() (int64, error) {
+	return r.dump.latest, nil
+}
+
+// DatedRevision retorna a última revisão com data anterior ou igual à
+// informada
+func (r *Repository) DatedRevision(date time.Time) (int64, error) {
+	found := r.dump.first
+	for rev := r.dump.first; rev <= r.dump.latest; rev++ {
+		revision, ok := r.dump.revs[rev]
+		if !ok {
+			continue
+		}
+		revDate, err := time.Parse(time.RFC3339Nano, revision.props["svn:date"])
+		if err != nil {
+			continue
+		}
+		if revDate.After(date) {
+			break
+		}
+		found = rev
+	}
+	return found, nil
+}
+
+// CheckPath retorna o tipo do nó no caminho e revisão informados
+func (r *Repository) CheckPath(path string, rev int64) (svn.NodeKind, error) {
+	revision, err := r.dump.revision(rev)
+	if err != nil {
+		return "", err
+	}
+	n := lookup(revision.root, r.fullPath(path))
+	if n == nil {
+		return svn.NodeNone, nil
+	}
+	return n.kind, nil
+}
+
+// GetDir lista um diretório com suas propriedades. O ID das entradas
+// identifica o nó no dump, compartilhado entre as revisões e as cópias.
+func (r *Repository) GetDir(path string, rev int64) (*svn.Dir, error) {
+	n, err := r.node(path, rev, svn.NodeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := &svn.Dir{Props: copyProps(n.props)}
+	for name, child := range n.children {
+		entry := svn.DirEntry{Name: name, Kind: child.kind, CreatedRev: child.rev, ID: r.dump.nodeID(child)}
+		if child.kind == svn.NodeFile {
+			entry.Size = child.text.fullSize()
+		}
+		dir.Entries = append(dir.Entries, entry)
+	}
+	sort.Slice(dir.Entries, func(i, j int) bool { return dir.Entries[i].Name < dir.Entries[j].Name })
+	return dir, nil
+}
+
+// GetFile obtém o checksum, as propriedades e, opcionalmente, o conteúdo de
+// um arquivo
+func (r *Repository) GetFile(path string, rev int64, withContent bool) (*svn.File, error) {
+	n, err := r.node(path, rev, svn.NodeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &svn.File{Checksum: n.text.checksum(), Props: copyProps(n.props)}
+	if withContent {
+		if file.Content, err = n.text.content(); err != nil {
+			return nil, fmt.Errorf("erro ao ler '%s': %w", path, err)
+		}
+	}
+	return file, nil
+}
+
+// GetLog obtém as entradas de log do caminho entre start e end, em ordem
+// decrescente quando start > end
+func (r *Repository) GetLog(path string, start, end int64, limit int) ([]svn.LogEntry, error) {
+	revs, err := svn.HistoryRevisions(r.fullPath(path), start, end, limit, r.dump.changes)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]svn.LogEntry, 0, len(revs))
+	for _, rev := range revs {
+		revision, err := r.dump.revision(rev)
+		if err != nil {
+			return nil, err
+		}
+		entry := svn.LogEntry{
+			Revision: fmt.Sprint(rev),
+			Author:   revision.props["svn:author"],
+			Message:  revision.props["svn:log"],
+		}
+		if date, err := time.Parse(time.RFC3339Nano, revision.props["svn:date"]); err == nil {
+			entry.Date = date
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// node busca o nó do caminho exigindo o tipo informado
+func (r *Repository) node(path string, rev int64, kind svn.NodeKind) (*node, error) {
+	revision, err := r.dump.revision(rev)
+	if err != nil {
+		return nil, err
+	}
+	n := lookup(revision.root, r.fullPath(path))
+	if n == nil {
+		return nil, fmt.Errorf("caminho não encontrado na revisão %d: %s", rev, r.fullPath(path))
+	}
+	if n.kind != kind {
+		return nil, fmt.Errorf("'%s' não é um %s", r.fullPath(path), kindName(kind))
+	}
+	return n, nil
+}
+
+// ChangedPaths retorna os caminhos alterados na revisão, com a origem das
+// cópias
+func (r *Repository) ChangedPaths(rev int64) ([]svn.ChangedPath, error) {
+	return r.dump.changes(rev)
+}
+
+// BasePath retorna o caminho absoluto da URL aberta no repositório
+func (r *Repository) BasePath() string {
+	return "/" + strings.Trim(r.base, "/")
+}
+
+// fullPath junta o caminho relativo à base da URL
+func (r *Repository) fullPath(path string) string {
+	return strings.Trim(r.base+"/"+path, "/")
+}
+
+// revision retorna o estado do repositório na revisão. Revisões anteriores à
+// primeira de um dump incremental não estão disponíveis.
+func (d *Dump) revision(rev int64) (*revision, error) {
+	revision, ok := d.revs[rev]
+	if !ok {
+		return nil, fmt.Errorf("revisão %d fora do dump (r%d a r%d)", rev, d.first, d.latest)
+	}
+	return revision, nil
+}
+
+// changes retorna os caminhos alterados na revisão, para o histórico
+func (d *Dump) changes(rev int64) ([]svn.ChangedPath, error) {
+	if rev < d.first {
+		return nil, nil
+	}
+	revision, err := d.revision(rev)
+	if err != nil {
+		return nil, err
+	}
+	return revision.changes, nil
+}
+
+// nodeID identifica o nó de forma única entre dumps diferentes de um mesmo
+// repositório, que compartilham o UUID
+func (d *Dump) nodeID(n *node) string {
+	return fmt.Sprintf("%s#%d", d.path, n.id)
+}
+
+func copyProps(props map[string]string) map[string]string {
+	c := make(map[string]string, len(props))
+	for name, value := range props {
+		c[name] = value
+	}
+	return c
+}
+
+// kindName descreve o tipo de nó nas mensagens de erro
+func kindName(kind svn.NodeKind) string {
+	if kind == svn.NodeDir {
+		return "diretório"
+	}
+	return "arquivo"
+}
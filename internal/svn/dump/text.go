@@ -0,0 +1,90 @@
+package dump
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"svndiff/internal/svn/delta"
+)
+
+// text é o conteúdo de um arquivo gravado no dump: texto completo ou delta
+// svndiff sobre o texto anterior do nó
+type text struct {
+	dump   *Dump
+	offset int64
+	length int64
+	delta  bool
+	base   *text
+	md5    string
+
+	// size e sum são calculados na primeira leitura do texto completo
+	once sync.Once
+	size int64
+	sum  string
+}
+
+// content reconstrói o texto completo, aplicando os deltas em cadeia
+func (t *text) content() ([]byte, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	data := make([]byte, t.length)
+	if _, err := t.dump.file.ReadAt(data, t.offset); err != nil {
+		return nil, fmt.Errorf("erro ao ler o texto na posição %d: %w", t.offset, err)
+	}
+	if !t.delta {
+		return data, nil
+	}
+
+	base, err := t.base.content()
+	if err != nil {
+		return nil, err
+	}
+	full, err := delta.Apply(base, data)
+	if err != nil {
+		return nil, fmt.Errorf("delta inválido na posição %d: %w", t.offset, err)
+	}
+	return full, nil
+}
+
+// fullSize retorna o tamanho do texto completo. Deltas e textos sem MD5 no
+// dump são lidos uma vez para obter o tamanho e o checksum.
+func (t *text) fullSize() int64 {
+	if t == nil {
+		return 0
+	}
+	if !t.delta && t.md5 != "" {
+		return t.length
+	}
+	t.measure()
+	return t.size
+}
+
+// checksum retorna o MD5 do texto completo
+func (t *text) checksum() string {
+	if t == nil {
+		sum := md5.Sum(nil)
+		return hex.EncodeToString(sum[:])
+	}
+	if t.md5 != "" {
+		return t.md5
+	}
+	t.measure()
+	return t.sum
+}
+
+// measure lê o texto completo uma única vez
+func (t *text) measure() {
+	t.once.Do(func() {
+		content, err := t.content()
+		if err != nil {
+			t.size = -1
+			return
+		}
+		sum := md5.Sum(content)
+		t.size, t.sum = int64(len(content)), hex.EncodeToString(sum[:])
+	})
+}
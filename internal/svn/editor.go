@@ -0,0 +1,80 @@
+package svn
+
+// EditorNode acompanha um diretório ou arquivo aberto durante um editor de
+// diferenças
+type EditorNode struct {
+	Path  string
+	Kind  NodeKind
+	Added bool
+	// Text indica que o conteúdo do arquivo mudou
+	Text bool
+	// Props indica que alguma propriedade versionada mudou
+	Props bool
+}
+
+// SummaryEditor converte os eventos de um editor de diferenças (comando diff
+// do ra_svn ou update-report do WebDAV) em mudanças no formato do
+// svn diff --summarize
+type SummaryEditor struct {
+	changes []Change
+}
+
+// Open registra a abertura ou adição de um nó. Diretórios adicionados são
+// relatados imediatamente, antes do seu conteúdo.
+func (e *SummaryEditor) Open(path string, kind NodeKind, added bool) *EditorNode {
+	if added && kind == NodeDir {
+		e.changes = append(e.changes, Change{Path: path, Kind: NodeDir, Text: 'A', Props: ' '})
+	}
+	return &EditorNode{Path: path, Kind: kind, Added: added}
+}
+
+// Delete registra a remoção de um caminho. O tipo do nó não é informado
+// pelos editores e deve ser resolvido depois com ResolveDeleted.
+func (e *SummaryEditor) Delete(path string) {
+	e.changes = append(e.changes, Change{Path: path, Text: 'D', Props: ' '})
+}
+
+// ChangeProp registra a mudança de uma propriedade, ignorando as internas
+func (e *SummaryEditor) ChangeProp(node *EditorNode, name string) {
+	if node != nil && IsRegularProp(name) {
+		node.Props = true
+	}
+}
+
+// Close registra a mudança de um nó ao ser fechado
+func (e *SummaryEditor) Close(node *EditorNode) {
+	change := Change{Path: displayPath(node.Path), Kind: node.Kind, Text: ' ', Props: ' '}
+	switch {
+	case node.Added && node.Kind == NodeDir:
+		return
+	case node.Added:
+		change.Text = 'A'
+	default:
+		if node.Text {
+			change.Text = 'M'
+		}
+		if node.Props {
+			change.Props = 'M'
+		}
+		if change.Text == ' ' && change.Props == ' ' {
+			return
+		}
+	}
+	e.changes = append(e.changes, change)
+}
+
+// ResolveDeleted preenche o tipo dos caminhos removidos consultando a árvore
+// de origem, e retorna as mudanças registradas
+func (e *SummaryEditor) ResolveDeleted(kindOf func(path string) (NodeKind, error)) ([]Change, error) {
+	for i, change := range e.changes {
+		if change.Kind != "" {
+			continue
+		}
+		kind, err := kindOf(change.Path)
+		if err != nil {
+			return nil, err
+		}
+		e.changes[i].Kind = kind
+	}
+	return e.changes, nil
+}
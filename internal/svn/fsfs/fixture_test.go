@@ -0,0 +1,598 @@
+package fsfs
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"svndiff/internal/svn"
+)
+
+// fixtureOp é uma operação aplicada sobre a árvore da revisão anterior
+type fixtureOp struct {
+	action  string // add-file, add-dir, modify, delete, copy ou propset
+	path    string
+	content string
+	from    string
+	fromRev int64
+	prop    string
+	value   string
+}
+
+// fixtureRev descreve uma revisão do repositório de teste
+type fixtureRev struct {
+	author, date, log string
+	ops               []fixtureOp
+}
+
+// fixtureOptions define o formato gravado
+type fixtureOptions struct {
+	format    int
+	shardSize int64
+	logical   bool
+	// packed é a primeira revisão não empacotada (min-unpacked-rev)
+	packed int64
+}
+
+// tnode é um nó da árvore em memória usada para gravar as revisões
+type tnode struct {
+	kind     svn.NodeKind
+	content  string
+	props    map[string]string
+	children map[string]*tnode
+
+	id      string
+	nodeID  int
+	text    *fixtureRep
+	pred    *fixtureRep
+	propRep *fixtureRep
+}
+
+type fixtureRep struct {
+	rev, item, size int64
+	content         string
+}
+
+// revData é o conteúdo gravado de uma revisão, antes dos índices
+type revData struct {
+	data          []byte
+	items         map[int64]int64
+	root, changes int64
+	props         map[string]string
+}
+
+type fixtureWriter struct {
+	opts     fixtureOptions
+	roots    []*tnode
+	revs     []*revData
+	cur      *revData
+	rev      int64
+	nextItem int64
+	nextNode int
+}
+
+// writeFixture grava em dir um repositório FSFS com as revisões informadas
+func writeFixture(t *testing.T, dir string, opts fixtureOptions, revisions []fixtureRev) {
+	t.Helper()
+	w := &fixtureWriter{opts: opts}
+
+	// Revisão 0: apenas a raiz vazia
+	w.begin(0, map[string]string{"svn:date": "2024-03-01T08:00:00.000000Z"})
+	root := &tnode{kind: svn.NodeDir, children: map[string]*tnode{}}
+	w.writeNode(root, "/")
+	w.finish(root, nil)
+
+	for i, rev := range revisions {
+		w.begin(int64(i+1), map[string]string{"svn:author": rev.author, "svn:date": rev.date, "svn:log": rev.log})
+		root := clone(w.roots[len(w.roots)-1])
+		for _, op := range rev.ops {
+			if err := w.apply(root, op); err != nil {
+				t.Fatalf("revisão %d: %v", i+1, err)
+			}
+		}
+		w.writeNode(root, "/")
+		w.finish(root, rev.ops)
+	}
+
+	if err := w.emit(dir); err != nil {
+		t.Fatalf("gravação do repositório: %v", err)
+	}
+}
+
+func clone(n *tnode) *tnode {
+	c := *n
+	c.id = ""
+	if n.kind == svn.NodeFile {
+		c.pred, c.text = n.text, nil
+	} else {
+		c.text = nil
+		c.children = make(map[string]*tnode, len(n.children))
+		for name, child := range n.children {
+			c.children[name] = child
+		}
+	}
+	return &c
+}
+
+// mutable retorna o nó do caminho, copiando os nós já gravados ao longo dele
+func mutable(root *tnode, path string) (*tnode, error) {
+	node := root
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		if name == "" {
+			continue
+		}
+		child, ok := node.children[name]
+		if !ok {
+			return nil, fmt.Errorf("caminho inexistente: %s", path)
+		}
+		if child.id != "" {
+			child = clone(child)
+			node.children[name] = child
+		}
+		node = child
+	}
+	return node, nil
+}
+
+// find retorna o nó do caminho sem copiá-lo
+func find(root *tnode, path string) *tnode {
+	node := root
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		if name != "" {
+			node = node.children[name]
+		}
+	}
+	return node
+}
+
+func (w *fixtureWriter) apply(root *tnode, op fixtureOp) error {
+	parentPath, name := filepath.Split(strings.Trim(op.path, "/"))
+	parent, err := mutable(root, parentPath)
+	if err != nil {
+		return err
+	}
+
+	switch op.action {
+	case "add-file":
+		w.nextNode++
+		parent.children[name] = &tnode{kind: svn.NodeFile, content: op.content, nodeID: w.nextNode}
+	case "add-dir":
+		w.nextNode++
+		parent.children[name] = &tnode{kind: svn.NodeDir, children: map[string]*tnode{}, nodeID: w.nextNode}
+	case "copy":
+		w.nextNode++
+		source := clone(find(w.roots[op.fromRev], op.from))
+		source.nodeID = w.nextNode
+		parent.children[name] = source
+	case "delete":
+		delete(parent.children, name)
+	case "modify", "propset":
+		node, err := mutable(root, op.path)
+		if err != nil {
+			return err
+		}
+		if op.action == "modify" {
+			node.content = op.content
+		} else {
+			props := map[string]string{op.prop: op.value}
+			for k, v := range node.props {
+				if k != op.prop {
+					props[k] = v
+				}
+			}
+			node.props, node.propRep = props, nil
+		}
+	default:
+		return fmt.Errorf("operação desconhecida: %s", op.action)
+	}
+	return nil
+}
+
+func (w *fixtureWriter) begin(rev int64, props map[string]string) {
+	w.rev = rev
+	w.cur = &revData{items: map[int64]int64{}, props: props}
+	w.nextItem = 3
+}
+
+// allocate reserva o item de um dado gravado na posição atual
+func (w *fixtureWriter) allocate(item int64) int64 {
+	off := int64(len(w.cur.data))
+	if !w.opts.logical {
+		return off
+	}
+	if item == 0 {
+		item = w.nextItem
+		w.nextItem++
+	}
+	w.cur.items[item] = off
+	return item
+}
+
+// writeRep grava uma representação: PLAIN, delta contra vazio ou delta
+// contra a representação anterior do arquivo
+func (w *fixtureWriter) writeRep(content string, pred *fixtureRep, plain bool) *fixtureRep {
+	item := w.allocate(0)
+	var header string
+	var data []byte
+	switch {
+	case plain:
+		header, data = "PLAIN\n", []byte(content)
+	case pred != nil:
+		header = fmt.Sprintf("DELTA %d %d %d\n", pred.rev, pred.item, pred.size)
+		data = encodeDelta(pred.content, content, true)
+	default:
+		header, data = "DELTA\n", encodeDelta("", content, false)
+	}
+	w.cur.data = append(w.cur.data, header...)
+	w.cur.data = append(w.cur.data, data...)
+	w.cur.data = append(w.cur.data, "ENDREP\n"...)
+	return &fixtureRep{rev: w.rev, item: item, size: int64(len(data)), content: content}
+}
+
+// writeNode grava o nó e seus filhos ainda não gravados
+func (w *fixtureWriter) writeNode(n *tnode, path string) {
+	if n.id != "" {
+		return
+	}
+
+	if n.kind == svn.NodeDir {
+		var hash strings.Builder
+		for _, name := range sortedNames(n.children) {
+			child := n.children[name]
+			w.writeNode(child, strings.TrimSuffix(path, "/")+"/"+name)
+			value := string(child.kind) + " " + child.id
+			fmt.Fprintf(&hash, "K %d\n%s\nV %d\n%s\n", len(name), name, len(value), value)
+		}
+		hash.WriteString("END\n")
+		n.text = w.writeRep(hash.String(), nil, true)
+	} else if n.text == nil {
+		n.text = w.writeRep(n.content, n.pred, n.pred == nil && len(n.content)%2 == 0)
+	}
+
+	if n.propRep == nil && len(n.props) > 0 {
+		var hash strings.Builder
+		for _, name := range sortedNames(n.props) {
+			fmt.Fprintf(&hash, "K %d\n%s\nV %d\n%s\n", len(name), name, len(n.props[name]), n.props[name])
+		}
+		hash.WriteString("END\n")
+		n.propRep = w.writeRep(hash.String(), nil, true)
+	}
+
+	item := int64(0)
+	if path == "/" {
+		item = itemRootNode
+	}
+	item = w.allocate(item)
+	if path == "/" {
+		w.cur.root = item
+	}
+	n.id = fmt.Sprintf("%d.0.r%d/%d", n.nodeID, w.rev, item)
+
+	var noderev strings.Builder
+	fmt.Fprintf(&noderev, "id: %s\ntype: %s\ncount: 0\n", n.id, n.kind)
+	if n.text != nil {
+		fmt.Fprintf(&noderev, "text: %s\n", repLine(n.text, w.opts.format))
+	}
+	if n.propRep != nil {
+		fmt.Fprintf(&noderev, "props: %s\n", repLine(n.propRep, w.opts.format))
+	}
+	fmt.Fprintf(&noderev, "cpath: %s\n\n", path)
+	w.cur.data = append(w.cur.data, noderev.String()...)
+}
+
+func repLine(rep *fixtureRep, format int) string {
+	line := fmt.Sprintf("%d %d %d %d %x", rep.rev, rep.item, rep.size, len(rep.content), md5.Sum([]byte(rep.content)))
+	if format >= 7 {
+		line += " 0000000000000000000000000000000000000000 0-0/_1"
+	}
+	return line
+}
+
+// finish grava a lista de mudanças e o trailer da revisão
+func (w *fixtureWriter) finish(root *tnode, ops []fixtureOp) {
+	item := w.allocate(itemChanges)
+	w.cur.changes = item
+	for _, op := range ops {
+		node := find(root, op.path)
+		id, kind := "_0.0.t0-0", svn.NodeFile
+		if node != nil {
+			id, kind = node.id, node.kind
+		} else if prev := find(w.roots[len(w.roots)-1], op.path); prev != nil {
+			kind = prev.kind
+		}
+
+		action, text, props, copyLine := "modify", "false", "false", ""
+		switch op.action {
+		case "add-file", "add-dir":
+			action, text = "add", "true"
+		case "copy":
+			action, copyLine = "add", fmt.Sprintf("%d %s", op.fromRev, op.from)
+		case "delete":
+			action = "delete"
+		case "modify":
+			text = "true"
+		case "propset":
+			props = "true"
+		}
+
+		fields := []string{id, action + "-" + string(kind), text, props}
+		if w.opts.format >= 7 {
+			fields = append(fields, "false")
+		}
+		fields = append(fields, op.path)
+		w.cur.data = append(w.cur.data, strings.Join(fields, " ")+"\n"+copyLine+"\n"...)
+	}
+
+	if w.opts.logical {
+		w.cur.data = append(w.cur.data, '\n')
+	} else {
+		w.cur.data = append(w.cur.data, fmt.Sprintf("\n%d %d\n", w.cur.root, w.cur.changes)...)
+	}
+	w.roots = append(w.roots, root)
+	w.revs = append(w.revs, w.cur)
+}
+
+// emit grava os arquivos do repositório
+func (w *fixtureWriter) emit(dir string) error {
+	opts := w.opts
+	db := filepath.Join(dir, "db")
+	youngest := int64(len(w.revs) - 1)
+
+	format := fmt.Sprintf("%d\n", opts.format)
+	if opts.format >= 3 {
+		if opts.shardSize > 0 {
+			format += fmt.Sprintf("layout sharded %d\n", opts.shardSize)
+		} else {
+			format += "layout linear\n"
+		}
+	}
+	if opts.format >= 7 {
+		addressing := "physical"
+		if opts.logical {
+			addressing = "logical"
+		}
+		format += "addressing " + addressing + "\n"
+	}
+
+	files := map[string]string{
+		"format":          "5\n",
+		"db/format":       format,
+		"db/fs-type":      "fsfs\n",
+		"db/uuid":         "0b5ab84c-96a6-4e52-b6c6-3d3e0b3f0f0a\n",
+		"db/current":      fmt.Sprintf("%d\n", youngest),
+		"db/rep-cache.db": "",
+	}
+	if opts.format >= 4 {
+		files["db/min-unpacked-rev"] = fmt.Sprintf("%d\n", opts.packed)
+	}
+	if opts.format == 6 {
+		files["db/min-unpacked-revprop"] = fmt.Sprintf("%d\n", opts.packed)
+	}
+
+	shardDir := func(kind string, rev int64) string {
+		if opts.shardSize == 0 {
+			return kind
+		}
+		return fmt.Sprintf("%s/%d", kind, rev/opts.shardSize)
+	}
+
+	for rev := int64(0); rev <= youngest; rev++ {
+		if rev >= opts.packed {
+			files[fmt.Sprintf("db/%s/%d", shardDir("revs", rev), rev)] = string(w.revFile([]int64{rev}))
+		}
+		if rev == 0 || rev >= opts.packed {
+			files[fmt.Sprintf("db/%s/%d", shardDir("revprops", rev), rev)] = serializeHash(w.revs[rev].props)
+		}
+	}
+
+	for first := int64(0); first < opts.packed; first += opts.shardSize {
+		var revs []int64
+		for rev := first; rev < first+opts.shardSize; rev++ {
+			revs = append(revs, rev)
+		}
+		packDir := fmt.Sprintf("db/revs/%d.pack", first/opts.shardSize)
+
+		if opts.logical {
+			files[packDir+"/pack"] = string(w.revFile(revs))
+		} else {
+			var pack []byte
+			var manifest strings.Builder
+			for _, rev := range revs {
+				fmt.Fprintf(&manifest, "%d\n", len(pack))
+				pack = append(pack, w.revFile([]int64{rev})...)
+			}
+			files[packDir+"/pack"] = string(pack)
+			files[packDir+"/manifest"] = manifest.String()
+		}
+
+		propDir := fmt.Sprintf("db/revprops/%d.pack", first/opts.shardSize)
+		var header, body strings.Builder
+		fmt.Fprintf(&header, "%d\n%d\n", first, len(revs))
+		for _, rev := range revs {
+			hash := serializeHash(w.revs[rev].props)
+			fmt.Fprintf(&header, "%d\n", len(hash))
+			body.WriteString(hash)
+		}
+		name := fmt.Sprintf("%d.0", first)
+		files[propDir+"/"+name] = string(compressBlock(header.String() + "\n" + body.String()))
+		files[propDir+"/manifest"] = strings.Repeat(name+"\n", len(revs))
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return os.MkdirAll(filepath.Join(db, "transactions"), 0o755)
+}
+
+// revFile monta o arquivo de uma ou mais revisões; com endereçamento lógico
+// acrescenta o índice l2p, um p2l fictício e o rodapé
+func (w *fixtureWriter) revFile(revs []int64) []byte {
+	if !w.opts.logical {
+		return w.revs[revs[0]].data
+	}
+
+	var data []byte
+	var offsets []map[int64]int64
+	for _, rev := range revs {
+		base := int64(len(data))
+		items := map[int64]int64{}
+		for item, off := range w.revs[rev].items {
+			items[item] = base + off
+		}
+		offsets = append(offsets, items)
+		data = append(data, w.revs[rev].data...)
+	}
+
+	l2pOffset := int64(len(data))
+	l2p := encodeL2P(revs[0], offsets, 2)
+	data = append(data, l2p...)
+	p2lOffset := int64(len(data))
+	p2l := []byte("P2L-INDEX\n\x00")
+	data = append(data, p2l...)
+
+	footer := fmt.Sprintf("%d %x %d %x", l2pOffset, md5.Sum(l2p), p2lOffset, md5.Sum(p2l))
+	data = append(data, footer...)
+	return append(data, byte(len(footer)))
+}
+
+// encodeL2P gera o índice l2p com o tamanho de página informado
+func encodeL2P(firstRev int64, revItems []map[int64]int64, pageSize int64) []byte {
+	var revPages []uint64
+	var pageSizes, pageEntries []uint64
+	var pages []byte
+
+	for _, items := range revItems {
+		var maxItem int64
+		for item := range items {
+			maxItem = max(maxItem, item)
+		}
+		count := maxItem + 1
+		pageCount := (count + pageSize - 1) / pageSize
+		revPages = append(revPages, uint64(pageCount))
+
+		for p := int64(0); p < pageCount; p++ {
+			var page []byte
+			var last int64
+			entries := min(pageSize, count-p*pageSize)
+			for i := int64(0); i < entries; i++ {
+				var value int64
+				if off, ok := items[p*pageSize+i]; ok {
+					value = off + 1
+				}
+				diff := value - last
+				last = value
+				if diff < 0 {
+					page = appendPacked(page, uint64(-1-2*diff))
+				} else {
+					page = appendPacked(page, uint64(2*diff))
+				}
+			}
+			pageSizes = append(pageSizes, uint64(len(page)))
+			pageEntries = append(pageEntries, uint64(entries))
+			pages = append(pages, page...)
+		}
+	}
+
+	out := []byte(l2pPrefix)
+	for _, v := range []uint64{uint64(firstRev), uint64(len(revItems)), uint64(pageSize), uint64(len(pageSizes))} {
+		out = appendPacked(out, v)
+	}
+	for _, v := range revPages {
+		out = appendPacked(out, v)
+	}
+	for i := range pageSizes {
+		out = appendPacked(appendPacked(out, pageSizes[i]), pageEntries[i])
+	}
+	return append(out, pages...)
+}
+
+func appendPacked(p []byte, v uint64) []byte {
+	for v >= 0x80 {
+		p = append(p, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(p, byte(v))
+}
+
+// appendUint codifica um inteiro no formato svndiff
+func appendUint(p []byte, v uint64) []byte {
+	groups := []byte{byte(v & 0x7f)}
+	for v >>= 7; v > 0; v >>= 7 {
+		groups = append(groups, byte(v&0x7f)|0x80)
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		p = append(p, groups[i])
+	}
+	return p
+}
+
+// compressBlock gera um bloco no formato svn__compress com zlib
+func compressBlock(data string) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write([]byte(data))
+	zw.Close()
+	return append(appendUint(nil, uint64(len(data))), buf.Bytes()...)
+}
+
+// encodeDelta gera um svndiff de uma janela que copia o prefixo comum da
+// origem e insere o restante; na versão 1 as seções são comprimidas
+func encodeDelta(source, target string, compressed bool) []byte {
+	common := 0
+	for common < len(source) && common < len(target) && source[common] == target[common] {
+		common++
+	}
+
+	var ins []byte
+	if common > 0 {
+		ins = appendUint(append(ins, 0<<6), uint64(common))
+		ins = appendUint(ins, 0)
+	}
+	newData := []byte(target[common:])
+	if len(newData) > 0 {
+		ins = appendUint(append(ins, 2<<6), uint64(len(newData)))
+	}
+
+	version := byte(0)
+	if compressed {
+		version = 1
+		ins = compressBlock(string(ins))
+		newData = append(appendUint(nil, uint64(len(newData))), newData...)
+	}
+
+	out := []byte{'S', 'V', 'N', version}
+	for _, v := range []uint64{0, uint64(len(source)), uint64(len(target)), uint64(len(ins)), uint64(len(newData))} {
+		out = appendUint(out, v)
+	}
+	return append(append(out, ins...), newData...)
+}
+
+func serializeHash(hash map[string]string) string {
+	var b strings.Builder
+	for _, name := range sortedNames(hash) {
+		fmt.Fprintf(&b, "K %d\n%s\nV %d\n%s\n", len(name), name, len(hash[name]), hash[name])
+	}
+	b.WriteString("END\n")
+	return b.String()
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,226 @@
+// Package fsfs lê diretamente do disco repositórios Subversion no formato
+// FSFS (formatos 1 a 8), usado para URLs file:// sem depender do comando
+// svn. Os arquivos de revisão, empacotados ou não, são interpretados com
+// endereçamento físico ou lógico; o rep-cache.db não precisa ser lido, pois
+// os nós-revisão já apontam para a representação compartilhada.
+package fsfs
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxFormat é o formato FSFS mais recente suportado
+const maxFormat = 8
+
+// FS é um sistema de arquivos FSFS aberto para leitura
+type FS struct {
+	root string
+	db   string
+
+	format     int
+	shardSize  int64
+	logical    bool
+	uuid       string
+	minPacked  int64 // revisões abaixo desta estão empacotadas
+	minPropRev int64 // revprops abaixo desta estão empacotadas
+
+	mu      sync.Mutex
+	files   map[string]*revFile
+	dirs    map[string][]dirent
+	revRoot map[int64]*nodeRev
+}
+
+// OpenFS abre o repositório FSFS no diretório root
+func OpenFS(root string) (*FS, error) {
+	fs := &FS{
+		root:    root,
+		db:      filepath.Join(root, "db"),
+		files:   make(map[string]*revFile),
+		dirs:    make(map[string][]dirent),
+		revRoot: make(map[int64]*nodeRev),
+	}
+
+	if fsType, err := os.ReadFile(filepath.Join(fs.db, "fs-type")); err == nil &&
+		strings.TrimSpace(string(fsType)) != "fsfs" {
+		return nil, fmt.Errorf("tipo de repositório não suportado: %s", strings.TrimSpace(string(fsType)))
+	}
+	if err := fs.readFormat(); err != nil {
+		return nil, err
+	}
+
+	uuid, err := readFirstLine(filepath.Join(fs.db, "uuid"))
+	if err != nil {
+		return nil, err
+	}
+	fs.uuid = uuid
+
+	if fs.minPacked, err = readRevisionFile(filepath.Join(fs.db, "min-unpacked-rev")); err != nil {
+		return nil, err
+	}
+	fs.minPropRev = fs.minPacked
+	if fs.format == 6 {
+		if fs.minPropRev, err = readRevisionFile(filepath.Join(fs.db, "min-unpacked-revprop")); err != nil {
+			return nil, err
+		}
+	}
+
+	return fs, nil
+}
+
+// readFormat interpreta db/format: número do formato seguido das opções
+// de layout e endereçamento
+func (fs *FS) readFormat() error {
+	f, err := os.Open(filepath.Join(fs.db, "format"))
+	if err != nil {
+		return fmt.Errorf("repositório FSFS inválido: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return fmt.Errorf("arquivo db/format vazio")
+	}
+	if fs.format, err = strconv.Atoi(strings.TrimSpace(scanner.Text())); err != nil {
+		return fmt.Errorf("formato FSFS inválido: %q", scanner.Text())
+	}
+	if fs.format < 1 || fs.format > maxFormat {
+		return fmt.Errorf("formato FSFS não suportado: %d", fs.format)
+	}
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 3 && fields[0] == "layout" && fields[1] == "sharded":
+			if fs.shardSize, err = strconv.ParseInt(fields[2], 10, 64); err != nil || fs.shardSize <= 0 {
+				return fmt.Errorf("layout FSFS inválido: %q", scanner.Text())
+			}
+		case len(fields) == 2 && fields[0] == "addressing":
+			fs.logical = fields[1] == "logical"
+		}
+	}
+	return scanner.Err()
+}
+
+// UUID retorna o identificador do repositório
+func (fs *FS) UUID() string {
+	return fs.uuid
+}
+
+// Youngest retorna a revisão mais recente, lida de db/current
+func (fs *FS) Youngest() (int64, error) {
+	line, err := readFirstLine(filepath.Join(fs.db, "current"))
+	if err != nil {
+		return 0, err
+	}
+	// Formatos antigos acrescentam os próximos identificadores de nó e cópia
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("arquivo db/current vazio")
+	}
+	rev, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("revisão inválida em db/current: %q", line)
+	}
+	return rev, nil
+}
+
+// shardPath retorna o caminho do arquivo de uma revisão em dir (revs ou
+// revprops), considerando o layout
+func (fs *FS) shardPath(dir string, rev int64) string {
+	if fs.shardSize == 0 {
+		return filepath.Join(fs.db, dir, strconv.FormatInt(rev, 10))
+	}
+	return filepath.Join(fs.db, dir, strconv.FormatInt(rev/fs.shardSize, 10), strconv.FormatInt(rev, 10))
+}
+
+// packPath retorna o diretório do pacote da revisão em dir
+func (fs *FS) packPath(dir string, rev int64) string {
+	return filepath.Join(fs.db, dir, fmt.Sprintf("%d.pack", rev/fs.shardSize))
+}
+
+// isPacked indica se a revisão está em um arquivo de pacote
+func (fs *FS) isPacked(rev int64) bool {
+	return fs.shardSize > 0 && rev < fs.minPacked
+}
+
+// isPackedRevprop indica se as propriedades da revisão estão empacotadas.
+// A revisão 0 nunca é empacotada.
+func (fs *FS) isPackedRevprop(rev int64) bool {
+	return fs.shardSize > 0 && rev != 0 && rev < fs.minPropRev
+}
+
+// repoRoot localiza, subindo a partir de path, o diretório raiz de um
+// repositório FSFS, retornando também o caminho restante dentro dele
+func repoRoot(path string) (string, string, error) {
+	path = filepath.Clean(path)
+	var rest []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "db", "format")); err == nil {
+			if _, err := os.Stat(filepath.Join(dir, "format")); err == nil {
+				return dir, strings.Join(rest, "/"), nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", fmt.Errorf("repositório FSFS não encontrado em %s", path)
+		}
+		rest = append([]string{filepath.Base(dir)}, rest...)
+	}
+}
+
+var windowsDrive = regexp.MustCompile(`^/[A-Za-z]:`)
+
+// localPath converte uma URL file:// em caminho local
+func localPath(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("URL inválida '%s': %w", rawURL, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("esquema não suportado pelo leitor FSFS: %s", u.Scheme)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("URLs file:// remotas não são suportadas: %s", rawURL)
+	}
+
+	path := u.Path
+	if windowsDrive.MatchString(path) {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// readFirstLine lê a primeira linha de um arquivo
+func readFirstLine(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// readRevisionFile lê um arquivo com um número de revisão, retornando 0
+// se ele não existir
+func readRevisionFile(path string) (int64, error) {
+	line, err := readFirstLine(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	rev, err := strconv.ParseInt(line, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("revisão inválida em %s: %q", filepath.Base(path), line)
+	}
+	return rev, nil
+}
@@ -0,0 +1,273 @@
+package fsfs
+
+import (
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+// history é o histórico usado nos testes: trunk criado em r1, copiado para
+// branches/x em r2, alterado na branch em r3 e no trunk em r4
+var history = []fixtureRev{
+	{author: "alice", date: "2024-03-01T10:00:00.000000Z", log: "importa trunk", ops: []fixtureOp{
+		{action: "add-dir", path: "/trunk"},
+		{action: "add-dir", path: "/branches"},
+		{action: "add-file", path: "/trunk/a.txt", content: "um\ndois\n"},
+		{action: "propset", path: "/trunk/a.txt", prop: "svn:eol-style", value: "native"},
+		{action: "add-file", path: "/trunk/old.txt", content: "velho\n"},
+		{action: "add-dir", path: "/trunk/lib"},
+		{action: "add-file", path: "/trunk/lib/x.c", content: "int x;\n"},
+	}},
+	{author: "bob", date: "2024-03-02T10:00:00.000000Z", log: "cria branch x", ops: []fixtureOp{
+		{action: "copy", path: "/branches/x", from: "/trunk", fromRev: 1},
+	}},
+	{author: "alice", date: "2024-03-03T10:00:00.000000Z", log: "PROJ-42 ajusta a branch", ops: []fixtureOp{
+		{action: "modify", path: "/branches/x/a.txt", content: "um\n2\n"},
+		{action: "add-file", path: "/branches/x/new.txt", content: "novo\n"},
+		{action: "delete", path: "/branches/x/old.txt"},
+	}},
+	{author: "carol", date: "2024-03-04T10:00:00.000000Z", log: "ajusta trunk", ops: []fixtureOp{
+		{action: "modify", path: "/trunk/a.txt", content: "um\ndois\ntres\n"},
+	}},
+}
+
+var layouts = []struct {
+	name string
+	opts fixtureOptions
+}{
+	{"formato 4 linear", fixtureOptions{format: 4}},
+	{"formato 6 empacotado", fixtureOptions{format: 6, shardSize: 2, packed: 2}},
+	{"formato 7 lógico", fixtureOptions{format: 7, shardSize: 3, logical: true}},
+	{"formato 7 lógico empacotado", fixtureOptions{format: 7, shardSize: 2, logical: true, packed: 4}},
+}
+
+func fileURL(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	if !strings.HasPrefix(u.Path, "/") {
+		u.Path = "/" + u.Path
+	}
+	return u.String()
+}
+
+func TestRepository(t *testing.T) {
+	for _, layout := range layouts {
+		t.Run(layout.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "repo")
+			writeFixture(t, dir, layout.opts, history)
+
+			repo, err := Open(fileURL(filepath.Join(dir, "branches", "x")))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer repo.Close()
+
+			if latest, err := repo.LatestRevision(); err != nil || latest != 4 {
+				t.Errorf("LatestRevision() = %d, %v; want 4", latest, err)
+			}
+			if got := repo.RootURL(); got != fileURL(dir) {
+				t.Errorf("RootURL() = %q, want %q", got, fileURL(dir))
+			}
+
+			for path, want := range map[string]svn.NodeKind{
+				"": svn.NodeDir, "a.txt": svn.NodeFile, "lib": svn.NodeDir, "old.txt": svn.NodeNone, "a.txt/x": svn.NodeNone,
+			} {
+				if kind, err := repo.CheckPath(path, 3); err != nil || kind != want {
+					t.Errorf("CheckPath(%q) = %q, %v; want %q", path, kind, err, want)
+				}
+			}
+
+			dir3, err := repo.GetDir("", 3)
+			if err != nil {
+				t.Fatalf("GetDir() error = %v", err)
+			}
+			var names []string
+			for _, entry := range dir3.Entries {
+				names = append(names, entry.Name)
+				if entry.Name == "new.txt" && (entry.Size != 5 || entry.CreatedRev != 3) {
+					t.Errorf("GetDir() new.txt = %+v", entry)
+				}
+			}
+			if want := []string{"a.txt", "lib", "new.txt"}; !reflect.DeepEqual(names, want) {
+				t.Errorf("GetDir() nomes = %v, want %v", names, want)
+			}
+
+			file, err := repo.GetFile("a.txt", 3, true)
+			if err != nil {
+				t.Fatalf("GetFile() error = %v", err)
+			}
+			if string(file.Content) != "um\n2\n" || file.Props["svn:eol-style"] != "native" {
+				t.Errorf("GetFile() = %q %v", file.Content, file.Props)
+			}
+
+			trunk, err := Open(fileURL(filepath.Join(dir, "trunk")))
+			if err != nil {
+				t.Fatalf("Open(trunk) error = %v", err)
+			}
+			defer trunk.Close()
+			file, err = trunk.GetFile("a.txt", 4, true)
+			if err != nil || string(file.Content) != "um\ndois\ntres\n" {
+				t.Errorf("GetFile(trunk@4) = %+v, %v", file, err)
+			}
+
+			dated, err := repo.DatedRevision(time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC))
+			if err != nil || dated != 2 {
+				t.Errorf("DatedRevision() = %d, %v; want 2", dated, err)
+			}
+		})
+	}
+}
+
+func TestRepository_GetLog(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "repo")
+	writeFixture(t, dir, layouts[3].opts, history)
+
+	tests := []struct {
+		name       string
+		path       string
+		start, end int64
+		limit      int
+		want       []string
+	}{
+		{"branch segue a cópia", "branches/x", 4, 1, 0, []string{"3", "2", "1"}},
+		{"trunk", "trunk", 4, 1, 0, []string{"4", "1"}},
+		{"arquivo da branch", "branches/x/a.txt", 4, 1, 0, []string{"3", "2", "1"}},
+		{"ordem crescente com limite", "branches/x", 1, 4, 2, []string{"1", "2"}},
+		{"limite", "branches/x", 4, 1, 1, []string{"3"}},
+		{"raiz", "", 4, 0, 0, []string{"4", "3", "2", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := Open(fileURL(filepath.Join(dir, filepath.FromSlash(tt.path))))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer repo.Close()
+
+			entries, err := repo.GetLog("", tt.start, tt.end, tt.limit)
+			if err != nil {
+				t.Fatalf("GetLog() error = %v", err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Revision)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetLog() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	repo, _ := Open(fileURL(filepath.Join(dir, "branches", "x")))
+	defer repo.Close()
+	entries, _ := repo.GetLog("", 2, 2, 0)
+	want := svn.LogEntry{Revision: "2", Author: "bob", Message: "cria branch x",
+		Date: time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)}
+	if len(entries) != 1 || !reflect.DeepEqual(entries[0], want) {
+		t.Errorf("GetLog(2:2) = %+v, want %+v", entries, want)
+	}
+}
+
+func TestRepositoryBackend_GetDiff(t *testing.T) {
+	for _, layout := range layouts {
+		t.Run(layout.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "repo")
+			writeFixture(t, dir, layout.opts, history)
+
+			backend := svn.NewRepositoryBackend(func(url string) (svn.Repository, error) {
+				return Open(url)
+			})
+			branchA := &config.BranchConfig{URL: fileURL(filepath.Join(dir, "trunk")), Revisions: []string{"4"}}
+			branchB := &config.BranchConfig{URL: fileURL(filepath.Join(dir, "branches", "x")), Revisions: []string{"3"}}
+
+			summary, err := backend.GetDiff(branchA, branchB, true)
+			if err != nil {
+				t.Fatalf("GetDiff(summarize) error = %v", err)
+			}
+			want := "M       a.txt\nA       new.txt\nD       old.txt\n"
+			if got := svntest.SummaryLines(t, summary.Output); got != want {
+				t.Errorf("GetDiff(summarize) = %q, want %q", got, want)
+			}
+
+			full, err := backend.GetDiff(branchA, branchB, false)
+			if err != nil {
+				t.Fatalf("GetDiff() error = %v", err)
+			}
+			if !strings.Contains(full.Output, "@@ -1,3 +1,2 @@\n um\n-dois\n-tres\n+2\n") {
+				t.Errorf("GetDiff() output inesperado:\n%s", full.Output)
+			}
+		})
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"esquema", "svn://host/repo"},
+		{"host remoto", "file://servidor/repo"},
+		{"sem repositório", fileURL(dir)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Open(tt.url); err == nil {
+				t.Errorf("Open(%q) esperava erro", tt.url)
+			}
+		})
+	}
+}
+
+// TestIntegration_Svnadmin lê repositórios criados pelo svnadmin em cada
+// formato suportado. É ignorado quando svnadmin não está instalado.
+func TestIntegration_Svnadmin(t *testing.T) {
+	dump := svntest.WriteDump([][]svntest.DumpNode{
+		{
+			{Path: "trunk", Kind: "dir", Action: "add"},
+			{Path: "branches", Kind: "dir", Action: "add"},
+			{Path: "trunk/a.txt", Kind: "file", Action: "add", Content: "um\ndois\n", Props: []string{"svn:eol-style", "native"}},
+		},
+		{
+			{Path: "branches/x", Kind: "dir", Action: "add", CopyFrom: "trunk", CopyRev: 1},
+		},
+		{
+			{Path: "branches/x/a.txt", Kind: "file", Action: "change", Content: "um\n2\n", Props: []string{"svn:eol-style", "native"}},
+			{Path: "branches/x/new.txt", Kind: "file", Action: "add", Content: "novo\n"},
+		},
+	})
+
+	for _, version := range []string{"1.6", "1.8", "1.9", "1.10"} {
+		t.Run(version, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "repo")
+			svntest.CreateRepository(t, dir, dump, "--compatible-version", version)
+
+			backend := svn.NewRepositoryBackend(func(url string) (svn.Repository, error) {
+				return Open(url)
+			})
+			branchA := &config.BranchConfig{URL: fileURL(filepath.Join(dir, "trunk")), Revisions: []string{"1"}}
+			branchB := &config.BranchConfig{URL: fileURL(filepath.Join(dir, "branches", "x")), Revisions: []string{"3"}}
+
+			summary, err := backend.GetDiff(branchA, branchB, true)
+			if err != nil {
+				t.Fatalf("GetDiff(summarize) error = %v", err)
+			}
+			if got := svntest.SummaryLines(t, summary.Output); got != "M       a.txt\nA       new.txt\n" {
+				t.Errorf("GetDiff(summarize) = %q", got)
+			}
+
+			entries, err := backend.GetLogEntries(branchB.URL, svn.LogOptions{Range: "HEAD:1"})
+			if err != nil || len(entries) != 3 {
+				t.Errorf("GetLogEntries() = %+v, %v", entries, err)
+			}
+		})
+	}
+}
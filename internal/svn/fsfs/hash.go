@@ -0,0 +1,69 @@
+package fsfs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parseHash lê um hash serializado ("K n\nchave\nV n\nvalor\n" ... "END\n"),
+// formato usado em propriedades, diretórios e revprops
+func parseHash(r *bufio.Reader) (map[string]string, error) {
+	hash := make(map[string]string)
+	for {
+		line, err := r.ReadString('\n')
+		if err == io.EOF && line == "" {
+			// Representações vazias não têm o terminador
+			return hash, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("hash truncado: %w", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "END":
+			return hash, nil
+		case strings.HasPrefix(line, "K "):
+			key, err := readCounted(r, line[2:])
+			if err != nil {
+				return nil, err
+			}
+			line, err := r.ReadString('\n')
+			if err != nil || !strings.HasPrefix(line, "V ") {
+				return nil, fmt.Errorf("valor ausente para a chave %q", key)
+			}
+			value, err := readCounted(r, strings.TrimSuffix(line[2:], "\n"))
+			if err != nil {
+				return nil, err
+			}
+			hash[key] = value
+		case strings.HasPrefix(line, "D "):
+			key, err := readCounted(r, line[2:])
+			if err != nil {
+				return nil, err
+			}
+			delete(hash, key)
+		default:
+			return nil, fmt.Errorf("linha inesperada no hash: %q", line)
+		}
+	}
+}
+
+// readCounted lê um bloco com o tamanho informado seguido de quebra de linha
+func readCounted(r *bufio.Reader, length string) (string, error) {
+	n, err := strconv.Atoi(length)
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("tamanho inválido no hash: %q", length)
+	}
+	data := make([]byte, n+1)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", fmt.Errorf("hash truncado: %w", err)
+	}
+	if data[n] != '\n' {
+		return "", fmt.Errorf("hash sem quebra de linha após %d bytes", n)
+	}
+	return string(data[:n]), nil
+}
@@ -0,0 +1,162 @@
+package fsfs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// l2pPrefix inicia o índice lógico-físico de um arquivo de revisão
+const l2pPrefix = "L2P-INDEX\n"
+
+// l2pIndex é o cabeçalho do índice que converte itens em deslocamentos nos
+// repositórios com endereçamento lógico
+type l2pIndex struct {
+	firstRev int64
+	pageSize int64
+	// revPages[i] é a primeira página da revisão firstRev+i
+	revPages []int64
+	pages    []l2pPage
+}
+
+// l2pPage localiza uma página de deslocamentos
+type l2pPage struct {
+	offset  int64
+	entries int64
+}
+
+// readL2P lê o cabeçalho do índice l2p que começa em off
+func readL2P(rf *revFile, off int64) (*l2pIndex, error) {
+	r := &countingReader{r: rf.readerAt(off)}
+
+	prefix := make([]byte, len(l2pPrefix))
+	if _, err := io.ReadFull(r, prefix); err != nil || string(prefix) != l2pPrefix {
+		return nil, fmt.Errorf("índice l2p inválido")
+	}
+
+	var header [4]uint64
+	for i := range header {
+		var err error
+		if header[i], err = readPacked(r); err != nil {
+			return nil, err
+		}
+	}
+	idx := &l2pIndex{firstRev: int64(header[0]), pageSize: int64(header[2])}
+	revCount, pageCount := int64(header[1]), int64(header[3])
+	if idx.pageSize == 0 {
+		return nil, fmt.Errorf("índice l2p com páginas vazias")
+	}
+
+	idx.revPages = make([]int64, revCount+1)
+	for i := int64(0); i < revCount; i++ {
+		count, err := readPacked(r)
+		if err != nil {
+			return nil, err
+		}
+		idx.revPages[i+1] = idx.revPages[i] + int64(count)
+	}
+	if idx.revPages[revCount] != pageCount {
+		return nil, fmt.Errorf("índice l2p inconsistente")
+	}
+
+	sizes := make([]int64, pageCount)
+	idx.pages = make([]l2pPage, pageCount)
+	for i := range idx.pages {
+		size, err := readPacked(r)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := readPacked(r)
+		if err != nil {
+			return nil, err
+		}
+		sizes[i] = int64(size)
+		idx.pages[i].entries = int64(entries)
+	}
+
+	// As páginas seguem o cabeçalho, na ordem da tabela
+	pageOffset := off + r.n
+	for i := range idx.pages {
+		idx.pages[i].offset = pageOffset
+		pageOffset += sizes[i]
+	}
+	return idx, nil
+}
+
+// lookup retorna o deslocamento do item da revisão
+func (idx *l2pIndex) lookup(rf *revFile, rev, item int64) (int64, error) {
+	i := rev - idx.firstRev
+	if i < 0 || i+1 >= int64(len(idx.revPages)) {
+		return 0, fmt.Errorf("revisão %d ausente do índice l2p", rev)
+	}
+	pageNo := idx.revPages[i] + item/idx.pageSize
+	if item < 0 || pageNo >= idx.revPages[i+1] {
+		return 0, fmt.Errorf("item %d ausente do índice l2p da revisão %d", item, rev)
+	}
+	page := idx.pages[pageNo]
+	entry := item % idx.pageSize
+	if entry >= page.entries {
+		return 0, fmt.Errorf("item %d ausente do índice l2p da revisão %d", item, rev)
+	}
+
+	// Os deslocamentos são gravados somados de 1 (0 indica item não usado),
+	// como diferenças em relação ao anterior
+	r := rf.readerAt(page.offset)
+	var last int64
+	for j := int64(0); j <= entry; j++ {
+		value, err := readPacked(r)
+		if err != nil {
+			return 0, err
+		}
+		last += decodeSigned(value)
+	}
+	if last <= 0 {
+		return 0, fmt.Errorf("item %d não usado na revisão %d", item, rev)
+	}
+	return last - 1, nil
+}
+
+// readPacked lê um inteiro em grupos de 7 bits, menos significativos primeiro
+func readPacked(r io.ByteReader) (uint64, error) {
+	var value uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, fmt.Errorf("índice truncado: %w", err)
+		}
+		value |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("inteiro inválido no índice")
+}
+
+// decodeSigned desfaz o mapeamento de inteiros com sinal em sem sinal
+// (0, -1, 1, -2, ... para 0, 1, 2, 3, ...)
+func decodeSigned(value uint64) int64 {
+	if value&1 != 0 {
+		return -1 - int64(value>>1)
+	}
+	return int64(value >> 1)
+}
+
+// countingReader conta os bytes lidos
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
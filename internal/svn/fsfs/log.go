@@ -0,0 +1,179 @@
+package fsfs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/delta"
+)
+
+// revProps lê as propriedades da revisão
+func (fs *FS) revProps(rev int64) (map[string]string, error) {
+	if fs.isPackedRevprop(rev) {
+		return fs.packedRevProps(rev)
+	}
+
+	data, err := os.ReadFile(fs.shardPath("revprops", rev))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("revisão inexistente: %d", rev)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseHash(bufio.NewReader(bytes.NewReader(data)))
+}
+
+// packedRevProps lê as propriedades de uma revisão empacotada. O manifesto
+// indica o arquivo do pacote; este, descomprimido, traz a primeira revisão,
+// a quantidade e o tamanho de cada hash, seguidos dos hashes.
+func (fs *FS) packedRevProps(rev int64) (map[string]string, error) {
+	dir := fs.packPath("revprops", rev)
+	manifest, err := os.ReadFile(filepath.Join(dir, "manifest"))
+	if err != nil {
+		return nil, err
+	}
+	names := strings.Fields(string(manifest))
+	i := rev % fs.shardSize
+	if i >= int64(len(names)) {
+		return nil, fmt.Errorf("revisão %d ausente do manifesto de revprops", rev)
+	}
+
+	packed, err := os.ReadFile(filepath.Join(dir, names[i]))
+	if err != nil {
+		return nil, err
+	}
+	data, err := delta.Decompress(packed, false)
+	if err != nil {
+		return nil, fmt.Errorf("pacote de revprops inválido: %w", err)
+	}
+
+	r := bufio.NewReader(bytes.NewReader(data))
+	var header []int64
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("pacote de revprops truncado: %w", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			break
+		}
+		n, err := strconv.ParseInt(line, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("pacote de revprops inválido: %q", line)
+		}
+		header = append(header, n)
+	}
+	if len(header) < 2 || int64(len(header)) != header[1]+2 {
+		return nil, fmt.Errorf("cabeçalho de pacote de revprops inválido")
+	}
+
+	first, sizes := header[0], header[2:]
+	if rev < first || rev-first >= int64(len(sizes)) {
+		return nil, fmt.Errorf("revisão %d ausente do pacote de revprops", rev)
+	}
+	for _, size := range sizes[:rev-first] {
+		if _, err := r.Discard(int(size)); err != nil {
+			return nil, fmt.Errorf("pacote de revprops truncado: %w", err)
+		}
+	}
+	hash := make([]byte, sizes[rev-first])
+	if _, err := io.ReadFull(r, hash); err != nil {
+		return nil, fmt.Errorf("pacote de revprops truncado: %w", err)
+	}
+	return parseHash(bufio.NewReader(bytes.NewReader(hash)))
+}
+
+// revDate retorna a data de commit da revisão
+func (fs *FS) revDate(rev int64) (time.Time, error) {
+	props, err := fs.revProps(rev)
+	if err != nil {
+		return time.Time{}, err
+	}
+	date := props["svn:date"]
+	if date == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, date)
+}
+
+// actionCodes converte as ações da lista de mudanças nos códigos do svn log
+var actionCodes = map[string]byte{"add": 'A', "delete": 'D', "modify": 'M', "replace": 'R'}
+
+// changes lê a lista de caminhos alterados na revisão. Cada mudança ocupa
+// duas linhas: "id ação texto props [mergeinfo] caminho" e a origem da cópia
+// ("REV caminho" ou vazia); uma linha vazia encerra a lista.
+func (fs *FS) changes(rev int64) ([]svn.ChangedPath, error) {
+	if rev == 0 {
+		return nil, nil
+	}
+	_, item, err := fs.rootAndChanges(rev)
+	if err != nil {
+		return nil, err
+	}
+	rf, off, err := fs.itemOffset(rev, item)
+	if err != nil {
+		return nil, err
+	}
+
+	// A partir do formato 7 há o indicador de mudança de mergeinfo
+	fieldCount := 5
+	if fs.format >= 7 {
+		fieldCount = 6
+	}
+
+	r := rf.readerAt(off)
+	var list []svn.ChangedPath
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil || line == "\n" {
+			break
+		}
+		fields := strings.SplitN(strings.TrimSuffix(line, "\n"), " ", fieldCount)
+		if len(fields) != fieldCount {
+			return nil, fmt.Errorf("lista de mudanças inválida na revisão %d: %q", rev, line)
+		}
+		action, _, _ := strings.Cut(fields[1], "-")
+		c := svn.ChangedPath{Path: fields[fieldCount-1], Action: actionCodes[action]}
+
+		copyLine, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("lista de mudanças truncada na revisão %d: %w", rev, err)
+		}
+		if copyRev, copyPath, ok := strings.Cut(strings.TrimSuffix(copyLine, "\n"), " "); ok {
+			if c.CopyRev, err = strconv.ParseInt(copyRev, 10, 64); err != nil {
+				return nil, fmt.Errorf("origem de cópia inválida na revisão %d: %q", rev, copyLine)
+			}
+			c.CopyPath = copyPath
+		}
+		list = append(list, c)
+	}
+	return list, nil
+}
+
+// logEntry monta a entrada de log da revisão
+func (fs *FS) logEntry(rev int64) (svn.LogEntry, error) {
+	props, err := fs.revProps(rev)
+	if err != nil {
+		return svn.LogEntry{}, err
+	}
+	entry := svn.LogEntry{
+		Revision: strconv.FormatInt(rev, 10),
+		Author:   props["svn:author"],
+		Message:  strings.TrimSpace(props["svn:log"]),
+	}
+	if date := props["svn:date"]; date != "" {
+		if entry.Date, err = time.Parse(time.RFC3339Nano, date); err != nil {
+			return entry, fmt.Errorf("data inválida na revisão %d: %w", rev, err)
+		}
+	}
+	return entry, nil
+}
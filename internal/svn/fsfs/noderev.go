@@ -0,0 +1,300 @@
+package fsfs
+
+import (
+	"bufio"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/delta"
+)
+
+// nodeRev é um nó-revisão: o estado de um arquivo ou diretório em uma revisão
+type nodeRev struct {
+	id    string
+	rev   int64
+	kind  svn.NodeKind
+	text  *repRef
+	props *repRef
+}
+
+// repRef aponta para uma representação (conteúdo ou propriedades)
+type repRef struct {
+	rev      int64
+	item     int64
+	size     int64 // tamanho gravado, sem cabeçalho e ENDREP
+	expanded int64 // tamanho do conteúdo reconstruído
+	md5      string
+}
+
+// dirent é uma entrada do conteúdo de um diretório
+type dirent struct {
+	name string
+	kind svn.NodeKind
+	id   string
+}
+
+// parseNodeID extrai revisão e item de um id "nó.cópia.rREV/ITEM"
+func parseNodeID(id string) (int64, int64, error) {
+	i := strings.LastIndex(id, ".r")
+	if i < 0 {
+		return 0, 0, fmt.Errorf("id de nó-revisão inválido: %s", id)
+	}
+	revText, itemText, ok := strings.Cut(id[i+2:], "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("id de nó-revisão inválido: %s", id)
+	}
+	rev, err1 := strconv.ParseInt(revText, 10, 64)
+	item, err2 := strconv.ParseInt(itemText, 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("id de nó-revisão inválido: %s", id)
+	}
+	return rev, item, nil
+}
+
+// readNodeRev lê o nó-revisão com o id informado
+func (fs *FS) readNodeRev(id string) (*nodeRev, error) {
+	rev, item, err := parseNodeID(id)
+	if err != nil {
+		return nil, err
+	}
+	return fs.readNodeRevAt(rev, item)
+}
+
+// readNodeRevAt lê o nó-revisão gravado no item da revisão
+func (fs *FS) readNodeRevAt(rev, item int64) (*nodeRev, error) {
+	rf, off, err := fs.itemOffset(rev, item)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &nodeRev{}
+	r := rf.readerAt(off)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("nó-revisão truncado na revisão %d: %w", rev, err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			break
+		}
+
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			return nil, fmt.Errorf("nó-revisão inválido na revisão %d: %q", rev, line)
+		}
+		switch key {
+		case "id":
+			node.id = value
+		case "type":
+			node.kind = svn.NodeKind(value)
+		case "text":
+			if node.text, err = parseRepRef(value); err != nil {
+				return nil, err
+			}
+		case "props":
+			if node.props, err = parseRepRef(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if node.kind != svn.NodeFile && node.kind != svn.NodeDir {
+		return nil, fmt.Errorf("nó-revisão com tipo inválido na revisão %d: %q", rev, node.kind)
+	}
+	if node.rev, _, err = parseNodeID(node.id); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// parseRepRef interpreta "REV ITEM TAMANHO EXPANDIDO MD5 [SHA1 UNIQ]"
+func parseRepRef(value string) (*repRef, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 5 {
+		return nil, fmt.Errorf("representação inválida: %q", value)
+	}
+	var numbers [4]int64
+	for i := range numbers {
+		n, err := strconv.ParseInt(fields[i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("representação inválida: %q", value)
+		}
+		numbers[i] = n
+	}
+	return &repRef{rev: numbers[0], item: numbers[1], size: numbers[2], expanded: numbers[3], md5: fields[4]}, nil
+}
+
+// readRep reconstrói o conteúdo de uma representação, aplicando a cadeia de
+// deltas até a base
+func (fs *FS) readRep(ref *repRef) ([]byte, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	rf, off, err := fs.itemOffset(ref.rev, ref.item)
+	if err != nil {
+		return nil, err
+	}
+
+	r := rf.readerAt(off)
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("representação truncada na revisão %d: %w", ref.rev, err)
+	}
+	data := make([]byte, ref.size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("representação truncada na revisão %d: %w", ref.rev, err)
+	}
+
+	var content []byte
+	fields := strings.Fields(header)
+	switch {
+	case len(fields) == 1 && fields[0] == "PLAIN":
+		content = data
+	case len(fields) >= 1 && fields[0] == "DELTA":
+		var source []byte
+		if len(fields) == 4 {
+			base := &repRef{}
+			_, err := fmt.Sscan(strings.Join(fields[1:], " "), &base.rev, &base.item, &base.size)
+			if err != nil {
+				return nil, fmt.Errorf("base de delta inválida na revisão %d: %q", ref.rev, header)
+			}
+			if source, err = fs.readRep(base); err != nil {
+				return nil, err
+			}
+		}
+		if content, err = delta.Apply(source, data); err != nil {
+			return nil, fmt.Errorf("representação inválida na revisão %d: %w", ref.rev, err)
+		}
+	default:
+		return nil, fmt.Errorf("cabeçalho de representação inválido na revisão %d: %q", ref.rev, header)
+	}
+
+	if ref.expanded > 0 && int64(len(content)) != ref.expanded {
+		return nil, fmt.Errorf("representação com %d bytes na revisão %d, esperados %d", len(content), ref.rev, ref.expanded)
+	}
+	return content, nil
+}
+
+// fileSize retorna o tamanho do conteúdo de um arquivo
+func (n *nodeRev) fileSize() int64 {
+	if n.text == nil {
+		return 0
+	}
+	if n.text.expanded == 0 {
+		return n.text.size
+	}
+	return n.text.expanded
+}
+
+// checksum retorna o MD5 do conteúdo de um arquivo
+func (n *nodeRev) checksum() string {
+	if n.text == nil {
+		return fmt.Sprintf("%x", md5.Sum(nil))
+	}
+	return n.text.md5
+}
+
+// readProps lê as propriedades de um nó
+func (fs *FS) readProps(node *nodeRev) (map[string]string, error) {
+	data, err := fs.readRep(node.props)
+	if err != nil {
+		return nil, err
+	}
+	props, err := parseHash(bufio.NewReader(strings.NewReader(string(data))))
+	if err != nil {
+		return nil, fmt.Errorf("propriedades inválidas em %s: %w", node.id, err)
+	}
+	return props, nil
+}
+
+// readDir lê as entradas de um diretório, ordenadas por nome
+func (fs *FS) readDir(node *nodeRev) ([]dirent, error) {
+	fs.mu.Lock()
+	entries, ok := fs.dirs[node.id]
+	fs.mu.Unlock()
+	if ok {
+		return entries, nil
+	}
+
+	data, err := fs.readRep(node.text)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := parseHash(bufio.NewReader(strings.NewReader(string(data))))
+	if err != nil {
+		return nil, fmt.Errorf("diretório inválido em %s: %w", node.id, err)
+	}
+
+	entries = make([]dirent, 0, len(hash))
+	for name, value := range hash {
+		kind, id, ok := strings.Cut(value, " ")
+		if !ok {
+			return nil, fmt.Errorf("entrada de diretório inválida em %s: %q", node.id, value)
+		}
+		entries = append(entries, dirent{name: name, kind: svn.NodeKind(kind), id: id})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+
+	fs.mu.Lock()
+	fs.dirs[node.id] = entries
+	fs.mu.Unlock()
+	return entries, nil
+}
+
+// rootNode retorna o nó raiz da revisão
+func (fs *FS) rootNode(rev int64) (*nodeRev, error) {
+	fs.mu.Lock()
+	root, ok := fs.revRoot[rev]
+	fs.mu.Unlock()
+	if ok {
+		return root, nil
+	}
+
+	item, _, err := fs.rootAndChanges(rev)
+	if err != nil {
+		return nil, err
+	}
+	if root, err = fs.readNodeRevAt(rev, item); err != nil {
+		return nil, err
+	}
+
+	fs.mu.Lock()
+	fs.revRoot[rev] = root
+	fs.mu.Unlock()
+	return root, nil
+}
+
+// lookup retorna o nó no caminho da revisão, ou nil se não existir
+func (fs *FS) lookup(path string, rev int64) (*nodeRev, error) {
+	node, err := fs.rootNode(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range strings.Split(path, "/") {
+		if name == "" {
+			continue
+		}
+		if node.kind != svn.NodeDir {
+			return nil, nil
+		}
+		entries, err := fs.readDir(node)
+		if err != nil {
+			return nil, err
+		}
+		i := sort.Search(len(entries), func(i int) bool { return entries[i].name >= name })
+		if i == len(entries) || entries[i].name != name {
+			return nil, nil
+		}
+		if node, err = fs.readNodeRev(entries[i].id); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
@@ -0,0 +1,206 @@
+package fsfs
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"svndiff/internal/svn"
+)
+
+// Repository é um repositório FSFS local ancorado no caminho de uma URL
+// file://, implementando svn.Repository
+type Repository struct {
+	fs   *FS
+	base string
+}
+
+// Open abre o repositório que contém o caminho da URL file://
+func Open(rawURL string) (*Repository, error) {
+	path, err := localPath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	root, base, err := repoRoot(path)
+	if err != nil {
+		return nil, err
+	}
+	fs, err := OpenFS(root)
+	if err != nil {
+		return nil, err
+	}
+	return &Repository{fs: fs, base: base}, nil
+}
+
+// Close fecha os arquivos do repositório
+func (r *Repository) Close() error {
+	return r.fs.Close()
+}
+
+// UUID retorna o identificador do repositório
+func (r *Repository) UUID() string {
+	return r.fs.UUID()
+}
+
+// RootURL retorna a URL file:// da raiz do repositório
+func (r *Repository) RootURL() string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(r.fs.root)}
+	if !strings.HasPrefix(u.Path, "/") {
+		u.Path = "/" + u.Path
+	}
+	return u.String()
+}
+
+// LatestRevision retorna a revisão mais recente do repositório
+func (r *Repository) LatestRevision() (int64, error) {
+	return r.fs.Youngest()
+}
+
+// DatedRevision retorna a revisão vigente na data informada, por busca
+// binária nas datas de commit
+func (r *Repository) DatedRevision(date time.Time) (int64, error) {
+	youngest, err := r.fs.Youngest()
+	if err != nil {
+		return 0, err
+	}
+
+	var searchErr error
+	i := sort.Search(int(youngest)+1, func(rev int) bool {
+		revDate, err := r.fs.revDate(int64(rev))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return revDate.After(date)
+	})
+	if searchErr != nil {
+		return 0, searchErr
+	}
+	return max(int64(i)-1, 0), nil
+}
+
+// CheckPath retorna o tipo do nó no caminho e revisão informados
+func (r *Repository) CheckPath(path string, rev int64) (svn.NodeKind, error) {
+	node, err := r.fs.lookup(r.fullPath(path), rev)
+	if err != nil {
+		return "", err
+	}
+	if node == nil {
+		return svn.NodeNone, nil
+	}
+	return node.kind, nil
+}
+
+// GetDir lista um diretório com suas propriedades. O ID das entradas é o do
+// nó-revisão, que permite ignorar subárvores idênticas na comparação.
+func (r *Repository) GetDir(path string, rev int64) (*svn.Dir, error) {
+	node, err := r.node(path, rev, svn.NodeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	props, err := r.fs.readProps(node)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := r.fs.readDir(node)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := &svn.Dir{Props: props}
+	for _, entry := range entries {
+		child, err := r.fs.readNodeRev(entry.id)
+		if err != nil {
+			return nil, err
+		}
+		dirEntry := svn.DirEntry{Name: entry.name, Kind: child.kind, CreatedRev: child.rev, ID: entry.id}
+		if child.kind == svn.NodeFile {
+			dirEntry.Size = child.fileSize()
+		}
+		dir.Entries = append(dir.Entries, dirEntry)
+	}
+	return dir, nil
+}
+
+// GetFile obtém o checksum, as propriedades e, opcionalmente, o conteúdo de
+// um arquivo
+func (r *Repository) GetFile(path string, rev int64, withContent bool) (*svn.File, error) {
+	node, err := r.node(path, rev, svn.NodeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	props, err := r.fs.readProps(node)
+	if err != nil {
+		return nil, err
+	}
+	file := &svn.File{Checksum: node.checksum(), Props: props}
+	if withContent {
+		if file.Content, err = r.fs.readRep(node.text); err != nil {
+			return nil, fmt.Errorf("erro ao ler '%s': %w", path, err)
+		}
+	}
+	return file, nil
+}
+
+// GetLog obtém as entradas de log do caminho entre start e end, em ordem
+// decrescente quando start > end
+func (r *Repository) GetLog(path string, start, end int64, limit int) ([]svn.LogEntry, error) {
+	revs, err := svn.HistoryRevisions(r.fullPath(path), start, end, limit, r.fs.changes)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]svn.LogEntry, 0, len(revs))
+	for _, rev := range revs {
+		entry, err := r.fs.logEntry(rev)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// node busca o nó do caminho exigindo o tipo informado
+func (r *Repository) node(path string, rev int64, kind svn.NodeKind) (*nodeRev, error) {
+	node, err := r.fs.lookup(r.fullPath(path), rev)
+	if err != nil {
+		return nil, err
+	}
+	if node == nil {
+		return nil, fmt.Errorf("caminho não encontrado na revisão %d: %s", rev, r.fullPath(path))
+	}
+	if node.kind != kind {
+		return nil, fmt.Errorf("'%s' não é um %s", r.fullPath(path), kindName(kind))
+	}
+	return node, nil
+}
+
+// ChangedPaths retorna os caminhos alterados na revisão, com a origem das
+// cópias
+func (r *Repository) ChangedPaths(rev int64) ([]svn.ChangedPath, error) {
+	return r.fs.changes(rev)
+}
+
+// BasePath retorna o caminho absoluto da URL aberta no repositório
+func (r *Repository) BasePath() string {
+	return "/" + strings.Trim(r.base, "/")
+}
+
+// fullPath junta o caminho relativo à base da URL
+func (r *Repository) fullPath(path string) string {
+	return strings.Trim(r.base+"/"+path, "/")
+}
+
+// kindName descreve o tipo de nó nas mensagens de erro
+func kindName(kind svn.NodeKind) string {
+	if kind == svn.NodeDir {
+		return "diretório"
+	}
+	return "arquivo"
+}
@@ -0,0 +1,228 @@
+package fsfs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Itens com posição fixa em revisões de endereçamento lógico
+const (
+	itemChanges  = 1
+	itemRootNode = 2
+)
+
+// trailerLength é o máximo lido do fim de uma revisão para encontrar o
+// trailer ou o rodapé do índice, cujo tamanho cabe em um byte
+const trailerLength = 256
+
+// revFile é um arquivo de revisão ou de pacote aberto
+type revFile struct {
+	f    *os.File
+	size int64
+
+	// manifest contém o início de cada revisão de um pacote com
+	// endereçamento físico
+	manifest []int64
+	// l2p é o índice lógico-físico, carregado sob demanda
+	l2p *l2pIndex
+}
+
+// readerAt retorna um leitor posicionado em off
+func (rf *revFile) readerAt(off int64) *bufio.Reader {
+	return bufio.NewReader(io.NewSectionReader(rf.f, off, rf.size-off))
+}
+
+// openRev abre o arquivo que contém a revisão, reaproveitando os já abertos
+func (fs *FS) openRev(rev int64) (*revFile, error) {
+	path := fs.shardPath("revs", rev)
+	if fs.isPacked(rev) {
+		path = filepath.Join(fs.packPath("revs", rev), "pack")
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if rf, ok := fs.files[path]; ok {
+		return rf, nil
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("revisão inexistente: %d", rev)
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	rf := &revFile{f: f, size: info.Size()}
+
+	if fs.isPacked(rev) && !fs.logical {
+		if rf.manifest, err = readManifest(filepath.Join(filepath.Dir(path), "manifest")); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	fs.files[path] = rf
+	return rf, nil
+}
+
+// readManifest lê o manifesto de um pacote: o início de cada revisão, uma
+// por linha
+func readManifest(path string) ([]int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var offsets []int64
+	for _, line := range strings.Fields(string(data)) {
+		off, err := strconv.ParseInt(line, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("manifesto inválido em %s: %q", path, line)
+		}
+		offsets = append(offsets, off)
+	}
+	return offsets, nil
+}
+
+// revBounds retorna o início e o fim da revisão dentro do seu arquivo
+func (fs *FS) revBounds(rf *revFile, rev int64) (int64, int64, error) {
+	if rf.manifest == nil {
+		return 0, rf.size, nil
+	}
+	i := rev % fs.shardSize
+	if i >= int64(len(rf.manifest)) {
+		return 0, 0, fmt.Errorf("revisão %d ausente do manifesto do pacote", rev)
+	}
+	end := rf.size
+	if i+1 < int64(len(rf.manifest)) {
+		end = rf.manifest[i+1]
+	}
+	return rf.manifest[i], end, nil
+}
+
+// itemOffset localiza um item da revisão: com endereçamento físico o item é
+// o deslocamento relativo ao início da revisão; com endereçamento lógico é
+// resolvido pelo índice l2p
+func (fs *FS) itemOffset(rev, item int64) (*revFile, int64, error) {
+	rf, err := fs.openRev(rev)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if fs.logical {
+		idx, err := fs.loadL2P(rf)
+		if err != nil {
+			return nil, 0, err
+		}
+		off, err := idx.lookup(rf, rev, item)
+		return rf, off, err
+	}
+
+	start, _, err := fs.revBounds(rf, rev)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rf, start + item, nil
+}
+
+// loadL2P carrega o índice l2p do arquivo, localizado pelo rodapé
+func (fs *FS) loadL2P(rf *revFile) (*l2pIndex, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if rf.l2p != nil {
+		return rf.l2p, nil
+	}
+
+	tail, err := readTail(rf, rf.size)
+	if err != nil {
+		return nil, err
+	}
+	footerLen := int(tail[len(tail)-1])
+	if footerLen+1 > len(tail) {
+		return nil, fmt.Errorf("rodapé de revisão inválido")
+	}
+	fields := strings.Fields(string(tail[len(tail)-1-footerLen : len(tail)-1]))
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("rodapé de revisão inválido: %q", tail)
+	}
+	l2pOffset, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("rodapé de revisão inválido: %q", tail)
+	}
+
+	if rf.l2p, err = readL2P(rf, l2pOffset); err != nil {
+		return nil, err
+	}
+	return rf.l2p, nil
+}
+
+// rootAndChanges retorna os itens do nó raiz e da lista de mudanças da
+// revisão. Com endereçamento físico eles estão no trailer
+// "\n<raiz> <mudanças>\n" ao fim da revisão.
+func (fs *FS) rootAndChanges(rev int64) (int64, int64, error) {
+	if fs.logical {
+		return itemRootNode, itemChanges, nil
+	}
+
+	rf, err := fs.openRev(rev)
+	if err != nil {
+		return 0, 0, err
+	}
+	start, end, err := fs.revBounds(rf, rev)
+	if err != nil {
+		return 0, 0, err
+	}
+	tail, err := readTail(rf, end)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tail = bytes.TrimSuffix(tail, []byte("\n"))
+	if i := bytes.LastIndexByte(tail, '\n'); i >= 0 {
+		tail = tail[i+1:]
+	}
+	fields := strings.Fields(string(tail))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("trailer inválido na revisão %d", rev)
+	}
+	root, err1 := strconv.ParseInt(fields[0], 10, 64)
+	changes, err2 := strconv.ParseInt(fields[1], 10, 64)
+	if err1 != nil || err2 != nil || root >= end-start {
+		return 0, 0, fmt.Errorf("trailer inválido na revisão %d", rev)
+	}
+	return root, changes, nil
+}
+
+// readTail lê os últimos bytes antes de end
+func readTail(rf *revFile, end int64) ([]byte, error) {
+	start := max(end-trailerLength, 0)
+	tail := make([]byte, end-start)
+	if _, err := rf.f.ReadAt(tail, start); err != nil {
+		return nil, err
+	}
+	if len(tail) == 0 {
+		return nil, fmt.Errorf("arquivo de revisão vazio")
+	}
+	return tail, nil
+}
+
+// Close fecha os arquivos abertos
+func (fs *FS) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for path, rf := range fs.files {
+		rf.f.Close()
+		delete(fs.files, path)
+	}
+	return nil
+}
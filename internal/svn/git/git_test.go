@@ -0,0 +1,419 @@
+package git
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/dump"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("0123456789abcdef")
+	tests := []struct {
+		name    string
+		delta   []byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "cópia e inserção",
+			delta: []byte{16, 9, 0x91, 2, 4, 3, 'x', 'y', 'z', 0x90, 2},
+			want:  "2345xyz01",
+		},
+		{name: "base com tamanho errado", delta: []byte{15, 1, 1, 'x'}, wantErr: true},
+		{name: "cópia fora da base", delta: []byte{16, 4, 0x91, 14, 4}, wantErr: true},
+		{name: "inserção truncada", delta: []byte{16, 3, 3, 'x'}, wantErr: true},
+		{name: "resultado com tamanho errado", delta: []byte{16, 5, 1, 'x'}, wantErr: true},
+		{name: "instrução reservada", delta: []byte{16, 0, 0}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyDelta(base, tt.delta)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyDelta() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("applyDelta() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadHeaders(t *testing.T) {
+	// Blob (3) de 300 bytes: 300 = 0b1_0010_1100
+	typ, size, err := readEntryHeader(bytes.NewReader([]byte{0x80 | 3<<4 | 0x0c, 0x12}))
+	if err != nil || typ != typeBlob || size != 300 {
+		t.Errorf("readEntryHeader() = %v, %d, %v", typ, size, err)
+	}
+
+	// Distância 200: (0+1)<<7 | 72
+	if n, err := readOfsDelta(bytes.NewReader([]byte{0x80, 72})); err != nil || n != 200 {
+		t.Errorf("readOfsDelta() = %d, %v, want 200", n, err)
+	}
+	if _, err := readOfsDelta(bytes.NewReader([]byte{0x80})); err == nil {
+		t.Error("readOfsDelta(truncado) esperava erro")
+	}
+}
+
+func TestParseIndex(t *testing.T) {
+	names := []hash{{0x01}, {0xab, 0xcd}, {0xab, 0xce}}
+	fanout := func() []byte {
+		buf := make([]byte, 256*4)
+		for b := 0; b < 256; b++ {
+			count := 0
+			for _, h := range names {
+				if int(h[0]) <= b {
+					count++
+				}
+			}
+			binary.BigEndian.PutUint32(buf[b*4:], uint32(count))
+		}
+		return buf
+	}
+
+	v1 := fanout()
+	for i, h := range names {
+		v1 = binary.BigEndian.AppendUint32(v1, uint32(12+i*10))
+		v1 = append(v1, h[:]...)
+	}
+
+	v2 := append([]byte("\xfftOc\x00\x00\x00\x02"), fanout()...)
+	for _, h := range names {
+		v2 = append(v2, h[:]...)
+	}
+	v2 = append(v2, make([]byte, 4*len(names))...) // CRCs
+	v2 = binary.BigEndian.AppendUint32(v2, 12)
+	v2 = binary.BigEndian.AppendUint32(v2, 22)
+	v2 = binary.BigEndian.AppendUint32(v2, 0x80000000)
+	v2 = binary.BigEndian.AppendUint64(v2, 1<<33)
+
+	tests := []struct {
+		name string
+		data []byte
+		want []int64
+	}{
+		{"versão 1", v1, []int64{12, 22, 32}},
+		{"versão 2 com deslocamento grande", v2, []int64{12, 22, 1 << 33}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, err := parseIndex(tt.data)
+			if err != nil {
+				t.Fatalf("parseIndex() error = %v", err)
+			}
+			for i, h := range names {
+				if off, ok := idx.find(h); !ok || off != tt.want[i] {
+					t.Errorf("find(%s) = %d, %v, want %d", h, off, ok, tt.want[i])
+				}
+			}
+			if _, ok := idx.find(hash{0xff}); ok {
+				t.Error("find(inexistente) = true")
+			}
+			if got := idx.withPrefix("abc"); len(got) != 2 {
+				t.Errorf("withPrefix(abc) = %v, want 2 hashes", got)
+			}
+		})
+	}
+
+	if _, err := parseIndex(v2[:100]); err == nil {
+		t.Error("parseIndex(truncado) esperava erro")
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url            string
+		dir, ref, base string
+		wantErr        bool
+	}{
+		{url: "git+file:///srv/mirror", dir: "/srv/mirror", ref: "HEAD"},
+		{url: "git+file:///srv/mirror?ref=release/1.0&path=/src/", dir: "/srv/mirror", ref: "release/1.0", base: "src"},
+		{url: "git+file:mirror?ref=v1", dir: "mirror", ref: "v1"},
+		{url: "git+file://localhost/srv/mirror", dir: "/srv/mirror", ref: "HEAD"},
+		{url: "git+file:///C:/repos/mirror", dir: "C:/repos/mirror", ref: "HEAD"},
+		{url: "git+file://servidor/srv/mirror", wantErr: true},
+		{url: "file:///srv/mirror", wantErr: true},
+		{url: "git+file:?ref=main", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			dir, ref, base, err := parseURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if dir != filepath.FromSlash(tt.dir) || ref != tt.ref || base != tt.base {
+				t.Errorf("parseURL() = %q, %q, %q, want %q, %q, %q", dir, ref, base, tt.dir, tt.ref, tt.base)
+			}
+		})
+	}
+}
+
+func TestMapLayout(t *testing.T) {
+	tests := []struct {
+		git, svn string
+		want     string
+		wantErr  bool
+	}{
+		{git: "git+file:///m", svn: "https://svn/proj/trunk", want: "git+file:///m?ref=HEAD"},
+		{git: "git+file:///m", svn: "https://svn/proj/branches/feat/src", want: "git+file:///m?path=src&ref=feat"},
+		{git: "git+file:///m?path=proj", svn: "svn://svn/tags/v1.0", want: "git+file:///m?path=proj&ref=v1.0"},
+		{git: "git+file:mirror", svn: "file:///r/trunk/lib", want: "git+file:mirror?path=lib&ref=HEAD"},
+		{git: "git+file:///m?ref=main", svn: "https://svn/proj/branches/x", want: "git+file:///m?ref=main"},
+		{git: "git+file:///m", svn: "https://svn/proj/branches", wantErr: true},
+		{git: "git+file:///m", svn: "https://svn/proj/lib", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.svn, func(t *testing.T) {
+			got, err := MapLayout(tt.git, tt.svn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MapLayout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("MapLayout() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// gitRepo cria um repositório com o git instalado: main com dois commits,
+// a tag anotada v1 no primeiro e o branch feature
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git não encontrado no PATH")
+	}
+	dir := filepath.Join(t.TempDir(), "mirror")
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+			"GIT_AUTHOR_NAME=alice", "GIT_AUTHOR_EMAIL=alice@example.com",
+			"GIT_COMMITTER_NAME=alice", "GIT_COMMITTER_EMAIL=alice@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string, mode os.FileMode) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	run("init", "-q", "-b", "main")
+	write("a.txt", "um\ndois\n", 0o644)
+	write("big.txt", bigText(0), 0o644)
+	write("run.sh", "#!/bin/sh\n", 0o755)
+	write("lib/x.c", "int x;\n", 0o644)
+	if err := os.Symlink("a.txt", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".")
+	run("commit", "-q", "-m", "primeiro")
+	run("tag", "-a", "v1", "-m", "versão 1")
+	run("branch", "feature")
+
+	write("big.txt", bigText(1), 0o644)
+	write("novo.txt", "novo\n", 0o644)
+	run("rm", "-q", "lib/x.c")
+	run("add", ".")
+	run("commit", "-q", "-m", "segundo")
+
+	run("checkout", "-q", "feature")
+	write("a.txt", "um\n2\n", 0o644)
+	run("commit", "-q", "-am", "feature")
+	run("checkout", "-q", "main")
+	return dir
+}
+
+// bigText gera um arquivo grande o bastante para o git gc gravar deltas
+func bigText(version int) string {
+	var b strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&b, "linha %d\n", i)
+		if i == 100 && version > 0 {
+			fmt.Fprintf(&b, "inserida na versão %d\n", version)
+		}
+	}
+	return b.String()
+}
+
+func TestRepository(t *testing.T) {
+	dir := gitRepo(t)
+	url := Scheme + "//" + filepath.ToSlash(dir)
+
+	check := func(t *testing.T) {
+		main, err := Open(url)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer main.Close()
+
+		root, err := main.GetDir("", 0)
+		if err != nil {
+			t.Fatalf("GetDir() error = %v", err)
+		}
+		var names []string
+		for _, entry := range root.Entries {
+			names = append(names, entry.Name)
+		}
+		if want := []string{"a.txt", "big.txt", "link", "novo.txt", "run.sh"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("GetDir() nomes = %v, want %v", names, want)
+		}
+
+		files := []struct {
+			path, content string
+			props         map[string]string
+		}{
+			{"a.txt", "um\ndois\n", map[string]string{}},
+			{"big.txt", bigText(1), map[string]string{}},
+			{"run.sh", "#!/bin/sh\n", map[string]string{"svn:executable": "*"}},
+			{"link", "link a.txt", map[string]string{"svn:special": "*"}},
+		}
+		for _, f := range files {
+			file, err := main.GetFile(f.path, 0, true)
+			if err != nil {
+				t.Fatalf("GetFile(%s) error = %v", f.path, err)
+			}
+			if string(file.Content) != f.content || !reflect.DeepEqual(file.Props, f.props) {
+				t.Errorf("GetFile(%s) = %q %v, want %q %v", f.path, file.Content, file.Props, f.content, f.props)
+			}
+		}
+		if kind, _ := main.CheckPath("lib", 0); kind != svn.NodeNone {
+			t.Errorf("CheckPath(lib) = %v, want none", kind)
+		}
+		if !strings.HasPrefix(main.RevisionLabel(0), "(git HEAD ") {
+			t.Errorf("RevisionLabel() = %q", main.RevisionLabel(0))
+		}
+
+		tag, err := Open(url + "?ref=v1&path=lib")
+		if err != nil {
+			t.Fatalf("Open(v1) error = %v", err)
+		}
+		defer tag.Close()
+		if file, err := tag.GetFile("x.c", 0, true); err != nil || string(file.Content) != "int x;\n" {
+			t.Errorf("GetFile(v1:lib/x.c) = %+v, %v", file, err)
+		}
+
+		feature, err := Open(url + "?ref=feature")
+		if err != nil {
+			t.Fatalf("Open(feature) error = %v", err)
+		}
+		defer feature.Close()
+		if file, err := feature.GetFile("a.txt", 0, true); err != nil || string(file.Content) != "um\n2\n" {
+			t.Errorf("GetFile(feature:a.txt) = %+v, %v", file, err)
+		}
+	}
+
+	t.Run("objetos soltos", check)
+
+	cmd := exec.Command("git", "gc", "-q", "--aggressive")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git gc: %v\n%s", err, out)
+	}
+	t.Run("pacotes", check)
+}
+
+func TestOpen_Errors(t *testing.T) {
+	dir := gitRepo(t)
+	url := Scheme + "//" + filepath.ToSlash(dir)
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"não é repositório", Scheme + "//" + filepath.ToSlash(t.TempDir())},
+		{"ref inexistente", url + "?ref=nada"},
+		{"caminho inexistente", url + "?path=nada"},
+		{"caminho é arquivo", url + "?path=a.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if repo, err := Open(tt.url); err == nil {
+				repo.Close()
+				t.Errorf("Open(%q) esperava erro", tt.url)
+			}
+		})
+	}
+}
+
+func TestRepositoryBackend_GetDiff(t *testing.T) {
+	dir := gitRepo(t)
+	history := [][]svntest.DumpNode{{
+		{Path: "trunk", Kind: "dir", Action: "add", Props: []string{"svn:ignore", "build\n"}},
+		{Path: "trunk/a.txt", Kind: "file", Action: "add", Content: "um\ndois\n", Props: []string{"svn:eol-style", "native"}},
+		{Path: "trunk/big.txt", Kind: "file", Action: "add", Content: bigText(0)},
+		{Path: "trunk/run.sh", Kind: "file", Action: "add", Content: "#!/bin/sh\n"},
+		{Path: "trunk/lib", Kind: "dir", Action: "add"},
+		{Path: "trunk/lib/x.c", Kind: "file", Action: "add", Content: "int x;\n"},
+	}}
+	dumpFile := filepath.Join(t.TempDir(), "repo.dump")
+	if err := os.WriteFile(dumpFile, svntest.WriteDump(history), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := svn.NewRepositoryBackend(func(url string) (svn.Repository, error) {
+		if strings.HasPrefix(url, dump.Scheme) {
+			return dump.Open(url)
+		}
+		return Open(url)
+	})
+	branchA := &config.BranchConfig{URL: dump.Scheme + filepath.ToSlash(dumpFile) + "/trunk", Revisions: []string{"1"}}
+	branchB := &config.BranchConfig{URL: Scheme + "//" + filepath.ToSlash(dir)}
+
+	// svn:eol-style e svn:ignore não existem no Git e não contam como mudança
+	summary, err := backend.GetDiff(branchA, branchB, true)
+	if err != nil {
+		t.Fatalf("GetDiff(summarize) error = %v", err)
+	}
+	want := "M       big.txt\nD       lib\nA       link\nA       novo.txt\n M      run.sh\n"
+	if got := svntest.SummaryLines(t, summary.Output); got != want {
+		t.Errorf("GetDiff(summarize) = %q, want %q", got, want)
+	}
+
+	full, err := backend.GetDiff(branchA, branchB, false)
+	if err != nil {
+		t.Fatalf("GetDiff() error = %v", err)
+	}
+	for _, fragment := range []string{
+		" linha 100\n+inserida na versão 1\n",
+		"+++ big.txt\t(git HEAD ",
+		"Added: svn:executable\n",
+	} {
+		if !strings.Contains(full.Output, fragment) {
+			t.Errorf("GetDiff() sem %q:\n%s", fragment, full.Output)
+		}
+	}
+	if strings.Contains(full.Output, "svn:eol-style") {
+		t.Errorf("GetDiff() comparou svn:eol-style:\n%s", full.Output)
+	}
+}
@@ -0,0 +1,58 @@
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// LayoutRef deduz a ref e o caminho interno correspondentes a uma URL SVN
+// no layout padrão: trunk vira HEAD, branches/X vira X e tags/T vira T. O
+// restante da URL após o branch é o caminho dentro da árvore Git.
+func LayoutRef(svnURL string) (string, string, error) {
+	u, err := url.Parse(svnURL)
+	if err != nil {
+		return "", "", fmt.Errorf("URL SVN inválida: %w", err)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, part := range parts {
+		switch part {
+		case "trunk":
+			return "HEAD", strings.Join(parts[i+1:], "/"), nil
+		case "branches", "tags":
+			if i+1 < len(parts) && parts[i+1] != "" {
+				return parts[i+1], strings.Join(parts[i+2:], "/"), nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("URL fora do layout trunk/branches/tags: %s", svnURL)
+}
+
+// MapLayout completa uma URL git+file sem ref com a ref e o caminho
+// deduzidos da URL SVN do outro lado. URLs com ref explícita não mudam.
+func MapLayout(gitURL, svnURL string) (string, error) {
+	u, err := url.Parse(gitURL)
+	if err != nil {
+		return "", fmt.Errorf("URL Git inválida: %w", err)
+	}
+	query := u.Query()
+	if query.Get("ref") != "" {
+		return gitURL, nil
+	}
+
+	ref, path, err := LayoutRef(svnURL)
+	if err != nil {
+		return "", err
+	}
+	query.Set("ref", ref)
+	if base := strings.Trim(query.Get("path"), "/"); base != "" && path != "" {
+		path = base + "/" + path
+	} else if base != "" {
+		path = base
+	}
+	if path != "" {
+		query.Set("path", path)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
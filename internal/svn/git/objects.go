@@ -0,0 +1,218 @@
+package git
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// objectType é o tipo de um objeto Git, com a numeração usada nos pacotes
+type objectType int
+
+const (
+	typeCommit objectType = 1
+	typeTree   objectType = 2
+	typeBlob   objectType = 3
+	typeTag    objectType = 4
+)
+
+var typeNames = map[string]objectType{"commit": typeCommit, "tree": typeTree, "blob": typeBlob, "tag": typeTag}
+
+func (t objectType) String() string {
+	for name, typ := range typeNames {
+		if typ == t {
+			return name
+		}
+	}
+	return fmt.Sprintf("tipo %d", int(t))
+}
+
+// hash é o identificador SHA-1 de um objeto
+type hash [20]byte
+
+func (h hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+func parseHash(s string) (hash, error) {
+	var h hash
+	if len(s) != 40 {
+		return h, fmt.Errorf("hash inválido: %q", s)
+	}
+	if _, err := hex.Decode(h[:], []byte(s)); err != nil {
+		return h, fmt.Errorf("hash inválido: %q", s)
+	}
+	return h, nil
+}
+
+// store dá acesso aos objetos de um repositório: soltos, empacotados e os
+// dos diretórios listados em objects/info/alternates
+type store struct {
+	dirs  []string
+	packs []*pack
+}
+
+// openStore abre o diretório de objetos e seus alternativos
+func openStore(objectsDir string) (*store, error) {
+	s := &store{}
+	if err := s.addDir(objectsDir, 0); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// maxAlternates limita o encadeamento de alternates, como o git
+const maxAlternates = 5
+
+func (s *store) addDir(dir string, depth int) error {
+	if depth > maxAlternates {
+		return fmt.Errorf("encadeamento de alternates profundo demais em %s", dir)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("diretório de objetos não encontrado: %s", dir)
+	}
+	s.dirs = append(s.dirs, dir)
+
+	idxs, err := filepath.Glob(filepath.Join(dir, "pack", "pack-*.idx"))
+	if err != nil {
+		return err
+	}
+	for _, idx := range idxs {
+		p, err := openPack(idx)
+		if err != nil {
+			return err
+		}
+		s.packs = append(s.packs, p)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "info", "alternates"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(dir, line)
+		}
+		if err := s.addDir(line, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close fecha os pacotes abertos
+func (s *store) Close() error {
+	for _, p := range s.packs {
+		p.f.Close()
+	}
+	return nil
+}
+
+// read retorna o tipo e o conteúdo de um objeto
+func (s *store) read(h hash) (objectType, []byte, error) {
+	for _, p := range s.packs {
+		if off, ok := p.idx.find(h); ok {
+			return p.read(s, off)
+		}
+	}
+
+	name := h.String()
+	for _, dir := range s.dirs {
+		f, err := os.Open(filepath.Join(dir, name[:2], name[2:]))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		defer f.Close()
+		typ, data, err := readLoose(f)
+		if err != nil {
+			return 0, nil, fmt.Errorf("objeto %s: %w", name, err)
+		}
+		return typ, data, nil
+	}
+	return 0, nil, fmt.Errorf("objeto não encontrado: %s", name)
+}
+
+// readTyped lê um objeto exigindo o tipo informado
+func (s *store) readTyped(h hash, want objectType) ([]byte, error) {
+	typ, data, err := s.read(h)
+	if err != nil {
+		return nil, err
+	}
+	if typ != want {
+		return nil, fmt.Errorf("objeto %s é um %s, esperado %s", h, typ, want)
+	}
+	return data, nil
+}
+
+// readLoose lê um objeto solto: "tipo tamanho\0conteúdo" comprimido com zlib
+func readLoose(r io.Reader) (objectType, []byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	header, content, ok := bytes.Cut(data, []byte{0})
+	if !ok {
+		return 0, nil, fmt.Errorf("cabeçalho de objeto inválido")
+	}
+	name, sizeText, _ := strings.Cut(string(header), " ")
+	typ, ok := typeNames[name]
+	size, err := strconv.Atoi(sizeText)
+	if !ok || err != nil || size != len(content) {
+		return 0, nil, fmt.Errorf("cabeçalho de objeto inválido: %q", header)
+	}
+	return typ, content, nil
+}
+
+// findPrefix resolve um hash abreviado, exigindo que seja único
+func (s *store) findPrefix(prefix string) (hash, error) {
+	prefix = strings.ToLower(prefix)
+	found := map[hash]bool{}
+
+	for _, p := range s.packs {
+		for _, h := range p.idx.withPrefix(prefix) {
+			found[h] = true
+		}
+	}
+	for _, dir := range s.dirs {
+		names, _ := os.ReadDir(filepath.Join(dir, prefix[:2]))
+		for _, entry := range names {
+			if full := prefix[:2] + entry.Name(); strings.HasPrefix(full, prefix) {
+				if h, err := parseHash(full); err == nil {
+					found[h] = true
+				}
+			}
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return hash{}, fmt.Errorf("objeto não encontrado: %s", prefix)
+	case 1:
+		for h := range found {
+			return h, nil
+		}
+	}
+	return hash{}, fmt.Errorf("hash abreviado ambíguo: %s", prefix)
+}
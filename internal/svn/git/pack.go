@@ -0,0 +1,333 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Tipos de entrada exclusivos dos pacotes
+const (
+	typeOfsDelta objectType = 6
+	typeRefDelta objectType = 7
+)
+
+// errCorruptPack indica um pacote ou índice inválido
+var errCorruptPack = errors.New("pacote Git corrompido")
+
+// maxDeltaChain limita a cadeia de deltas, protegendo contra ciclos
+const maxDeltaChain = 1000
+
+// maxCachedBases é a quantidade de bases de delta mantidas em memória
+const maxCachedBases = 128
+
+// pack é um arquivo .pack com seu índice .idx
+type pack struct {
+	f   *os.File
+	idx *packIndex
+
+	mu    sync.Mutex
+	bases map[int64]cachedObject
+}
+
+type cachedObject struct {
+	typ  objectType
+	data []byte
+}
+
+// packIndex relaciona os hashes, em ordem, aos deslocamentos no pacote
+type packIndex struct {
+	names   []hash
+	offsets []int64
+}
+
+// openPack abre o índice e o pacote correspondente
+func openPack(idxPath string) (*pack, error) {
+	data, err := os.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := parseIndex(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", idxPath, err)
+	}
+
+	packPath := strings.TrimSuffix(idxPath, ".idx") + ".pack"
+	f, err := os.Open(packPath)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(f, header); err != nil || string(header[:4]) != "PACK" {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w: cabeçalho inválido", packPath, errCorruptPack)
+	}
+	if version := binary.BigEndian.Uint32(header[4:]); version != 2 && version != 3 {
+		f.Close()
+		return nil, fmt.Errorf("%s: versão de pacote não suportada: %d", packPath, version)
+	}
+	return &pack{f: f, idx: idx, bases: map[int64]cachedObject{}}, nil
+}
+
+// parseIndex lê um índice nas versões 1 (sem assinatura) ou 2 ("\377tOc")
+func parseIndex(data []byte) (*packIndex, error) {
+	version := 1
+	if bytes.HasPrefix(data, []byte("\xfftOc")) {
+		if len(data) < 8 {
+			return nil, errCorruptPack
+		}
+		if version = int(binary.BigEndian.Uint32(data[4:])); version != 2 {
+			return nil, fmt.Errorf("versão de índice não suportada: %d", version)
+		}
+		data = data[8:]
+	}
+	if len(data) < 256*4 {
+		return nil, errCorruptPack
+	}
+	count := int(binary.BigEndian.Uint32(data[255*4:]))
+	data = data[256*4:]
+	idx := &packIndex{names: make([]hash, count), offsets: make([]int64, count)}
+
+	if version == 1 {
+		if len(data) < count*24 {
+			return nil, errCorruptPack
+		}
+		for i := 0; i < count; i++ {
+			entry := data[i*24:]
+			idx.offsets[i] = int64(binary.BigEndian.Uint32(entry))
+			copy(idx.names[i][:], entry[4:24])
+		}
+		return idx, nil
+	}
+
+	// Versão 2: nomes, CRCs, deslocamentos de 31 bits e deslocamentos grandes
+	if len(data) < count*(20+4+4) {
+		return nil, errCorruptPack
+	}
+	for i := 0; i < count; i++ {
+		copy(idx.names[i][:], data[i*20:])
+	}
+	offsets := data[count*24:]
+	large := offsets[count*4:]
+	for i := 0; i < count; i++ {
+		off := binary.BigEndian.Uint32(offsets[i*4:])
+		if off&0x80000000 == 0 {
+			idx.offsets[i] = int64(off)
+			continue
+		}
+		j := int(off & 0x7fffffff)
+		if len(large) < (j+1)*8 {
+			return nil, errCorruptPack
+		}
+		idx.offsets[i] = int64(binary.BigEndian.Uint64(large[j*8:]))
+	}
+	return idx, nil
+}
+
+// find retorna o deslocamento do objeto no pacote
+func (idx *packIndex) find(h hash) (int64, bool) {
+	i := sort.Search(len(idx.names), func(i int) bool {
+		return bytes.Compare(idx.names[i][:], h[:]) >= 0
+	})
+	if i < len(idx.names) && idx.names[i] == h {
+		return idx.offsets[i], true
+	}
+	return 0, false
+}
+
+// withPrefix retorna os hashes do índice que começam pelo prefixo hexadecimal
+func (idx *packIndex) withPrefix(prefix string) []hash {
+	var found []hash
+	i := sort.Search(len(idx.names), func(i int) bool {
+		return idx.names[i].String() >= prefix
+	})
+	for ; i < len(idx.names) && strings.HasPrefix(idx.names[i].String(), prefix); i++ {
+		found = append(found, idx.names[i])
+	}
+	return found
+}
+
+// read retorna o objeto no deslocamento, aplicando os deltas
+func (p *pack) read(s *store, off int64) (objectType, []byte, error) {
+	return p.readChain(s, off, 0)
+}
+
+func (p *pack) readChain(s *store, off int64, depth int) (objectType, []byte, error) {
+	if depth > maxDeltaChain {
+		return 0, nil, fmt.Errorf("%w: cadeia de deltas longa demais", errCorruptPack)
+	}
+	p.mu.Lock()
+	cached, ok := p.bases[off]
+	p.mu.Unlock()
+	if ok {
+		return cached.typ, cached.data, nil
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(p.f, off, 1<<62))
+	typ, size, err := readEntryHeader(r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("entrada em %d: %w", off, err)
+	}
+
+	var baseType objectType
+	var base []byte
+	switch typ {
+	case typeCommit, typeTree, typeBlob, typeTag:
+	case typeOfsDelta:
+		rel, err := readOfsDelta(r)
+		if err != nil || rel <= 0 || rel > off {
+			return 0, nil, fmt.Errorf("%w: delta com base inválida em %d", errCorruptPack, off)
+		}
+		if baseType, base, err = p.readChain(s, off-rel, depth+1); err != nil {
+			return 0, nil, err
+		}
+	case typeRefDelta:
+		var h hash
+		if _, err := io.ReadFull(r, h[:]); err != nil {
+			return 0, nil, fmt.Errorf("%w: delta truncado em %d", errCorruptPack, off)
+		}
+		if baseType, base, err = s.read(h); err != nil {
+			return 0, nil, err
+		}
+	default:
+		return 0, nil, fmt.Errorf("%w: tipo %d em %d", errCorruptPack, typ, off)
+	}
+
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("entrada em %d: %w", off, err)
+	}
+	defer zr.Close()
+	data := make([]byte, size)
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return 0, nil, fmt.Errorf("entrada em %d: %w", off, err)
+	}
+
+	if typ == typeOfsDelta || typ == typeRefDelta {
+		if data, err = applyDelta(base, data); err != nil {
+			return 0, nil, fmt.Errorf("entrada em %d: %w", off, err)
+		}
+		typ = baseType
+	}
+
+	p.mu.Lock()
+	if len(p.bases) >= maxCachedBases {
+		clear(p.bases)
+	}
+	p.bases[off] = cachedObject{typ: typ, data: data}
+	p.mu.Unlock()
+	return typ, data, nil
+}
+
+// readEntryHeader lê o tipo (3 bits) e o tamanho descomprimido da entrada
+func readEntryHeader(r io.ByteReader) (objectType, int64, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	typ := objectType(b >> 4 & 7)
+	size := int64(b & 0x0f)
+	for shift := 4; b&0x80 != 0; shift += 7 {
+		if b, err = r.ReadByte(); err != nil {
+			return 0, 0, err
+		}
+		size |= int64(b&0x7f) << shift
+	}
+	return typ, size, nil
+}
+
+// readOfsDelta lê a distância até a base, codificada com deslocamento
+// acumulado a cada byte de continuação
+func readOfsDelta(r io.ByteReader) (int64, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	n := int64(b & 0x7f)
+	for b&0x80 != 0 {
+		if b, err = r.ReadByte(); err != nil {
+			return 0, err
+		}
+		n = (n+1)<<7 | int64(b&0x7f)
+	}
+	return n, nil
+}
+
+// applyDelta reconstrói o objeto a partir da base e das instruções do delta:
+// cópia de um trecho da base ou inserção de bytes literais
+func applyDelta(base, delta []byte) ([]byte, error) {
+	srcSize, delta, err := deltaSize(delta)
+	if err != nil || srcSize != len(base) {
+		return nil, fmt.Errorf("%w: tamanho da base do delta", errCorruptPack)
+	}
+	dstSize, delta, err := deltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, dstSize)
+	for len(delta) > 0 {
+		cmd := delta[0]
+		delta = delta[1:]
+		switch {
+		case cmd&0x80 != 0:
+			var off, size int
+			for i := 0; i < 7; i++ {
+				if cmd&(1<<i) == 0 {
+					continue
+				}
+				if len(delta) == 0 {
+					return nil, fmt.Errorf("%w: delta truncado", errCorruptPack)
+				}
+				if i < 4 {
+					off |= int(delta[0]) << (8 * i)
+				} else {
+					size |= int(delta[0]) << (8 * (i - 4))
+				}
+				delta = delta[1:]
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if off+size > len(base) {
+				return nil, fmt.Errorf("%w: cópia fora da base do delta", errCorruptPack)
+			}
+			out = append(out, base[off:off+size]...)
+		case cmd != 0:
+			if int(cmd) > len(delta) {
+				return nil, fmt.Errorf("%w: delta truncado", errCorruptPack)
+			}
+			out = append(out, delta[:cmd]...)
+			delta = delta[cmd:]
+		default:
+			return nil, fmt.Errorf("%w: instrução de delta reservada", errCorruptPack)
+		}
+	}
+	if len(out) != dstSize {
+		return nil, fmt.Errorf("%w: tamanho do resultado do delta", errCorruptPack)
+	}
+	return out, nil
+}
+
+// deltaSize lê um tamanho do cabeçalho do delta, 7 bits por byte, menos
+// significativos primeiro
+func deltaSize(p []byte) (int, []byte, error) {
+	size := 0
+	for shift := 0; len(p) > 0; shift += 7 {
+		b := p[0]
+		p = p[1:]
+		size |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return size, p, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("%w: cabeçalho de delta truncado", errCorruptPack)
+}
@@ -0,0 +1,201 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// repo é um repositório Git localizado no disco
+type repo struct {
+	// gitDir contém HEAD; commonDir contém refs, packed-refs e objects, e só
+	// difere de gitDir em worktrees adicionais
+	gitDir    string
+	commonDir string
+	objects   *store
+}
+
+// openRepo localiza o repositório a partir de uma worktree (com .git) ou
+// de um repositório bare
+func openRepo(path string) (*repo, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	gitDir := ""
+	dotGit := filepath.Join(abs, ".git")
+	switch info, err := os.Stat(dotGit); {
+	case err == nil && info.IsDir():
+		gitDir = dotGit
+	case err == nil:
+		// Worktrees adicionais e submódulos: arquivo "gitdir: caminho"
+		data, err := os.ReadFile(dotGit)
+		if err != nil {
+			return nil, err
+		}
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+		if !ok {
+			return nil, fmt.Errorf("arquivo .git inválido: %s", dotGit)
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(abs, target)
+		}
+		gitDir = target
+	default:
+		gitDir = abs
+	}
+
+	if _, err := os.Stat(filepath.Join(gitDir, "HEAD")); err != nil {
+		return nil, fmt.Errorf("'%s' não é um repositório Git", path)
+	}
+
+	commonDir := gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	objects, err := openStore(filepath.Join(commonDir, "objects"))
+	if err != nil {
+		return nil, err
+	}
+	return &repo{gitDir: gitDir, commonDir: commonDir, objects: objects}, nil
+}
+
+// hexPrefix reconhece hashes completos ou abreviados
+var hexPrefix = regexp.MustCompile(`^[0-9a-fA-F]{4,40}$`)
+
+// resolve converte uma ref no hash do objeto, na mesma ordem de busca do
+// git rev-parse: o nome exato, refs/, refs/tags/, refs/heads/,
+// refs/remotes/ e refs/remotes/<nome>/HEAD, e por fim um hash
+func (r *repo) resolve(name string) (hash, error) {
+	candidates := []string{name, "refs/" + name, "refs/tags/" + name, "refs/heads/" + name,
+		"refs/remotes/" + name, "refs/remotes/" + name + "/HEAD"}
+	for _, candidate := range candidates {
+		h, ok, err := r.readRef(candidate, 0)
+		if err != nil {
+			return hash{}, err
+		}
+		if ok {
+			return h, nil
+		}
+	}
+
+	if hexPrefix.MatchString(name) {
+		if len(name) == 40 {
+			return parseHash(strings.ToLower(name))
+		}
+		return r.objects.findPrefix(name)
+	}
+	return hash{}, fmt.Errorf("ref Git não encontrada: %s", name)
+}
+
+// maxSymref limita o encadeamento de refs simbólicas
+const maxSymref = 10
+
+// readRef lê uma ref solta ou do packed-refs, seguindo refs simbólicas
+func (r *repo) readRef(name string, depth int) (hash, bool, error) {
+	if depth > maxSymref {
+		return hash{}, false, fmt.Errorf("refs simbólicas em ciclo: %s", name)
+	}
+	if strings.Contains(name, "..") {
+		return hash{}, false, nil
+	}
+
+	// HEAD e refs por worktree ficam em gitDir; as demais em commonDir
+	for _, dir := range []string{r.gitDir, r.commonDir} {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			continue
+		}
+		content := strings.TrimSpace(string(data))
+		if target, ok := strings.CutPrefix(content, "ref: "); ok {
+			return r.readRef(target, depth+1)
+		}
+		h, err := parseHash(content)
+		if err != nil {
+			// Diretórios como refs/heads também caem aqui
+			continue
+		}
+		return h, true, nil
+	}
+	return r.packedRef(name)
+}
+
+// packedRef procura a ref no arquivo packed-refs
+func (r *repo) packedRef(name string) (hash, bool, error) {
+	data, err := os.ReadFile(filepath.Join(r.commonDir, "packed-refs"))
+	if os.IsNotExist(err) {
+		return hash{}, false, nil
+	}
+	if err != nil {
+		return hash{}, false, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "^") {
+			continue
+		}
+		value, ref, ok := strings.Cut(line, " ")
+		if ok && ref == name {
+			h, err := parseHash(value)
+			if err != nil {
+				return hash{}, false, fmt.Errorf("packed-refs inválido: %q", line)
+			}
+			return h, true, nil
+		}
+	}
+	return hash{}, false, scanner.Err()
+}
+
+// peelToTree segue tags anotadas e commits até a árvore
+func (r *repo) peelToTree(h hash) (hash, hash, error) {
+	commit := hash{}
+	for range maxSymref {
+		typ, data, err := r.objects.read(h)
+		if err != nil {
+			return hash{}, hash{}, err
+		}
+		switch typ {
+		case typeTree:
+			return h, commit, nil
+		case typeTag:
+			next, err := headerHash(data, "object")
+			if err != nil {
+				return hash{}, hash{}, fmt.Errorf("tag %s: %w", h, err)
+			}
+			h = next
+		case typeCommit:
+			tree, err := headerHash(data, "tree")
+			if err != nil {
+				return hash{}, hash{}, fmt.Errorf("commit %s: %w", h, err)
+			}
+			commit, h = h, tree
+		default:
+			return hash{}, hash{}, fmt.Errorf("'%s' aponta para um %s, não para um commit", h, typ)
+		}
+	}
+	return hash{}, hash{}, fmt.Errorf("tags aninhadas demais a partir de %s", h)
+}
+
+// headerHash lê o hash de um campo do cabeçalho de um commit ou tag
+func headerHash(data []byte, field string) (hash, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, field+" "); ok {
+			return parseHash(value)
+		}
+	}
+	return hash{}, fmt.Errorf("campo %s ausente", field)
+}
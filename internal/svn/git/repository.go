@@ -0,0 +1,306 @@
+// Package git lê diretamente os objetos de um repositório Git local (objetos
+// soltos, pacotes com índice v1/v2 e deltas) e expõe a árvore de uma ref como
+// svn.Repository, para comparar branches SVN com espelhos Git durante uma
+// migração.
+package git
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"svndiff/internal/svn"
+)
+
+// Scheme é o prefixo das URLs de repositórios Git locais:
+// git+file:///caminho/repo?ref=main&path=subdir
+const Scheme = "git+file:"
+
+// Modos das entradas de árvore
+const (
+	modeDir        = "40000"
+	modeExecutable = "100755"
+	modeSymlink    = "120000"
+	modeSubmodule  = "160000"
+)
+
+// Repository é a árvore de uma ref, implementando svn.Repository. Há uma
+// única revisão: as revisões informadas são ignoradas.
+type Repository struct {
+	repo   *repo
+	ref    string
+	commit hash
+	base   string
+	root   hash
+
+	mu    sync.Mutex
+	trees map[hash][]treeEntry
+}
+
+// treeEntry é uma entrada de um objeto tree
+type treeEntry struct {
+	name string
+	mode string
+	hash hash
+}
+
+// Open abre o repositório da URL e resolve a ref (HEAD por padrão)
+func Open(rawURL string) (*Repository, error) {
+	dir, ref, base, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	r, err := openRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := r.resolve(ref)
+	if err != nil {
+		r.objects.Close()
+		return nil, err
+	}
+	tree, commit, err := r.peelToTree(h)
+	if err != nil {
+		r.objects.Close()
+		return nil, fmt.Errorf("ref '%s': %w", ref, err)
+	}
+
+	repo := &Repository{repo: r, ref: ref, commit: commit, base: base, trees: map[hash][]treeEntry{}}
+	entry, ok, err := repo.lookupFrom(tree, base)
+	if err != nil {
+		r.objects.Close()
+		return nil, err
+	}
+	if !ok || entry.mode != modeDir {
+		r.objects.Close()
+		return nil, fmt.Errorf("diretório '%s' não encontrado em %s", base, ref)
+	}
+	repo.root = entry.hash
+	return repo, nil
+}
+
+// windowsDrive reconhece "/C:/..." em URLs git+file:///C:/...
+var windowsDrive = regexp.MustCompile(`^/[A-Za-z]:/`)
+
+// parseURL separa o diretório do repositório, a ref e o caminho interno.
+// Caminhos relativos usam a forma opaca git+file:caminho.
+func parseURL(rawURL string) (string, string, string, error) {
+	if !strings.HasPrefix(rawURL, Scheme) {
+		return "", "", "", fmt.Errorf("URL Git inválida: %s", rawURL)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", "", fmt.Errorf("URL Git inválida: %w", err)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", "", "", fmt.Errorf("apenas repositórios Git locais são suportados: %s", rawURL)
+	}
+
+	dir := u.Path
+	if u.Opaque != "" {
+		if dir, err = url.PathUnescape(u.Opaque); err != nil {
+			return "", "", "", fmt.Errorf("URL Git inválida: %w", err)
+		}
+	}
+	if windowsDrive.MatchString(dir) {
+		dir = dir[1:]
+	}
+	if dir == "" {
+		return "", "", "", fmt.Errorf("URL Git sem caminho: %s", rawURL)
+	}
+
+	query := u.Query()
+	ref := query.Get("ref")
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return filepath.FromSlash(dir), ref, strings.Trim(query.Get("path"), "/"), nil
+}
+
+// Close fecha os pacotes do repositório
+func (r *Repository) Close() error {
+	return r.repo.objects.Close()
+}
+
+// UUID identifica o banco de objetos, permitindo ignorar subárvores com o
+// mesmo hash ao comparar duas refs do mesmo repositório
+func (r *Repository) UUID() string {
+	return "git:" + filepath.Join(r.repo.commonDir, "objects")
+}
+
+// LatestRevision retorna 0: a ref tem uma única versão
+func (r *Repository) LatestRevision() (int64, error) {
+	return 0, nil
+}
+
+// RevisionLabel identifica a ref e o commit nos cabeçalhos do diff
+func (r *Repository) RevisionLabel(int64) string {
+	if r.commit == (hash{}) {
+		return fmt.Sprintf("(git %s)", r.ref)
+	}
+	return fmt.Sprintf("(git %s %s)", r.ref, r.commit.String()[:12])
+}
+
+// ComparesProp restringe a comparação às propriedades que o Git representa
+// pelo modo dos arquivos
+func (r *Repository) ComparesProp(name string) bool {
+	return name == "svn:executable" || name == "svn:special"
+}
+
+// CheckPath retorna o tipo do nó no caminho
+func (r *Repository) CheckPath(path string, _ int64) (svn.NodeKind, error) {
+	entry, ok, err := r.lookupFrom(r.root, path)
+	switch {
+	case err != nil:
+		return "", err
+	case !ok || entry.mode == modeSubmodule:
+		return svn.NodeNone, nil
+	case entry.mode == modeDir:
+		return svn.NodeDir, nil
+	}
+	return svn.NodeFile, nil
+}
+
+// GetDir lista uma árvore. Submódulos são omitidos e o tamanho dos arquivos
+// não é calculado, para não ler todos os blobs. O ID das entradas é o hash.
+func (r *Repository) GetDir(path string, _ int64) (*svn.Dir, error) {
+	entry, err := r.entry(path, true)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := r.readTree(entry.hash)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := &svn.Dir{Props: map[string]string{}}
+	for _, e := range entries {
+		if e.mode == modeSubmodule {
+			continue
+		}
+		kind := svn.NodeFile
+		if e.mode == modeDir {
+			kind = svn.NodeDir
+		}
+		dir.Entries = append(dir.Entries, svn.DirEntry{Name: e.name, Kind: kind, ID: e.hash.String()})
+	}
+	return dir, nil
+}
+
+// GetFile lê um blob. Executáveis recebem svn:executable e links simbólicos
+// viram "link destino" com svn:special, como no svn.
+func (r *Repository) GetFile(path string, _ int64, withContent bool) (*svn.File, error) {
+	entry, err := r.entry(path, false)
+	if err != nil {
+		return nil, err
+	}
+	content, err := r.repo.objects.readTyped(entry.hash, typeBlob)
+	if err != nil {
+		return nil, err
+	}
+
+	props := map[string]string{}
+	switch entry.mode {
+	case modeExecutable:
+		props["svn:executable"] = "*"
+	case modeSymlink:
+		props["svn:special"] = "*"
+		content = append([]byte("link "), content...)
+	}
+
+	sum := md5.Sum(content)
+	file := &svn.File{Checksum: hex.EncodeToString(sum[:]), Props: props}
+	if withContent {
+		file.Content = bytes.Clone(content)
+	}
+	return file, nil
+}
+
+// GetLog não é suportado: o histórico Git não tem revisões numeradas
+func (r *Repository) GetLog(string, int64, int64, int) ([]svn.LogEntry, error) {
+	return nil, fmt.Errorf("histórico não disponível para repositórios Git")
+}
+
+// entry busca o caminho exigindo o tipo informado
+func (r *Repository) entry(path string, dir bool) (treeEntry, error) {
+	entry, ok, err := r.lookupFrom(r.root, path)
+	if err != nil {
+		return treeEntry{}, err
+	}
+	switch {
+	case !ok || entry.mode == modeSubmodule:
+		return treeEntry{}, fmt.Errorf("caminho não encontrado em %s: %s", r.ref, path)
+	case dir && entry.mode != modeDir:
+		return treeEntry{}, fmt.Errorf("'%s' não é um diretório", path)
+	case !dir && entry.mode == modeDir:
+		return treeEntry{}, fmt.Errorf("'%s' não é um arquivo", path)
+	}
+	return entry, nil
+}
+
+// lookupFrom percorre as árvores a partir de root até o caminho
+func (r *Repository) lookupFrom(root hash, path string) (treeEntry, bool, error) {
+	entry := treeEntry{mode: modeDir, hash: root}
+	for _, name := range strings.Split(path, "/") {
+		if name == "" {
+			continue
+		}
+		if entry.mode != modeDir {
+			return treeEntry{}, false, nil
+		}
+		entries, err := r.readTree(entry.hash)
+		if err != nil {
+			return treeEntry{}, false, err
+		}
+		found := false
+		for _, e := range entries {
+			if e.name == name {
+				entry, found = e, true
+				break
+			}
+		}
+		if !found {
+			return treeEntry{}, false, nil
+		}
+	}
+	return entry, true, nil
+}
+
+// readTree lê e guarda em cache as entradas de um objeto tree:
+// "modo nome\0" seguido dos 20 bytes do hash
+func (r *Repository) readTree(h hash) ([]treeEntry, error) {
+	r.mu.Lock()
+	entries, ok := r.trees[h]
+	r.mu.Unlock()
+	if ok {
+		return entries, nil
+	}
+
+	data, err := r.repo.objects.readTyped(h, typeTree)
+	if err != nil {
+		return nil, err
+	}
+	for len(data) > 0 {
+		header, rest, ok := bytes.Cut(data, []byte{0})
+		mode, name, okName := strings.Cut(string(header), " ")
+		if !ok || !okName || len(rest) < 20 {
+			return nil, fmt.Errorf("árvore %s inválida", h)
+		}
+		entry := treeEntry{name: name, mode: mode}
+		copy(entry.hash[:], rest[:20])
+		entries = append(entries, entry)
+		data = rest[20:]
+	}
+
+	r.mu.Lock()
+	r.trees[h] = entries
+	r.mu.Unlock()
+	return entries, nil
+}
@@ -0,0 +1,85 @@
+package svn
+
+import "strings"
+
+// ChangedPath é um caminho alterado em uma revisão, como listado pelo
+// svn log -v
+type ChangedPath struct {
+	// Path é absoluto, iniciado por "/"
+	Path string
+	// Action é 'A', 'D', 'M' ou 'R'
+	Action   byte
+	CopyPath string
+	CopyRev  int64
+}
+
+// WalkHistory percorre, da revisão hi até lo, as revisões que alteraram path
+// (absoluto), seguindo as cópias como o svn log. changes fornece os caminhos
+// alterados de cada revisão; visit é chamado para cada revisão do histórico
+// e interrompe o percurso ao retornar false.
+func WalkHistory(path string, hi, lo int64, changes func(rev int64) ([]ChangedPath, error), visit func(rev int64) bool) error {
+	path = "/" + strings.Trim(path, "/")
+	for rev := hi; rev >= lo && rev > 0; rev-- {
+		list, err := changes(rev)
+		if err != nil {
+			return err
+		}
+
+		affected, origin := false, (*ChangedPath)(nil)
+		for i, c := range list {
+			if isAncestor(path, c.Path) {
+				affected = true
+			}
+			if (c.Action == 'A' || c.Action == 'R') && isAncestor(c.Path, path) {
+				if origin == nil || len(c.Path) > len(origin.Path) {
+					origin = &list[i]
+				}
+			}
+		}
+		if origin != nil {
+			affected = true
+		}
+		if affected && !visit(rev) {
+			return nil
+		}
+
+		if origin != nil {
+			if origin.CopyPath == "" {
+				return nil
+			}
+			// Continua a partir da origem da cópia
+			path = origin.CopyPath + strings.TrimPrefix(path, origin.Path)
+			rev = origin.CopyRev + 1
+		}
+	}
+	return nil
+}
+
+// isAncestor indica se dir é o próprio path ou um diretório acima dele
+func isAncestor(dir, path string) bool {
+	return dir == "/" || path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+// HistoryRevisions retorna as revisões do histórico de path entre start e
+// end, na ordem pedida (decrescente quando start > end), respeitando limit
+// como o svn log
+func HistoryRevisions(path string, start, end int64, limit int, changes func(rev int64) ([]ChangedPath, error)) ([]int64, error) {
+	var revs []int64
+	err := WalkHistory(path, max(start, end), min(start, end), changes, func(rev int64) bool {
+		revs = append(revs, rev)
+		return start < end || limit <= 0 || len(revs) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if start < end {
+		for i, j := 0, len(revs)-1; i < j; i, j = i+1, j-1 {
+			revs[i], revs[j] = revs[j], revs[i]
+		}
+		if limit > 0 && len(revs) > limit {
+			revs = revs[:limit]
+		}
+	}
+	return revs, nil
+}
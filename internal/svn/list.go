@@ -0,0 +1,62 @@
+package svn
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"svndiff/pkg/config"
+)
+
+// ListEntry é um caminho do conteúdo de um diretório versionado, relativo ao
+// diretório listado
+type ListEntry struct {
+	Path string
+	Kind NodeKind
+}
+
+// xmlList mapeia a saída de svn list --xml
+type xmlList struct {
+	Entries []struct {
+		Kind NodeKind `xml:"kind,attr"`
+		Name string   `xml:"name"`
+	} `xml:"list>entry"`
+}
+
+// ListTree lista recursivamente o conteúdo do diretório path da branch na
+// última revisão configurada
+func (c *Client) ListTree(branch *config.BranchConfig, path string) ([]ListEntry, error) {
+	args := []string{"list", "--xml", "--recursive"}
+
+	// Adiciona credenciais se fornecidas
+	args = append(args, c.authArgs()...)
+
+	args = append(args, fmt.Sprintf("%s/%s@%s", strings.TrimSuffix(branch.URL, "/"), path, branch.GetLatestRevision()))
+
+	cmd := exec.Command("svn", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("comando svn list falhou: %s\nSaída de erro: %s",
+				err.Error(), string(exitError.Stderr))
+		}
+		return nil, fmt.Errorf("erro ao executar comando svn list: %w", err)
+	}
+
+	return parseListXML(output)
+}
+
+// parseListXML processa a saída de svn list --xml
+func parseListXML(output []byte) ([]ListEntry, error) {
+	var list xmlList
+	if err := xml.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar listagem XML: %w", err)
+	}
+
+	entries := make([]ListEntry, 0, len(list.Entries))
+	for _, e := range list.Entries {
+		entries = append(entries, ListEntry{Path: e.Name, Kind: e.Kind})
+	}
+	return entries, nil
+}
@@ -0,0 +1,47 @@
+package svn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseListXML(t *testing.T) {
+	output := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<lists>
+<list
+   path="https://svn.example.com/trunk/lib">
+<entry
+   kind="dir">
+<name>sub</name>
+<commit
+   revision="12">
+<author>alice</author>
+<date>2024-03-05T14:22:10.123456Z</date>
+</commit>
+</entry>
+<entry
+   kind="file">
+<name>sub/a &amp; b.txt</name>
+<size>4</size>
+<commit
+   revision="12">
+<author>alice</author>
+<date>2024-03-05T14:22:10.123456Z</date>
+</commit>
+</entry>
+</list>
+</lists>`)
+
+	entries, err := parseListXML(output)
+	if err != nil {
+		t.Fatalf("parseListXML() error = %v", err)
+	}
+	want := []ListEntry{{Path: "sub", Kind: NodeDir}, {Path: "sub/a & b.txt", Kind: NodeFile}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("parseListXML() = %+v, want %+v", entries, want)
+	}
+
+	if _, err := parseListXML([]byte("<lists><list>")); err == nil {
+		t.Error("parseListXML() com XML truncado não retornou erro")
+	}
+}
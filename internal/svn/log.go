@@ -0,0 +1,95 @@
+package svn
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogEntry representa uma entrada do svn log
+type LogEntry struct {
+	Revision string
+	Author   string
+	Date     time.Time
+	Message  string
+}
+
+// LogOptions define a janela de revisões consultada pelo svn log
+type LogOptions struct {
+	// Range é o argumento passado em -r (ex: "HEAD:1" ou "{2024-01-01}:{2024-01-15}")
+	Range string
+	// Limit limita a quantidade de entradas retornadas (0 = sem limite)
+	Limit int
+}
+
+// xmlLog mapeia a saída de svn log --xml
+type xmlLog struct {
+	Entries []struct {
+		Revision string `xml:"revision,attr"`
+		Author   string `xml:"author"`
+		Date     string `xml:"date"`
+		Message  string `xml:"msg"`
+	} `xml:"logentry"`
+}
+
+// GetLogEntries obtém as entradas de log de uma URL dentro da janela informada
+func (c *Client) GetLogEntries(url string, opts LogOptions) ([]LogEntry, error) {
+	args := []string{"log", "--xml"}
+
+	// Adiciona credenciais se fornecidas
+	args = append(args, c.authArgs()...)
+
+	if opts.Range != "" {
+		args = append(args, "-r", opts.Range)
+	}
+	if opts.Limit > 0 {
+		args = append(args, "-l", strconv.Itoa(opts.Limit))
+	}
+
+	args = append(args, url)
+
+	cmd := exec.Command("svn", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("comando svn log falhou: %s\nSaída de erro: %s",
+				err.Error(), string(exitError.Stderr))
+		}
+		return nil, fmt.Errorf("erro ao executar comando svn log: %w", err)
+	}
+
+	return parseLogXML(output)
+}
+
+// parseLogXML processa a saída de svn log --xml
+func parseLogXML(output []byte) ([]LogEntry, error) {
+	var log xmlLog
+	if err := xml.Unmarshal(output, &log); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar log XML: %w", err)
+	}
+
+	entries := make([]LogEntry, 0, len(log.Entries))
+	for _, e := range log.Entries {
+		entry := LogEntry{
+			Revision: e.Revision,
+			Author:   e.Author,
+			Message:  strings.TrimSpace(e.Message),
+		}
+
+		// Entradas sem data (ex: revisões sem permissão de leitura) são mantidas
+		if e.Date != "" {
+			date, err := time.Parse(time.RFC3339Nano, e.Date)
+			if err != nil {
+				return nil, fmt.Errorf("data inválida na revisão %s: %w", e.Revision, err)
+			}
+			entry.Date = date
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
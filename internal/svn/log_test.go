@@ -0,0 +1,47 @@
+package svn
+
+import (
+	"testing"
+)
+
+func TestParseLogXML(t *testing.T) {
+	output := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<log>
+<logentry revision="125">
+<author>alice</author>
+<date>2024-03-05T14:22:10.123456Z</date>
+<msg>PROJ-1234: corrige validação
+</msg>
+</logentry>
+<logentry revision="124">
+<author>bob</author>
+<date>2024-03-04T09:00:00.000000Z</date>
+<msg>Ajustes gerais</msg>
+</logentry>
+</log>`)
+
+	entries, err := parseLogXML(output)
+	if err != nil {
+		t.Fatalf("parseLogXML() error = %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("parseLogXML() len = %d, want 2", len(entries))
+	}
+
+	if entries[0].Revision != "125" || entries[0].Author != "alice" {
+		t.Errorf("parseLogXML()[0] = %+v", entries[0])
+	}
+	if entries[0].Message != "PROJ-1234: corrige validação" {
+		t.Errorf("parseLogXML()[0].Message = %q", entries[0].Message)
+	}
+	if entries[1].Date.Day() != 4 {
+		t.Errorf("parseLogXML()[1].Date = %v", entries[1].Date)
+	}
+}
+
+func TestParseLogXML_Invalid(t *testing.T) {
+	if _, err := parseLogXML([]byte("<log><logentry")); err == nil {
+		t.Error("parseLogXML() expected error for malformed XML")
+	}
+}
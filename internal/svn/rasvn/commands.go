@@ -0,0 +1,200 @@
+package rasvn
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"svndiff/internal/svn"
+)
+
+// LatestRevision retorna a revisão mais recente do repositório
+func (s *Session) LatestRevision() (int64, error) {
+	if err := s.command("get-latest-rev"); err != nil {
+		return 0, err
+	}
+	params, err := s.readResponse()
+	if err != nil {
+		return 0, err
+	}
+	return numberAt(params, 0), nil
+}
+
+// DatedRevision retorna a revisão vigente na data informada
+func (s *Session) DatedRevision(date time.Time) (int64, error) {
+	if err := s.command("get-dated-rev", date.UTC().Format("2006-01-02T15:04:05.000000Z")); err != nil {
+		return 0, err
+	}
+	params, err := s.readResponse()
+	if err != nil {
+		return 0, err
+	}
+	return numberAt(params, 0), nil
+}
+
+// CheckPath retorna o tipo do nó no caminho e revisão informados
+func (s *Session) CheckPath(path string, rev int64) (svn.NodeKind, error) {
+	if err := s.command("check-path", path, optRev(rev)); err != nil {
+		return "", err
+	}
+	params, err := s.readResponse()
+	if err != nil {
+		return "", err
+	}
+
+	switch kind := stringAt(params, 0); kind {
+	case "file":
+		return svn.NodeFile, nil
+	case "dir":
+		return svn.NodeDir, nil
+	case "none":
+		return svn.NodeNone, nil
+	default:
+		return "", fmt.Errorf("tipo de nó desconhecido: %s", kind)
+	}
+}
+
+// GetDir lista um diretório com suas propriedades
+func (s *Session) GetDir(path string, rev int64) (*svn.Dir, error) {
+	fields := []any{Word("kind"), Word("size"), Word("has-props"), Word("created-rev")}
+	if err := s.command("get-dir", path, optRev(rev), true, true, fields); err != nil {
+		return nil, err
+	}
+	params, err := s.readResponse()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := &svn.Dir{Props: parseProps(listAt(params, 1))}
+	for _, entry := range listAt(params, 2) {
+		dir.Entries = append(dir.Entries, svn.DirEntry{
+			Name:       stringAt(entry.List, 0),
+			Kind:       svn.NodeKind(stringAt(entry.List, 1)),
+			Size:       numberAt(entry.List, 2),
+			CreatedRev: numberAt(entry.List, 4),
+		})
+	}
+
+	return dir, nil
+}
+
+// GetFile obtém o checksum, as propriedades e, opcionalmente, o conteúdo de
+// um arquivo
+func (s *Session) GetFile(path string, rev int64, withContent bool) (*svn.File, error) {
+	if err := s.command("get-file", path, optRev(rev), true, withContent); err != nil {
+		return nil, err
+	}
+	params, err := s.readResponse()
+	if err != nil {
+		return nil, err
+	}
+
+	file := &svn.File{
+		Checksum: stringAt(listAt(params, 0), 0),
+		Props:    parseProps(listAt(params, 2)),
+	}
+	if !withContent {
+		return file, nil
+	}
+
+	// O conteúdo chega como uma sequência de strings terminada por uma vazia
+	var content bytes.Buffer
+	for {
+		item, err := s.r.readItem()
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler conteúdo de '%s': %w", path, err)
+		}
+		if item.Kind != ItemString {
+			return nil, fmt.Errorf("conteúdo inesperado em '%s': %s", path, item)
+		}
+		if item.Str == "" {
+			break
+		}
+		content.WriteString(item.Str)
+	}
+	file.Content = content.Bytes()
+
+	if _, err := s.readResponse(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// GetLog obtém as entradas de log do caminho entre start e end, em ordem
+// decrescente quando start > end
+func (s *Session) GetLog(path string, start, end int64, limit int) ([]svn.LogEntry, error) {
+	revprops := []any{"svn:author", "svn:date", "svn:log"}
+	err := s.command("log", []any{path}, optRev(start), optRev(end), false, false, limit, false,
+		Word("revprops"), revprops)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []svn.LogEntry
+	for {
+		item, err := s.r.readItem()
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler log: %w", err)
+		}
+		if item.Kind == ItemWord && item.Str == "done" {
+			break
+		}
+		if item.Kind != ItemList {
+			return nil, fmt.Errorf("entrada de log inesperada: %s", item)
+		}
+
+		fields := item.List
+		entry := svn.LogEntry{
+			Revision: fmt.Sprintf("%d", numberAt(fields, 1)),
+			Author:   stringAt(listAt(fields, 2), 0),
+			Message:  strings.TrimSpace(stringAt(listAt(fields, 4), 0)),
+		}
+		if date := stringAt(listAt(fields, 3), 0); date != "" {
+			if entry.Date, err = time.Parse(time.RFC3339Nano, date); err != nil {
+				return nil, fmt.Errorf("data inválida na revisão %s: %w", entry.Revision, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	if _, err := s.readResponse(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// CompareTrees calcula no servidor as mudanças entre a URL da sessão em revA
+// e urlB em revB, usando o comando diff e o editor de diferenças sem deltas
+// de conteúdo, da mesma forma que svn diff --summarize
+func (s *Session) CompareTrees(revA int64, urlB string, revB int64) ([]svn.Change, error) {
+	urlB = strings.TrimSuffix(urlB, "/")
+	if s.root == "" || (urlB != s.root && !strings.HasPrefix(urlB, s.root+"/")) {
+		return nil, svn.ErrUnsupported
+	}
+
+	if err := s.command("diff", optRev(revB), "", true, true, urlB, false, Word("infinity")); err != nil {
+		return nil, err
+	}
+
+	// Relata que a árvore local corresponde à URL da sessão em revA
+	if err := s.send(Word("set-path"), []any{"", revA, false, []any{}, Word("infinity")}); err != nil {
+		return nil, err
+	}
+	if err := s.command("finish-report"); err != nil {
+		return nil, err
+	}
+
+	ed := newSummaryEditor()
+	if err := ed.drive(s); err != nil {
+		return nil, err
+	}
+	if _, err := s.readResponse(); err != nil {
+		return nil, err
+	}
+
+	// O editor não informa o tipo dos nós removidos
+	return ed.ResolveDeleted(func(path string) (svn.NodeKind, error) {
+		return s.CheckPath(path, revA)
+	})
+}
@@ -0,0 +1,86 @@
+package rasvn
+
+import (
+	"fmt"
+
+	"svndiff/internal/svn"
+)
+
+// summaryEditor recebe os comandos do editor de diferenças enviados pelo
+// svnserve, associando os tokens aos nós abertos
+type summaryEditor struct {
+	svn.SummaryEditor
+	nodes map[string]*svn.EditorNode
+}
+
+func newSummaryEditor() *summaryEditor {
+	return &summaryEditor{nodes: make(map[string]*svn.EditorNode)}
+}
+
+// drive processa os comandos do editor enviados pelo servidor até close-edit
+func (e *summaryEditor) drive(s *Session) error {
+	for {
+		item, err := s.r.readItem()
+		if err != nil {
+			return fmt.Errorf("erro ao ler comando do editor: %w", err)
+		}
+		cmd := stringAt(item.List, 0)
+		params := listAt(item.List, 1)
+
+		switch cmd {
+		case "failure":
+			return failureError(params)
+		case "close-edit":
+			return s.send(Word("success"), []any{})
+		case "abort-edit":
+			return fmt.Errorf("o servidor abortou a edição")
+		}
+		if err := e.handle(cmd, params); err != nil {
+			return err
+		}
+	}
+}
+
+// handle aplica um comando do editor
+func (e *summaryEditor) handle(cmd string, params []Item) error {
+	switch cmd {
+	case "target-rev", "textdelta-chunk", "textdelta-end", "absent-dir", "absent-file", "finish-replay":
+		// Sem efeito no resumo
+
+	case "open-root":
+		e.nodes[stringAt(params, 1)] = e.Open("", svn.NodeDir, false)
+
+	case "add-dir", "add-file", "open-dir", "open-file":
+		kind := svn.NodeDir
+		if cmd == "add-file" || cmd == "open-file" {
+			kind = svn.NodeFile
+		}
+		added := cmd == "add-dir" || cmd == "add-file"
+		e.nodes[stringAt(params, 2)] = e.Open(stringAt(params, 0), kind, added)
+
+	case "delete-entry":
+		e.Delete(stringAt(params, 0))
+
+	case "apply-textdelta":
+		if node, ok := e.nodes[stringAt(params, 0)]; ok {
+			node.Text = true
+		}
+
+	case "change-dir-prop", "change-file-prop":
+		e.ChangeProp(e.nodes[stringAt(params, 0)], stringAt(params, 1))
+
+	case "close-dir", "close-file":
+		token := stringAt(params, 0)
+		node, ok := e.nodes[token]
+		if !ok {
+			return fmt.Errorf("token desconhecido no editor: %s", token)
+		}
+		delete(e.nodes, token)
+		e.Close(node)
+
+	default:
+		return fmt.Errorf("comando de editor não suportado: %s", cmd)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package rasvn
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+// TestIntegration_Svnserve executa o cliente contra um svnserve local.
+// É ignorado quando svnadmin e svnserve não estão instalados.
+func TestIntegration_Svnserve(t *testing.T) {
+	if _, err := exec.LookPath("svnserve"); err != nil {
+		t.Skip("svnserve não encontrado no PATH")
+	}
+
+	root := t.TempDir()
+	svntest.CreateRepository(t, filepath.Join(root, "repo"), svntest.WriteDump([][]svntest.DumpNode{
+		{
+			{Path: "trunk", Kind: "dir", Action: "add"},
+			{Path: "branches", Kind: "dir", Action: "add"},
+			{Path: "trunk/a.txt", Kind: "file", Action: "add", Content: "um\ndois\n"},
+		},
+		{
+			{Path: "branches/x", Kind: "dir", Action: "add", CopyFrom: "trunk", CopyRev: 1},
+		},
+		{
+			{Path: "branches/x/a.txt", Kind: "file", Action: "change", Content: "um\n2\n"},
+			{Path: "branches/x/new.txt", Kind: "file", Action: "add", Content: "novo\n"},
+		},
+	}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	listener.Close()
+
+	server := exec.Command("svnserve", "-d", "--foreground", "-r", root,
+		"--listen-host", "127.0.0.1", "--listen-port", fmt.Sprint(addr.Port))
+	if err := server.Start(); err != nil {
+		t.Fatalf("svnserve: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = server.Process.Kill()
+		_ = server.Wait()
+	})
+
+	base := fmt.Sprintf("svn://127.0.0.1:%d/repo", addr.Port)
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		conn, err := net.Dial("tcp", addr.String())
+		if err == nil {
+			conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("svnserve não respondeu: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	backend := svn.NewRepositoryBackend(func(url string) (svn.Repository, error) {
+		return Dial(url, "", "")
+	})
+	branchA := &config.BranchConfig{URL: base + "/trunk", Revisions: []string{"1"}}
+	branchB := &config.BranchConfig{URL: base + "/branches/x", Revisions: []string{"3"}}
+
+	summary, err := backend.GetDiff(branchA, branchB, true)
+	if err != nil {
+		t.Fatalf("GetDiff(summarize) error = %v", err)
+	}
+	got := svntest.SummaryLines(t, summary.Output)
+	for _, want := range []string{"M       a.txt", "A       new.txt"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GetDiff(summarize) output não contém %q:\n%s", want, got)
+		}
+	}
+
+	full, err := backend.GetDiff(branchA, branchB, false)
+	if err != nil {
+		t.Fatalf("GetDiff() error = %v", err)
+	}
+	if !strings.Contains(full.Output, "-dois\n+2\n") {
+		t.Errorf("GetDiff() output inesperado:\n%s", full.Output)
+	}
+
+	// O log acompanha a cópia de trunk: r3, r2 e r1
+	entries, err := backend.GetLogEntries(branchB.URL, svn.LogOptions{Range: "HEAD:1"})
+	if err != nil || len(entries) != 3 {
+		t.Errorf("GetLogEntries() = %+v, %v", entries, err)
+	}
+}
@@ -0,0 +1,234 @@
+package rasvn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ItemKind identifica o tipo de um item do protocolo
+type ItemKind int
+
+const (
+	ItemNumber ItemKind = iota
+	ItemString
+	ItemWord
+	ItemList
+)
+
+// maxStringLength limita o tamanho de uma string recebida em uma única leitura
+const maxStringLength = 1 << 30
+
+// Item é um elemento do protocolo svn: número, string, palavra ou lista
+type Item struct {
+	Kind   ItemKind
+	Number uint64
+	Str    string
+	List   []Item
+}
+
+// Word constrói uma palavra do protocolo ao codificar mensagens
+type Word string
+
+// String formata o item na sintaxe do protocolo, útil para mensagens de erro
+func (it Item) String() string {
+	switch it.Kind {
+	case ItemNumber:
+		return strconv.FormatUint(it.Number, 10)
+	case ItemString:
+		return strconv.Quote(it.Str)
+	case ItemWord:
+		return it.Str
+	}
+	s := "("
+	for _, child := range it.List {
+		s += " " + child.String()
+	}
+	return s + " )"
+}
+
+// reader lê itens do protocolo a partir da conexão
+type reader struct {
+	r *bufio.Reader
+}
+
+// readItem lê o próximo item, ignorando espaços em branco anteriores
+func (r *reader) readItem() (Item, error) {
+	c, err := r.skipSpace()
+	if err != nil {
+		return Item{}, err
+	}
+	return r.readItemFrom(c)
+}
+
+// readItemFrom lê um item cujo primeiro byte já foi consumido
+func (r *reader) readItemFrom(c byte) (Item, error) {
+	switch {
+	case c == '(':
+		var list []Item
+		for {
+			c, err := r.skipSpace()
+			if err != nil {
+				return Item{}, err
+			}
+			if c == ')' {
+				return Item{Kind: ItemList, List: list}, nil
+			}
+			item, err := r.readItemFrom(c)
+			if err != nil {
+				return Item{}, err
+			}
+			list = append(list, item)
+		}
+
+	case c >= '0' && c <= '9':
+		n := uint64(c - '0')
+		for {
+			c, err := r.r.ReadByte()
+			if err != nil {
+				return Item{}, err
+			}
+			switch {
+			case c >= '0' && c <= '9':
+				if n > (1<<63)/10 {
+					return Item{}, fmt.Errorf("número muito grande no protocolo")
+				}
+				n = n*10 + uint64(c-'0')
+			case c == ':':
+				if n > maxStringLength {
+					return Item{}, fmt.Errorf("string muito grande no protocolo: %d bytes", n)
+				}
+				buf := make([]byte, n)
+				if _, err := io.ReadFull(r.r, buf); err != nil {
+					return Item{}, err
+				}
+				if err := r.expectSpace(); err != nil {
+					return Item{}, err
+				}
+				return Item{Kind: ItemString, Str: string(buf)}, nil
+			case isSpace(c):
+				return Item{Kind: ItemNumber, Number: n}, nil
+			default:
+				return Item{}, fmt.Errorf("caractere inesperado '%c' em número", c)
+			}
+		}
+
+	case isAlpha(c):
+		word := []byte{c}
+		for {
+			c, err := r.r.ReadByte()
+			if err != nil {
+				return Item{}, err
+			}
+			if isSpace(c) {
+				return Item{Kind: ItemWord, Str: string(word)}, nil
+			}
+			if !isAlpha(c) && !(c >= '0' && c <= '9') && c != '-' {
+				return Item{}, fmt.Errorf("caractere inesperado '%c' em palavra", c)
+			}
+			word = append(word, c)
+		}
+	}
+
+	return Item{}, fmt.Errorf("caractere inesperado '%c' no protocolo", c)
+}
+
+// skipSpace consome espaços em branco e retorna o próximo byte
+func (r *reader) skipSpace() (byte, error) {
+	for {
+		c, err := r.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !isSpace(c) {
+			return c, nil
+		}
+	}
+}
+
+// expectSpace consome o espaço em branco obrigatório após um item
+func (r *reader) expectSpace() error {
+	c, err := r.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if !isSpace(c) {
+		return fmt.Errorf("esperado espaço após item, recebido '%c'", c)
+	}
+	return nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n'
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// encode serializa valores Go na sintaxe do protocolo: inteiros viram
+// números, string vira string, Word e bool viram palavras e []any vira lista
+func encode(buf []byte, values ...any) []byte {
+	for _, v := range values {
+		switch v := v.(type) {
+		case int:
+			buf = strconv.AppendInt(buf, int64(v), 10)
+		case int64:
+			buf = strconv.AppendInt(buf, v, 10)
+		case uint64:
+			buf = strconv.AppendUint(buf, v, 10)
+		case string:
+			buf = strconv.AppendInt(buf, int64(len(v)), 10)
+			buf = append(buf, ':')
+			buf = append(buf, v...)
+		case Word:
+			buf = append(buf, v...)
+		case bool:
+			if v {
+				buf = append(buf, "true"...)
+			} else {
+				buf = append(buf, "false"...)
+			}
+		case []any:
+			buf = append(buf, "( "...)
+			buf = encode(buf, v...)
+			buf = append(buf, ')')
+		default:
+			panic(fmt.Sprintf("rasvn: tipo não suportado na codificação: %T", v))
+		}
+		buf = append(buf, ' ')
+	}
+	return buf
+}
+
+// Funções de acesso tolerantes usadas na interpretação das respostas
+
+// listAt retorna a lista na posição i, ou nil se ausente
+func listAt(items []Item, i int) []Item {
+	if i < len(items) && items[i].Kind == ItemList {
+		return items[i].List
+	}
+	return nil
+}
+
+// stringAt retorna a string ou palavra na posição i, ou "" se ausente
+func stringAt(items []Item, i int) string {
+	if i < len(items) && (items[i].Kind == ItemString || items[i].Kind == ItemWord) {
+		return items[i].Str
+	}
+	return ""
+}
+
+// numberAt retorna o número na posição i, ou -1 se ausente
+func numberAt(items []Item, i int) int64 {
+	if i < len(items) && items[i].Kind == ItemNumber {
+		return int64(items[i].Number)
+	}
+	return -1
+}
+
+// boolAt interpreta a palavra true/false na posição i
+func boolAt(items []Item, i int) bool {
+	return stringAt(items, i) == "true"
+}
@@ -0,0 +1,640 @@
+package rasvn
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+
+	"svndiff/internal/svn"
+	"svndiff/pkg/config"
+)
+
+// fakeNode é um nó do repositório em memória do servidor de teste
+type fakeNode struct {
+	kind    string
+	content string
+	props   map[string]string
+}
+
+// fakeRevision é o estado do repositório em uma revisão
+type fakeRevision struct {
+	author  string
+	message string
+	date    string
+	tree    map[string]*fakeNode
+}
+
+// fakeServer simula um svnserve com um repositório em memória
+type fakeServer struct {
+	t         *testing.T
+	listener  net.Listener
+	root      string
+	revisions []fakeRevision
+	users     map[string]string
+}
+
+// newFakeServer inicia o servidor com o histórico de teste:
+// r1 cria trunk, r2 copia trunk para branches/x e r3 altera a branch.
+// Com users não vazio o servidor exige autenticação CRAM-MD5.
+func newFakeServer(t *testing.T, users map[string]string) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+
+	dir := func() *fakeNode { return &fakeNode{kind: "dir", props: map[string]string{}} }
+	file := func(content string, props map[string]string) *fakeNode {
+		if props == nil {
+			props = map[string]string{}
+		}
+		return &fakeNode{kind: "file", content: content, props: props}
+	}
+
+	r1 := map[string]*fakeNode{
+		"":                 dir(),
+		"trunk":            dir(),
+		"trunk/a.txt":      file("linha 1\nlinha 2\nlinha 3\n", nil),
+		"trunk/b.txt":      file("b\n", nil),
+		"trunk/sub":        dir(),
+		"trunk/sub/c.txt":  file("c\n", nil),
+		"trunk/image.bin":  file("\x00\x01", map[string]string{"svn:mime-type": "application/octet-stream"}),
+		"trunk/unchanged":  file("igual\n", nil),
+		"trunk/sub/d.txt":  file("d", nil),
+		"branches":         dir(),
+		"trunk/sub/.empty": file("", nil),
+	}
+
+	r2 := copyTree(r1)
+	for path, node := range r1 {
+		if strings.HasPrefix(path, "trunk") {
+			r2["branches/x"+strings.TrimPrefix(path, "trunk")] = node
+		}
+	}
+
+	r3 := copyTree(r2)
+	r3["branches/x/a.txt"] = file("linha 1\nlinha dois\nlinha 3\n", nil)
+	r3["branches/x/b.txt"] = file("b\n", map[string]string{"svn:eol-style": "native"})
+	r3["branches/x/new.txt"] = file("novo\n", nil)
+	for path := range r3 {
+		if strings.HasPrefix(path, "branches/x/sub") {
+			delete(r3, path)
+		}
+	}
+
+	s := &fakeServer{
+		t:        t,
+		listener: listener,
+		users:    users,
+		root:     "svn://" + listener.Addr().String() + "/repo",
+		revisions: []fakeRevision{
+			{tree: map[string]*fakeNode{"": dir()}},
+			{author: "alice", message: "PROJ-1: cria trunk", date: "2024-03-01T10:00:00.000000Z", tree: r1},
+			{author: "bob", message: "cria branch x", date: "2024-03-02T10:00:00.000000Z", tree: r2},
+			{author: "alice", message: "PROJ-1: ajustes na branch", date: "2024-03-03T10:00:00.000000Z", tree: r3},
+		},
+	}
+
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func copyTree(tree map[string]*fakeNode) map[string]*fakeNode {
+	copied := make(map[string]*fakeNode, len(tree))
+	for path, node := range tree {
+		copied[path] = node
+	}
+	return copied
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// fakeConn é a conexão de um cliente com o servidor de teste
+type fakeConn struct {
+	server *fakeServer
+	conn   net.Conn
+	r      reader
+	w      *bufio.Writer
+	base   string
+}
+
+func (c *fakeConn) write(values ...any) {
+	c.w.Write(encode(nil, []any(values)))
+	c.w.Flush()
+}
+
+func (c *fakeConn) read() Item {
+	item, err := c.r.readItem()
+	if err != nil {
+		panic(err)
+	}
+	return item
+}
+
+func (c *fakeConn) success(params ...any) {
+	c.write(Word("success"), params)
+}
+
+func (c *fakeConn) failure(message string) {
+	c.write(Word("failure"), []any{[]any{210000, message, "fake.c", 1}})
+}
+
+func (c *fakeConn) trivialAuth() {
+	c.success([]any{}, "")
+}
+
+func (s *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+	defer func() {
+		// Conexões encerradas pelo cliente terminam a goroutine
+		_ = recover()
+	}()
+
+	c := &fakeConn{server: s, conn: conn, r: reader{r: bufio.NewReader(conn)}, w: bufio.NewWriter(conn)}
+	c.success(2, 2, []any{}, []any{Word("edit-pipeline"), Word("svndiff1"), Word("depth"), Word("log-revprops")})
+
+	response := c.read().List
+	sessionURL := stringAt(response, 2)
+	if !strings.HasPrefix(sessionURL, s.root) {
+		c.failure("URL fora do repositório")
+		return
+	}
+	c.base = strings.Trim(strings.TrimPrefix(sessionURL, s.root), "/")
+
+	if !s.authenticate(c) {
+		return
+	}
+	c.success("uuid-teste", s.root, []any{Word("mergeinfo")})
+
+	for {
+		cmd := c.read().List
+		name := stringAt(cmd, 0)
+		params := listAt(cmd, 1)
+		switch name {
+		case "get-latest-rev":
+			c.trivialAuth()
+			c.success(len(s.revisions) - 1)
+		case "get-dated-rev":
+			c.trivialAuth()
+			c.success(2)
+		case "check-path":
+			c.trivialAuth()
+			node := s.lookup(c, stringAt(params, 0), listAt(params, 1))
+			kind := "none"
+			if node != nil {
+				kind = node.kind
+			}
+			c.success(Word(kind))
+		case "get-dir":
+			c.trivialAuth()
+			s.getDir(c, params)
+		case "get-file":
+			c.trivialAuth()
+			s.getFile(c, params)
+		case "log":
+			c.trivialAuth()
+			s.log(c, params)
+		case "diff":
+			c.trivialAuth()
+			s.diff(c, params)
+		default:
+			c.trivialAuth()
+			c.failure("comando desconhecido: " + name)
+		}
+	}
+}
+
+// authenticate trata a autenticação inicial, anônima ou CRAM-MD5
+func (s *fakeServer) authenticate(c *fakeConn) bool {
+	if len(s.users) == 0 {
+		c.success([]any{Word("ANONYMOUS")}, "realm")
+		if mech := stringAt(c.read().List, 0); mech != "ANONYMOUS" {
+			c.write(Word("failure"), []any{"mecanismo inesperado"})
+			return false
+		}
+		c.success()
+		return true
+	}
+
+	c.success([]any{Word("CRAM-MD5")}, "realm")
+	if mech := stringAt(c.read().List, 0); mech != "CRAM-MD5" {
+		c.write(Word("failure"), []any{"mecanismo inesperado"})
+		return false
+	}
+	challenge := "<1234.5678@fake>"
+	c.write(Word("step"), []any{challenge})
+
+	reply := strings.SplitN(c.read().Str, " ", 2)
+	password, ok := s.users[reply[0]]
+	mac := hmac.New(md5.New, []byte(password))
+	mac.Write([]byte(challenge))
+	if !ok || len(reply) != 2 || reply[1] != hex.EncodeToString(mac.Sum(nil)) {
+		c.write(Word("failure"), []any{"Username not found or password incorrect"})
+		return false
+	}
+	c.success()
+	return true
+}
+
+// revision resolve uma revisão opcional, usando HEAD quando ausente
+func (s *fakeServer) revision(rev []Item) int {
+	if len(rev) == 0 {
+		return len(s.revisions) - 1
+	}
+	return int(rev[0].Number)
+}
+
+func (s *fakeServer) fullPath(c *fakeConn, path string) string {
+	return strings.Trim(c.base+"/"+path, "/")
+}
+
+func (s *fakeServer) lookup(c *fakeConn, path string, rev []Item) *fakeNode {
+	return s.revisions[s.revision(rev)].tree[s.fullPath(c, path)]
+}
+
+// children retorna os filhos diretos de um diretório, ordenados
+func children(tree map[string]*fakeNode, dir string) []string {
+	var names []string
+	for path := range tree {
+		if path == "" || path == dir {
+			continue
+		}
+		parent, name := "", path
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			parent, name = path[:i], path[i+1:]
+		}
+		if parent == dir {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func proplist(props map[string]string, entry bool) []any {
+	list := []any{}
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		list = append(list, []any{name, props[name]})
+	}
+	if entry {
+		// O svnserve também envia as propriedades internas do nó
+		list = append(list, []any{"svn:entry:committed-rev", "1"})
+	}
+	return list
+}
+
+func (s *fakeServer) getDir(c *fakeConn, params []Item) {
+	rev := s.revision(listAt(params, 1))
+	tree := s.revisions[rev].tree
+	path := s.fullPath(c, stringAt(params, 0))
+	node := tree[path]
+	if node == nil || node.kind != "dir" {
+		c.failure("diretório não encontrado: " + path)
+		return
+	}
+
+	entries := []any{}
+	for _, name := range children(tree, path) {
+		child := tree[strings.Trim(path+"/"+name, "/")]
+		entries = append(entries, []any{name, Word(child.kind), len(child.content), len(child.props) > 0, rev})
+	}
+	c.success(rev, proplist(node.props, true), entries)
+}
+
+func (s *fakeServer) getFile(c *fakeConn, params []Item) {
+	node := s.lookup(c, stringAt(params, 0), listAt(params, 1))
+	if node == nil || node.kind != "file" {
+		c.failure("arquivo não encontrado")
+		return
+	}
+	checksum := fmt.Sprintf("%x", md5.Sum([]byte(node.content)))
+	c.success([]any{checksum}, s.revision(listAt(params, 1)), proplist(node.props, true))
+
+	if boolAt(params, 3) {
+		// Conteúdo enviado em blocos, terminado por uma string vazia
+		content := node.content
+		for len(content) > 4 {
+			c.w.Write(encode(nil, content[:4]))
+			content = content[4:]
+		}
+		if content != "" {
+			c.w.Write(encode(nil, content))
+		}
+		c.w.Write(encode(nil, ""))
+		c.w.Flush()
+		c.success()
+	}
+}
+
+func (s *fakeServer) log(c *fakeConn, params []Item) {
+	start := s.revision(listAt(params, 1))
+	end := s.revision(listAt(params, 2))
+	limit := int(numberAt(params, 5))
+
+	step := -1
+	if start < end {
+		step = 1
+	}
+	count := 0
+	for rev := start; ; rev += step {
+		if rev > 0 && (limit == 0 || count < limit) {
+			r := s.revisions[rev]
+			c.write([]any{}, rev, []any{r.author}, []any{r.date}, []any{r.message}, false, false, 0, []any{})
+			count++
+		}
+		if rev == end {
+			break
+		}
+	}
+	c.w.WriteString("done ")
+	c.w.Flush()
+	c.success()
+}
+
+// diff simula o relatório e o editor de diferenças do svnserve
+func (s *fakeServer) diff(c *fakeConn, params []Item) {
+	revB := s.revision(listAt(params, 0))
+	urlB := stringAt(params, 4)
+	pathB := strings.Trim(strings.TrimPrefix(urlB, s.root), "/")
+
+	setPath := c.read().List
+	revA := int(numberAt(listAt(setPath, 1), 1))
+	if finish := stringAt(c.read().List, 0); finish != "finish-report" {
+		panic("finish-report esperado")
+	}
+	c.trivialAuth()
+
+	treeA := s.revisions[revA].tree
+	treeB := s.revisions[revB].tree
+	tokens := 0
+	token := func() string { tokens++; return fmt.Sprintf("t%d", tokens) }
+
+	var addTree func(path, parent string)
+	addTree = func(rel, parent string) {
+		node := treeB[strings.Trim(pathB+"/"+rel, "/")]
+		t := token()
+		if node.kind == "dir" {
+			c.write(Word("add-dir"), []any{rel, parent, t, []any{}})
+			for _, name := range children(treeB, strings.Trim(pathB+"/"+rel, "/")) {
+				addTree(rel+"/"+name, t)
+			}
+			c.write(Word("close-dir"), []any{t})
+			return
+		}
+		c.write(Word("add-file"), []any{rel, parent, t, []any{}})
+		c.write(Word("apply-textdelta"), []any{t, []any{}})
+		c.write(Word("textdelta-end"), []any{t})
+		c.write(Word("change-file-prop"), []any{t, "svn:entry:committed-rev", []any{"3"}})
+		c.write(Word("close-file"), []any{t, []any{}})
+	}
+
+	var compare func(rel, dirToken string)
+	compare = func(rel, dirToken string) {
+		dirA := strings.Trim(c.base+"/"+rel, "/")
+		dirB := strings.Trim(pathB+"/"+rel, "/")
+		names := map[string]bool{}
+		for _, n := range children(treeA, dirA) {
+			names[n] = true
+		}
+		for _, n := range children(treeB, dirB) {
+			names[n] = true
+		}
+		sorted := make([]string, 0, len(names))
+		for n := range names {
+			sorted = append(sorted, n)
+		}
+		sort.Strings(sorted)
+
+		for _, name := range sorted {
+			childRel := strings.TrimPrefix(rel+"/"+name, "/")
+			a := treeA[dirA+"/"+name]
+			b := treeB[dirB+"/"+name]
+			switch {
+			case b == nil:
+				c.write(Word("delete-entry"), []any{childRel, []any{revA}, dirToken})
+			case a == nil:
+				addTree(childRel, dirToken)
+			case a.kind == "dir":
+				t := token()
+				c.write(Word("open-dir"), []any{childRel, dirToken, t, []any{revA}})
+				compare(childRel, t)
+				c.write(Word("close-dir"), []any{t})
+			case a.content != b.content || fmt.Sprint(a.props) != fmt.Sprint(b.props):
+				t := token()
+				c.write(Word("open-file"), []any{childRel, dirToken, t, []any{revA}})
+				if a.content != b.content {
+					c.write(Word("apply-textdelta"), []any{t, []any{}})
+					c.write(Word("textdelta-end"), []any{t})
+				}
+				for prop, value := range b.props {
+					if a.props[prop] != value {
+						c.write(Word("change-file-prop"), []any{t, prop, []any{value}})
+					}
+				}
+				c.write(Word("change-file-prop"), []any{t, "svn:entry:committed-rev", []any{"3"}})
+				c.write(Word("close-file"), []any{t, []any{}})
+			}
+		}
+	}
+
+	c.write(Word("target-rev"), []any{revB})
+	root := token()
+	c.write(Word("open-root"), []any{[]any{revA}, root})
+	compare("", root)
+	c.write(Word("close-dir"), []any{root})
+	c.write(Word("close-edit"), []any{})
+
+	if status := stringAt(c.read().List, 0); status != "success" {
+		panic("resposta de close-edit esperada")
+	}
+	c.success()
+}
+
+func TestSession_Commands(t *testing.T) {
+	server := newFakeServer(t, nil)
+
+	s, err := Dial(server.root+"/trunk", "", "")
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer s.Close()
+
+	if s.UUID() != "uuid-teste" || s.RootURL() != server.root {
+		t.Errorf("Dial() uuid = %s, root = %s", s.UUID(), s.RootURL())
+	}
+
+	latest, err := s.LatestRevision()
+	if err != nil || latest != 3 {
+		t.Fatalf("LatestRevision() = %d, %v", latest, err)
+	}
+
+	kind, err := s.CheckPath("sub", 1)
+	if err != nil || kind != svn.NodeDir {
+		t.Errorf("CheckPath(sub) = %s, %v", kind, err)
+	}
+	kind, err = s.CheckPath("nao-existe", 1)
+	if err != nil || kind != svn.NodeNone {
+		t.Errorf("CheckPath(nao-existe) = %s, %v", kind, err)
+	}
+
+	dir, err := s.GetDir("", 1)
+	if err != nil {
+		t.Fatalf("GetDir() error = %v", err)
+	}
+	var names []string
+	for _, entry := range dir.Entries {
+		names = append(names, entry.Name+":"+string(entry.Kind))
+	}
+	if got := strings.Join(names, ","); got != "a.txt:file,b.txt:file,image.bin:file,sub:dir,unchanged:file" {
+		t.Errorf("GetDir() entries = %s", got)
+	}
+	if len(dir.Props) != 0 {
+		t.Errorf("GetDir() props = %v, propriedades internas devem ser descartadas", dir.Props)
+	}
+
+	file, err := s.GetFile("a.txt", 1, true)
+	if err != nil {
+		t.Fatalf("GetFile() error = %v", err)
+	}
+	if string(file.Content) != "linha 1\nlinha 2\nlinha 3\n" {
+		t.Errorf("GetFile() content = %q", file.Content)
+	}
+	if file.Checksum != fmt.Sprintf("%x", md5.Sum(file.Content)) {
+		t.Errorf("GetFile() checksum = %s", file.Checksum)
+	}
+
+	entries, err := s.GetLog("", 3, 1, 2)
+	if err != nil {
+		t.Fatalf("GetLog() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Revision != "3" || entries[1].Author != "bob" {
+		t.Errorf("GetLog() = %+v", entries)
+	}
+	if entries[0].Message != "PROJ-1: ajustes na branch" || entries[0].Date.Day() != 3 {
+		t.Errorf("GetLog()[0] = %+v", entries[0])
+	}
+}
+
+func TestSession_CompareTrees(t *testing.T) {
+	server := newFakeServer(t, nil)
+
+	s, err := Dial(server.root+"/trunk", "", "")
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer s.Close()
+
+	changes, err := s.CompareTrees(1, server.root+"/branches/x", 3)
+	if err != nil {
+		t.Fatalf("CompareTrees() error = %v", err)
+	}
+
+	var lines []string
+	for _, change := range changes {
+		lines = append(lines, change.SummaryLine()+" ("+string(change.Kind)+")")
+	}
+	sort.Strings(lines)
+	want := []string{
+		"A       new.txt (file)",
+		"D       sub (dir)",
+		"M       a.txt (file)",
+		" M      b.txt (file)",
+	}
+	sort.Strings(want)
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CompareTrees() =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+
+	if _, err := s.CompareTrees(1, "svn://outro/repo/trunk", 3); err != svn.ErrUnsupported {
+		t.Errorf("CompareTrees() em outro repositório error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestRepositoryBackend_GetDiff(t *testing.T) {
+	server := newFakeServer(t, nil)
+	backend := svn.NewRepositoryBackend(func(url string) (svn.Repository, error) {
+		return Dial(url, "", "")
+	})
+
+	branchA := &config.BranchConfig{URL: server.root + "/trunk", Revisions: []string{"1"}}
+	branchB := &config.BranchConfig{URL: server.root + "/branches/x", Revisions: []string{"3"}}
+
+	result, err := backend.GetDiff(branchA, branchB, false)
+	if err != nil {
+		t.Fatalf("GetDiff() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"Index: a.txt\n===================================================================\n--- a.txt\t(revision 1)\n+++ a.txt\t(revision 3)\n@@ -1,3 +1,3 @@\n linha 1\n-linha 2\n+linha dois\n linha 3\n",
+		"--- new.txt\t(nonexistent)\n+++ new.txt\t(revision 3)\n@@ -0,0 +1 @@\n+novo\n",
+		"--- sub/d.txt\t(revision 1)\n+++ sub/d.txt\t(nonexistent)\n@@ -1 +0,0 @@\n-d\n\\ No newline at end of file\n",
+		"Property changes on: b.txt\n___________________________________________________________________\nAdded: svn:eol-style\n## -0,0 +1 ##\n+native\n\\ No newline at end of property\n",
+	} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("GetDiff() output não contém:\n%s\n--- output:\n%s", want, result.Output)
+		}
+	}
+
+	if err := backend.CheckConnection(server.root + "/nao-existe"); err == nil {
+		t.Error("CheckConnection() expected error for missing path")
+	}
+
+	entries, err := backend.GetLogEntries(branchB.URL, svn.LogOptions{Range: "HEAD:1", Limit: 10})
+	if err != nil || len(entries) != 3 {
+		t.Errorf("GetLogEntries() = %+v, %v", entries, err)
+	}
+}
+
+func TestDial_CRAMMD5(t *testing.T) {
+	server := newFakeServer(t, map[string]string{"alice": "segredo"})
+
+	s, err := Dial(server.root+"/trunk", "alice", "segredo")
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	s.Close()
+
+	if _, err := Dial(server.root+"/trunk", "alice", "errada"); err == nil {
+		t.Error("Dial() expected error for wrong password")
+	}
+	if _, err := Dial(server.root+"/trunk", "", ""); err == nil {
+		t.Error("Dial() expected error without credentials")
+	}
+}
+
+func TestReader_ReadItem(t *testing.T) {
+	input := "( success ( 2 2 ( ) ( edit-pipeline svndiff1 ) 5:a b\nc ) ) "
+	r := reader{r: bufio.NewReader(strings.NewReader(input))}
+
+	item, err := r.readItem()
+	if err != nil {
+		t.Fatalf("readItem() error = %v", err)
+	}
+	if got := item.String(); got != `( success ( 2 2 ( ) ( edit-pipeline svndiff1 ) "a b\nc" ) )` {
+		t.Errorf("readItem() = %s", got)
+	}
+
+	encoded := string(encode(nil, []any{Word("get-file"), []any{"a b", []any{int64(3)}, true, false}}))
+	if encoded != "( get-file ( 3:a b ( 3 ) true false ) ) " {
+		t.Errorf("encode() = %q", encoded)
+	}
+}
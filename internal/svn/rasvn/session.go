@@ -0,0 +1,291 @@
+// Package rasvn implementa um cliente em Go puro para o protocolo ra_svn,
+// usado pelo svnserve em URLs svn://, dispensando o comando svn.
+package rasvn
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"svndiff/internal/svn"
+)
+
+// DefaultPort é a porta padrão do svnserve
+const DefaultPort = "3690"
+
+// dialTimeout limita o tempo de estabelecimento da conexão
+const dialTimeout = 30 * time.Second
+
+// clientCapabilities são as capacidades anunciadas ao servidor
+var clientCapabilities = []any{
+	Word("edit-pipeline"), Word("svndiff1"), Word("absent-entries"),
+	Word("depth"), Word("mergeinfo"), Word("log-revprops"),
+}
+
+// Session é uma conexão autenticada com um servidor svnserve, ancorada na
+// URL informada em Dial
+type Session struct {
+	conn     net.Conn
+	r        reader
+	w        *bufio.Writer
+	url      string
+	root     string
+	uuid     string
+	caps     map[string]bool
+	user     string
+	password string
+}
+
+// Dial conecta ao svnserve da URL e autentica com as credenciais
+// informadas; sem usuário é usada autenticação anônima
+func Dial(rawURL, user, password string) (*Session, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("URL inválida '%s': %w", rawURL, err)
+	}
+	if u.Scheme != "svn" {
+		return nil, fmt.Errorf("esquema não suportado pelo cliente ra_svn: %s", u.Scheme)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), DefaultPort)
+	}
+
+	conn, err := net.DialTimeout("tcp", host, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar em %s: %w", host, err)
+	}
+
+	s := newSession(conn, strings.TrimSuffix(rawURL, "/"), user, password)
+	if err := s.handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// newSession cria a sessão sobre uma conexão já estabelecida
+func newSession(conn net.Conn, sessionURL, user, password string) *Session {
+	return &Session{
+		conn:     conn,
+		r:        reader{r: bufio.NewReader(conn)},
+		w:        bufio.NewWriter(conn),
+		url:      sessionURL,
+		caps:     make(map[string]bool),
+		user:     user,
+		password: password,
+	}
+}
+
+// Close encerra a conexão
+func (s *Session) Close() error {
+	return s.conn.Close()
+}
+
+// UUID retorna o identificador do repositório
+func (s *Session) UUID() string {
+	return s.uuid
+}
+
+// RootURL retorna a URL raiz do repositório
+func (s *Session) RootURL() string {
+	return s.root
+}
+
+// handshake troca as mensagens iniciais do protocolo e autentica a sessão
+func (s *Session) handshake() error {
+	greeting, err := s.readResponse()
+	if err != nil {
+		return fmt.Errorf("erro na saudação do servidor: %w", err)
+	}
+	if numberAt(greeting, 0) > 2 || numberAt(greeting, 1) < 2 {
+		return fmt.Errorf("versão do protocolo não suportada pelo servidor: %d..%d",
+			numberAt(greeting, 0), numberAt(greeting, 1))
+	}
+	for _, cap := range listAt(greeting, 3) {
+		s.caps[cap.Str] = true
+	}
+	if !s.caps["edit-pipeline"] {
+		return fmt.Errorf("servidor svnserve muito antigo: edit-pipeline não suportado")
+	}
+
+	if err := s.send(2, clientCapabilities, s.url, "svndiff/1.0", []any{}); err != nil {
+		return err
+	}
+	if err := s.handleAuth(); err != nil {
+		return err
+	}
+
+	info, err := s.readResponse()
+	if err != nil {
+		return err
+	}
+	s.uuid = stringAt(info, 0)
+	s.root = strings.TrimSuffix(stringAt(info, 1), "/")
+	for _, cap := range listAt(info, 2) {
+		s.caps[cap.Str] = true
+	}
+
+	return nil
+}
+
+// handleAuth trata a requisição de autenticação que o servidor envia no
+// início da sessão e antes da resposta de cada comando
+func (s *Session) handleAuth() error {
+	params, err := s.readResponse()
+	if err != nil {
+		return fmt.Errorf("erro na autenticação: %w", err)
+	}
+
+	mechs := make(map[string]bool)
+	for _, mech := range listAt(params, 0) {
+		mechs[mech.Str] = true
+	}
+	if len(mechs) == 0 {
+		return nil
+	}
+
+	switch {
+	case s.user != "" && mechs["CRAM-MD5"]:
+		return s.authCRAMMD5()
+	case s.user != "" && mechs["PLAIN"]:
+		token := "\x00" + s.user + "\x00" + s.password
+		if err := s.send(Word("PLAIN"), []any{token}); err != nil {
+			return err
+		}
+	case mechs["ANONYMOUS"]:
+		if err := s.send(Word("ANONYMOUS"), []any{""}); err != nil {
+			return err
+		}
+	case s.user == "":
+		return fmt.Errorf("o servidor exige autenticação, informe usuário e senha")
+	default:
+		return fmt.Errorf("nenhum mecanismo de autenticação suportado: %v", listAt(params, 0))
+	}
+
+	return s.readAuthResult(nil)
+}
+
+// authCRAMMD5 executa a autenticação CRAM-MD5 do svnserve
+func (s *Session) authCRAMMD5() error {
+	if err := s.send(Word("CRAM-MD5"), []any{}); err != nil {
+		return err
+	}
+	return s.readAuthResult(func(challenge string) string {
+		mac := hmac.New(md5.New, []byte(s.password))
+		mac.Write([]byte(challenge))
+		return s.user + " " + hex.EncodeToString(mac.Sum(nil))
+	})
+}
+
+// readAuthResult lê as respostas do mecanismo de autenticação até o
+// resultado final, respondendo aos desafios com step
+func (s *Session) readAuthResult(step func(challenge string) string) error {
+	for {
+		item, err := s.r.readItem()
+		if err != nil {
+			return err
+		}
+		status := stringAt(item.List, 0)
+		params := listAt(item.List, 1)
+
+		switch {
+		case status == "success":
+			return nil
+		case status == "failure":
+			return fmt.Errorf("autenticação recusada: %s", stringAt(params, 0))
+		case status == "step" && step != nil:
+			if err := s.sendRaw(step(stringAt(params, 0))); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("resposta de autenticação inesperada: %s", item)
+		}
+	}
+}
+
+// send escreve uma lista com os valores informados e envia ao servidor
+func (s *Session) send(values ...any) error {
+	return s.sendRaw([]any(values))
+}
+
+// sendRaw codifica um único valor e envia ao servidor
+func (s *Session) sendRaw(value any) error {
+	if _, err := s.w.Write(encode(nil, value)); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+// command envia um comando e trata a requisição de autenticação que o
+// precede, deixando a resposta para ser lida pelo chamador
+func (s *Session) command(name string, params ...any) error {
+	if err := s.send(Word(name), params); err != nil {
+		return fmt.Errorf("erro ao enviar comando %s: %w", name, err)
+	}
+	return s.handleAuth()
+}
+
+// readResponse lê uma resposta de comando e retorna seus parâmetros,
+// convertendo respostas de falha em erro
+func (s *Session) readResponse() ([]Item, error) {
+	item, err := s.r.readItem()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler resposta do servidor: %w", err)
+	}
+	if item.Kind != ItemList {
+		return nil, fmt.Errorf("resposta inesperada do servidor: %s", item)
+	}
+
+	switch stringAt(item.List, 0) {
+	case "success":
+		return listAt(item.List, 1), nil
+	case "failure":
+		return nil, failureError(listAt(item.List, 1))
+	}
+	return nil, fmt.Errorf("resposta inesperada do servidor: %s", item)
+}
+
+// failureError converte a lista de erros de uma resposta de falha
+func failureError(errs []Item) error {
+	var messages []string
+	for _, e := range errs {
+		if msg := stringAt(e.List, 1); msg != "" {
+			messages = append(messages, msg)
+		}
+	}
+	if len(messages) == 0 {
+		return fmt.Errorf("o servidor svnserve retornou uma falha")
+	}
+	return fmt.Errorf("svnserve: %s", strings.Join(messages, ": "))
+}
+
+// optRev codifica uma revisão opcional; valores negativos indicam HEAD
+func optRev(rev int64) []any {
+	if rev < 0 {
+		return []any{}
+	}
+	return []any{rev}
+}
+
+// parseProps converte uma lista de propriedades, descartando as propriedades
+// internas (svn:entry:*, svn:wc:*) que não fazem parte do conteúdo versionado
+func parseProps(items []Item) map[string]string {
+	props := make(map[string]string)
+	for _, prop := range items {
+		name := stringAt(prop.List, 0)
+		if !svn.IsRegularProp(name) {
+			continue
+		}
+		props[name] = stringAt(prop.List, 1)
+	}
+	return props
+}
@@ -0,0 +1,745 @@
+package svn
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"svndiff/pkg/config"
+)
+
+// Backend abstrai a origem dos dados usados pela comparação. É implementado
+// pelo Client, que executa o comando svn, e pelo RepositoryBackend, que usa
+// clientes nativos em Go.
+type Backend interface {
+	CheckConnection(url string) error
+	GetDiff(branchA, branchB *config.BranchConfig, summarize bool) (*DiffResult, error)
+	GetLogEntries(url string, opts LogOptions) ([]LogEntry, error)
+}
+
+// FileReader é implementado pelos backends que leem o conteúdo de um
+// arquivo de uma branch, usado para incluir binários em patches Git. ok é
+// false quando o caminho não existe na branch.
+type FileReader interface {
+	ReadFile(branch *config.BranchConfig, path string) (content []byte, ok bool, err error)
+}
+
+// Lister é implementado pelos backends que listam o conteúdo de um
+// diretório de uma branch, usado para detalhar diretórios adicionados ou
+// removidos. As entradas vêm em profundidade, relativas a path.
+type Lister interface {
+	ListTree(branch *config.BranchConfig, path string) ([]ListEntry, error)
+}
+
+// DiffStreamer é implementado pelos backends que entregam a saída do diff à
+// medida que ela é produzida, sem mantê-la inteira em memória. O leitor
+// retorna os erros da comparação e deve ser fechado.
+type DiffStreamer interface {
+	StreamDiff(branchA, branchB *config.BranchConfig, summarize bool) (io.ReadCloser, error)
+}
+
+// RevisionDiffer é implementado pelos backends que geram o diff das mudanças
+// de uma única revisão da branch, como svn diff -c. Na revisão que cria a
+// branch, todo o seu conteúdo aparece como adicionado.
+type RevisionDiffer interface {
+	GetRevisionDiff(branch *config.BranchConfig, rev int64) (*DiffResult, error)
+}
+
+// NodeKind identifica o tipo de um nó versionado
+type NodeKind string
+
+const (
+	NodeNone NodeKind = "none"
+	NodeFile NodeKind = "file"
+	NodeDir  NodeKind = "dir"
+)
+
+// DirEntry representa uma entrada de um diretório versionado
+type DirEntry struct {
+	Name       string
+	Kind       NodeKind
+	Size       int64
+	CreatedRev int64
+	// ID identifica o nó-revisão, quando o repositório o expõe. Entradas com
+	// o mesmo ID no mesmo repositório têm conteúdo idêntico.
+	ID string
+}
+
+// Dir representa o conteúdo de um diretório versionado
+type Dir struct {
+	Props   map[string]string
+	Entries []DirEntry
+}
+
+// File representa um arquivo versionado. Content só é preenchido quando
+// solicitado.
+type File struct {
+	Checksum string
+	Props    map[string]string
+	Content  []byte
+}
+
+// Repository dá acesso de leitura a um repositório SVN. Os caminhos são
+// relativos à URL usada para abrir o repositório.
+type Repository interface {
+	LatestRevision() (int64, error)
+	CheckPath(path string, rev int64) (NodeKind, error)
+	GetDir(path string, rev int64) (*Dir, error)
+	GetFile(path string, rev int64, withContent bool) (*File, error)
+	GetLog(path string, start, end int64, limit int) ([]LogEntry, error)
+	Close() error
+}
+
+// TreeComparer é implementado por repositórios capazes de calcular no
+// servidor as mudanças entre duas árvores. Deve retornar ErrUnsupported
+// quando urlB não pertence ao mesmo repositório.
+type TreeComparer interface {
+	CompareTrees(revA int64, urlB string, revB int64) ([]Change, error)
+}
+
+// RevisionLabeler é implementado por repositórios que descrevem a revisão
+// de forma própria nos cabeçalhos do diff, como as cópias de trabalho
+type RevisionLabeler interface {
+	RevisionLabel(rev int64) string
+}
+
+// PropertyScoper é implementado por repositórios que representam só parte
+// das propriedades do svn, como os repositórios Git. Quando um dos lados o
+// implementa, apenas as propriedades aceitas são comparadas.
+type PropertyScoper interface {
+	ComparesProp(name string) bool
+}
+
+// DatedRevisioner é implementado por repositórios capazes de resolver a
+// revisão vigente em uma data
+type DatedRevisioner interface {
+	DatedRevision(date time.Time) (int64, error)
+}
+
+// ChangeLister é implementado por repositórios que listam os caminhos
+// alterados em cada revisão, com a origem das cópias, como o svn log -v. Os
+// caminhos são absolutos no repositório; BasePath é o da URL aberta
+// ("/branches/x").
+type ChangeLister interface {
+	ChangedPaths(rev int64) ([]ChangedPath, error)
+	BasePath() string
+}
+
+// ErrUnsupported indica uma operação não suportada pelo repositório
+var ErrUnsupported = errors.New("operação não suportada pelo repositório")
+
+// Change representa a diferença de um caminho entre as duas árvores
+type Change struct {
+	Path string
+	Kind NodeKind
+	// Text é o status do conteúdo: 'A', 'D', 'M' ou ' '
+	Text byte
+	// Props é o status das propriedades: 'M' ou ' '
+	Props byte
+}
+
+// SummaryLine formata a mudança como uma linha do svn diff --summarize
+func (c Change) SummaryLine() string {
+	return fmt.Sprintf("%c%c      %s", c.Text, c.Props, c.Path)
+}
+
+// Opener abre o Repository correspondente à URL de uma branch
+type Opener func(url string) (Repository, error)
+
+// RepositoryBackend implementa Backend sobre clientes nativos, sem depender
+// do comando svn
+type RepositoryBackend struct {
+	open Opener
+}
+
+// NewRepositoryBackend cria um backend que abre os repositórios com open
+func NewRepositoryBackend(open Opener) *RepositoryBackend {
+	return &RepositoryBackend{open: open}
+}
+
+// CheckConnection verifica se é possível abrir o repositório e se a URL existe
+func (b *RepositoryBackend) CheckConnection(url string) error {
+	repo, err := b.open(url)
+	if err != nil {
+		return fmt.Errorf("não foi possível conectar ao SVN: %w", err)
+	}
+	defer repo.Close()
+
+	latest, err := repo.LatestRevision()
+	if err != nil {
+		return fmt.Errorf("não foi possível conectar ao SVN: %w", err)
+	}
+	kind, err := repo.CheckPath("", latest)
+	if err != nil {
+		return fmt.Errorf("não foi possível conectar ao SVN: %w", err)
+	}
+	if kind == NodeNone {
+		return fmt.Errorf("caminho não encontrado no repositório: %s", url)
+	}
+
+	return nil
+}
+
+// GetLogEntries obtém as entradas de log de uma URL dentro da janela informada
+func (b *RepositoryBackend) GetLogEntries(url string, opts LogOptions) ([]LogEntry, error) {
+	repo, err := b.open(url)
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Close()
+
+	start, end := "HEAD", "1"
+	if opts.Range != "" {
+		start, end = splitRevisionRange(opts.Range)
+	}
+
+	startRev, err := resolveRevision(repo, start, 1)
+	if err != nil {
+		return nil, err
+	}
+	endRev, err := resolveRevision(repo, end, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetLog("", startRev, endRev, opts.Limit)
+}
+
+// ReadFile lê o conteúdo de um arquivo da branch na última revisão
+// configurada
+func (b *RepositoryBackend) ReadFile(branch *config.BranchConfig, path string) ([]byte, bool, error) {
+	repo, err := b.open(branch.URL)
+	if err != nil {
+		return nil, false, err
+	}
+	defer repo.Close()
+
+	rev, err := resolveRevision(repo, branch.GetLatestRevision(), 0)
+	if err != nil {
+		return nil, false, err
+	}
+	kind, err := repo.CheckPath(path, rev)
+	switch {
+	case err != nil:
+		return nil, false, err
+	case kind == NodeNone:
+		return nil, false, nil
+	case kind == NodeDir:
+		return nil, false, fmt.Errorf("'%s' é um diretório", path)
+	}
+
+	file, err := repo.GetFile(path, rev, true)
+	if err != nil {
+		return nil, false, err
+	}
+	if file.Content == nil {
+		return []byte{}, true, nil
+	}
+	return file.Content, true, nil
+}
+
+// ListTree lista recursivamente o conteúdo do diretório path da branch na
+// última revisão configurada
+func (b *RepositoryBackend) ListTree(branch *config.BranchConfig, path string) ([]ListEntry, error) {
+	repo, err := b.open(branch.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Close()
+
+	rev, err := resolveRevision(repo, branch.GetLatestRevision(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ListEntry
+	var walk func(dir, prefix string) error
+	walk = func(dir, prefix string) error {
+		listing, err := repo.GetDir(dir, rev)
+		if err != nil {
+			return fmt.Errorf("erro ao listar '%s': %w", displayPath(dir), err)
+		}
+		for _, entry := range sortedEntries(listing.Entries) {
+			name := joinPath(prefix, entry.Name)
+			entries = append(entries, ListEntry{Path: name, Kind: entry.Kind})
+			if entry.Kind == NodeDir {
+				if err := walk(joinPath(dir, entry.Name), name); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(repoPath(path), ""); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetDiff compara as duas branches gerando a mesma saída do svn diff
+func (b *RepositoryBackend) GetDiff(branchA, branchB *config.BranchConfig, summarize bool) (*DiffResult, error) {
+	session, err := b.openDiff(branchA, branchB)
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+
+	result := &DiffResult{}
+	var out strings.Builder
+	if err := session.write(&out, summarize); err != nil {
+		return nil, err
+	}
+	result.Output = out.String()
+
+	if summarize {
+		for _, change := range session.changes {
+			result.FileList = append(result.FileList, change.Path)
+		}
+	}
+	return result, nil
+}
+
+// StreamDiff compara as duas branches e entrega a saída do diff arquivo a
+// arquivo, à medida que o conteúdo é lido dos repositórios
+func (b *RepositoryBackend) StreamDiff(branchA, branchB *config.BranchConfig, summarize bool) (io.ReadCloser, error) {
+	session, err := b.openDiff(branchA, branchB)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer session.close()
+		pw.CloseWithError(session.write(pw, summarize))
+	}()
+	return pr, nil
+}
+
+// GetRevisionDiff gera o diff das mudanças de rev na branch. Quando a branch
+// ainda não existia em rev-1, o lado anterior é tratado como vazio.
+func (b *RepositoryBackend) GetRevisionDiff(branch *config.BranchConfig, rev int64) (*DiffResult, error) {
+	before := &config.BranchConfig{URL: branch.URL, Revisions: []string{strconv.FormatInt(rev-1, 10)}}
+	after := &config.BranchConfig{URL: branch.URL, Revisions: []string{strconv.FormatInt(rev, 10)}}
+
+	repo, err := b.open(branch.URL)
+	if err != nil {
+		return nil, err
+	}
+	kind, err := repo.CheckPath("", rev-1)
+	repo.Close()
+	if err != nil || kind != NodeNone {
+		return b.GetDiff(before, after, false)
+	}
+
+	// Sem o lado anterior, o conteúdo da branch em rev é todo adicionado
+	s := &diffSession{revA: rev - 1, revB: rev}
+	if s.repoA, err = b.open(branch.URL); err != nil {
+		return nil, err
+	}
+	if s.repoB, err = b.open(branch.URL); err != nil {
+		s.repoA.Close()
+		return nil, err
+	}
+	defer s.close()
+
+	root, err := s.repoB.GetDir("", rev)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar a branch na revisão %d: %w", rev, err)
+	}
+	w := &treeWalker{repoA: s.repoA, revA: s.revA, repoB: s.repoB, revB: s.revB}
+	for _, entry := range sortedEntries(root.Entries) {
+		if err := w.addTree(entry.Name, entry.Kind); err != nil {
+			return nil, err
+		}
+	}
+	s.changes = w.changes
+
+	var out strings.Builder
+	if err := s.write(&out, false); err != nil {
+		return nil, err
+	}
+	return &DiffResult{Output: out.String()}, nil
+}
+
+// diffSession guarda os repositórios abertos e as mudanças de uma comparação
+type diffSession struct {
+	repoA, repoB Repository
+	revA, revB   int64
+	changes      []Change
+}
+
+// openDiff abre os repositórios das branches e calcula as mudanças entre as
+// últimas revisões
+func (b *RepositoryBackend) openDiff(branchA, branchB *config.BranchConfig) (*diffSession, error) {
+	repoA, err := b.open(branchA.URL)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir Branch A: %w", err)
+	}
+	repoB, err := b.open(branchB.URL)
+	if err != nil {
+		repoA.Close()
+		return nil, fmt.Errorf("erro ao abrir Branch B: %w", err)
+	}
+	s := &diffSession{repoA: repoA, repoB: repoB}
+
+	if s.revA, err = resolveRevision(repoA, branchA.GetLatestRevision(), 0); err == nil {
+		if s.revB, err = resolveRevision(repoB, branchB.GetLatestRevision(), 0); err == nil {
+			s.changes, err = compareRepositories(repoA, s.revA, repoB, s.revB, branchB.URL)
+		}
+	}
+	if err != nil {
+		s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// write escreve as mudanças em w no formato do svn diff, ou do svn diff
+// --summarize --xml com summarize, um arquivo por vez
+func (s *diffSession) write(w io.Writer, summarize bool) error {
+	if summarize {
+		sw := &summaryWriter{w: w}
+		for _, change := range s.changes {
+			if err := sw.write(change); err != nil {
+				return err
+			}
+		}
+		return sw.close()
+	}
+
+	var out strings.Builder
+	uw := &unifiedWriter{
+		out:       &out,
+		repoA:     s.repoA,
+		revA:      s.revA,
+		repoB:     s.repoB,
+		revB:      s.revB,
+		labelA:    revisionLabel(s.repoA, s.revA),
+		labelB:    revisionLabel(s.repoB, s.revB),
+		propScope: propertyScope(s.repoA, s.repoB),
+	}
+	for _, change := range s.changes {
+		if err := uw.writeChange(change); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, out.String()); err != nil {
+			return err
+		}
+		out.Reset()
+	}
+	return nil
+}
+
+func (s *diffSession) close() {
+	s.repoA.Close()
+	s.repoB.Close()
+}
+
+// compareRepositories calcula as mudanças entre as duas árvores, delegando ao
+// servidor quando possível e percorrendo as árvores caso contrário
+func compareRepositories(repoA Repository, revA int64, repoB Repository, revB int64, urlB string) ([]Change, error) {
+	if comparer, ok := repoA.(TreeComparer); ok {
+		changes, err := comparer.CompareTrees(revA, urlB, revB)
+		if !errors.Is(err, ErrUnsupported) {
+			return changes, err
+		}
+	}
+
+	w := &treeWalker{
+		repoA: repoA, revA: revA, repoB: repoB, revB: revB,
+		sameRepo:  sameRepository(repoA, repoB),
+		propScope: propertyScope(repoA, repoB),
+	}
+	if err := w.compareDir(""); err != nil {
+		return nil, err
+	}
+	return w.changes, nil
+}
+
+// revisionLabel descreve a revisão nos cabeçalhos do diff
+func revisionLabel(repo Repository, rev int64) string {
+	if labeler, ok := repo.(RevisionLabeler); ok {
+		return labeler.RevisionLabel(rev)
+	}
+	return fmt.Sprintf("(revision %d)", rev)
+}
+
+// sameRepository indica se os dois repositórios são o mesmo, pelo UUID
+func sameRepository(a, b Repository) bool {
+	type identified interface{ UUID() string }
+	idA, okA := a.(identified)
+	idB, okB := b.(identified)
+	return okA && okB && idA.UUID() != "" && idA.UUID() == idB.UUID()
+}
+
+// treeWalker compara duas árvores percorrendo os diretórios de ambas
+type treeWalker struct {
+	repoA, repoB Repository
+	revA, revB   int64
+	sameRepo     bool
+	// propScope restringe as propriedades comparadas; nil compara todas
+	propScope func(string) bool
+	changes   []Change
+}
+
+// compareDir compara recursivamente o diretório path nas duas árvores
+func (w *treeWalker) compareDir(path string) error {
+	dirA, err := w.repoA.GetDir(path, w.revA)
+	if err != nil {
+		return fmt.Errorf("erro ao listar '%s' na Branch A: %w", displayPath(path), err)
+	}
+	dirB, err := w.repoB.GetDir(path, w.revB)
+	if err != nil {
+		return fmt.Errorf("erro ao listar '%s' na Branch B: %w", displayPath(path), err)
+	}
+
+	if !propsEqual(scopeProps(dirA.Props, w.propScope), scopeProps(dirB.Props, w.propScope)) {
+		w.changes = append(w.changes, Change{Path: displayPath(path), Kind: NodeDir, Text: ' ', Props: 'M'})
+	}
+
+	entriesA := indexEntries(dirA.Entries)
+	entriesB := indexEntries(dirB.Entries)
+
+	for _, name := range mergeNames(entriesA, entriesB) {
+		entryA, inA := entriesA[name]
+		entryB, inB := entriesB[name]
+		child := joinPath(path, name)
+
+		switch {
+		case inA && (!inB || entryA.Kind != entryB.Kind):
+			w.changes = append(w.changes, Change{Path: child, Kind: entryA.Kind, Text: 'D', Props: ' '})
+			if inB {
+				if err := w.addTree(child, entryB.Kind); err != nil {
+					return err
+				}
+			}
+		case !inA:
+			if err := w.addTree(child, entryB.Kind); err != nil {
+				return err
+			}
+		case w.sameRepo && entryA.ID != "" && entryA.ID == entryB.ID:
+			// Mesmo nó-revisão: a subárvore não mudou
+		case entryA.Kind == NodeDir:
+			if err := w.compareDir(child); err != nil {
+				return err
+			}
+		default:
+			if err := w.compareFile(child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// compareFile compara o conteúdo e as propriedades de um arquivo
+func (w *treeWalker) compareFile(path string) error {
+	fileA, err := w.repoA.GetFile(path, w.revA, false)
+	if err != nil {
+		return fmt.Errorf("erro ao ler '%s' na Branch A: %w", path, err)
+	}
+	fileB, err := w.repoB.GetFile(path, w.revB, false)
+	if err != nil {
+		return fmt.Errorf("erro ao ler '%s' na Branch B: %w", path, err)
+	}
+
+	change := Change{Path: path, Kind: NodeFile, Text: ' ', Props: ' '}
+	if fileA.Checksum != fileB.Checksum {
+		change.Text = 'M'
+	}
+	if !propsEqual(scopeProps(fileA.Props, w.propScope), scopeProps(fileB.Props, w.propScope)) {
+		change.Props = 'M'
+	}
+	if change.Text != ' ' || change.Props != ' ' {
+		w.changes = append(w.changes, change)
+	}
+
+	return nil
+}
+
+// addTree registra um caminho adicionado e, se for diretório, todo o seu conteúdo
+func (w *treeWalker) addTree(path string, kind NodeKind) error {
+	w.changes = append(w.changes, Change{Path: path, Kind: kind, Text: 'A', Props: ' '})
+	if kind != NodeDir {
+		return nil
+	}
+
+	dir, err := w.repoB.GetDir(path, w.revB)
+	if err != nil {
+		return fmt.Errorf("erro ao listar '%s' na Branch B: %w", path, err)
+	}
+	for _, entry := range sortedEntries(dir.Entries) {
+		if err := w.addTree(joinPath(path, entry.Name), entry.Kind); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveRevision converte uma revisão textual (número, HEAD ou {data}) em
+// número. Datas não suportadas pelo repositório são ampliadas para o início
+// (fallback > 0) ou o fim (fallback < 0) do histórico; com fallback 0 geram erro.
+func resolveRevision(repo Repository, spec string, fallback int) (int64, error) {
+	switch {
+	case spec == "" || strings.EqualFold(spec, "HEAD"):
+		return repo.LatestRevision()
+	case strings.HasPrefix(spec, "{") && strings.HasSuffix(spec, "}"):
+		date, err := time.Parse(time.RFC3339, strings.Trim(spec, "{}"))
+		if err != nil {
+			date, err = time.ParseInLocation("2006-01-02", strings.Trim(spec, "{}"), time.Local)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("data de revisão inválida '%s'", spec)
+		}
+		if dated, ok := repo.(DatedRevisioner); ok {
+			return dated.DatedRevision(date)
+		}
+		switch {
+		case fallback > 0:
+			return 0, nil
+		case fallback < 0:
+			return repo.LatestRevision()
+		}
+		return 0, fmt.Errorf("revisões por data não são suportadas: %s", spec)
+	}
+
+	rev, err := strconv.ParseInt(strings.TrimPrefix(spec, "r"), 10, 64)
+	if err != nil || rev < 0 {
+		return 0, fmt.Errorf("revisão inválida '%s'", spec)
+	}
+	return rev, nil
+}
+
+// splitRevisionRange separa um intervalo "início:fim", respeitando datas
+// entre chaves que também contêm ':'
+func splitRevisionRange(value string) (string, string) {
+	depth := 0
+	for i, r := range value {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ':':
+			if depth == 0 {
+				return value[:i], value[i+1:]
+			}
+		}
+	}
+	return value, value
+}
+
+// indexEntries indexa as entradas de um diretório pelo nome
+func indexEntries(entries []DirEntry) map[string]DirEntry {
+	index := make(map[string]DirEntry, len(entries))
+	for _, entry := range entries {
+		index[entry.Name] = entry
+	}
+	return index
+}
+
+// mergeNames retorna os nomes presentes em qualquer um dos índices, ordenados
+func mergeNames(a, b map[string]DirEntry) []string {
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedEntries retorna as entradas ordenadas por nome
+func sortedEntries(entries []DirEntry) []DirEntry {
+	sorted := append([]DirEntry(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// propertyScope retorna o filtro de propriedades dos repositórios que
+// implementam PropertyScoper, ou nil quando todas são comparáveis
+func propertyScope(repos ...Repository) func(string) bool {
+	var scopers []PropertyScoper
+	for _, repo := range repos {
+		if scoper, ok := repo.(PropertyScoper); ok {
+			scopers = append(scopers, scoper)
+		}
+	}
+	if len(scopers) == 0 {
+		return nil
+	}
+	return func(name string) bool {
+		for _, scoper := range scopers {
+			if !scoper.ComparesProp(name) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// scopeProps retorna as propriedades aceitas pelo filtro
+func scopeProps(props map[string]string, scope func(string) bool) map[string]string {
+	if scope == nil {
+		return props
+	}
+	scoped := make(map[string]string, len(props))
+	for name, value := range props {
+		if scope(name) {
+			scoped[name] = value
+		}
+	}
+	return scoped
+}
+
+// propsEqual compara dois conjuntos de propriedades
+func propsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if other, ok := b[name]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// joinPath junta caminhos relativos do repositório
+func joinPath(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return dir + "/" + name
+}
+
+// displayPath retorna o caminho como exibido nas saídas, usando "." para a raiz
+func displayPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+// repoPath converte um caminho exibido de volta ao caminho do repositório
+func repoPath(path string) string {
+	if path == "." {
+		return ""
+	}
+	return path
+}
+
+// IsRegularProp indica se a propriedade faz parte do conteúdo versionado,
+// excluindo as propriedades internas mantidas pelo svn (svn:entry:*, svn:wc:*)
+func IsRegularProp(name string) bool {
+	return !strings.HasPrefix(name, "svn:entry:") && !strings.HasPrefix(name, "svn:wc:")
+}
@@ -0,0 +1,137 @@
+package svn
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// SummaryEntry é um caminho da saída de svn diff --summarize --xml
+type SummaryEntry struct {
+	// Path é o caminho como relatado: a URL completa da Branch A no comando
+	// svn e o caminho relativo à branch nos clientes nativos
+	Path string `xml:",chardata"`
+	// Item é o status do conteúdo: added, deleted, modified, replaced ou none
+	Item string `xml:"item,attr"`
+	// Props é o status das propriedades: modified ou none
+	Props string   `xml:"props,attr"`
+	Kind  NodeKind `xml:"kind,attr"`
+}
+
+// summaryItems traduz os status de Change para os do resumo XML
+var summaryItems = map[byte]string{'A': "added", 'D': "deleted", 'M': "modified", 'R': "replaced"}
+
+// SummaryEntry converte a mudança na entrada do resumo XML
+func (c Change) SummaryEntry() SummaryEntry {
+	entry := SummaryEntry{Path: c.Path, Item: "none", Props: "none", Kind: c.Kind}
+	if entry.Kind == "" {
+		entry.Kind = NodeNone
+	}
+	if item, ok := summaryItems[c.Text]; ok {
+		entry.Item = item
+	}
+	if c.Props == 'M' {
+		entry.Props = "modified"
+	}
+	return entry
+}
+
+// Line formata a entrada como uma linha do svn diff --summarize sem --xml
+func (e SummaryEntry) Line() string {
+	text, props := byte(' '), byte(' ')
+	for code, item := range summaryItems {
+		if item == e.Item {
+			text = code
+		}
+	}
+	if e.Props == "modified" {
+		props = 'M'
+	}
+	return fmt.Sprintf("%c%c      %s", text, props, e.Path)
+}
+
+// SummaryReader lê as entradas do svn diff --summarize --xml uma a uma, sem
+// carregar a saída inteira em memória
+type SummaryReader struct {
+	dec *xml.Decoder
+}
+
+// NewSummaryReader cria um leitor da saída XML lida de r
+func NewSummaryReader(r io.Reader) *SummaryReader {
+	return &SummaryReader{dec: xml.NewDecoder(r)}
+}
+
+// Next retorna a próxima entrada, ou io.EOF no fim da saída
+func (r *SummaryReader) Next() (SummaryEntry, error) {
+	for {
+		tok, err := r.dec.Token()
+		if err != nil {
+			return SummaryEntry{}, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "path" {
+			continue
+		}
+		var entry SummaryEntry
+		if err := r.dec.DecodeElement(&entry, &start); err != nil {
+			return SummaryEntry{}, err
+		}
+		return entry, nil
+	}
+}
+
+// ReadSummary lê todas as entradas do svn diff --summarize --xml
+func ReadSummary(r io.Reader) ([]SummaryEntry, error) {
+	reader := NewSummaryReader(r)
+	var entries []SummaryEntry
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao interpretar resumo XML: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// summaryWriter escreve mudanças no formato do svn diff --summarize --xml
+type summaryWriter struct {
+	w       io.Writer
+	started bool
+}
+
+// write escreve a entrada de uma mudança, precedida do cabeçalho na primeira
+func (s *summaryWriter) write(change Change) error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	entry := change.SummaryEntry()
+	if _, err := fmt.Fprintf(s.w, "<path\n   props=\"%s\"\n   kind=\"%s\"\n   item=\"%s\">", entry.Props, entry.Kind, entry.Item); err != nil {
+		return err
+	}
+	if err := xml.EscapeText(s.w, []byte(entry.Path)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(s.w, "</path>\n")
+	return err
+}
+
+// close fecha os elementos abertos
+func (s *summaryWriter) close() error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(s.w, "</paths>\n</diff>\n")
+	return err
+}
+
+func (s *summaryWriter) start() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+	_, err := io.WriteString(s.w, xml.Header+"<diff>\n<paths>\n")
+	return err
+}
@@ -0,0 +1,86 @@
+package svn
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadSummary(t *testing.T) {
+	output := `<?xml version="1.0" encoding="UTF-8"?>
+<diff>
+<paths>
+<path
+   props="none"
+   kind="file"
+   item="modified">https://svn.example.com/trunk/ leading space.txt</path>
+<path
+   props="modified"
+   kind="dir"
+   item="none">https://svn.example.com/trunk/src</path>
+<path
+   props="none"
+   kind="file"
+   item="added">https://svn.example.com/trunk/a&amp;b.txt</path>
+</paths>
+</diff>
+`
+	entries, err := ReadSummary(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("ReadSummary() error = %v", err)
+	}
+	want := []SummaryEntry{
+		{Path: "https://svn.example.com/trunk/ leading space.txt", Item: "modified", Props: "none", Kind: NodeFile},
+		{Path: "https://svn.example.com/trunk/src", Item: "none", Props: "modified", Kind: NodeDir},
+		{Path: "https://svn.example.com/trunk/a&b.txt", Item: "added", Props: "none", Kind: NodeFile},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("ReadSummary() = %+v, want %+v", entries, want)
+	}
+
+	if _, err := ReadSummary(strings.NewReader("<diff><paths><path")); err == nil {
+		t.Error("ReadSummary() com XML truncado não retornou erro")
+	}
+}
+
+func TestSummaryWriter(t *testing.T) {
+	changes := []Change{
+		{Path: " a<b>.txt", Kind: NodeFile, Text: 'M', Props: 'M'},
+		{Path: "lib", Kind: NodeDir, Text: 'D', Props: ' '},
+		{Path: "run.sh", Kind: NodeFile, Text: ' ', Props: 'M'},
+	}
+	var out strings.Builder
+	sw := &summaryWriter{w: &out}
+	for _, change := range changes {
+		if err := sw.write(change); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sw.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadSummary(strings.NewReader(out.String()))
+	if err != nil {
+		t.Fatalf("ReadSummary() error = %v\n%s", err, out.String())
+	}
+	var lines []string
+	for _, entry := range entries {
+		lines = append(lines, entry.Line())
+	}
+	if got, want := strings.Join(lines, "\n"), "MM       a<b>.txt\nD       lib\n M      run.sh"; got != want {
+		t.Errorf("resumo =\n%s\nwant\n%s", got, want)
+	}
+	if entries[1].Kind != NodeDir {
+		t.Errorf("tipo de lib = %q", entries[1].Kind)
+	}
+
+	// sem mudanças, o documento continua válido
+	out.Reset()
+	if err := (&summaryWriter{w: &out}).close(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := ReadSummary(strings.NewReader(out.String())); err != nil || len(entries) != 0 {
+		t.Errorf("resumo vazio = %v, %v", entries, err)
+	}
+}
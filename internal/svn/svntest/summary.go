@@ -0,0 +1,23 @@
+package svntest
+
+import (
+	"strings"
+	"testing"
+
+	"svndiff/internal/svn"
+)
+
+// SummaryLines converte a saída do svn diff --summarize --xml nas linhas do
+// formato texto ("SP      PATH"), para comparações legíveis nos testes
+func SummaryLines(t *testing.T, output string) string {
+	t.Helper()
+	entries, err := svn.ReadSummary(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("ReadSummary() error = %v\n%s", err, output)
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		b.WriteString(entry.Line() + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,264 @@
+package svn
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"svndiff/internal/diff"
+)
+
+// diffContext é a quantidade de linhas de contexto usada pelo svn diff
+const diffContext = 3
+
+// sniffLength é a quantidade de bytes examinada para detectar conteúdo binário
+const sniffLength = 8000
+
+// unifiedWriter gera, a partir das mudanças entre duas árvores, a mesma
+// saída textual do svn diff
+type unifiedWriter struct {
+	out            *strings.Builder
+	repoA, repoB   Repository
+	revA, revB     int64
+	labelA, labelB string
+	// propScope restringe as propriedades comparadas; nil compara todas
+	propScope func(string) bool
+}
+
+// writeChange escreve o diff de uma mudança
+func (w *unifiedWriter) writeChange(change Change) error {
+	path := repoPath(change.Path)
+
+	if change.Kind == NodeDir {
+		switch change.Text {
+		case 'D':
+			// Um diretório removido aparece como a remoção de cada arquivo
+			return w.writeDeletedDir(path)
+		case 'A':
+			// Os arquivos do diretório adicionado são mudanças próprias
+			return nil
+		}
+		propsA, propsB, err := w.dirProps(path)
+		if err != nil {
+			return err
+		}
+		w.writeHeader(change.Path, "", "")
+		w.writePropChanges(change.Path, propsA, propsB)
+		return nil
+	}
+
+	var fileA, fileB *File
+	var err error
+	if change.Text != 'A' {
+		if fileA, err = w.repoA.GetFile(path, w.revA, true); err != nil {
+			return fmt.Errorf("erro ao ler '%s' na Branch A: %w", path, err)
+		}
+	}
+	if change.Text != 'D' {
+		if fileB, err = w.repoB.GetFile(path, w.revB, true); err != nil {
+			return fmt.Errorf("erro ao ler '%s' na Branch B: %w", path, err)
+		}
+	}
+
+	w.writeFile(change.Path, fileA, fileB)
+	return nil
+}
+
+// writeDeletedDir escreve a remoção de todos os arquivos de um diretório
+func (w *unifiedWriter) writeDeletedDir(path string) error {
+	dir, err := w.repoA.GetDir(path, w.revA)
+	if err != nil {
+		return fmt.Errorf("erro ao listar '%s' na Branch A: %w", path, err)
+	}
+
+	for _, entry := range sortedEntries(dir.Entries) {
+		child := joinPath(path, entry.Name)
+		if entry.Kind == NodeDir {
+			if err := w.writeDeletedDir(child); err != nil {
+				return err
+			}
+			continue
+		}
+		file, err := w.repoA.GetFile(child, w.revA, true)
+		if err != nil {
+			return fmt.Errorf("erro ao ler '%s' na Branch A: %w", child, err)
+		}
+		w.writeFile(child, file, nil)
+	}
+	return nil
+}
+
+// dirProps obtém as propriedades de um diretório nas duas árvores
+func (w *unifiedWriter) dirProps(path string) (map[string]string, map[string]string, error) {
+	dirA, err := w.repoA.GetDir(path, w.revA)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao listar '%s' na Branch A: %w", displayPath(path), err)
+	}
+	dirB, err := w.repoB.GetDir(path, w.revB)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao listar '%s' na Branch B: %w", displayPath(path), err)
+	}
+	return scopeProps(dirA.Props, w.propScope), scopeProps(dirB.Props, w.propScope), nil
+}
+
+// writeFile escreve o diff de um arquivo; nil indica arquivo inexistente
+func (w *unifiedWriter) writeFile(path string, fileA, fileB *File) {
+	var contentA, contentB []byte
+	var propsA, propsB map[string]string
+	labelA, labelB := w.labelA, w.labelB
+	if fileA != nil {
+		contentA, propsA = fileA.Content, fileA.Props
+	} else {
+		labelA = "(nonexistent)"
+	}
+	if fileB != nil {
+		contentB, propsB = fileB.Content, fileB.Props
+	} else {
+		labelB = "(nonexistent)"
+	}
+
+	textChanged := !bytes.Equal(contentA, contentB) || fileA == nil || fileB == nil
+	scopedA, scopedB := scopeProps(propsA, w.propScope), scopeProps(propsB, w.propScope)
+	propsChanged := !propsEqual(scopedA, scopedB)
+	if !textChanged && !propsChanged {
+		return
+	}
+
+	if textChanged && (isBinary(contentA, propsA) || isBinary(contentB, propsB)) {
+		fmt.Fprintf(w.out, "Index: %s\n", path)
+		w.out.WriteString("===================================================================\n")
+		w.out.WriteString("Cannot display: file marked as a binary type.\n")
+		mimeType := propsB["svn:mime-type"]
+		if mimeType == "" {
+			mimeType = propsA["svn:mime-type"]
+		}
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+		fmt.Fprintf(w.out, "svn:mime-type = %s\n", mimeType)
+	} else {
+		w.writeHeader(path, labelA, labelB)
+		if textChanged {
+			writeHunks(w.out, splitLines(contentA), splitLines(contentB), "\\ No newline at end of file")
+		}
+	}
+
+	if propsChanged {
+		w.writePropChanges(path, scopedA, scopedB)
+	}
+}
+
+// writeHeader escreve o cabeçalho Index/---/+++ de um caminho
+func (w *unifiedWriter) writeHeader(path, labelA, labelB string) {
+	if labelA == "" {
+		labelA = w.labelA
+	}
+	if labelB == "" {
+		labelB = w.labelB
+	}
+	fmt.Fprintf(w.out, "Index: %s\n", path)
+	w.out.WriteString("===================================================================\n")
+	fmt.Fprintf(w.out, "--- %s\t%s\n", path, labelA)
+	fmt.Fprintf(w.out, "+++ %s\t%s\n", path, labelB)
+}
+
+// writePropChanges escreve a seção "Property changes on" do svn diff
+func (w *unifiedWriter) writePropChanges(path string, propsA, propsB map[string]string) {
+	names := make([]string, 0, len(propsA)+len(propsB))
+	for name := range propsA {
+		names = append(names, name)
+	}
+	for name := range propsB {
+		if _, ok := propsA[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w.out, "\nProperty changes on: %s\n", path)
+	w.out.WriteString("___________________________________________________________________\n")
+	for _, name := range names {
+		valueA, inA := propsA[name]
+		valueB, inB := propsB[name]
+		switch {
+		case !inA:
+			fmt.Fprintf(w.out, "Added: %s\n", name)
+		case !inB:
+			fmt.Fprintf(w.out, "Deleted: %s\n", name)
+		case valueA != valueB:
+			fmt.Fprintf(w.out, "Modified: %s\n", name)
+		default:
+			continue
+		}
+		hunks := diff.Hunks(splitLines([]byte(valueA)), splitLines([]byte(valueB)), 1<<30)
+		for _, hunk := range hunks {
+			header := hunk.Header()
+			fmt.Fprintf(w.out, "## %s ##\n", header[3:len(header)-3])
+			writeHunkLines(w.out, hunk, "\\ No newline at end of property")
+		}
+	}
+}
+
+// writeHunks escreve os hunks do diff entre as linhas de a e b
+func writeHunks(out *strings.Builder, a, b []string, noEOL string) {
+	for _, hunk := range diff.Hunks(a, b, diffContext) {
+		out.WriteString(hunk.Header())
+		out.WriteString("\n")
+		writeHunkLines(out, hunk, noEOL)
+	}
+}
+
+// writeHunkLines escreve as linhas de um hunk, sinalizando a ausência de
+// quebra de linha ao final do conteúdo
+func writeHunkLines(out *strings.Builder, hunk diff.Hunk, noEOL string) {
+	for _, line := range hunk.Lines {
+		switch line.Kind {
+		case diff.Delete:
+			out.WriteString("-")
+		case diff.Insert:
+			out.WriteString("+")
+		default:
+			out.WriteString(" ")
+		}
+		out.WriteString(line.Text)
+		if !strings.HasSuffix(line.Text, "\n") {
+			out.WriteString("\n")
+			out.WriteString(noEOL)
+			out.WriteString("\n")
+		}
+	}
+}
+
+// splitLines divide o conteúdo em linhas preservando os terminadores, de
+// modo que uma última linha sem quebra seja diferente da mesma linha com quebra
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// isBinary indica se o conteúdo deve ser tratado como binário, pelo
+// svn:mime-type ou pela presença de bytes nulos
+func isBinary(content []byte, props map[string]string) bool {
+	if mimeType, ok := props["svn:mime-type"]; ok {
+		// Mesma regra do svn: apenas text/* e alguns formatos de imagem textuais
+		return !strings.HasPrefix(mimeType, "text/") &&
+			mimeType != "image/x-xbitmap" && mimeType != "image/x-xpixmap"
+	}
+	return IsBinaryContent(content)
+}
+
+// IsBinaryContent indica se o conteúdo de um arquivo sem svn:mime-type é
+// binário, pela presença de bytes nulos no início
+func IsBinaryContent(content []byte) bool {
+	if len(content) > sniffLength {
+		content = content[:sniffLength]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
@@ -0,0 +1,216 @@
+package wc
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+)
+
+// fixtureTable é uma tabela gravada por writeSQLite. Os valores das linhas
+// podem ser nil, int64, int, string ou []byte; o rowid é a posição + 1.
+type fixtureTable struct {
+	name string
+	sql  string
+	rows [][]any
+}
+
+// sqliteWriter monta um banco SQLite mínimo com páginas pequenas, para que
+// tabelas e registros grandes usem páginas internas e de overflow
+type sqliteWriter struct {
+	pageSize int
+	pages    [][]byte
+}
+
+// writeSQLite grava as tabelas em path com o sqlite_master na página 1
+func writeSQLite(t *testing.T, path string, pageSize int, tables []fixtureTable) {
+	t.Helper()
+	w := &sqliteWriter{pageSize: pageSize}
+	w.alloc() // página 1, preenchida por último
+
+	var master [][]any
+	for _, table := range tables {
+		root := w.writeTable(table.rows, false)
+		master = append(master, []any{"table", table.name, table.name, root, table.sql})
+	}
+	if root := w.writeTable(master, true); root != 1 {
+		t.Fatalf("sqlite_master não coube na página 1")
+	}
+
+	page1 := w.pages[0]
+	copy(page1, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(page1[16:], uint16(pageSize))
+	page1[18], page1[19], page1[21], page1[22], page1[23] = 1, 1, 64, 32, 32
+	binary.BigEndian.PutUint32(page1[28:], uint32(len(w.pages)))
+	binary.BigEndian.PutUint32(page1[44:], 4)
+	binary.BigEndian.PutUint32(page1[56:], 1)
+
+	var data []byte
+	for _, p := range w.pages {
+		data = append(data, p...)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (w *sqliteWriter) alloc() int {
+	w.pages = append(w.pages, make([]byte, w.pageSize))
+	return len(w.pages)
+}
+
+// writeTable grava as linhas em folhas e, se houver mais de uma, em uma
+// página interna que é a raiz. Com master, a única folha é a página 1.
+func (w *sqliteWriter) writeTable(rows [][]any, master bool) int {
+	type leaf struct {
+		cells  [][]byte
+		maxRow int
+		used   int
+	}
+	hdr := 0
+	if master {
+		hdr = 100
+	}
+	leaves := []*leaf{{used: hdr + 8}}
+	for i, row := range rows {
+		cell := w.leafCell(int64(i+1), encodeRecord(row))
+		cur := leaves[len(leaves)-1]
+		if cur.used+len(cell)+2 > w.pageSize && len(cur.cells) > 0 {
+			cur = &leaf{used: 8}
+			leaves = append(leaves, cur)
+		}
+		cur.cells = append(cur.cells, cell)
+		cur.used += len(cell) + 2
+		cur.maxRow = i + 1
+	}
+
+	var pageNos []int
+	for _, l := range leaves {
+		pageNo := 1
+		if !master {
+			pageNo = w.alloc()
+		}
+		w.fillPage(pageNo, hdr, 0x0d, l.cells, 0)
+		pageNos = append(pageNos, pageNo)
+	}
+	if len(leaves) == 1 {
+		return pageNos[0]
+	}
+
+	var cells [][]byte
+	for i, l := range leaves[:len(leaves)-1] {
+		cell := binary.BigEndian.AppendUint32(nil, uint32(pageNos[i]))
+		cells = append(cells, appendVarint(cell, uint64(l.maxRow)))
+	}
+	root := w.alloc()
+	w.fillPage(root, 0, 0x05, cells, pageNos[len(pageNos)-1])
+	return root
+}
+
+// fillPage grava o cabeçalho, os ponteiros e as células do fim para o início
+func (w *sqliteWriter) fillPage(pageNo, hdr int, kind byte, cells [][]byte, right int) {
+	p := w.pages[pageNo-1]
+	headerLen := 8
+	if kind == 0x05 {
+		headerLen = 12
+		binary.BigEndian.PutUint32(p[hdr+8:], uint32(right))
+	}
+	p[hdr] = kind
+	binary.BigEndian.PutUint16(p[hdr+3:], uint16(len(cells)))
+
+	end := len(p)
+	for i, cell := range cells {
+		end -= len(cell)
+		copy(p[end:], cell)
+		binary.BigEndian.PutUint16(p[hdr+headerLen+2*i:], uint16(end))
+	}
+	binary.BigEndian.PutUint16(p[hdr+5:], uint16(end))
+}
+
+// leafCell monta uma célula de folha, movendo o excesso para páginas de
+// overflow conforme as regras do SQLite
+func (w *sqliteWriter) leafCell(rowid int64, payload []byte) []byte {
+	cell := appendVarint(nil, uint64(len(payload)))
+	cell = appendVarint(cell, uint64(rowid))
+
+	u := w.pageSize
+	maxLocal := u - 35
+	if len(payload) <= maxLocal {
+		return append(cell, payload...)
+	}
+	minLocal := (u-12)*32/255 - 23
+	local := minLocal + (len(payload)-minLocal)%(u-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	cell = append(cell, payload[:local]...)
+
+	rest := payload[local:]
+	first := 0
+	prev := -1
+	for len(rest) > 0 {
+		pageNo := w.alloc()
+		if prev < 0 {
+			first = pageNo
+		} else {
+			binary.BigEndian.PutUint32(w.pages[prev-1], uint32(pageNo))
+		}
+		n := copy(w.pages[pageNo-1][4:], rest)
+		rest = rest[n:]
+		prev = pageNo
+	}
+	return binary.BigEndian.AppendUint32(cell, uint32(first))
+}
+
+// encodeRecord serializa os valores no formato de registro do SQLite
+func encodeRecord(values []any) []byte {
+	var types, body []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+			types = appendVarint(types, 0)
+		case int:
+			types, body = appendInt(types, body, int64(v))
+		case int64:
+			types, body = appendInt(types, body, v)
+		case string:
+			types = appendVarint(types, uint64(13+2*len(v)))
+			body = append(body, v...)
+		case []byte:
+			types = appendVarint(types, uint64(12+2*len(v)))
+			body = append(body, v...)
+		}
+	}
+	// O tamanho do cabeçalho inclui o próprio varint, de 1 byte nos testes
+	return append(append([]byte{byte(len(types) + 1)}, types...), body...)
+}
+
+func appendInt(types, body []byte, v int64) ([]byte, []byte) {
+	switch {
+	case v == 0:
+		return append(types, 8), body
+	case v == 1:
+		return append(types, 9), body
+	case v >= -128 && v < 128:
+		return append(types, 1), append(body, byte(v))
+	case v >= -32768 && v < 32768:
+		return append(types, 2), binary.BigEndian.AppendUint16(body, uint16(v))
+	default:
+		return append(types, 6), binary.BigEndian.AppendUint64(body, uint64(v))
+	}
+}
+
+// appendVarint grava um varint do SQLite para valores menores que 2^56
+func appendVarint(p []byte, v uint64) []byte {
+	var groups []byte
+	for {
+		groups = append([]byte{byte(v & 0x7f)}, groups...)
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	for i := range groups[:len(groups)-1] {
+		groups[i] |= 0x80
+	}
+	return append(p, groups...)
+}
@@ -0,0 +1,289 @@
+// Package wc lê uma cópia de trabalho do Subversion como um repositório de
+// revisão única: a árvore é a do disco, com as modificações locais e os
+// arquivos não versionados (exceto os ignorados), e as propriedades vêm do
+// .svn/wc.db.
+package wc
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"svndiff/internal/svn"
+)
+
+// defaultGlobalIgnores é o valor padrão de global-ignores do svn
+var defaultGlobalIgnores = strings.Fields("*.o *.lo *.la *.al .libs *.so *.so.[0-9]* *.a *.pyc *.pyo __pycache__ *.rej *~ #*# .#* .*.swp .DS_Store [Tt]humbs.db")
+
+// Repository é um diretório de uma cópia de trabalho, implementando
+// svn.Repository. As revisões informadas são ignoradas.
+type Repository struct {
+	root string
+	base string
+	db   *wcDB
+}
+
+// Open abre a cópia de trabalho que contém o caminho
+func Open(dir string) (*Repository, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("cópia de trabalho não encontrada: %s", dir)
+	}
+	root, err := findRoot(abs)
+	if err != nil {
+		return nil, err
+	}
+	db, err := readDB(root)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := filepath.Rel(root, abs)
+	if err != nil {
+		return nil, err
+	}
+	if base = filepath.ToSlash(base); base == "." {
+		base = ""
+	}
+	if _, ok := db.nodes[base]; !ok {
+		return nil, fmt.Errorf("'%s' não está versionado", dir)
+	}
+	return &Repository{root: root, base: base, db: db}, nil
+}
+
+// findRoot sobe a partir do caminho até o diretório que contém .svn/wc.db
+func findRoot(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".svn", "wc.db")); err == nil {
+			return dir, nil
+		}
+		if filepath.Dir(dir) == dir {
+			return "", fmt.Errorf("'%s' não é uma cópia de trabalho do svn", path)
+		}
+	}
+}
+
+// Close não mantém recursos abertos
+func (r *Repository) Close() error {
+	return nil
+}
+
+// LatestRevision retorna a revisão base do diretório
+func (r *Repository) LatestRevision() (int64, error) {
+	return r.db.nodes[r.base].baseRev, nil
+}
+
+// RevisionLabel identifica a cópia de trabalho nos cabeçalhos do diff
+func (r *Repository) RevisionLabel(int64) string {
+	return "(working copy)"
+}
+
+// CheckPath retorna o tipo do nó no disco, desconsiderando os ignorados
+func (r *Repository) CheckPath(path string, _ int64) (svn.NodeKind, error) {
+	rel := r.relPath(path)
+	info, err := os.Lstat(r.absPath(rel))
+	if err != nil || r.ignored(rel) {
+		return svn.NodeNone, nil
+	}
+	if info.IsDir() {
+		return svn.NodeDir, nil
+	}
+	return svn.NodeFile, nil
+}
+
+// GetDir lista o diretório no disco, incluindo os arquivos não versionados
+// que não são ignorados
+func (r *Repository) GetDir(dirPath string, _ int64) (*svn.Dir, error) {
+	rel := r.relPath(dirPath)
+	infos, err := os.ReadDir(r.absPath(rel))
+	if err != nil {
+		return nil, err
+	}
+
+	dir := &svn.Dir{Props: r.props(rel)}
+	for _, info := range infos {
+		child := joinRel(rel, info.Name())
+		if info.Name() == ".svn" || r.ignored(child) {
+			continue
+		}
+		entry := svn.DirEntry{Name: info.Name(), Kind: svn.NodeFile}
+		if info.IsDir() {
+			entry.Kind = svn.NodeDir
+		} else if fi, err := info.Info(); err == nil {
+			entry.Size = fi.Size()
+		}
+		if node, ok := r.db.nodes[child]; ok {
+			entry.CreatedRev = node.baseRev
+		}
+		dir.Entries = append(dir.Entries, entry)
+	}
+	return dir, nil
+}
+
+// GetFile lê o arquivo do disco na forma normalizada do repositório: fins
+// de linha em LF para svn:eol-style e palavras-chave de svn:keywords
+// contraídas. Links simbólicos viram "link destino", como no repositório.
+func (r *Repository) GetFile(filePath string, _ int64, withContent bool) (*svn.File, error) {
+	rel := r.relPath(filePath)
+	abs := r.absPath(rel)
+	info, err := os.Lstat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("'%s' não é um arquivo", rel)
+	}
+
+	props := r.props(rel)
+	var content []byte
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(abs)
+		if err != nil {
+			return nil, err
+		}
+		content = []byte("link " + filepath.ToSlash(target))
+		if props == nil {
+			props = map[string]string{"svn:special": "*"}
+		}
+	} else if content, err = os.ReadFile(abs); err != nil {
+		return nil, err
+	}
+	content = normalize(content, props)
+
+	sum := md5.Sum(content)
+	file := &svn.File{Checksum: hex.EncodeToString(sum[:]), Props: props}
+	if withContent {
+		file.Content = content
+	}
+	return file, nil
+}
+
+// GetLog não é suportado: o histórico está no repositório de origem
+func (r *Repository) GetLog(string, int64, int64, int) ([]svn.LogEntry, error) {
+	return nil, fmt.Errorf("histórico não disponível para a cópia de trabalho %s", r.absPath(r.base))
+}
+
+// props retorna as propriedades versionadas do caminho, ou nil se não
+// versionado
+func (r *Repository) props(rel string) map[string]string {
+	node, ok := r.db.nodes[rel]
+	if !ok {
+		return nil
+	}
+	props := make(map[string]string, len(node.props))
+	for name, value := range node.props {
+		props[name] = value
+	}
+	return props
+}
+
+// ignored indica se um caminho não versionado é ignorado pelo svn:ignore do
+// diretório pai, pelo svn:global-ignores herdado ou pelo global-ignores
+// padrão. Caminhos agendados para remoção também ficam fora da árvore.
+func (r *Repository) ignored(rel string) bool {
+	if _, ok := r.db.nodes[rel]; ok {
+		return false
+	}
+	if r.db.deleted[rel] {
+		return true
+	}
+
+	name := path.Base(rel)
+	patterns := append([]string(nil), defaultGlobalIgnores...)
+	parent := path.Dir(rel)
+	if parent == "." {
+		parent = ""
+	}
+	if node, ok := r.db.nodes[parent]; ok {
+		patterns = append(patterns, strings.Fields(node.props["svn:ignore"])...)
+	}
+	for dir := parent; ; dir = path.Dir(dir) {
+		if dir == "." {
+			dir = ""
+		}
+		if node, ok := r.db.nodes[dir]; ok {
+			patterns = append(patterns, strings.Fields(node.props["svn:global-ignores"])...)
+		}
+		if dir == "" {
+			break
+		}
+	}
+
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// relPath converte um caminho relativo à URL em caminho relativo à raiz
+func (r *Repository) relPath(p string) string {
+	return joinRel(r.base, strings.Trim(p, "/"))
+}
+
+func (r *Repository) absPath(rel string) string {
+	return filepath.Join(r.root, filepath.FromSlash(rel))
+}
+
+func joinRel(dir, name string) string {
+	switch {
+	case dir == "":
+		return name
+	case name == "":
+		return dir
+	}
+	return dir + "/" + name
+}
+
+// keywordAliases relaciona cada palavra-chave aos nomes equivalentes
+var keywordAliases = map[string][]string{
+	"LastChangedDate":     {"LastChangedDate", "Date"},
+	"Date":                {"LastChangedDate", "Date"},
+	"LastChangedRevision": {"LastChangedRevision", "Rev", "Revision"},
+	"Rev":                 {"LastChangedRevision", "Rev", "Revision"},
+	"Revision":            {"LastChangedRevision", "Rev", "Revision"},
+	"LastChangedBy":       {"LastChangedBy", "Author"},
+	"Author":              {"LastChangedBy", "Author"},
+	"HeadURL":             {"HeadURL", "URL"},
+	"URL":                 {"HeadURL", "URL"},
+	"Id":                  {"Id"},
+	"Header":              {"Header"},
+}
+
+// normalize converte o conteúdo do disco para a forma gravada no repositório
+func normalize(content []byte, props map[string]string) []byte {
+	if props["svn:eol-style"] != "" {
+		content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+		content = bytes.ReplaceAll(content, []byte("\r"), []byte("\n"))
+	}
+
+	var names []string
+	for _, keyword := range strings.Fields(props["svn:keywords"]) {
+		name, _, custom := strings.Cut(keyword, "=")
+		if aliases, ok := keywordAliases[name]; ok && !custom {
+			names = append(names, aliases...)
+		} else {
+			names = append(names, regexp.QuoteMeta(name))
+		}
+	}
+	if len(names) > 0 {
+		re := regexp.MustCompile(`\$(` + strings.Join(names, "|") + `): [^$\n]* \$`)
+		content = re.ReplaceAll(content, []byte("$$$1$$"))
+	}
+	return content
+}
@@ -0,0 +1,82 @@
+package wc
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// parseProps decodifica a lista de propriedades gravada no wc.db como skel:
+// "(nome valor nome valor ...)". Os átomos são implícitos (uma palavra que
+// começa por letra) ou explícitos ("tamanho bytes").
+func parseProps(data []byte) (map[string]string, error) {
+	props := map[string]string{}
+	if len(data) == 0 {
+		return props, nil
+	}
+
+	p := skipSpace(data, 0)
+	if p >= len(data) || data[p] != '(' {
+		return nil, fmt.Errorf("propriedades inválidas no wc.db: %q", data)
+	}
+	p++
+
+	var atoms []string
+	for {
+		p = skipSpace(data, p)
+		if p >= len(data) {
+			return nil, fmt.Errorf("propriedades truncadas no wc.db")
+		}
+		if data[p] == ')' {
+			break
+		}
+		atom, next, err := readAtom(data, p)
+		if err != nil {
+			return nil, err
+		}
+		atoms = append(atoms, atom)
+		p = next
+	}
+
+	if len(atoms)%2 != 0 {
+		return nil, fmt.Errorf("propriedades inválidas no wc.db: número ímpar de átomos")
+	}
+	for i := 0; i < len(atoms); i += 2 {
+		props[atoms[i]] = atoms[i+1]
+	}
+	return props, nil
+}
+
+// readAtom lê um átomo a partir de p, retornando a posição seguinte
+func readAtom(data []byte, p int) (string, int, error) {
+	switch c := data[p]; {
+	case c >= '0' && c <= '9':
+		end := p
+		for end < len(data) && data[end] >= '0' && data[end] <= '9' {
+			end++
+		}
+		n, err := strconv.Atoi(string(data[p:end]))
+		if err != nil || end >= len(data) || !isSpace(data[end]) || end+1+n > len(data) {
+			return "", 0, fmt.Errorf("átomo inválido nas propriedades do wc.db")
+		}
+		return string(data[end+1 : end+1+n]), end + 1 + n, nil
+	case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		end := p
+		for end < len(data) && !isSpace(data[end]) && data[end] != '(' && data[end] != ')' {
+			end++
+		}
+		return string(data[p:end]), end, nil
+	default:
+		return "", 0, fmt.Errorf("átomo inválido nas propriedades do wc.db: %q", c)
+	}
+}
+
+func skipSpace(data []byte, p int) int {
+	for p < len(data) && isSpace(data[p]) {
+		p++
+	}
+	return p
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,385 @@
+package wc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// errCorrupt indica um banco SQLite inválido ou em formato não suportado
+var errCorrupt = errors.New("banco SQLite corrompido")
+
+// sqliteDB é um leitor mínimo do formato de arquivo do SQLite 3, suficiente
+// para percorrer as tabelas do wc.db sem depender de uma biblioteca externa.
+// Apenas tabelas com rowid e codificação UTF-8 são suportadas.
+type sqliteDB struct {
+	data     []byte
+	pageSize int
+	usable   int
+}
+
+// sqliteTable é uma tabela localizada no sqlite_master
+type sqliteTable struct {
+	db      *sqliteDB
+	root    int
+	columns []string
+	// rowid é a coluna declarada como INTEGER PRIMARY KEY, gravada como NULL
+	// no registro e substituída pelo rowid, ou -1
+	rowid int
+}
+
+// sqliteRow é uma linha indexada pelo nome da coluna. Os valores são int64,
+// float64, string, []byte ou nil.
+type sqliteRow map[string]any
+
+func openSQLite(path string) (*sqliteDB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 100 || !bytes.HasPrefix(data, []byte("SQLite format 3\x00")) {
+		return nil, fmt.Errorf("%s não é um banco SQLite", path)
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(data[16:]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("%s: tamanho de página inválido: %d", path, pageSize)
+	}
+	if encoding := binary.BigEndian.Uint32(data[56:]); encoding != 0 && encoding != 1 {
+		return nil, fmt.Errorf("%s: codificação de texto não suportada: %d", path, encoding)
+	}
+	return &sqliteDB{data: data, pageSize: pageSize, usable: pageSize - int(data[20])}, nil
+}
+
+// page retorna o conteúdo da página, numerada a partir de 1
+func (db *sqliteDB) page(n int) ([]byte, error) {
+	start := (n - 1) * db.pageSize
+	if n < 1 || start+db.pageSize > len(db.data) {
+		return nil, fmt.Errorf("%w: página %d inexistente", errCorrupt, n)
+	}
+	return db.data[start : start+db.pageSize], nil
+}
+
+// table localiza a tabela no sqlite_master e lê os nomes das colunas do seu
+// CREATE TABLE
+func (db *sqliteDB) table(name string) (*sqliteTable, error) {
+	master := &sqliteTable{db: db, root: 1, columns: []string{"type", "name", "tbl_name", "rootpage", "sql"}, rowid: -1}
+
+	var found *sqliteTable
+	err := master.scan(func(row sqliteRow) error {
+		if row["type"] != "table" || !strings.EqualFold(asString(row["name"]), name) {
+			return nil
+		}
+		sql := asString(row["sql"])
+		if strings.Contains(strings.ToUpper(sql), "WITHOUT ROWID") {
+			return fmt.Errorf("tabela %s sem rowid não suportada", name)
+		}
+		columns, rowid, err := parseColumns(sql)
+		if err != nil {
+			return fmt.Errorf("tabela %s: %w", name, err)
+		}
+		found = &sqliteTable{db: db, root: int(asInt(row["rootpage"])), columns: columns, rowid: rowid}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("tabela %s não encontrada", name)
+	}
+	return found, nil
+}
+
+// parseColumns extrai os nomes das colunas de um CREATE TABLE, ignorando as
+// restrições da tabela
+func parseColumns(sql string) ([]string, int, error) {
+	open, end := strings.IndexByte(sql, '('), strings.LastIndexByte(sql, ')')
+	if open < 0 || end < open {
+		return nil, -1, fmt.Errorf("CREATE TABLE inválido: %q", sql)
+	}
+
+	var parts []string
+	depth, start := 0, open+1
+	for i := open + 1; i < end; i++ {
+		switch sql[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, sql[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, sql[start:end])
+
+	var columns []string
+	rowid := -1
+	for _, part := range parts {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+			continue
+		}
+		if strings.Contains(strings.ToUpper(strings.Join(fields, " ")), "INTEGER PRIMARY KEY") {
+			rowid = len(columns)
+		}
+		columns = append(columns, strings.Trim(fields[0], "\"`[]'"))
+	}
+	return columns, rowid, nil
+}
+
+// scan percorre todas as linhas da tabela em ordem de rowid
+func (t *sqliteTable) scan(fn func(sqliteRow) error) error {
+	return t.visit(t.root, 0, fn)
+}
+
+// maxDepth limita a profundidade da árvore B, protegendo contra ciclos
+const maxDepth = 32
+
+func (t *sqliteTable) visit(pageNo, depth int, fn func(sqliteRow) error) error {
+	if depth > maxDepth {
+		return fmt.Errorf("%w: árvore B profunda demais", errCorrupt)
+	}
+	p, err := t.db.page(pageNo)
+	if err != nil {
+		return err
+	}
+	hdr := 0
+	if pageNo == 1 {
+		hdr = 100
+	}
+	cells := int(binary.BigEndian.Uint16(p[hdr+3:]))
+
+	// O vetor de ponteiros das células segue o cabeçalho da página, de 12
+	// bytes nas páginas internas e 8 nas folhas
+	pointers := hdr + 8
+	if p[hdr] == 0x05 {
+		pointers = hdr + 12
+	}
+	if pointers+2*cells > len(p) {
+		return fmt.Errorf("%w: %d células não cabem na página %d", errCorrupt, cells, pageNo)
+	}
+
+	switch p[hdr] {
+	case 0x05: // página interna de tabela
+		for i := 0; i < cells; i++ {
+			ptr := int(binary.BigEndian.Uint16(p[pointers+2*i:]))
+			if ptr+4 > len(p) {
+				return fmt.Errorf("%w: célula fora da página %d", errCorrupt, pageNo)
+			}
+			if err := t.visit(int(binary.BigEndian.Uint32(p[ptr:])), depth+1, fn); err != nil {
+				return err
+			}
+		}
+		return t.visit(int(binary.BigEndian.Uint32(p[hdr+8:])), depth+1, fn)
+	case 0x0d: // folha de tabela
+		for i := 0; i < cells; i++ {
+			ptr := int(binary.BigEndian.Uint16(p[pointers+2*i:]))
+			row, err := t.readCell(p, ptr)
+			if err != nil {
+				return fmt.Errorf("página %d: %w", pageNo, err)
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: página %d de tipo %#x", errCorrupt, pageNo, p[hdr])
+	}
+}
+
+// readCell lê uma célula de folha: tamanho do registro, rowid e registro,
+// seguindo as páginas de overflow quando o registro não cabe na página
+func (t *sqliteTable) readCell(p []byte, ptr int) (sqliteRow, error) {
+	if ptr >= len(p) {
+		return nil, errCorrupt
+	}
+	size, n := readVarint(p[ptr:])
+	if n == 0 {
+		return nil, errCorrupt
+	}
+	ptr += n
+	rowid, n := readVarint(p[ptr:])
+	if n == 0 {
+		return nil, errCorrupt
+	}
+	ptr += n
+
+	// Um registro não pode ser maior que o próprio arquivo
+	if size > uint64(len(t.db.data)) {
+		return nil, fmt.Errorf("%w: registro de %d bytes", errCorrupt, size)
+	}
+	payload, err := t.payload(p, ptr, int(size))
+	if err != nil {
+		return nil, err
+	}
+	values, err := parseRecord(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	row := make(sqliteRow, len(t.columns))
+	for i, name := range t.columns {
+		if i < len(values) {
+			row[name] = values[i]
+		}
+	}
+	if t.rowid >= 0 && row[t.columns[t.rowid]] == nil {
+		row[t.columns[t.rowid]] = int64(rowid)
+	}
+	return row, nil
+}
+
+// payload monta o registro a partir da parte local e da cadeia de overflow
+func (t *sqliteTable) payload(p []byte, ptr, size int) ([]byte, error) {
+	u := t.db.usable
+	maxLocal := u - 35
+	local := size
+	if size > maxLocal {
+		minLocal := (u-12)*32/255 - 23
+		local = minLocal + (size-minLocal)%(u-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	if ptr+local > len(p) {
+		return nil, errCorrupt
+	}
+
+	payload := make([]byte, 0, size)
+	payload = append(payload, p[ptr:ptr+local]...)
+	if local == size {
+		return payload, nil
+	}
+
+	if ptr+local+4 > len(p) {
+		return nil, errCorrupt
+	}
+	next := int(binary.BigEndian.Uint32(p[ptr+local:]))
+	for len(payload) < size {
+		if next == 0 {
+			return nil, fmt.Errorf("%w: cadeia de overflow truncada", errCorrupt)
+		}
+		op, err := t.db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		chunk := min(size-len(payload), u-4)
+		payload = append(payload, op[4:4+chunk]...)
+		next = int(binary.BigEndian.Uint32(op))
+	}
+	return payload, nil
+}
+
+// parseRecord decodifica um registro: cabeçalho com os tipos seriais
+// seguido dos valores
+func parseRecord(p []byte) ([]any, error) {
+	hdrLen, n := readVarint(p)
+	if int(hdrLen) > len(p) || n == 0 {
+		return nil, errCorrupt
+	}
+
+	var types []uint64
+	for pos := n; pos < int(hdrLen); {
+		st, n := readVarint(p[pos:])
+		if n == 0 {
+			return nil, errCorrupt
+		}
+		types = append(types, st)
+		pos += n
+	}
+
+	values := make([]any, 0, len(types))
+	body := p[hdrLen:]
+	for _, st := range types {
+		var size int
+		switch {
+		case st == 0, st == 8, st == 9:
+			size = 0
+		case st >= 1 && st <= 4:
+			size = int(st)
+		case st == 5:
+			size = 6
+		case st == 6, st == 7:
+			size = 8
+		case st >= 12:
+			size = int(st-12) / 2
+		default:
+			return nil, fmt.Errorf("%w: tipo serial %d", errCorrupt, st)
+		}
+		if size > len(body) {
+			return nil, errCorrupt
+		}
+		field := body[:size]
+		body = body[size:]
+
+		switch {
+		case st == 0:
+			values = append(values, nil)
+		case st == 8:
+			values = append(values, int64(0))
+		case st == 9:
+			values = append(values, int64(1))
+		case st == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(field)))
+		case st <= 6:
+			v := int64(int8(field[0]))
+			for _, b := range field[1:] {
+				v = v<<8 | int64(b)
+			}
+			values = append(values, v)
+		case st%2 == 0:
+			values = append(values, bytes.Clone(field))
+		default:
+			values = append(values, string(field))
+		}
+	}
+	return values, nil
+}
+
+// readVarint lê um inteiro de até 9 bytes, mais significativos primeiro;
+// o nono byte contribui com os 8 bits. Retorna 0 bytes lidos se truncado.
+func readVarint(p []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(p); i++ {
+		if i == 8 {
+			return v<<8 | uint64(p[i]), 9
+		}
+		v = v<<7 | uint64(p[i]&0x7f)
+		if p[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+func asString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+func asInt(v any) int64 {
+	if i, ok := v.(int64); ok {
+		return i
+	}
+	return 0
+}
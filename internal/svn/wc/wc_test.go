@@ -0,0 +1,396 @@
+package wc
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"svndiff/internal/svn"
+	"svndiff/internal/svn/dump"
+	"svndiff/internal/svn/svntest"
+	"svndiff/pkg/config"
+)
+
+const (
+	nodesSQL = "CREATE TABLE NODES ( wc_id INTEGER NOT NULL REFERENCES WCROOT (id), local_relpath TEXT NOT NULL, " +
+		"op_depth INTEGER NOT NULL, parent_relpath TEXT, repos_id INTEGER REFERENCES REPOSITORY (id), repos_path TEXT, " +
+		"revision INTEGER, presence TEXT NOT NULL, moved_here INTEGER, moved_to TEXT, kind TEXT NOT NULL, properties BLOB, " +
+		"depth TEXT, checksum TEXT REFERENCES PRISTINE (checksum), symlink_target TEXT, changed_revision INTEGER, " +
+		"changed_date INTEGER, changed_author TEXT, translated_size INTEGER, last_mod_time INTEGER, dav_cache BLOB, " +
+		"file_external INTEGER, inherited_props BLOB, PRIMARY KEY (wc_id, local_relpath, op_depth) )"
+	actualSQL = "CREATE TABLE ACTUAL_NODE ( wc_id INTEGER NOT NULL REFERENCES WCROOT (id), local_relpath TEXT NOT NULL, " +
+		"parent_relpath TEXT, properties BLOB, conflict_old TEXT, conflict_new TEXT, conflict_working TEXT, " +
+		"prop_reject TEXT, changelist TEXT, text_mod TEXT, PRIMARY KEY (wc_id, local_relpath) )"
+)
+
+// node monta uma linha de NODES com as colunas usadas pelo leitor
+func node(relpath string, opDepth int, presence, kind, props string) []any {
+	var blob any
+	if props != "" {
+		blob = []byte(props)
+	}
+	return []any{1, relpath, opDepth, nil, 1, "trunk/" + relpath, 1, presence, nil, nil, kind, blob,
+		nil, nil, nil, 1, nil, "alice", nil, nil, nil, nil, nil}
+}
+
+// history é o repositório de origem: trunk em r1
+var history = [][]svntest.DumpNode{{
+	{Path: "trunk", Kind: "dir", Action: "add", Props: []string{"svn:ignore", "build\n"}},
+	{Path: "trunk/a.txt", Kind: "file", Action: "add", Content: "um\ndois\n", Props: []string{"svn:eol-style", "native"}},
+	{Path: "trunk/k.txt", Kind: "file", Action: "add", Content: "$Id$\n", Props: []string{"svn:keywords", "Id"}},
+	{Path: "trunk/old.txt", Kind: "file", Action: "add", Content: "velho\n"},
+	{Path: "trunk/gone.txt", Kind: "file", Action: "add", Content: "sumiu\n"},
+	{Path: "trunk/rm.txt", Kind: "file", Action: "add", Content: "rm\n"},
+	{Path: "trunk/lib", Kind: "dir", Action: "add"},
+	{Path: "trunk/lib/x.c", Kind: "file", Action: "add", Content: "int x;\n"},
+}}
+
+// writeWorkingCopy cria uma cópia de trabalho de trunk@1 com modificações
+// locais, arquivos não versionados e ignorados
+func writeWorkingCopy(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "wc")
+	files := map[string]string{
+		"a.txt":    "um\r\n2\r\n",
+		"k.txt":    "$Id: k.txt 1 2024-03-01 alice $\n",
+		"old.txt":  "velho\n",
+		"rm.txt":   "rm\n",
+		"new.txt":  "novo\n",
+		"lib/x.c":  "int x;\n",
+		"lib/x.o":  "\x7fELF",
+		"build/a":  "gerado\n",
+		"notas~":   "rascunho\n",
+		".svn/tmp": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeSQLite(t, filepath.Join(dir, ".svn", "wc.db"), 4096, []fixtureTable{
+		{name: "NODES", sql: nodesSQL, rows: [][]any{
+			node("", 0, "normal", "dir", "(svn:ignore 6 build\n)"),
+			node("a.txt", 0, "normal", "file", "(svn:eol-style 6 native)"),
+			node("k.txt", 0, "normal", "file", "(svn:keywords 2 Id)"),
+			node("old.txt", 0, "normal", "file", "()"),
+			node("gone.txt", 0, "normal", "file", "()"),
+			node("rm.txt", 0, "normal", "file", "()"),
+			node("rm.txt", 1, "base-deleted", "file", ""),
+			node("lib", 0, "normal", "dir", "()"),
+			node("lib/x.c", 0, "normal", "file", "()"),
+		}},
+		{name: "ACTUAL_NODE", sql: actualSQL, rows: [][]any{
+			{1, "old.txt", "", []byte("(svn:mime-type 10 text/plain)"), nil, nil, nil, nil, nil, nil},
+			{1, "lib", "", nil, nil, nil, nil, nil, "minhas", nil},
+		}},
+	})
+	return dir
+}
+
+func TestSQLite(t *testing.T) {
+	big := []byte(strings.Repeat("0123456789", 500))
+	var rows [][]any
+	for i := 0; i < 200; i++ {
+		rows = append(rows, []any{nil, "linha", int64(i * 1000), nil})
+	}
+	rows = append(rows, []any{nil, "grande", int64(-70000), big})
+
+	path := filepath.Join(t.TempDir(), "teste.db")
+	writeSQLite(t, path, 512, []fixtureTable{
+		{name: "OUTRA", sql: "CREATE TABLE OUTRA (x TEXT)", rows: [][]any{{"y"}}},
+		{name: "ITENS", sql: "CREATE TABLE ITENS ( id INTEGER PRIMARY KEY AUTOINCREMENT, nome TEXT, valor INTEGER, dados BLOB, UNIQUE (nome, valor) )", rows: rows},
+	})
+
+	db, err := openSQLite(path)
+	if err != nil {
+		t.Fatalf("openSQLite() error = %v", err)
+	}
+	table, err := db.table("itens")
+	if err != nil {
+		t.Fatalf("table() error = %v", err)
+	}
+	if want := []string{"id", "nome", "valor", "dados"}; !reflect.DeepEqual(table.columns, want) {
+		t.Errorf("colunas = %v, want %v", table.columns, want)
+	}
+
+	var got []sqliteRow
+	if err := table.scan(func(row sqliteRow) error {
+		got = append(got, row)
+		return nil
+	}); err != nil {
+		t.Fatalf("scan() error = %v", err)
+	}
+	if len(got) != len(rows) {
+		t.Fatalf("scan() leu %d linhas, want %d", len(got), len(rows))
+	}
+	if got[7]["id"] != int64(8) || got[7]["valor"] != int64(7000) || got[7]["dados"] != nil {
+		t.Errorf("linha 8 = %v", got[7])
+	}
+	last := got[len(got)-1]
+	if last["nome"] != "grande" || last["valor"] != int64(-70000) || !reflect.DeepEqual(last["dados"], big) {
+		t.Errorf("linha com overflow = %v %v, %d bytes", last["nome"], last["valor"], len(asString(last["dados"])))
+	}
+
+	if _, err := db.table("NADA"); err == nil {
+		t.Error("table(NADA) esperava erro")
+	}
+}
+
+func TestParseProps(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"vazio", "", map[string]string{}, false},
+		{"lista vazia", "()", map[string]string{}, false},
+		{"átomos implícitos", "(svn:eol-style native)", map[string]string{"svn:eol-style": "native"}, false},
+		{"átomos explícitos", "(13 svn:eol-style 6 native 8 svn:mime 0 )", map[string]string{"svn:eol-style": "native", "svn:mime": ""}, false},
+		{"valor com quebras", "(svn:ignore 12 build\n*.tmp\n)", map[string]string{"svn:ignore": "build\n*.tmp\n"}, false},
+		{"ímpar", "(svn:eol-style)", nil, true},
+		{"truncado", "(svn:eol-style 9 native)", nil, true},
+		{"sem lista", "svn:eol-style", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseProps([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseProps() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseProps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository(t *testing.T) {
+	dir := writeWorkingCopy(t)
+
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if latest, err := repo.LatestRevision(); err != nil || latest != 1 {
+		t.Errorf("LatestRevision() = %d, %v; want 1", latest, err)
+	}
+
+	root, err := repo.GetDir("", 0)
+	if err != nil {
+		t.Fatalf("GetDir() error = %v", err)
+	}
+	var names []string
+	for _, entry := range root.Entries {
+		names = append(names, entry.Name)
+	}
+	if want := []string{"a.txt", "k.txt", "lib", "new.txt", "old.txt"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("GetDir() nomes = %v, want %v", names, want)
+	}
+	if root.Props["svn:ignore"] != "build\n" {
+		t.Errorf("GetDir() props = %v", root.Props)
+	}
+
+	for path, want := range map[string]string{"a.txt": "um\n2\n", "k.txt": "$Id$\n", "new.txt": "novo\n"} {
+		file, err := repo.GetFile(path, 0, true)
+		if err != nil || string(file.Content) != want {
+			t.Errorf("GetFile(%q) = %+v, %v; want %q", path, file, err, want)
+		}
+	}
+	if file, _ := repo.GetFile("old.txt", 0, false); file.Props["svn:mime-type"] != "text/plain" {
+		t.Errorf("GetFile(old.txt) props = %v", file.Props)
+	}
+
+	for path, want := range map[string]svn.NodeKind{
+		"lib": svn.NodeDir, "new.txt": svn.NodeFile, "gone.txt": svn.NodeNone, "lib/x.o": svn.NodeNone, "build": svn.NodeNone,
+	} {
+		if kind, _ := repo.CheckPath(path, 0); kind != want {
+			t.Errorf("CheckPath(%q) = %q, want %q", path, kind, want)
+		}
+	}
+
+	sub, err := Open(filepath.Join(dir, "lib"))
+	if err != nil {
+		t.Fatalf("Open(lib) error = %v", err)
+	}
+	if file, err := sub.GetFile("x.c", 0, true); err != nil || string(file.Content) != "int x;\n" {
+		t.Errorf("GetFile(lib/x.c) = %+v, %v", file, err)
+	}
+	if _, err := sub.GetLog("", 1, 1, 0); err == nil {
+		t.Error("GetLog() esperava erro")
+	}
+}
+
+func TestRepositoryBackend_GetDiff(t *testing.T) {
+	dir := writeWorkingCopy(t)
+	dumpFile := filepath.Join(t.TempDir(), "repo.dump")
+	if err := os.WriteFile(dumpFile, svntest.WriteDump(history), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := svn.NewRepositoryBackend(func(url string) (svn.Repository, error) {
+		if strings.HasPrefix(url, dump.Scheme) {
+			return dump.Open(url)
+		}
+		return Open(url)
+	})
+	branchA := &config.BranchConfig{URL: dump.Scheme + filepath.ToSlash(dumpFile) + "/trunk", Revisions: []string{"1"}}
+	branchB := &config.BranchConfig{URL: dir}
+
+	summary, err := backend.GetDiff(branchA, branchB, true)
+	if err != nil {
+		t.Fatalf("GetDiff(summarize) error = %v", err)
+	}
+	want := "M       a.txt\nD       gone.txt\nA       new.txt\n M      old.txt\nD       rm.txt\n"
+	if got := svntest.SummaryLines(t, summary.Output); got != want {
+		t.Errorf("GetDiff(summarize) = %q, want %q", got, want)
+	}
+
+	full, err := backend.GetDiff(branchA, branchB, false)
+	if err != nil {
+		t.Fatalf("GetDiff() error = %v", err)
+	}
+	for _, fragment := range []string{
+		"+++ a.txt\t(working copy)\n@@ -1,2 +1,2 @@\n um\n-dois\n+2\n",
+		"Index: new.txt\n",
+		"Added: svn:mime-type\n",
+	} {
+		if !strings.Contains(full.Output, fragment) {
+			t.Errorf("GetDiff() sem %q:\n%s", fragment, full.Output)
+		}
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	dir := writeWorkingCopy(t)
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"sem .svn", t.TempDir()},
+		{"inexistente", filepath.Join(dir, "nada")},
+		{"arquivo", filepath.Join(dir, "a.txt")},
+		{"não versionado", filepath.Join(dir, "build")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Open(tt.path); err == nil {
+				t.Errorf("Open(%q) esperava erro", tt.path)
+			}
+		})
+	}
+}
+
+// TestIntegration_Checkout lê uma cópia de trabalho criada pelo svn. É
+// ignorado quando o svn não está instalado.
+func TestIntegration_Checkout(t *testing.T) {
+	if _, err := exec.LookPath("svn"); err != nil {
+		t.Skip("svn não encontrado no PATH")
+	}
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	svntest.CreateRepository(t, repoDir, svntest.WriteDump(history))
+
+	dir := filepath.Join(t.TempDir(), "wc")
+	run := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("svn", args...).CombinedOutput(); err != nil {
+			t.Fatalf("svn %v: %v\n%s", args, err, out)
+		}
+	}
+	run("checkout", "-q", "file://"+filepath.ToSlash(repoDir)+"/trunk", dir)
+	run("propset", "-q", "svn:mime-type", "text/plain", filepath.Join(dir, "old.txt"))
+	run("rm", "-q", "--keep-local", filepath.Join(dir, "rm.txt"))
+	os.Remove(filepath.Join(dir, "gone.txt"))
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("um\n2\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("novo\n"), 0o644)
+
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	root, err := repo.GetDir("", 0)
+	if err != nil {
+		t.Fatalf("GetDir() error = %v", err)
+	}
+	var names []string
+	for _, entry := range root.Entries {
+		names = append(names, entry.Name)
+	}
+	if want := []string{"a.txt", "k.txt", "lib", "new.txt", "old.txt"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("GetDir() nomes = %v, want %v", names, want)
+	}
+	if file, err := repo.GetFile("k.txt", 0, true); err != nil || string(file.Content) != "$Id$\n" {
+		t.Errorf("GetFile(k.txt) = %+v, %v", file, err)
+	}
+	if file, err := repo.GetFile("old.txt", 0, false); err != nil || file.Props["svn:mime-type"] != "text/plain" {
+		t.Errorf("GetFile(old.txt) = %+v, %v", file, err)
+	}
+}
+
+func TestSQLite_Corrupt(t *testing.T) {
+	var rows [][]any
+	for i := 0; i < 5; i++ {
+		rows = append(rows, []any{"linha", int64(i)})
+	}
+
+	tests := []struct {
+		name    string
+		corrupt func(page []byte)
+	}{
+		{"células demais", func(page []byte) { binary.BigEndian.PutUint16(page[3:], 0xffff) }},
+		{"registro negativo", func(page []byte) {
+			ptr := binary.BigEndian.Uint16(page[8:])
+			copy(page[ptr:], []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+		}},
+		{"registro maior que o arquivo", func(page []byte) {
+			ptr := binary.BigEndian.Uint16(page[8:])
+			copy(page[ptr:], []byte{0x87, 0xff, 0xff, 0x7f})
+		}},
+		{"varint truncado no fim da página", func(page []byte) {
+			binary.BigEndian.PutUint16(page[8:], uint16(len(page)-1))
+			page[len(page)-1] = 0xff
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "teste.db")
+			writeSQLite(t, path, 512, []fixtureTable{
+				{name: "ITENS", sql: "CREATE TABLE ITENS (nome TEXT, valor INTEGER)", rows: rows},
+			})
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// A tabela ocupa a página 2, logo após o sqlite_master
+			tt.corrupt(data[512:1024])
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			db, err := openSQLite(path)
+			if err != nil {
+				t.Fatalf("openSQLite() error = %v", err)
+			}
+			table, err := db.table("ITENS")
+			if err != nil {
+				t.Fatalf("table() error = %v", err)
+			}
+			err = table.scan(func(sqliteRow) error { return nil })
+			if !errors.Is(err, errCorrupt) {
+				t.Errorf("scan() error = %v, want errCorrupt", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package wc
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"svndiff/internal/svn"
+)
+
+// versioned é o estado versionado de um caminho na cópia de trabalho,
+// já considerando adições, remoções e mudanças locais de propriedades
+type versioned struct {
+	kind    svn.NodeKind
+	props   map[string]string
+	baseRev int64
+}
+
+// wcDB é o conteúdo relevante do .svn/wc.db
+type wcDB struct {
+	nodes map[string]*versioned
+	// deleted contém os caminhos agendados para remoção; os mantidos no
+	// disco (svn rm --keep-local) não fazem parte da árvore
+	deleted map[string]bool
+}
+
+// nodeRow é a linha de NODES de maior op_depth de um caminho, que
+// representa o estado de trabalho
+type nodeRow struct {
+	opDepth  int64
+	presence string
+	kind     string
+	props    []byte
+}
+
+// readDB lê as tabelas NODES e ACTUAL_NODE do wc.db da raiz
+func readDB(root string) (*wcDB, error) {
+	path := filepath.Join(root, ".svn", "wc.db")
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := db.table("NODES")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	working := map[string]*nodeRow{}
+	baseRevs := map[string]int64{}
+	err = nodes.scan(func(row sqliteRow) error {
+		relpath := asString(row["local_relpath"])
+		opDepth := asInt(row["op_depth"])
+		if opDepth == 0 {
+			baseRevs[relpath] = asInt(row["revision"])
+		}
+		if cur, ok := working[relpath]; ok && cur.opDepth > opDepth {
+			return nil
+		}
+		props, _ := row["properties"].([]byte)
+		working[relpath] = &nodeRow{
+			opDepth:  opDepth,
+			presence: asString(row["presence"]),
+			kind:     asString(row["kind"]),
+			props:    props,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	actual, err := db.table("ACTUAL_NODE")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	actualProps := map[string][]byte{}
+	err = actual.scan(func(row sqliteRow) error {
+		if props, ok := row["properties"].([]byte); ok {
+			actualProps[asString(row["local_relpath"])] = props
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	wc := &wcDB{nodes: map[string]*versioned{}, deleted: map[string]bool{}}
+	for relpath, row := range working {
+		// Caminhos removidos, excluídos ou ausentes no servidor não existem
+		// na árvore de trabalho
+		if row.presence == "base-deleted" {
+			wc.deleted[relpath] = true
+		}
+		if row.presence != "normal" && row.presence != "incomplete" {
+			continue
+		}
+		data := row.props
+		if props, ok := actualProps[relpath]; ok {
+			data = props
+		}
+		props, err := parseProps(data)
+		if err != nil {
+			return nil, fmt.Errorf("'%s': %w", relpath, err)
+		}
+		kind := svn.NodeFile
+		if row.kind == "dir" {
+			kind = svn.NodeDir
+		}
+		wc.nodes[relpath] = &versioned{kind: kind, props: props, baseRev: baseRevs[relpath]}
+	}
+
+	return wc, nil
+}
@@ -5,22 +5,131 @@ package config
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 )
 
 // Config representa a configuração principal da aplicação
 type Config struct {
-	BranchA   BranchConfig `mapstructure:"branchA"`
-	BranchB   BranchConfig `mapstructure:"branchB"`
-	Auth      AuthConfig   `mapstructure:"auth"`
-	Output    string       `mapstructure:"output"`
-	Summarize bool         `mapstructure:"summarize"`
+	BranchA    BranchConfig     `mapstructure:"branchA"`
+	BranchB    BranchConfig     `mapstructure:"branchB"`
+	Auth       AuthConfig       `mapstructure:"auth"`
+	Issue      IssueConfig      `mapstructure:"issue"`
+	Git        GitConfig        `mapstructure:"git"`
+	SideBySide SideBySideConfig `mapstructure:"sideBySide"`
+	Markdown   MarkdownConfig   `mapstructure:"markdown"`
+	Filter     FilterConfig     `mapstructure:"filter"`
+	Renames    RenamesConfig    `mapstructure:"renames"`
+	Binary     BinaryConfig     `mapstructure:"binary"`
+	Ignore     IgnoreConfig     `mapstructure:"ignore"`
+	Backend    string           `mapstructure:"backend"`
+	Output     string           `mapstructure:"output"`
+	OutDir     string           `mapstructure:"outDir"`
+	Template   string           `mapstructure:"template"`
+	ExpandDirs bool             `mapstructure:"expandDirs"`
+	Summarize  bool             `mapstructure:"summarize"`
 }
 
 // BranchConfig contém a configuração para uma branch SVN específica
 type BranchConfig struct {
 	URL       string   `mapstructure:"url"`
 	Revisions []string `mapstructure:"revisions"`
+	Author    string   `mapstructure:"author"`
+	Since     string   `mapstructure:"since"`
+	Until     string   `mapstructure:"until"`
+}
+
+// GitConfig controla a comparação com repositórios Git locais
+type GitConfig struct {
+	// Layout deduz a ref e o caminho do lado Git a partir da URL SVN do
+	// outro lado (trunk, branches/X, tags/T) quando a URL Git não tem ref
+	Layout bool `mapstructure:"layout"`
+}
+
+// SideBySideConfig controla a saída em duas colunas no terminal
+type SideBySideConfig struct {
+	// Width é a largura total em colunas; zero usa a largura do terminal
+	Width int `mapstructure:"width"`
+	// Wrap quebra as linhas longas em vez de truncá-las
+	Wrap bool `mapstructure:"wrap"`
+}
+
+// MinSideBySideWidth é a menor largura aceita para a saída lado a lado
+const MinSideBySideWidth = 40
+
+// MarkdownConfig limita o tamanho do relatório Markdown, para caber em
+// comentários de tickets e páginas de wiki
+type MarkdownConfig struct {
+	MaxFileLines int `mapstructure:"maxFileLines"`
+	MaxBytes     int `mapstructure:"maxBytes"`
+}
+
+// DefaultMarkdownMaxFileLines é a quantidade de linhas de diff exibidas por
+// arquivo quando nenhuma é configurada
+const DefaultMarkdownMaxFileLines = 300
+
+// DefaultMarkdownMaxBytes é o tamanho máximo do relatório quando nenhum é
+// configurado, abaixo do limite de 65536 caracteres dos comentários do GitHub
+const DefaultMarkdownMaxBytes = 60000
+
+// GetMaxFileLines retorna a quantidade de linhas de diff exibidas por arquivo
+func (mc *MarkdownConfig) GetMaxFileLines() int {
+	if mc.MaxFileLines <= 0 {
+		return DefaultMarkdownMaxFileLines
+	}
+	return mc.MaxFileLines
+}
+
+// GetMaxBytes retorna o tamanho máximo do relatório
+func (mc *MarkdownConfig) GetMaxBytes() int {
+	if mc.MaxBytes <= 0 {
+		return DefaultMarkdownMaxBytes
+	}
+	return mc.MaxBytes
+}
+
+// RenamesConfig controla a detecção de renomeações e cópias entre as
+// branches, que sem ela aparecem como remoções e adições independentes
+type RenamesConfig struct {
+	// Detect lê do log da Branch B as cópias registradas (svn copy e svn move)
+	Detect bool `mapstructure:"detect"`
+	// Similarity é a similaridade mínima, em porcentagem, para parear pelo
+	// conteúdo as remoções e adições sem histórico de cópia; zero desativa
+	Similarity int `mapstructure:"similarity"`
+}
+
+// Enabled indica se alguma forma de detecção está ativa
+func (rc *RenamesConfig) Enabled() bool {
+	return rc.Detect || rc.Similarity > 0
+}
+
+// BinaryConfig controla os detalhes dos arquivos binários alterados, que o
+// svn diff só marca com "Cannot display"
+type BinaryConfig struct {
+	// Details relata o tamanho e o SHA-256 de cada lado
+	Details bool `mapstructure:"details"`
+	// ImageSize relata também as dimensões das imagens PNG, GIF e JPEG
+	ImageSize bool `mapstructure:"imageSize"`
+}
+
+// Enabled indica se os arquivos binários devem ser detalhados
+func (bc *BinaryConfig) Enabled() bool {
+	return bc.Details || bc.ImageSize
+}
+
+// IgnoreConfig escolhe as diferenças de espaço em branco desconsideradas no
+// resumo e no diff completo, comuns entre branches mantidas no Windows e no
+// Linux
+type IgnoreConfig struct {
+	// SpaceChange ignora mudanças na quantidade de espaços (reindentação)
+	SpaceChange bool `mapstructure:"spaceChange"`
+	// AllSpace ignora todos os espaços
+	AllSpace bool `mapstructure:"allSpace"`
+	// EOLStyle ignora CRLF contra LF
+	EOLStyle bool `mapstructure:"eolStyle"`
+	// BlankLines ignora linhas em branco adicionadas ou removidas
+	BlankLines bool `mapstructure:"blankLines"`
 }
 
 // AuthConfig contém as credenciais de autenticação para o SVN
@@ -29,6 +138,45 @@ type AuthConfig struct {
 	Password string `mapstructure:"password"`
 }
 
+// IssueConfig define a descoberta automática de revisões a partir de uma
+// chave de issue (ex: PROJ-1234) citada nas mensagens de commit
+type IssueConfig struct {
+	Key     string `mapstructure:"key"`
+	Pattern string `mapstructure:"pattern"`
+	Limit   int    `mapstructure:"limit"`
+}
+
+// DefaultIssuePattern é a expressão regular usada quando nenhuma é configurada.
+// O marcador {key} é substituído pela chave da issue escapada.
+const DefaultIssuePattern = `\b{key}\b`
+
+// DefaultIssueLimit é a quantidade de entradas de log examinadas por branch
+const DefaultIssueLimit = 500
+
+// IssueRegexp compila a expressão regular usada para localizar a issue nas
+// mensagens de commit
+func (ic *IssueConfig) IssueRegexp() (*regexp.Regexp, error) {
+	pattern := ic.Pattern
+	if pattern == "" {
+		pattern = DefaultIssuePattern
+	}
+	pattern = strings.ReplaceAll(pattern, "{key}", regexp.QuoteMeta(ic.Key))
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("expressão regular de issue inválida '%s': %w", ic.Pattern, err)
+	}
+	return re, nil
+}
+
+// GetLimit retorna a janela de entradas de log a examinar por branch
+func (ic *IssueConfig) GetLimit() int {
+	if ic.Limit <= 0 {
+		return DefaultIssueLimit
+	}
+	return ic.Limit
+}
+
 // Validate verifica se a configuração é válida
 func (c *Config) Validate() error {
 	if c.BranchA.URL == "" {
@@ -37,15 +185,58 @@ func (c *Config) Validate() error {
 	if c.BranchB.URL == "" {
 		return fmt.Errorf("URL da Branch B é obrigatória")
 	}
-	if len(c.BranchA.Revisions) == 0 {
+	// As revisões podem ser descobertas a partir da issue ou dos filtros de
+	// log; cópias de trabalho são comparadas no estado atual do disco e
+	// repositórios Git na ref da URL
+	if len(c.BranchA.Revisions) == 0 && c.Issue.Key == "" && !c.BranchA.HasLogFilters() && c.BranchA.HasRevisions() {
 		return fmt.Errorf("pelo menos uma revisão da Branch A é obrigatória")
 	}
-	if len(c.BranchB.Revisions) == 0 {
+	if len(c.BranchB.Revisions) == 0 && c.Issue.Key == "" && !c.BranchB.HasLogFilters() && c.BranchB.HasRevisions() {
 		return fmt.Errorf("pelo menos uma revisão da Branch B é obrigatória")
 	}
+	if err := c.BranchA.validateGit(); err != nil {
+		return fmt.Errorf("Branch A: %w", err)
+	}
+	if err := c.BranchB.validateGit(); err != nil {
+		return fmt.Errorf("Branch B: %w", err)
+	}
+	if c.Git.Layout && c.BranchA.IsGit() == c.BranchB.IsGit() {
+		return fmt.Errorf("o mapeamento de layout Git exige exatamente um lado Git")
+	}
+	if c.BranchA.IsLocalPath() && c.BranchA.HasLogFilters() {
+		return fmt.Errorf("Branch A: filtros de log não se aplicam a cópias de trabalho")
+	}
+	if c.BranchB.IsLocalPath() && c.BranchB.HasLogFilters() {
+		return fmt.Errorf("Branch B: filtros de log não se aplicam a cópias de trabalho")
+	}
+	if c.Issue.Key != "" {
+		if _, err := c.Issue.IssueRegexp(); err != nil {
+			return err
+		}
+	}
+	if err := c.BranchA.validateDates(); err != nil {
+		return fmt.Errorf("Branch A: %w", err)
+	}
+	if err := c.BranchB.validateDates(); err != nil {
+		return fmt.Errorf("Branch B: %w", err)
+	}
+
+	// Valida o backend de acesso aos repositórios
+	validBackends := []string{"", "auto", "cli", "native"}
+	validBackend := false
+	for _, backend := range validBackends {
+		if c.Backend == backend {
+			validBackend = true
+			break
+		}
+	}
+	if !validBackend {
+		return fmt.Errorf("backend inválido '%s'. Opções válidas: %s",
+			c.Backend, strings.Join(validBackends[1:], ", "))
+	}
 
 	// Valida o formato de saída
-	validOutputs := []string{"list", "diff", "json"}
+	validOutputs := []string{"list", "diff", "json", "ndjson", "patch", "git-patch", "html", "side-by-side", "markdown", "template", "junit", "sarif"}
 	valid := false
 	for _, validOutput := range validOutputs {
 		if c.Output == validOutput {
@@ -57,6 +248,24 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("formato de saída inválido '%s'. Opções válidas: %s",
 			c.Output, strings.Join(validOutputs, ", "))
 	}
+	if c.OutDir != "" && c.Output != "patch" && c.Output != "git-patch" {
+		return fmt.Errorf("o diretório de saída só se aplica aos formatos patch e git-patch")
+	}
+	if c.Output == "template" && c.Template == "" {
+		return fmt.Errorf("o formato template exige o arquivo de modelo (--template)")
+	}
+	if c.Template != "" && c.Output != "template" {
+		return fmt.Errorf("o arquivo de modelo só se aplica ao formato template")
+	}
+	if c.SideBySide.Width != 0 && c.SideBySide.Width < MinSideBySideWidth {
+		return fmt.Errorf("largura inválida %d: o mínimo é %d colunas", c.SideBySide.Width, MinSideBySideWidth)
+	}
+	if _, err := c.Filter.Matcher(); err != nil {
+		return err
+	}
+	if c.Renames.Similarity < 0 || c.Renames.Similarity > 100 {
+		return fmt.Errorf("similaridade inválida %d: use um valor de 0 a 100", c.Renames.Similarity)
+	}
 
 	return nil
 }
@@ -82,3 +291,95 @@ func (bc *BranchConfig) GetRevisionRange() string {
 	}
 	return fmt.Sprintf("%s:%s", bc.Revisions[0], bc.Revisions[len(bc.Revisions)-1])
 }
+
+// IsLocalPath indica se a branch é um caminho local (uma cópia de trabalho)
+// em vez de uma URL
+func (bc *BranchConfig) IsLocalPath() bool {
+	return bc.URL != "" && !strings.Contains(bc.URL, "://") && !bc.IsGit()
+}
+
+// GitScheme é o prefixo das URLs de repositórios Git locais
+const GitScheme = "git+file:"
+
+// IsGit indica se a branch é um repositório Git local (git+file:)
+func (bc *BranchConfig) IsGit() bool {
+	return strings.HasPrefix(bc.URL, GitScheme)
+}
+
+// HasRevisions indica se a branch é comparada em revisões SVN. Cópias de
+// trabalho e repositórios Git têm um único estado.
+func (bc *BranchConfig) HasRevisions() bool {
+	return !bc.IsLocalPath() && !bc.IsGit()
+}
+
+// validateGit rejeita revisões e filtros de log em repositórios Git, cuja
+// versão é escolhida pela ref da URL
+func (bc *BranchConfig) validateGit() error {
+	if !bc.IsGit() {
+		return nil
+	}
+	if len(bc.Revisions) > 0 || bc.HasLogFilters() {
+		return fmt.Errorf("repositórios Git não usam revisões nem filtros de log; indique a ref na URL (?ref=)")
+	}
+	return nil
+}
+
+// HasLogFilters indica se a branch define filtros (autor ou datas) que devem
+// ser resolvidos via svn log em uma lista de revisões
+func (bc *BranchConfig) HasLogFilters() bool {
+	return bc.Author != "" || bc.Since != "" || bc.Until != ""
+}
+
+// SinceTime retorna o início (inclusivo) da janela de datas, ou o valor zero
+// quando não definido
+func (bc *BranchConfig) SinceTime() (time.Time, error) {
+	if bc.Since == "" {
+		return time.Time{}, nil
+	}
+	t, _, err := parseDate(bc.Since)
+	return t, err
+}
+
+// UntilTime retorna o fim (exclusivo) da janela de datas, ou o valor zero
+// quando não definido. Datas sem horário incluem o dia inteiro.
+func (bc *BranchConfig) UntilTime() (time.Time, error) {
+	if bc.Until == "" {
+		return time.Time{}, nil
+	}
+	t, dateOnly, err := parseDate(bc.Until)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if dateOnly {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// validateDates verifica os filtros de data da branch
+func (bc *BranchConfig) validateDates() error {
+	since, err := bc.SinceTime()
+	if err != nil {
+		return err
+	}
+	until, err := bc.UntilTime()
+	if err != nil {
+		return err
+	}
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		return fmt.Errorf("data inicial '%s' deve ser anterior à data final '%s'", bc.Since, bc.Until)
+	}
+	return nil
+}
+
+// parseDate interpreta datas nos formatos AAAA-MM-DD e RFC 3339, indicando
+// se a data foi informada sem horário
+func parseDate(value string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("data inválida '%s'. Use AAAA-MM-DD ou RFC 3339", value)
+}
@@ -40,6 +40,197 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "revisões descobertas pela issue",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/branchA"},
+				BranchB: BranchConfig{URL: "https://svn.example.com/branchB"},
+				Issue:   IssueConfig{Key: "PROJ-1234"},
+				Output:  "list",
+			},
+			wantErr: false,
+		},
+		{
+			name: "revisões descobertas por autor e datas",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/branchA", Author: "alice", Since: "2024-03-01"},
+				BranchB: BranchConfig{URL: "https://svn.example.com/branchB", Revisions: []string{"124"}},
+				Output:  "list",
+			},
+			wantErr: false,
+		},
+		{
+			name: "janela de datas invertida",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/branchA", Since: "2024-03-10", Until: "2024-03-01"},
+				BranchB: BranchConfig{URL: "https://svn.example.com/branchB", Revisions: []string{"124"}},
+				Output:  "list",
+			},
+			wantErr: true,
+		},
+		{
+			name: "data inválida",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/branchA", Since: "01/03/2024"},
+				BranchB: BranchConfig{URL: "https://svn.example.com/branchB", Revisions: []string{"124"}},
+				Output:  "list",
+			},
+			wantErr: true,
+		},
+		{
+			name: "expressão regular de issue inválida",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/branchA"},
+				BranchB: BranchConfig{URL: "https://svn.example.com/branchB"},
+				Issue:   IssueConfig{Key: "PROJ-1234", Pattern: "({key}"},
+				Output:  "list",
+			},
+			wantErr: true,
+		},
+		{
+			name: "cópia de trabalho sem revisões",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/branchA", Revisions: []string{"123"}},
+				BranchB: BranchConfig{URL: "./meu-checkout"},
+				Output:  "list",
+			},
+			wantErr: false,
+		},
+		{
+			name: "filtros de log em cópia de trabalho",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/branchA", Revisions: []string{"123"}},
+				BranchB: BranchConfig{URL: "/home/dev/checkout", Author: "alice"},
+				Output:  "list",
+			},
+			wantErr: true,
+		},
+		{
+			name: "repositório Git sem revisões",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/trunk", Revisions: []string{"123"}},
+				BranchB: BranchConfig{URL: "git+file:///srv/mirror?ref=main"},
+				Output:  "list",
+			},
+			wantErr: false,
+		},
+		{
+			name: "caminho Git relativo não é cópia de trabalho",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/trunk", Revisions: []string{"123"}},
+				BranchB: BranchConfig{URL: "git+file:mirror", Author: "alice"},
+				Output:  "list",
+			},
+			wantErr: true,
+		},
+		{
+			name: "revisões em repositório Git",
+			config: Config{
+				BranchA: BranchConfig{URL: "git+file:///srv/mirror", Revisions: []string{"10"}},
+				BranchB: BranchConfig{URL: "https://svn.example.com/trunk", Revisions: []string{"123"}},
+				Output:  "list",
+			},
+			wantErr: true,
+		},
+		{
+			name: "layout Git sem lado Git",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/trunk", Revisions: []string{"123"}},
+				BranchB: BranchConfig{URL: "https://svn.example.com/branches/x", Revisions: []string{"124"}},
+				Git:     GitConfig{Layout: true},
+				Output:  "list",
+			},
+			wantErr: true,
+		},
+		{
+			name: "backend inválido",
+			config: Config{
+				BranchA: BranchConfig{URL: "svn://svn.example.com/branchA", Revisions: []string{"123"}},
+				BranchB: BranchConfig{URL: "svn://svn.example.com/branchB", Revisions: []string{"124"}},
+				Backend: "ftp",
+				Output:  "list",
+			},
+			wantErr: true,
+		},
+		{
+			name: "patches por revisão",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/branchA", Revisions: []string{"123"}},
+				BranchB: BranchConfig{URL: "https://svn.example.com/branchB", Revisions: []string{"124", "130"}},
+				Output:  "patch",
+				OutDir:  "patches",
+			},
+			wantErr: false,
+		},
+		{
+			name: "diretório de saída fora do formato patch",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/branchA", Revisions: []string{"123"}},
+				BranchB: BranchConfig{URL: "https://svn.example.com/branchB", Revisions: []string{"124"}},
+				Output:  "diff",
+				OutDir:  "patches",
+			},
+			wantErr: true,
+		},
+		{
+			name: "modelo do usuário",
+			config: Config{
+				BranchA:  BranchConfig{URL: "https://svn.example.com/branchA", Revisions: []string{"123"}},
+				BranchB:  BranchConfig{URL: "https://svn.example.com/branchB", Revisions: []string{"124"}},
+				Output:   "template",
+				Template: "relatorio.tmpl",
+			},
+			wantErr: false,
+		},
+		{
+			name: "formato template sem modelo",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/branchA", Revisions: []string{"123"}},
+				BranchB: BranchConfig{URL: "https://svn.example.com/branchB", Revisions: []string{"124"}},
+				Output:  "template",
+			},
+			wantErr: true,
+		},
+		{
+			name: "modelo fora do formato template",
+			config: Config{
+				BranchA:  BranchConfig{URL: "https://svn.example.com/branchA", Revisions: []string{"123"}},
+				BranchB:  BranchConfig{URL: "https://svn.example.com/branchB", Revisions: []string{"124"}},
+				Output:   "json",
+				Template: "relatorio.tmpl",
+			},
+			wantErr: true,
+		},
+		{
+			name: "lado a lado com largura",
+			config: Config{
+				BranchA:    BranchConfig{URL: "https://svn.example.com/branchA", Revisions: []string{"123"}},
+				BranchB:    BranchConfig{URL: "https://svn.example.com/branchB", Revisions: []string{"124"}},
+				Output:     "side-by-side",
+				SideBySide: SideBySideConfig{Width: 160, Wrap: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "largura lado a lado pequena demais",
+			config: Config{
+				BranchA:    BranchConfig{URL: "https://svn.example.com/branchA", Revisions: []string{"123"}},
+				BranchB:    BranchConfig{URL: "https://svn.example.com/branchB", Revisions: []string{"124"}},
+				Output:     "side-by-side",
+				SideBySide: SideBySideConfig{Width: 20},
+			},
+			wantErr: true,
+		},
+		{
+			name: "similaridade de renomeação acima de 100%",
+			config: Config{
+				BranchA: BranchConfig{URL: "https://svn.example.com/branchA", Revisions: []string{"123"}},
+				BranchB: BranchConfig{URL: "https://svn.example.com/branchB", Revisions: []string{"124"}},
+				Output:  "list",
+				Renames: RenamesConfig{Detect: true, Similarity: 150},
+			},
+			wantErr: true,
+		},
 		{
 			name: "formato de saída inválido",
 			config: Config{
@@ -141,3 +332,29 @@ func TestBranchConfig_GetRevisionRange(t *testing.T) {
 		})
 	}
 }
+
+func TestIssueConfig_IssueRegexp(t *testing.T) {
+	tests := []struct {
+		name    string
+		issue   IssueConfig
+		message string
+		want    bool
+	}{
+		{"padrão casa chave exata", IssueConfig{Key: "PROJ-1234"}, "PROJ-1234: corrige bug", true},
+		{"padrão não casa prefixo", IssueConfig{Key: "PROJ-12"}, "PROJ-1234: corrige bug", false},
+		{"padrão personalizado", IssueConfig{Key: "1234", Pattern: `#{key}\b`}, "refs #1234", true},
+		{"padrão sem marcador", IssueConfig{Key: "X", Pattern: `(?i)hotfix`}, "HotFix urgente", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := tt.issue.IssueRegexp()
+			if err != nil {
+				t.Fatalf("IssueRegexp() error = %v", err)
+			}
+			if got := re.MatchString(tt.message); got != tt.want {
+				t.Errorf("IssueRegexp().MatchString(%q) = %v, want %v", tt.message, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FilterConfig seleciona os caminhos avaliados pelos relatórios de CI.
+// Os padrões são globs: "*" e "?" não cruzam "/", "**" cruza diretórios e
+// padrões sem "/" são comparados com o nome do arquivo.
+type FilterConfig struct {
+	// Include restringe a comparação aos caminhos que casam com algum padrão
+	Include []string `mapstructure:"include"`
+	// Exclude descarta os caminhos que casam com algum padrão
+	Exclude []string `mapstructure:"exclude"`
+	// Allow lista as diferenças esperadas, que não são tratadas como falha
+	Allow []string `mapstructure:"allow"`
+}
+
+// PathMatcher aplica os padrões compilados de um FilterConfig
+type PathMatcher struct {
+	include, exclude, allow []*regexp.Regexp
+}
+
+// Matcher compila os padrões do filtro
+func (fc *FilterConfig) Matcher() (*PathMatcher, error) {
+	var m PathMatcher
+	var err error
+	if m.include, err = compileGlobs(fc.Include); err != nil {
+		return nil, err
+	}
+	if m.exclude, err = compileGlobs(fc.Exclude); err != nil {
+		return nil, err
+	}
+	if m.allow, err = compileGlobs(fc.Allow); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Selects indica se o caminho é avaliado: casa com algum padrão de Include
+// (ou Include está vazio) e com nenhum de Exclude
+func (m *PathMatcher) Selects(path string) bool {
+	return (len(m.include) == 0 || matchAny(m.include, path)) && !matchAny(m.exclude, path)
+}
+
+// Allows indica se a diferença no caminho é esperada
+func (m *PathMatcher) Allows(path string) bool {
+	return matchAny(m.allow, path)
+}
+
+func matchAny(patterns []*regexp.Regexp, path string) bool {
+	for _, re := range patterns {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileGlobs(globs []string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		re, err := globRegexp(glob)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, nil
+}
+
+// globRegexp converte o glob em uma expressão regular ancorada
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	if strings.TrimSpace(glob) == "" {
+		return nil, fmt.Errorf("padrão de caminho vazio")
+	}
+	var b strings.Builder
+	if !strings.Contains(glob, "/") {
+		// sem diretório, o padrão vale para o nome em qualquer nível
+		b.WriteString("^(?:.*/)?")
+	} else {
+		b.WriteString("^")
+	}
+	pattern := strings.TrimPrefix(glob, "/")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("padrão de caminho inválido '%s': %w", glob, err)
+	}
+	return re, nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import "testing"
+
+func TestPathMatcher(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      FilterConfig
+		path        string
+		wantSelects bool
+		wantAllows  bool
+	}{
+		{"sem filtros", FilterConfig{}, "src/main.go", true, false},
+		{"include casa", FilterConfig{Include: []string{"src/**"}}, "src/app/main.go", true, false},
+		{"include não casa", FilterConfig{Include: []string{"src/**"}}, "docs/guia.md", false, false},
+		{"nome em qualquer nível", FilterConfig{Exclude: []string{"*.lock"}}, "web/deps/yarn.lock", false, false},
+		{"asterisco não cruza diretórios", FilterConfig{Exclude: []string{"src/*.go"}}, "src/app/main.go", true, false},
+		{"** no meio do padrão", FilterConfig{Exclude: []string{"src/**/gen/*.go"}}, "src/gen/api.go", false, false},
+		{"exclude vence include", FilterConfig{Include: []string{"src/**"}, Exclude: []string{"**/*_test.go"}}, "src/a_test.go", false, false},
+		{"interrogação e ponto literal", FilterConfig{Include: []string{"v?.txt"}}, "v1.txt", true, false},
+		{"ponto não é curinga", FilterConfig{Include: []string{"v1.txt"}}, "v1-txt", false, false},
+		{"barra inicial", FilterConfig{Allow: []string{"/CHANGELOG.md"}}, "CHANGELOG.md", true, true},
+		{"allowlist", FilterConfig{Allow: []string{"docs/**"}}, "docs/a/b.md", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := tt.filter.Matcher()
+			if err != nil {
+				t.Fatalf("Matcher() error = %v", err)
+			}
+			if got := m.Selects(tt.path); got != tt.wantSelects {
+				t.Errorf("Selects(%q) = %v, want %v", tt.path, got, tt.wantSelects)
+			}
+			if got := m.Allows(tt.path); got != tt.wantAllows {
+				t.Errorf("Allows(%q) = %v, want %v", tt.path, got, tt.wantAllows)
+			}
+		})
+	}
+
+	if _, err := (&FilterConfig{Exclude: []string{" "}}).Matcher(); err == nil {
+		t.Error("Matcher() com padrão vazio deveria falhar")
+	}
+}